@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/db/seeds"
+	appConfig "MyMoneyBackend/internal/config"
+	"MyMoneyBackend/internal/domain"
+)
+
+// usage describe los flags y subcomandos disponibles
+const usage = `Uso: main [flags] [comando]
+
+Sin comando inicia el servidor HTTP.
+
+Flags (tienen prioridad sobre el archivo y las variables de entorno):
+  -config archivo      Archivo de configuración YAML (CONFIG_FILE)
+  -port puerto         Puerto del servidor HTTP (PORT)
+  -db-driver driver    Base de datos: postgres o sqlite (DB_DRIVER)
+  -sqlite-path ruta    Archivo de la base de datos SQLite (SQLITE_PATH)
+
+Comandos:
+  migrate            Aplica las migraciones pendientes
+  migrate status     Muestra el estado de las migraciones
+  migrate rollback   Revierte la última migración aplicada
+  seed               Carga los datos iniciales (requiere las migraciones aplicadas)
+  config             Muestra la configuración efectiva con los secretos ocultos y la valida
+`
+
+// runCommand ejecuta el subcomando indicado en args y termina el proceso si falla
+func runCommand(cfg *domain.Config, args []string) {
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "migrate" && len(args) == 1:
+		migrator := newMigrator(cfg)
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Error applying migrations: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+		for _, migration := range applied {
+			log.Printf("Applied %04d_%s", migration.Version, migration.Name)
+		}
+
+	case args[0] == "migrate" && len(args) == 2 && args[1] == "status":
+		statuses, err := newMigrator(cfg).Status(ctx)
+		if err != nil {
+			log.Fatalf("Error reading migration status: %v", err)
+		}
+		printStatus(statuses)
+
+	case args[0] == "migrate" && len(args) == 2 && args[1] == "rollback":
+		reverted, err := newMigrator(cfg).Rollback(ctx)
+		if err != nil {
+			log.Fatalf("Error rolling back migration: %v", err)
+		}
+		if reverted == nil {
+			log.Println("No migrations to roll back")
+			return
+		}
+		log.Printf("Rolled back %04d_%s", reverted.Version, reverted.Name)
+
+	case args[0] == "seed" && len(args) == 1:
+		dbConn, err := config.NewConnection(cfg.Database)
+		if err != nil {
+			log.Fatalf("Error connecting to database: %v", err)
+		}
+		var fsys fs.FS = seeds.FS
+		if dbConn.Driver() == config.DriverSQLite {
+			fsys = seeds.SQLite
+		}
+		executed, err := migrate.Seed(ctx, dbConn.GetDB(), fsys)
+		for _, name := range executed {
+			log.Printf("Seeded %s", name)
+		}
+		if err != nil {
+			log.Fatalf("Error seeding database: %v", err)
+		}
+
+	case args[0] == "config" && len(args) == 1:
+		if err := appConfig.Write(os.Stdout, cfg); err != nil {
+			log.Fatalf("Error printing configuration: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("Invalid configuration:\n%v", err)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// newMigrator conecta a la base de datos y carga las migraciones embebidas de su motor
+func newMigrator(cfg *domain.Config) *migrate.Migrator {
+	dbConn, err := config.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	dialect, fsys := migrate.Postgres, fs.FS(migrations.FS)
+	if dbConn.Driver() == config.DriverSQLite {
+		dialect, fsys = migrate.SQLite, migrations.SQLite
+	}
+
+	migrator, err := migrate.New(dbConn.GetDB(), dialect, fsys)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+	return migrator
+}
+
+// printStatus imprime una tabla con el estado de cada migración
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case status.Missing:
+			state = "applied (file missing)"
+		case status.Modified:
+			state = "applied (modified)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
@@ -1,21 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"MyMoneyBackend/db/config"
-	"MyMoneyBackend/internal/application/auth"
-	categoryService "MyMoneyBackend/internal/application/category"
-	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
-	transactionService "MyMoneyBackend/internal/application/transaction"
-	userService "MyMoneyBackend/internal/application/user"
-	"MyMoneyBackend/internal/domain/ports/app"
+	appConfig "MyMoneyBackend/internal/config"
+	"MyMoneyBackend/internal/container"
+	middlewares "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 	"MyMoneyBackend/internal/infraestructure/inbound/httprest/routers"
-	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/server"
+	"MyMoneyBackend/internal/logging"
+	"MyMoneyBackend/internal/tracing"
 )
 
 // @title MyMoney Backend API
@@ -46,46 +52,105 @@ func main() {
 		log.Println("Warning: Error loading .env file")
 	}
 
-	// Configurar modo de Gin
-	if os.Getenv("GIN_MODE") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+	// Cargar configuración (valores por defecto, archivo, entorno y flags)
+	cfg, args, err := appConfig.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// Ejecutar subcomandos (migrate, seed, config) en lugar del servidor
+	if len(args) > 0 {
+		runCommand(cfg, args)
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	// Logs estructurados en JSON; el paquete log también escribe a través de este logger
+	level, _ := logging.ParseLevel(cfg.Log.Level) // ya validado
+	logger := logging.New(os.Stderr, level)
+	slog.SetDefault(logger)
+
+	// Trazas de OpenTelemetry hacia OTLP o stdout (TRACING_EXPORTER)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		fatal("error al configurar las trazas", err)
 	}
 
+	// Configurar modo de Gin
+	gin.SetMode(cfg.Server.Mode)
+
 	// Inicializar conexión a la base de datos
-	dbConn, err := config.NewConnection()
+	dbConn, err := config.NewConnection(cfg.Database)
+	if err != nil {
+		fatal("error al conectar con la base de datos", err)
+	}
+
+	// Construir repositorios, servicios y handlers una sola vez sobre la misma conexión
+	c, err := container.New(cfg, dbConn)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		dbConn.Close()
+		fatal("error al construir la aplicación", err)
 	}
-	db := dbConn.GetDB()
 
-	// Inicializar repositorios
-	userRepo := repository.NewUserRepository(db)
-	var categoryRepo app.CategoryRepository = repository.NewCategoryRepository(db)
-	var paymentMethodRepo app.PaymentMethodRepository = repository.NewPaymentMethodRepository(db)
-	var transactionRepo app.TransactionRepository = repository.NewTransactionRepository(db)
+	// SIGINT/SIGTERM cancelan ctx: se deja de servir y se detienen las tareas de fondo
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Inicializar servicios
-	tokenService := auth.NewTokenService()
-	userSvc := userService.NewUserService(userRepo)
-	categorySvc := categoryService.NewService(categoryRepo)
-	paymentMethodSvc := paymentMethodService.NewService(paymentMethodRepo)
-	transactionSvc := transactionService.NewService(transactionRepo)
+	// Iniciar planificador de suscripciones (renovaciones, reintentos de cobro y expiraciones)
+	c.Start(ctx)
+	if c.Scheduler != nil {
+		slog.Info("planificador de suscripciones iniciado", "interval", cfg.Scheduler.Interval.String())
+	}
 
-	// Inicializar router
-	r := gin.Default()
+	// Inicializar router: ID de petición, span por petición, log por petición y recuperación de panics
+	r := gin.New()
+	// La IP del cliente (límites por IP, logs) solo se toma de X-Forwarded-For si viene de un proxy de confianza
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("proxies de confianza no válidos", err)
+	}
+	r.Use(middlewares.RequestID(), middlewares.Tracing(), middlewares.RequestLogger(logger), middlewares.Recovery(logger))
 
 	// Configurar rutas de la API
-	routers.SetupRouter(r, userSvc, categorySvc, paymentMethodSvc, transactionSvc, tokenService)
+	routers.SetupRouter(r, c.Handlers, c.Middlewares)
 
 	// Iniciar servidor
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := cfg.Server.Port
+	slog.Info("servidor iniciado", "port", port, "swagger", fmt.Sprintf("http://localhost:%d/swagger/index.html", port))
+	srv := server.New(cfg.Server, r)
+	// Dejar de anunciarse como listo antes de esperar a las peticiones en curso
+	serveErr := server.Run(ctx, srv, cfg.Server.ShutdownTimeout, func() {
+		c.Handlers.Health.SetReady(false)
+	})
+	if serveErr != nil {
+		slog.Error("error en el servidor", "error", serveErr)
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Printf("Swagger documentation available at http://localhost:%s/swagger/index.html", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Detener las tareas de fondo aunque el servidor haya fallado, y cerrar la conexión
+	stop()
+	if err := c.Close(); err != nil {
+		slog.Error("error al cerrar la aplicación", "error", err)
 	}
+
+	// Enviar las trazas pendientes
+	tracingCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		slog.Error("error al enviar las trazas pendientes", "error", err)
+	}
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
+
+// fatal registra err y termina el proceso
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"MyMoneyBackend/db/config"
+	appConfig "MyMoneyBackend/internal/config"
 
 	"github.com/joho/godotenv"
 )
@@ -164,66 +165,39 @@ func testRESTAPI() {
 	}
 }
 
-// Prueba la conexión directa a la base de datos de Supabase
+// Prueba la conexión directa a la base de datos configurada
 func testDatabaseConnection() {
-	// Configurar variables de entorno para la conexión
-	if os.Getenv("SUPABASE_HOST") == "" {
-		os.Setenv("SUPABASE_HOST", "aws-0-us-east-2.pooler.supabase.com")
-		fmt.Println("SUPABASE_HOST not set, using default: aws-0-us-east-2.pooler.supabase.com")
-	}
-
-	if os.Getenv("SUPABASE_PORT") == "" {
-		os.Setenv("SUPABASE_PORT", "6543")
-		fmt.Println("SUPABASE_PORT not set, using default: 6543")
-	}
-
-	if os.Getenv("SUPABASE_USER") == "" {
-		os.Setenv("SUPABASE_USER", "postgres.eddrisdxafllaekoaduy")
-		fmt.Println("SUPABASE_USER not set, using default: postgres.eddrisdxafllaekoaduy")
-	}
-
-	if os.Getenv("SUPABASE_DBNAME") == "" {
-		os.Setenv("SUPABASE_DBNAME", "postgres")
-		fmt.Println("SUPABASE_DBNAME not set, using default: postgres")
+	cfg, _, err := appConfig.Load(nil)
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
 	}
-
-	// Verificar que la contraseña esté configurada
-	if os.Getenv("SUPABASE_PASSWORD") == "" {
-		log.Fatal("SUPABASE_PASSWORD environment variable must be set")
+	if err := cfg.Database.Validate(); err != nil {
+		log.Fatalf("Invalid database configuration:\n%v", err)
 	}
 
-	// Intentar crear una conexión a la base de datos usando el cliente de Supabase
-	fmt.Println("\nTesting Supabase database connection...")
+	fmt.Println("\nTesting database connection...")
 
 	// Mostrar información de conexión (sin contraseña)
-	host := os.Getenv("SUPABASE_HOST")
-	port := os.Getenv("SUPABASE_PORT")
-	user := os.Getenv("SUPABASE_USER")
-	dbname := os.Getenv("SUPABASE_DBNAME")
-
-	fmt.Printf("Connection info: host=%s port=%s user=%s dbname=%s\n",
-		host, port, user, dbname)
+	fmt.Printf("Connection info: driver=%s host=%s port=%d user=%s dbname=%s\n",
+		cfg.Database.Driver, cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Name)
 
-	// Configurar timeout para diagnóstico
-	fmt.Println("Attempting connection with 10 second timeout...")
 	connectionStart := time.Now()
 
-	// Intentar conexión directa con el cliente Supabase
-	client, err := config.NewSupabaseClient()
+	conn, err := config.NewConnection(cfg.Database)
 	if err != nil {
 		connectionDuration := time.Since(connectionStart)
 		fmt.Printf("Connection failed after %v\n", connectionDuration)
-		log.Fatalf("Error connecting to Supabase: %v", err)
+		log.Fatalf("Error connecting to database: %v", err)
 	}
-	defer client.Close()
+	defer conn.Close()
 
 	connectionDuration := time.Since(connectionStart)
 	fmt.Printf("Connection established in %v\n", connectionDuration)
 
 	// Verificar que la conexión funcione con una consulta simple
 	queryStart := time.Now()
-	result, err := client.ExecuteQuery()
-	if err != nil {
+	var result int
+	if err := conn.GetDB().QueryRow("SELECT 1").Scan(&result); err != nil {
 		log.Fatalf("Error executing test query: %v", err)
 	}
 	queryDuration := time.Since(queryStart)
@@ -3,75 +3,82 @@ package config
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"net/url"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/sqlite"
 
 	_ "github.com/lib/pq"
 )
 
-// Connection represents a PostgreSQL connection
+// Supported values for the database driver setting
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Connection represents a database connection and the driver behind it
 type Connection struct {
-	db *sql.DB
+	db     *sql.DB
+	driver string
 }
 
-// NewConnection creates a new PostgreSQL connection using Supabase credentials
-func NewConnection() (*Connection, error) {
-	// Try to use SupabaseClient first
-	supabaseClient, err := NewSupabaseClient()
-	if err == nil {
-		log.Println("Using Supabase client connection")
-		return &Connection{db: supabaseClient.GetDB()}, nil
+// NewConnection opens the database selected by cfg.Driver: postgres or sqlite
+func NewConnection(cfg domain.DatabaseConfig) (*Connection, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
 	}
 
-	// If Supabase client fails, fall back to direct connection
-	log.Printf("Supabase client connection failed: %v", err)
-	log.Println("Attempting direct PostgreSQL connection...")
-
-	// Get connection parameters from environment variables
-	host := os.Getenv("SUPABASE_HOST")
-	port := os.Getenv("SUPABASE_PORT")
-	user := os.Getenv("SUPABASE_USER")
-	password := os.Getenv("SUPABASE_PASSWORD")
-	dbname := os.Getenv("SUPABASE_DBNAME")
-	sslmode := os.Getenv("SUPABASE_SSLMODE")
-	apiKey := os.Getenv("SUPABASE_API_KEY")
-
-	// Check required parameters
-	if host == "" || port == "" || user == "" || password == "" || dbname == "" {
-		return nil, fmt.Errorf("missing required database connection parameters")
+	switch cfg.Driver {
+	case DriverSQLite:
+		return newSQLiteConnection(cfg)
+	default:
+		return newPostgresConnection(cfg)
 	}
+}
 
-	if sslmode == "" {
-		sslmode = "require" // Default to require SSL for Supabase
+// newSQLiteConnection opens the SQLite database file at cfg.SQLitePath
+func newSQLiteConnection(cfg domain.DatabaseConfig) (*Connection, error) {
+	slog.Info("conectando a SQLite", "path", cfg.SQLitePath)
+	db, err := sqlite.Open(cfg.SQLitePath)
+	if err != nil {
+		return nil, err
 	}
 
-	var connStr string
+	slog.Info("conectado a la base de datos SQLite")
+	return &Connection{db: db, driver: DriverSQLite}, nil
+}
 
-	// If API Key is provided, use it for connection
-	if apiKey != "" {
+// newPostgresConnection creates a new PostgreSQL (or Supabase) connection
+func newPostgresConnection(cfg domain.DatabaseConfig) (*Connection, error) {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:   cfg.Name,
+	}
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	if cfg.APIKey != "" {
 		// For Supabase with API key
-		log.Println("Using Supabase API Key for connection")
-		connStr = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s&options=apikey=%s",
-			user, password, host, port, dbname, sslmode, apiKey)
-	} else {
-		// Standard PostgreSQL connection
-		log.Println("Using standard PostgreSQL connection")
-		connStr = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			host, port, user, password, dbname, sslmode)
+		slog.Info("usando la API key de Supabase para la conexión")
+		query.Set("options", "apikey="+cfg.APIKey)
 	}
+	dsn.RawQuery = query.Encode()
 
 	// Log connection info (without password/api key for security)
-	log.Printf("Connecting to PostgreSQL: host=%s port=%s dbname=%s", host, port, dbname)
+	slog.Info("conectando a PostgreSQL", "host", cfg.Host, "port", cfg.Port, "dbname", cfg.Name)
 
 	// Open connection to database
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", dsn.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxOpenConns)
 
 	// Test connection
 	if err := db.Ping(); err != nil {
@@ -79,8 +86,8 @@ func NewConnection() (*Connection, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Connected to PostgreSQL database")
-	return &Connection{db: db}, nil
+	slog.Info("conectado a la base de datos PostgreSQL")
+	return &Connection{db: db, driver: DriverPostgres}, nil
 }
 
 // GetDB returns the database connection
@@ -88,6 +95,11 @@ func (c *Connection) GetDB() *sql.DB {
 	return c.db
 }
 
+// Driver returns the driver of the connection: DriverPostgres or DriverSQLite
+func (c *Connection) Driver() string {
+	return c.driver
+}
+
 // Close closes the database connection
 func (c *Connection) Close() error {
 	return c.db.Close()
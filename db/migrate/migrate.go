@@ -0,0 +1,417 @@
+// Package migrate aplica, revierte y reporta las migraciones versionadas de la base de datos
+// y ejecuta los datos iniciales.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockKey identifica el advisory lock que serializa las migraciones entre instancias.
+// Debe ser distinto de las claves del paquete lock.
+const LockKey int64 = 727002
+
+// Dialect es el motor de base de datos sobre el que se aplican las migraciones
+type Dialect string
+
+const (
+	// Postgres serializa las migraciones entre instancias con un advisory lock
+	Postgres Dialect = "postgres"
+	// SQLite no necesita bloqueo: la base de datos es local a una sola instancia
+	SQLite Dialect = "sqlite"
+)
+
+// migrationFile reconoce los nombres NNNN_nombre.up.sql y NNNN_nombre.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// seedFile reconoce los nombres NNNN_nombre.sql de los datos iniciales
+var seedFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Migration es una versión del esquema con su script de aplicación y de reversión
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 del script de aplicación
+}
+
+// Status es el estado de una migración en la base de datos
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // El archivo cambió después de aplicarse
+	Missing   bool // Está aplicada pero ya no existe el archivo
+}
+
+// Load lee y ordena las migraciones de fsys. Cada versión debe tener un archivo .up.sql
+// y un .down.sql, y las versiones no pueden repetirse.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer migraciones: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("versión de migración no válida en %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error al leer %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("la versión %d tiene dos nombres: %s y %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			migration.Checksum = checksum(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("la migración %d_%s no tiene archivo .up.sql", migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("la migración %d_%s no tiene archivo .down.sql", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// checksum calcula el SHA-256 en hexadecimal de un script
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// appliedMigration es una fila de schema_migrations
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator aplica las migraciones sobre una base de datos PostgreSQL o SQLite
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// New crea un Migrator con las migraciones de fsys, que deben estar escritas para dialect
+func New(db *sql.DB, dialect Dialect, fsys fs.FS) (*Migrator, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("motor de base de datos no soportado: %q", dialect)
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Up aplica en orden las migraciones pendientes, cada una en su propia transacción.
+// Falla sin aplicar nada si una migración ya aplicada fue modificada o eliminada.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Rollback revierte la última migración aplicada. Devuelve nil si no hay ninguna.
+func (m *Migrator) Rollback(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = &migration
+			return nil
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status devuelve el estado de cada migración, incluidas las aplicadas cuyo archivo ya no existe
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int64]bool, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = true
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if row, ok := done[migration.Version]; ok {
+				appliedAt := row.appliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+				status.Modified = row.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+
+		for version, row := range done {
+			if known[version] {
+				continue
+			}
+			appliedAt := row.appliedAt
+			statuses = append(statuses, Status{
+				Version:   version,
+				Name:      row.name,
+				Applied:   true,
+				AppliedAt: &appliedAt,
+				Missing:   true,
+			})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+		return nil
+	})
+	return statuses, err
+}
+
+// Verify comprueba que todas las migraciones estén aplicadas y sin cambios. A diferencia de Up y
+// Status no toma el bloqueo ni crea schema_migrations, así que puede llamarse con frecuencia.
+func (m *Migrator) Verify(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error al obtener conexión para verificar migraciones: %w", err)
+	}
+	defer conn.Close()
+
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(done); err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := done[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("hay %d migraciones pendientes: %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// schemaMigrationsTable crea la tabla de control en cada motor. En SQLite applied_at se declara
+// TIMESTAMP para que el driver la lea como fecha; el valor siempre lo escribe apply.
+var schemaMigrationsTable = map[Dialect]string{
+	Postgres: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)
+	`,
+	SQLite: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`,
+}
+
+// withLock toma el advisory lock de migraciones en una conexión dedicada (solo en PostgreSQL),
+// crea la tabla schema_migrations si no existe y ejecuta fn en esa misma conexión
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error al obtener conexión para migrar: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect == Postgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", LockKey); err != nil {
+			return fmt.Errorf("error al tomar el bloqueo de migraciones: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", LockKey); err != nil {
+				slog.ErrorContext(ctx, "error al liberar el bloqueo de migraciones", "error", err)
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, schemaMigrationsTable[m.dialect]); err != nil {
+		return fmt.Errorf("error al crear schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// applied obtiene las migraciones registradas en schema_migrations
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar migraciones aplicadas: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			row     appliedMigration
+		)
+		if err := rows.Scan(&version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear migración aplicada: %w", err)
+		}
+		done[version] = row
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sobre migraciones aplicadas: %w", err)
+	}
+
+	return done, nil
+}
+
+// verify comprueba que las migraciones aplicadas sigan existiendo y no hayan cambiado
+func (m *Migrator) verify(done map[int64]appliedMigration) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]int64, 0, len(done))
+	for version := range done {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	for _, version := range versions {
+		row := done[version]
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("la migración aplicada %d_%s no existe en los archivos", version, row.name)
+		}
+		if row.checksum != migration.Checksum {
+			return fmt.Errorf("la migración %d_%s fue modificada después de aplicarse (checksum %s, archivo %s)", version, migration.Name, row.checksum, migration.Checksum)
+		}
+	}
+	return nil
+}
+
+// apply ejecuta el script de aplicación y registra la migración en una transacción
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("error al aplicar la migración %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+		migration.Version,
+		migration.Name,
+		migration.Checksum,
+		time.Now(),
+	); err != nil {
+		return fmt.Errorf("error al registrar la migración %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+// revert ejecuta el script de reversión y elimina el registro de la migración en una transacción
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("error al revertir la migración %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+		return fmt.Errorf("error al eliminar el registro de la migración %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
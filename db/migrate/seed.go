@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// Seed ejecuta en orden los archivos de datos iniciales de fsys, cada uno en su propia transacción.
+// Los archivos deben ser idempotentes; devuelve los nombres de los archivos ejecutados.
+func Seed(ctx context.Context, db *sql.DB, fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer datos iniciales: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && seedFile.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return names[:i], fmt.Errorf("error al leer %s: %w", name, err)
+		}
+		if err := runSeed(ctx, db, name, string(content)); err != nil {
+			return names[:i], err
+		}
+	}
+
+	return names, nil
+}
+
+// runSeed ejecuta un archivo de datos iniciales en una transacción
+func runSeed(ctx context.Context, db *sql.DB, name, script string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("error al ejecutar %s: %w", name, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,26 @@
+-- Tabla de transacciones
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY,
+    amount DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    date TIMESTAMP WITH TIME ZONE NOT NULL,
+    category_id UUID NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('INCOME', 'EXPENSE')),
+    payment_method_id UUID,
+    currency_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT,
+    FOREIGN KEY (payment_method_id) REFERENCES payment_methods(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (currency_id) REFERENCES currencies(id)
+);
+
+-- Índices para transacciones
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions(category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_payment_method_id ON transactions(payment_method_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
+CREATE INDEX IF NOT EXISTS idx_transactions_currency_id ON transactions(currency_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
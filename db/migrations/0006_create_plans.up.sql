@@ -0,0 +1,20 @@
+-- Tabla para almacenar los planes de suscripción
+CREATE TABLE IF NOT EXISTS plans (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency_id UUID NOT NULL REFERENCES currencies(id),
+    interval VARCHAR(50) NOT NULL CHECK (interval IN ('monthly', 'yearly')),
+    features JSONB NOT NULL DEFAULT '[]'::JSONB,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    is_public BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para optimizar búsquedas
+CREATE INDEX IF NOT EXISTS idx_plans_is_active ON plans(is_active);
+CREATE INDEX IF NOT EXISTS idx_plans_is_public ON plans(is_public);
+CREATE INDEX IF NOT EXISTS idx_plans_sort_order ON plans(sort_order);
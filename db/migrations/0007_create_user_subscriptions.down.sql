@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_subscriptions;
+DROP FUNCTION IF EXISTS update_modified_column();
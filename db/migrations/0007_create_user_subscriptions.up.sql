@@ -1,4 +1,5 @@
--- Tabla de suscripciones de usuarios
+-- Tabla de suscripciones de usuarios. Las claves foráneas se declaran en 0017, al convertir los
+-- identificadores a UUID: con VARCHAR no pueden referenciar a users y plans.
 CREATE TABLE IF NOT EXISTS user_subscriptions (
     id VARCHAR(36) PRIMARY KEY,
     user_id VARCHAR(36) NOT NULL,
@@ -13,9 +14,7 @@ CREATE TABLE IF NOT EXISTS user_subscriptions (
     payment_method_id VARCHAR(100),
     metadata JSONB,
     created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    CONSTRAINT fk_subscription_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-    CONSTRAINT fk_subscription_plan FOREIGN KEY (plan_id) REFERENCES plans(id)
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
 -- Índices para optimizar las consultas más comunes
@@ -43,29 +42,3 @@ CREATE TRIGGER update_user_subscriptions_modtime
 BEFORE UPDATE ON user_subscriptions
 FOR EACH ROW
 EXECUTE FUNCTION update_modified_column();
-
--- Inserts de ejemplo (opcional)
-/*
-INSERT INTO user_subscriptions (
-    id, user_id, plan_id, status, start_date, end_date, 
-    renewal_date, cancellation_date, last_payment_date, metadata, created_at, updated_at
-) VALUES 
-(
-    'sub_01', 
-    'user_01', -- Reemplazar con un ID de usuario real
-    'plan_free', 
-    'active', 
-    NOW(), 
-    NOW() + INTERVAL '1 year', 
-    NULL, 
-    NULL, 
-    NULL, 
-    '{"source": "registration", "notes": "Plan gratuito inicial"}',
-    NOW(),
-    NOW()
-)
-ON CONFLICT (id) DO UPDATE SET
-    status = EXCLUDED.status,
-    end_date = EXCLUDED.end_date,
-    updated_at = NOW();
-*/ 
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_user_subscriptions_next_payment_attempt;
@@ -0,0 +1,2 @@
+-- Índice para que el planificador encuentre los reintentos de cobro vencidos
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_next_payment_attempt ON user_subscriptions(next_payment_attempt) WHERE status = 'failed';
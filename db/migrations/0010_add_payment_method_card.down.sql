@@ -0,0 +1,4 @@
+ALTER TABLE payment_methods
+    DROP COLUMN IF EXISTS card_brand,
+    DROP COLUMN IF EXISTS card_last4,
+    DROP COLUMN IF EXISTS card_token;
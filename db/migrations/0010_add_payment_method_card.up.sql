@@ -0,0 +1,4 @@
+-- Columnas de la tarjeta tokenizada en la pasarela de pago
+ALTER TABLE payment_methods ADD COLUMN IF NOT EXISTS card_brand VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE payment_methods ADD COLUMN IF NOT EXISTS card_last4 VARCHAR(4) NOT NULL DEFAULT '';
+ALTER TABLE payment_methods ADD COLUMN IF NOT EXISTS card_token VARCHAR(255) NOT NULL DEFAULT '';
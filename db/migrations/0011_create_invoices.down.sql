@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS pending_invoices;
+DROP TABLE IF EXISTS invoices;
+DROP FUNCTION IF EXISTS prevent_invoice_changes();
+DROP TABLE IF EXISTS invoice_sequences;
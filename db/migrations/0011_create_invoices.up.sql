@@ -0,0 +1,62 @@
+-- Contador de números de factura por cuenta de usuario
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    user_id UUID PRIMARY KEY,
+    last_sequence BIGINT NOT NULL,
+    CONSTRAINT fk_invoice_sequence_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Facturas de suscripciones. Las claves foráneas no borran en cascada: el historial de facturación
+-- se conserva, por lo que no se puede eliminar un usuario o una suscripción con facturas.
+-- subscription_id es VARCHAR como user_subscriptions.id hasta que 0017 convierte ambas a UUID.
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY,
+    number VARCHAR(32) NOT NULL,
+    sequence BIGINT NOT NULL,
+    user_id UUID NOT NULL,
+    subscription_id VARCHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('charge', 'proration', 'refund')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('paid', 'failed', 'refunded')),
+    plan_snapshot JSONB NOT NULL,
+    line_items JSONB NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL,
+    tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    tax DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL,
+    charge_id VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, sequence),
+    CONSTRAINT fk_invoice_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_invoice_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_subscription_id ON invoices(subscription_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_user_issued_at ON invoices(user_id, issued_at DESC);
+
+-- Las facturas son inmutables: rechazar cualquier modificación o borrado
+CREATE OR REPLACE FUNCTION prevent_invoice_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'las facturas son inmutables';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS invoices_immutable ON invoices;
+CREATE TRIGGER invoices_immutable
+BEFORE UPDATE OR DELETE ON invoices
+FOR EACH ROW
+EXECUTE FUNCTION prevent_invoice_changes();
+
+-- Facturas de cobros ya hechos en la pasarela que no se pudieron guardar. El ciclo de vida de
+-- suscripciones las vuelve a emitir con el mismo ID, por lo que reintentar no duplica facturas.
+CREATE TABLE IF NOT EXISTS pending_invoices (
+    id UUID PRIMARY KEY,
+    invoice JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_invoices_created_at ON pending_invoices(created_at);
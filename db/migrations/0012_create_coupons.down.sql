@@ -0,0 +1,4 @@
+ALTER TABLE user_subscriptions DROP COLUMN IF EXISTS trial_end_date;
+ALTER TABLE plans DROP COLUMN IF EXISTS trial_days;
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,38 @@
+-- Cupones promocionales de descuento sobre los planes de suscripción
+CREATE TABLE IF NOT EXISTS coupons (
+    id UUID PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percent', 'amount')),
+    percent_off DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    amount_off DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency_id UUID REFERENCES currencies(id),
+    duration VARCHAR(20) NOT NULL CHECK (duration IN ('once', 'repeating', 'forever')),
+    duration_periods INTEGER NOT NULL DEFAULT 0,
+    plan_ids JSONB NOT NULL DEFAULT '[]'::JSONB,
+    max_redemptions INTEGER,
+    times_redeemed INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (max_redemptions IS NULL OR times_redeemed <= max_redemptions)
+);
+
+-- Canjes de cupones: un usuario solo puede canjear cada cupón una vez
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id UUID PRIMARY KEY,
+    coupon_id UUID NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    subscription_id VARCHAR(36) NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (coupon_id, user_id),
+    CONSTRAINT fk_coupon_redemption_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_coupon_redemption_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_subscription_id ON coupon_redemptions(subscription_id);
+
+-- Días de prueba gratuita de cada plan y fin del período de prueba de cada suscripción
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS trial_days INTEGER NOT NULL DEFAULT 0 CHECK (trial_days >= 0);
+ALTER TABLE user_subscriptions ADD COLUMN IF NOT EXISTS trial_end_date TIMESTAMP;
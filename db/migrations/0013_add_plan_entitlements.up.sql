@@ -0,0 +1,10 @@
+-- Derechos de uso legibles por máquina de cada plan (un límite nulo significa ilimitado)
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS entitlements JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+UPDATE plans
+SET entitlements = '{"max_categories": 10, "max_transactions_per_month": 100, "attachments": false, "export_formats": ["csv"]}'::JSONB
+WHERE id = 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b11';
+
+UPDATE plans
+SET entitlements = '{"max_categories": null, "max_transactions_per_month": null, "attachments": true, "export_formats": ["csv", "json"]}'::JSONB
+WHERE id IN ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13');
@@ -0,0 +1,7 @@
+-- Falla si existen planes con intervalos que el esquema anterior no admite (día, semana, vitalicio
+-- o varias unidades); hay que migrarlos antes de revertir
+ALTER TABLE plans DROP CONSTRAINT IF EXISTS plans_interval_check;
+UPDATE plans SET interval = 'monthly' WHERE interval = 'month' AND interval_count = 1;
+UPDATE plans SET interval = 'yearly' WHERE interval = 'year' AND interval_count = 1;
+ALTER TABLE plans DROP COLUMN IF EXISTS interval_count;
+ALTER TABLE plans ADD CONSTRAINT plans_interval_check CHECK (interval IN ('monthly', 'yearly'));
@@ -0,0 +1,6 @@
+-- Intervalo de facturación como unidad y cantidad (antes solo 'monthly' y 'yearly')
+ALTER TABLE plans DROP CONSTRAINT IF EXISTS plans_interval_check;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS interval_count INTEGER NOT NULL DEFAULT 1 CHECK (interval_count >= 1);
+UPDATE plans SET interval = 'month' WHERE interval = 'monthly';
+UPDATE plans SET interval = 'year' WHERE interval = 'yearly';
+ALTER TABLE plans ADD CONSTRAINT plans_interval_check CHECK (interval IN ('day', 'week', 'month', 'year', 'lifetime'));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_plans_superseded_at;
+DROP INDEX IF EXISTS idx_plans_family_version;
+ALTER TABLE plans
+    DROP COLUMN IF EXISTS superseded_at,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,10 @@
+-- Versiones inmutables de los planes: cada actualización crea una nueva fila de la misma familia
+-- y marca la anterior como reemplazada. Los planes existentes son la versión 1 de su propia familia.
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1 CHECK (version >= 1);
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS superseded_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE plans SET family_id = id WHERE family_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plans_family_version ON plans(family_id, version);
+CREATE INDEX IF NOT EXISTS idx_plans_superseded_at ON plans(superseded_at);
@@ -0,0 +1,2 @@
+-- Archivado de planes: un plan archivado no admite nuevas suscripciones y se conserva para sus suscriptores
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,27 @@
+ALTER TABLE user_subscriptions DROP CONSTRAINT IF EXISTS fk_subscription_user;
+ALTER TABLE user_subscriptions DROP CONSTRAINT IF EXISTS fk_subscription_plan;
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS fk_invoice_subscription;
+ALTER TABLE coupon_redemptions DROP CONSTRAINT IF EXISTS fk_coupon_redemption_subscription;
+
+ALTER TABLE user_subscriptions
+    ALTER COLUMN id TYPE VARCHAR(36) USING id::text,
+    ALTER COLUMN user_id TYPE VARCHAR(36) USING user_id::text,
+    ALTER COLUMN plan_id TYPE VARCHAR(36) USING plan_id::text,
+    ALTER COLUMN payment_method_id TYPE VARCHAR(100) USING payment_method_id::text,
+    ALTER COLUMN start_date TYPE TIMESTAMP USING start_date AT TIME ZONE 'UTC',
+    ALTER COLUMN end_date TYPE TIMESTAMP USING end_date AT TIME ZONE 'UTC',
+    ALTER COLUMN renewal_date TYPE TIMESTAMP USING renewal_date AT TIME ZONE 'UTC',
+    ALTER COLUMN cancellation_date TYPE TIMESTAMP USING cancellation_date AT TIME ZONE 'UTC',
+    ALTER COLUMN last_payment_date TYPE TIMESTAMP USING last_payment_date AT TIME ZONE 'UTC',
+    ALTER COLUMN next_payment_attempt TYPE TIMESTAMP USING next_payment_attempt AT TIME ZONE 'UTC',
+    ALTER COLUMN trial_end_date TYPE TIMESTAMP USING trial_end_date AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE invoices
+    ALTER COLUMN subscription_id TYPE VARCHAR(36) USING subscription_id::text,
+    ADD CONSTRAINT fk_invoice_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE RESTRICT;
+
+ALTER TABLE coupon_redemptions
+    ALTER COLUMN subscription_id TYPE VARCHAR(36) USING subscription_id::text,
+    ADD CONSTRAINT fk_coupon_redemption_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE CASCADE;
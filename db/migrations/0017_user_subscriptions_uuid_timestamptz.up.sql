@@ -0,0 +1,38 @@
+-- Alinea user_subscriptions con el resto del esquema: identificadores UUID y fechas con zona horaria.
+-- Las fechas existentes se guardaron sin zona horaria y se interpretan como UTC.
+
+ALTER TABLE user_subscriptions DROP CONSTRAINT IF EXISTS fk_subscription_user;
+ALTER TABLE user_subscriptions DROP CONSTRAINT IF EXISTS fk_subscription_plan;
+
+-- Las facturas y los canjes de cupones referencian el ID de la suscripción: se quitan sus claves
+-- mientras cambia el tipo y se vuelven a declarar sobre UUID
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS fk_invoice_subscription;
+ALTER TABLE coupon_redemptions DROP CONSTRAINT IF EXISTS fk_coupon_redemption_subscription;
+
+ALTER TABLE user_subscriptions
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN user_id TYPE UUID USING user_id::uuid,
+    ALTER COLUMN plan_id TYPE UUID USING plan_id::uuid,
+    ALTER COLUMN payment_method_id TYPE UUID USING NULLIF(payment_method_id, '')::uuid,
+    ALTER COLUMN start_date TYPE TIMESTAMP WITH TIME ZONE USING start_date AT TIME ZONE 'UTC',
+    ALTER COLUMN end_date TYPE TIMESTAMP WITH TIME ZONE USING end_date AT TIME ZONE 'UTC',
+    ALTER COLUMN renewal_date TYPE TIMESTAMP WITH TIME ZONE USING renewal_date AT TIME ZONE 'UTC',
+    ALTER COLUMN cancellation_date TYPE TIMESTAMP WITH TIME ZONE USING cancellation_date AT TIME ZONE 'UTC',
+    ALTER COLUMN last_payment_date TYPE TIMESTAMP WITH TIME ZONE USING last_payment_date AT TIME ZONE 'UTC',
+    ALTER COLUMN next_payment_attempt TYPE TIMESTAMP WITH TIME ZONE USING next_payment_attempt AT TIME ZONE 'UTC',
+    ALTER COLUMN trial_end_date TYPE TIMESTAMP WITH TIME ZONE USING trial_end_date AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP WITH TIME ZONE USING updated_at AT TIME ZONE 'UTC';
+
+-- Con los tipos alineados las claves foráneas ya pueden declararse
+ALTER TABLE user_subscriptions
+    ADD CONSTRAINT fk_subscription_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_subscription_plan FOREIGN KEY (plan_id) REFERENCES plans(id);
+
+ALTER TABLE invoices
+    ALTER COLUMN subscription_id TYPE UUID USING subscription_id::uuid,
+    ADD CONSTRAINT fk_invoice_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE RESTRICT;
+
+ALTER TABLE coupon_redemptions
+    ALTER COLUMN subscription_id TYPE UUID USING subscription_id::uuid,
+    ADD CONSTRAINT fk_coupon_redemption_subscription FOREIGN KEY (subscription_id) REFERENCES user_subscriptions(id) ON DELETE CASCADE;
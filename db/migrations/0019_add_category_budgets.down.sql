@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_budget_currency_check;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS budget_currency_id,
+    DROP COLUMN IF EXISTS monthly_budget;
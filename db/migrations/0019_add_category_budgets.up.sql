@@ -0,0 +1,9 @@
+-- Presupuesto mensual opcional de cada categoría: al superarlo se notifica budget_exceeded.
+-- Solo cuentan los gastos en la moneda del presupuesto.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS monthly_budget DECIMAL(12, 2) CHECK (monthly_budget > 0),
+    ADD COLUMN IF NOT EXISTS budget_currency_id UUID REFERENCES currencies(id);
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_budget_currency_check;
+ALTER TABLE categories ADD CONSTRAINT categories_budget_currency_check
+    CHECK ((monthly_budget IS NULL) = (budget_currency_id IS NULL));
@@ -0,0 +1,28 @@
+// Package migrations contiene las migraciones versionadas del esquema de la base de datos.
+//
+// Cada migración es un par de archivos NNNN_nombre.up.sql y NNNN_nombre.down.sql que se
+// aplican en orden de versión. Una migración aplicada no debe modificarse: el ejecutor guarda
+// su checksum y se niega a continuar si el archivo cambió. Los datos iniciales van en db/seeds.
+//
+// Las versiones 0001 a 0007 reproducen el esquema que creaban los scripts anteriores al ejecutor,
+// con CREATE ... IF NOT EXISTS, y cada cambio posterior es una migración propia. Así una base de
+// datos creada con esos scripts se actualiza con las mismas migraciones que una nueva.
+//
+// El directorio sqlite contiene el esquema equivalente para SQLite, con su propia numeración.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contiene los archivos de migración de PostgreSQL embebidos en el binario
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite contiene los archivos de migración de SQLite
+var SQLite, _ = fs.Sub(sqliteFS, "sqlite")
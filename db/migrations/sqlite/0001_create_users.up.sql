@@ -0,0 +1,12 @@
+-- Tabla de usuarios. Las fechas se guardan como texto UTC de ancho fijo (ver el paquete sqlite)
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+);
+
+-- Índices para usuarios
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
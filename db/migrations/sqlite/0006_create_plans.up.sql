@@ -0,0 +1,32 @@
+-- Tabla para almacenar los planes de suscripción. Cada fila es una versión inmutable del plan:
+-- modificarlo crea una nueva fila de la misma familia y marca la anterior como reemplazada.
+-- Los documentos JSON se guardan como texto y se validan con json_valid.
+CREATE TABLE IF NOT EXISTS plans (
+    id TEXT PRIMARY KEY,
+    family_id TEXT,
+    version INTEGER NOT NULL DEFAULT 1 CHECK (version >= 1),
+    superseded_at TIMESTAMP,
+    archived_at TIMESTAMP,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency_id TEXT NOT NULL REFERENCES currencies(id),
+    interval VARCHAR(50) NOT NULL CONSTRAINT plans_interval_check CHECK (interval IN ('day', 'week', 'month', 'year', 'lifetime')),
+    interval_count INTEGER NOT NULL DEFAULT 1 CHECK (interval_count >= 1),
+    features TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(features)),
+    trial_days INTEGER NOT NULL DEFAULT 0 CHECK (trial_days >= 0),
+    -- Derechos de uso legibles por máquina de cada plan (un límite nulo significa ilimitado)
+    entitlements TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(entitlements)),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    is_public BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+);
+
+-- Índices para optimizar búsquedas
+CREATE INDEX IF NOT EXISTS idx_plans_is_active ON plans(is_active);
+CREATE INDEX IF NOT EXISTS idx_plans_is_public ON plans(is_public);
+CREATE INDEX IF NOT EXISTS idx_plans_sort_order ON plans(sort_order);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plans_family_version ON plans(family_id, version);
+CREATE INDEX IF NOT EXISTS idx_plans_superseded_at ON plans(superseded_at);
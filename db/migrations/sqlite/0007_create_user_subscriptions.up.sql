@@ -0,0 +1,43 @@
+-- Tabla de suscripciones de usuarios
+CREATE TABLE IF NOT EXISTS user_subscriptions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    plan_id TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'cancelled', 'expired', 'pending', 'failed')),
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    renewal_date TIMESTAMP,
+    cancellation_date TIMESTAMP,
+    last_payment_date TIMESTAMP,
+    next_payment_attempt TIMESTAMP,
+    -- Fin del período de prueba gratuito
+    trial_end_date TIMESTAMP,
+    payment_method_id TEXT,
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    CONSTRAINT fk_subscription_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_subscription_plan FOREIGN KEY (plan_id) REFERENCES plans(id)
+);
+
+-- Índices para optimizar las consultas más comunes
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_user_id ON user_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_plan_id ON user_subscriptions(plan_id);
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_status ON user_subscriptions(status);
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_end_date ON user_subscriptions(end_date);
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_renewal_date ON user_subscriptions(renewal_date);
+
+-- Índice compuesto para buscar suscripciones activas de un usuario (caso de uso común)
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_user_status ON user_subscriptions(user_id, status);
+
+-- Índice para que el planificador encuentre los reintentos de cobro vencidos
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_next_payment_attempt ON user_subscriptions(next_payment_attempt) WHERE status = 'failed';
+
+-- Trigger para actualizar automáticamente el campo updated_at. SQLite no vuelve a disparar
+-- el trigger por su propio UPDATE porque recursive_triggers está desactivado.
+CREATE TRIGGER IF NOT EXISTS update_user_subscriptions_modtime
+AFTER UPDATE ON user_subscriptions
+FOR EACH ROW
+BEGIN
+    UPDATE user_subscriptions SET updated_at = (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z') WHERE id = NEW.id;
+END;
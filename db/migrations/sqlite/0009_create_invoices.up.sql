@@ -0,0 +1,57 @@
+-- Contador de números de factura por cuenta de usuario
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_sequence BIGINT NOT NULL
+);
+
+-- Facturas de suscripciones. Las claves foráneas no borran en cascada: el historial de facturación
+-- se conserva, por lo que no se puede eliminar un usuario o una suscripción con facturas.
+CREATE TABLE IF NOT EXISTS invoices (
+    id TEXT PRIMARY KEY,
+    number VARCHAR(32) NOT NULL,
+    sequence BIGINT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    subscription_id TEXT NOT NULL REFERENCES user_subscriptions(id) ON DELETE RESTRICT,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('charge', 'proration', 'refund')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('paid', 'failed', 'refunded')),
+    plan_snapshot TEXT NOT NULL CHECK (json_valid(plan_snapshot)),
+    line_items TEXT NOT NULL CHECK (json_valid(line_items)),
+    currency VARCHAR(3) NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL,
+    tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    tax DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL,
+    charge_id VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    issued_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    UNIQUE (user_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_subscription_id ON invoices(subscription_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_user_issued_at ON invoices(user_id, issued_at DESC);
+
+-- Las facturas son inmutables: rechazar cualquier modificación o borrado
+CREATE TRIGGER IF NOT EXISTS invoices_immutable_update
+BEFORE UPDATE ON invoices
+BEGIN
+    SELECT RAISE(ABORT, 'las facturas son inmutables');
+END;
+
+CREATE TRIGGER IF NOT EXISTS invoices_immutable_delete
+BEFORE DELETE ON invoices
+BEGIN
+    SELECT RAISE(ABORT, 'las facturas son inmutables');
+END;
+
+-- Facturas de cobros ya hechos en la pasarela que no se pudieron guardar. El ciclo de vida de
+-- suscripciones las vuelve a emitir con el mismo ID, por lo que reintentar no duplica facturas.
+CREATE TABLE IF NOT EXISTS pending_invoices (
+    id TEXT PRIMARY KEY,
+    invoice TEXT NOT NULL CHECK (json_valid(invoice)),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_invoices_created_at ON pending_invoices(created_at);
@@ -0,0 +1,32 @@
+-- Cupones promocionales de descuento sobre los planes de suscripción
+CREATE TABLE IF NOT EXISTS coupons (
+    id TEXT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percent', 'amount')),
+    percent_off DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    amount_off DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency_id TEXT REFERENCES currencies(id),
+    duration VARCHAR(20) NOT NULL CHECK (duration IN ('once', 'repeating', 'forever')),
+    duration_periods INTEGER NOT NULL DEFAULT 0,
+    plan_ids TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(plan_ids)),
+    max_redemptions INTEGER,
+    times_redeemed INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    CHECK (max_redemptions IS NULL OR times_redeemed <= max_redemptions)
+);
+
+-- Canjes de cupones: un usuario solo puede canjear cada cupón una vez
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id TEXT PRIMARY KEY,
+    coupon_id TEXT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscription_id TEXT NOT NULL REFERENCES user_subscriptions(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+    UNIQUE (coupon_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_subscription_id ON coupon_redemptions(subscription_id);
@@ -0,0 +1,11 @@
+-- Contadores del limitador de peticiones compartidos entre instancias: una fila por clave y ventana.
+-- Las ventanas van en milisegundos Unix; las terminadas se borran periódicamente.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    bucket VARCHAR(255) NOT NULL,
+    window_start BIGINT NOT NULL,
+    window_end BIGINT NOT NULL,
+    hits INTEGER NOT NULL,
+    PRIMARY KEY (bucket, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_window_end ON rate_limits(window_end);
@@ -0,0 +1,2 @@
+ALTER TABLE categories DROP COLUMN budget_currency_id;
+ALTER TABLE categories DROP COLUMN monthly_budget;
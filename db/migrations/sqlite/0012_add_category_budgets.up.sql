@@ -0,0 +1,5 @@
+-- Presupuesto mensual opcional de cada categoría: al superarlo se notifica budget_exceeded.
+-- Solo cuentan los gastos en la moneda del presupuesto.
+ALTER TABLE categories ADD COLUMN monthly_budget DECIMAL(12, 2) CHECK (monthly_budget > 0);
+ALTER TABLE categories ADD COLUMN budget_currency_id TEXT REFERENCES currencies(id)
+    CHECK ((monthly_budget IS NULL) = (budget_currency_id IS NULL));
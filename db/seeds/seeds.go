@@ -0,0 +1,22 @@
+// Package seeds contiene los datos iniciales y de ejemplo de la base de datos.
+//
+// Los archivos NNNN_nombre.sql se ejecutan en orden con el comando seed, después de las
+// migraciones. Deben ser idempotentes (ON CONFLICT) porque pueden ejecutarse varias veces.
+// El directorio sqlite contiene los mismos datos escritos para SQLite.
+package seeds
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contiene los archivos de datos iniciales de PostgreSQL embebidos en el binario
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite contiene los archivos de datos iniciales de SQLite
+var SQLite, _ = fs.Sub(sqliteFS, "sqlite")
@@ -0,0 +1,8 @@
+-- Usuarios de ejemplo
+INSERT INTO users (id, email, name, password, created_at, updated_at)
+VALUES
+    ('00000000-0000-0000-0000-000000000001', 'admin@example.com', 'Admin', '$2a$10$1qAz2wSx3eDc4rFv5tGb5edIUVkIuDGJHGNQ4qRntVkH9aHktvQaO', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('00000000-0000-0000-0000-000000000002', 'user@example.com', 'User', '$2a$10$1qAz2wSx3eDc4rFv5tGb5edIUVkIuDGJHGNQ4qRntVkH9aHktvQaO', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    -- Dueño de las categorías y transacciones de ejemplo
+    ('d15ab58a-4689-4745-bb27-46ec4757731f', 'demo@example.com', 'Demo', '$2a$10$1qAz2wSx3eDc4rFv5tGb5edIUVkIuDGJHGNQ4qRntVkH9aHktvQaO', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'))
+ON CONFLICT (id) DO NOTHING;
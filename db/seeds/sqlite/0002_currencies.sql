@@ -0,0 +1,14 @@
+-- Insertar monedas iniciales
+INSERT INTO currencies (id, code, name, symbol, is_active, created_at, updated_at)
+VALUES
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'USD', 'Dólar estadounidense', '$', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12', 'EUR', 'Euro', '€', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a13', 'GBP', 'Libra esterlina', '£', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a14', 'JPY', 'Yen japonés', '¥', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a15', 'MXN', 'Peso mexicano', '$', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a16', 'BTC', 'Bitcoin', '₿', TRUE, (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'))
+ON CONFLICT (code) DO UPDATE
+SET name = EXCLUDED.name,
+    symbol = EXCLUDED.symbol,
+    is_active = EXCLUDED.is_active,
+    updated_at = (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z');
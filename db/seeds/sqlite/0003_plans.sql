@@ -0,0 +1,74 @@
+-- Planes iniciales. Solo se crean si no existen: los cambios posteriores se hacen creando
+-- nuevas versiones desde la API para no modificar el precio de los suscriptores actuales.
+INSERT INTO plans (
+    id, family_id, version, name, description, price, currency_id, interval, interval_count,
+    features, entitlements, is_active, is_public, sort_order, created_at, updated_at
+)
+VALUES
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b11',
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b11',
+        1,
+        'Gratis',
+        'Plan básico con funcionalidades limitadas',
+        0,
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'month',
+        1,
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "No disponible", "included": false},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "No disponible", "included": false}
+        ]',
+        '{"max_categories": 10, "max_transactions_per_month": 100, "attachments": false, "export_formats": ["csv"]}',
+        TRUE,
+        TRUE,
+        1,
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+    ),
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12',
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12',
+        1,
+        'Pro',
+        'Plan profesional con todas las funcionalidades',
+        19.99,
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'month',
+        1,
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "Ilimitado", "included": true}
+        ]',
+        '{"max_categories": null, "max_transactions_per_month": null, "attachments": true, "export_formats": ["csv", "json"]}',
+        TRUE,
+        TRUE,
+        2,
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+    ),
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13',
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13',
+        1,
+        'Pro Anual',
+        'Plan profesional con todas las funcionalidades - Facturación anual',
+        199.90,
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'year',
+        1,
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "Ilimitado", "included": true}
+        ]',
+        '{"max_categories": null, "max_transactions_per_month": null, "attachments": true, "export_formats": ["csv", "json"]}',
+        TRUE,
+        TRUE,
+        3,
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'),
+        (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')
+    )
+ON CONFLICT (id) DO NOTHING;
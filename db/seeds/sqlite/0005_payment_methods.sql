@@ -0,0 +1,7 @@
+-- Insertar algunos datos de ejemplo
+INSERT INTO payment_methods (id, name, description, is_active, user_id, created_at, updated_at)
+VALUES
+    ('11111111-1111-1111-1111-111111111111', 'Tarjeta de Crédito', 'Visa terminada en 4242', TRUE, '00000000-0000-0000-0000-000000000001', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('22222222-2222-2222-2222-222222222222', 'Efectivo', 'Pagos en efectivo', TRUE, '00000000-0000-0000-0000-000000000001', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('33333333-3333-3333-3333-333333333333', 'Transferencia Bancaria', 'Cuenta corriente', TRUE, '00000000-0000-0000-0000-000000000002', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'))
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,10 @@
+-- Insertar algunos datos de ejemplo
+INSERT INTO transactions (id, amount, description, date, category_id, type, payment_method_id, user_id, currency_id, created_at, updated_at)
+VALUES
+    ('11111111-1111-1111-1111-111111111201', 1500.00, 'Salario mensual', '2023-05-01 12:00:00.000000000Z', '11111111-1111-1111-1111-111111111101', 'INCOME', '11111111-1111-1111-1111-111111111111', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('22222222-2222-2222-2222-222222222202', 50.00, 'Dividendos', '2023-05-05 14:30:00.000000000Z', '22222222-2222-2222-2222-222222222202', 'INCOME', '11111111-1111-1111-1111-111111111111', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('33333333-3333-3333-3333-333333333203', 120.50, 'Compra supermercado', '2023-05-10 18:45:00.000000000Z', '33333333-3333-3333-3333-333333333303', 'EXPENSE', '22222222-2222-2222-2222-222222222222', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('44444444-4444-4444-4444-444444444204', 35.00, 'Gasolina', '2023-05-12 10:15:00.000000000Z', '44444444-4444-4444-4444-444444444404', 'EXPENSE', '11111111-1111-1111-1111-111111111111', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('55555555-5555-5555-5555-555555555205', 80.00, 'Cine y cena', '2023-05-15 20:30:00.000000000Z', '55555555-5555-5555-5555-555555555505', 'EXPENSE', '11111111-1111-1111-1111-111111111111', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z')),
+    ('66666666-6666-6666-6666-666666666206', 500.00, 'Proyecto freelance', '2023-05-20 09:00:00.000000000Z', '66666666-6666-6666-6666-666666666606', 'INCOME', '33333333-3333-3333-3333-333333333333', 'd15ab58a-4689-4745-bb27-46ec4757731f', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'), (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000Z'))
+ON CONFLICT (id) DO NOTHING;
@@ -2,10 +2,11 @@ package auth
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"MyMoneyBackend/internal/domain"
 )
 
 // UserClaims represents JWT claims with user information
@@ -24,7 +25,10 @@ type RefreshTokenClaims struct {
 }
 
 // TokenService handles token generation and validation
-type TokenService struct{}
+type TokenService struct {
+	secret        []byte
+	refreshSecret []byte
+}
 
 // TokenPair represents an access token and refresh token pair
 type TokenPair struct {
@@ -32,41 +36,43 @@ type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-// NewTokenService creates a new TokenService
-func NewTokenService() *TokenService {
-	return &TokenService{}
+// NewTokenService creates a new TokenService that signs tokens with the secrets in cfg.
+// Refresh tokens use the access token secret when no refresh secret is configured.
+func NewTokenService(cfg domain.AuthConfig) *TokenService {
+	refreshSecret := cfg.JWTRefreshSecret
+	if refreshSecret == "" {
+		refreshSecret = cfg.JWTSecret
+	}
+	return &TokenService{
+		secret:        []byte(cfg.JWTSecret),
+		refreshSecret: []byte(refreshSecret),
+	}
 }
 
 // ValidateToken validates a JWT token
 func (s *TokenService) ValidateToken(tokenString string) (*UserClaims, error) {
-	// Get JWT secret from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, fmt.Errorf("JWT_SECRET not set")
-	}
-
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secret), nil
+		return s.secret, nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, domain.NewUnauthorizedError("invalid_token", "invalid token: %v", err)
 	}
 
 	// Check if token is valid
 	if !token.Valid {
-		return nil, fmt.Errorf("invalid token")
+		return nil, domain.NewUnauthorizedError("invalid_token", "invalid token")
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(*UserClaims)
 	if !ok {
-		return nil, fmt.Errorf("invalid token claims")
+		return nil, domain.NewUnauthorizedError("invalid_token", "invalid token claims")
 	}
 
 	return claims, nil
@@ -74,37 +80,28 @@ func (s *TokenService) ValidateToken(tokenString string) (*UserClaims, error) {
 
 // ValidateRefreshToken validates a refresh token
 func (s *TokenService) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
-	// Get JWT refresh secret from environment
-	secret := os.Getenv("JWT_REFRESH_SECRET")
-	if secret == "" {
-		secret = os.Getenv("JWT_SECRET") // Fallback to regular secret if refresh secret not set
-		if secret == "" {
-			return nil, fmt.Errorf("JWT_SECRET not set")
-		}
-	}
-
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secret), nil
+		return s.refreshSecret, nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, domain.NewUnauthorizedError("invalid_refresh_token", "invalid refresh token: %v", err)
 	}
 
 	// Check if token is valid
 	if !token.Valid {
-		return nil, fmt.Errorf("invalid refresh token")
+		return nil, domain.NewUnauthorizedError("invalid_refresh_token", "invalid refresh token")
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(*RefreshTokenClaims)
 	if !ok {
-		return nil, fmt.Errorf("invalid refresh token claims")
+		return nil, domain.NewUnauthorizedError("invalid_refresh_token", "invalid refresh token claims")
 	}
 
 	return claims, nil
@@ -112,12 +109,6 @@ func (s *TokenService) ValidateRefreshToken(tokenString string) (*RefreshTokenCl
 
 // GenerateToken generates a JWT token for a user
 func (s *TokenService) GenerateToken(userID, email string) (string, error) {
-	// Get JWT secret from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", fmt.Errorf("JWT_SECRET not set")
-	}
-
 	// Create claims with expiration
 	expirationTime := time.Now().Add(24 * time.Hour) // Token valid for 24 hours
 	claims := &UserClaims{
@@ -136,7 +127,7 @@ func (s *TokenService) GenerateToken(userID, email string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign the token with the secret
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := token.SignedString(s.secret)
 	if err != nil {
 		return "", err
 	}
@@ -152,15 +143,6 @@ func (s *TokenService) GenerateTokenPair(userID, email string) (*TokenPair, erro
 		return nil, err
 	}
 
-	// Get JWT refresh secret from environment
-	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecret == "" {
-		refreshSecret = os.Getenv("JWT_SECRET") // Fallback to regular secret if refresh secret not set
-		if refreshSecret == "" {
-			return nil, fmt.Errorf("JWT_SECRET not set")
-		}
-	}
-
 	// Create refresh token with longer expiration
 	refreshExpirationTime := time.Now().Add(7 * 24 * time.Hour) // Refresh token valid for 7 days
 	tokenID := fmt.Sprintf("%s-%d", userID, time.Now().Unix())  // Unique token ID
@@ -179,7 +161,7 @@ func (s *TokenService) GenerateTokenPair(userID, email string) (*TokenPair, erro
 	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(refreshSecret))
+	refreshTokenString, err := refreshToken.SignedString(s.refreshSecret)
 	if err != nil {
 		return nil, err
 	}
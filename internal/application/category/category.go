@@ -2,6 +2,7 @@ package category
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"MyMoneyBackend/internal/domain"
@@ -12,13 +13,16 @@ import (
 
 // Service maneja la lógica de negocio relacionada con categorías
 type Service struct {
-	repo app.CategoryRepository
+	repo  app.CategoryRepository
+	guard app.EntitlementGuard
 }
 
-// NewService crea un nuevo servicio de categorías
-func NewService(repo app.CategoryRepository) *Service {
+// NewService crea un nuevo servicio de categorías.
+// Si guard es nil no se aplican los límites del plan.
+func NewService(repo app.CategoryRepository, guard app.EntitlementGuard) *Service {
 	return &Service{
-		repo: repo,
+		repo:  repo,
+		guard: guard,
 	}
 }
 
@@ -39,6 +43,16 @@ func (s *Service) CreateCategory(ctx context.Context, name, description string,
 		return nil, err
 	}
 
+	if s.guard != nil {
+		existing, err := s.repo.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error al contar categorías: %w", err)
+		}
+		if err := s.guard.CheckLimit(ctx, userID, domain.EntitlementMaxCategories, len(existing)); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.repo.Create(ctx, category); err != nil {
 		return nil, err
 	}
@@ -48,7 +62,14 @@ func (s *Service) CreateCategory(ctx context.Context, name, description string,
 
 // GetCategoryByID obtiene una categoría por su ID
 func (s *Service) GetCategoryByID(ctx context.Context, id string) (*domain.Category, error) {
-	return s.repo.GetByID(ctx, id)
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.NewNotFoundError("category", "categoría no encontrada con id: %s", id)
+	}
+	return category, nil
 }
 
 // GetCategoriesByUserID obtiene todas las categorías de un usuario
@@ -58,7 +79,7 @@ func (s *Service) GetCategoriesByUserID(ctx context.Context, userID string) ([]*
 
 // UpdateCategory actualiza una categoría existente
 func (s *Service) UpdateCategory(ctx context.Context, id, name, description string, icon, color string) (*domain.Category, error) {
-	category, err := s.repo.GetByID(ctx, id)
+	category, err := s.GetCategoryByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +111,27 @@ func (s *Service) UpdateCategory(ctx context.Context, id, name, description stri
 	return category, nil
 }
 
+// SetCategoryBudget fija el presupuesto mensual de una categoría, o lo quita si budget es nil
+func (s *Service) SetCategoryBudget(ctx context.Context, id string, budget *domain.CategoryBudget) (*domain.Category, error) {
+	category, err := s.GetCategoryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Budget = budget
+	category.UpdatedAt = time.Now()
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
 // DeleteCategory elimina una categoría
 func (s *Service) DeleteCategory(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
@@ -0,0 +1,167 @@
+package coupon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// Service encapsula la lógica de negocio relacionada con los cupones promocionales
+type Service struct {
+	couponRepo app.CouponRepository
+	planRepo   app.PlanRepository
+}
+
+// NewService crea una nueva instancia del servicio de cupones
+func NewService(couponRepo app.CouponRepository, planRepo app.PlanRepository) *Service {
+	return &Service{
+		couponRepo: couponRepo,
+		planRepo:   planRepo,
+	}
+}
+
+// CreateCoupon crea un nuevo cupón
+func (s *Service) CreateCoupon(ctx context.Context, req *domain.CouponRequest) (*domain.Coupon, error) {
+	coupon := &domain.Coupon{}
+	applyRequest(coupon, req)
+
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.resolvePlanFamilies(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.couponRepo.GetByCode(ctx, coupon.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.NewConflictError("coupon_code_taken", "ya existe un cupón con el código %s", coupon.Code)
+	}
+
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+// GetCouponByID obtiene un cupón por su ID
+func (s *Service) GetCouponByID(ctx context.Context, id string) (*domain.Coupon, error) {
+	coupon, err := s.couponRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, domain.NewNotFoundError("coupon", "cupón no encontrado con id: %s", id)
+	}
+	return coupon, nil
+}
+
+// GetAllCoupons obtiene todos los cupones
+func (s *Service) GetAllCoupons(ctx context.Context) ([]*domain.Coupon, error) {
+	return s.couponRepo.GetAll(ctx)
+}
+
+// UpdateCoupon actualiza un cupón existente. Los canjes ya realizados conservan sus condiciones.
+func (s *Service) UpdateCoupon(ctx context.Context, id string, req *domain.CouponRequest) (*domain.Coupon, error) {
+	coupon, err := s.GetCouponByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyRequest(coupon, req)
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.resolvePlanFamilies(ctx, coupon); err != nil {
+		return nil, err
+	}
+	if coupon.MaxRedemptions != nil && *coupon.MaxRedemptions < coupon.TimesRedeemed {
+		return nil, domain.NewValidationError("max_redemptions", "el límite de canjes no puede ser menor que los canjes realizados")
+	}
+
+	existing, err := s.couponRepo.GetByCode(ctx, coupon.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != coupon.ID {
+		return nil, domain.NewConflictError("coupon_code_taken", "ya existe un cupón con el código %s", coupon.Code)
+	}
+
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+// DeleteCoupon elimina un cupón por su ID
+func (s *Service) DeleteCoupon(ctx context.Context, id string) error {
+	return s.couponRepo.Delete(ctx, id)
+}
+
+// ValidateCoupon verifica que un código pueda canjearse para un plan sin canjearlo
+func (s *Service) ValidateCoupon(ctx context.Context, code, planID string) (*domain.Coupon, error) {
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar plan: %w", err)
+	}
+
+	coupon, err := s.couponRepo.GetByCode(ctx, domain.NormalizeCouponCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, domain.ErrCouponNotRedeemable
+	}
+
+	if err := coupon.CheckRedeemable(plan, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+// resolvePlanFamilies reemplaza los planes del cupón por sus familias, para que el cupón siga
+// aplicando cuando se crean nuevas versiones de esos planes
+func (s *Service) resolvePlanFamilies(ctx context.Context, coupon *domain.Coupon) error {
+	families := make([]string, 0, len(coupon.PlanIDs))
+	seen := make(map[string]bool, len(coupon.PlanIDs))
+	for _, id := range coupon.PlanIDs {
+		plan, err := s.planRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error al verificar plan del cupón: %w", err)
+		}
+		familyID := plan.FamilyID
+		if familyID == "" {
+			familyID = plan.ID
+		}
+		if !seen[familyID] {
+			seen[familyID] = true
+			families = append(families, familyID)
+		}
+	}
+	coupon.PlanIDs = families
+	return nil
+}
+
+// applyRequest copia los datos de la solicitud al cupón
+func applyRequest(coupon *domain.Coupon, req *domain.CouponRequest) {
+	coupon.Code = domain.NormalizeCouponCode(req.Code)
+	coupon.Description = req.Description
+	coupon.DiscountType = req.DiscountType
+	coupon.PercentOff = req.PercentOff
+	coupon.AmountOff = req.AmountOff
+	coupon.CurrencyID = req.CurrencyID
+	coupon.Duration = req.Duration
+	coupon.DurationPeriods = req.DurationPeriods
+	coupon.PlanIDs = req.PlanIDs
+	coupon.MaxRedemptions = req.MaxRedemptions
+	coupon.ExpiresAt = req.ExpiresAt
+	coupon.IsActive = req.IsActive
+}
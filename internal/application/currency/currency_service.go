@@ -2,7 +2,6 @@ package currency
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,19 +26,19 @@ func NewService(repo app.CurrencyRepository) *Service {
 func (s *Service) CreateCurrency(ctx context.Context, code, name, symbol string, isActive bool) (*domain.Currency, error) {
 	// Validar que los datos requeridos estén presentes
 	if code == "" {
-		return nil, errors.New("el código de la moneda es obligatorio")
+		return nil, domain.NewValidationError("code", "el código de la moneda es obligatorio")
 	}
 	if name == "" {
-		return nil, errors.New("el nombre de la moneda es obligatorio")
+		return nil, domain.NewValidationError("name", "el nombre de la moneda es obligatorio")
 	}
 	if symbol == "" {
-		return nil, errors.New("el símbolo de la moneda es obligatorio")
+		return nil, domain.NewValidationError("symbol", "el símbolo de la moneda es obligatorio")
 	}
 
 	// Verificar si ya existe una moneda con el mismo código
 	existingCurrency, err := s.repo.GetByCode(ctx, code)
 	if err == nil && existingCurrency != nil {
-		return nil, errors.New("ya existe una moneda con este código")
+		return nil, domain.NewConflictError("currency_code_taken", "ya existe una moneda con este código")
 	}
 
 	// Crear la nueva moneda
@@ -85,16 +84,16 @@ func (s *Service) GetActiveCurrencies(ctx context.Context) ([]*domain.Currency,
 func (s *Service) UpdateCurrency(ctx context.Context, id, code, name, symbol string, isActive bool) (*domain.Currency, error) {
 	// Validar que los datos requeridos estén presentes
 	if id == "" {
-		return nil, errors.New("el ID de la moneda es obligatorio")
+		return nil, domain.NewValidationError("id", "el ID de la moneda es obligatorio")
 	}
 	if code == "" {
-		return nil, errors.New("el código de la moneda es obligatorio")
+		return nil, domain.NewValidationError("code", "el código de la moneda es obligatorio")
 	}
 	if name == "" {
-		return nil, errors.New("el nombre de la moneda es obligatorio")
+		return nil, domain.NewValidationError("name", "el nombre de la moneda es obligatorio")
 	}
 	if symbol == "" {
-		return nil, errors.New("el símbolo de la moneda es obligatorio")
+		return nil, domain.NewValidationError("symbol", "el símbolo de la moneda es obligatorio")
 	}
 
 	// Obtener la moneda existente
@@ -107,7 +106,7 @@ func (s *Service) UpdateCurrency(ctx context.Context, id, code, name, symbol str
 	if code != currency.Code {
 		existingCurrency, err := s.repo.GetByCode(ctx, code)
 		if err == nil && existingCurrency != nil && existingCurrency.ID != id {
-			return nil, errors.New("ya existe otra moneda con este código")
+			return nil, domain.NewConflictError("currency_code_taken", "ya existe otra moneda con este código")
 		}
 	}
 
@@ -126,7 +125,38 @@ func (s *Service) UpdateCurrency(ctx context.Context, id, code, name, symbol str
 	return currency, nil
 }
 
-// DeleteCurrency elimina una moneda por su ID
+// ArchiveCurrency desactiva una moneda: deja de poder usarse en nuevos planes
+// y se conserva para los registros que ya la referencian
+func (s *Service) ArchiveCurrency(ctx context.Context, id string) (*domain.Currency, error) {
+	currency, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !currency.IsActive {
+		return currency, nil
+	}
+
+	currency.IsActive = false
+	currency.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	return currency, nil
+}
+
+// DeleteCurrency elimina una moneda por su ID.
+// Solo se pueden eliminar las monedas que no usan planes, transacciones ni cupones; las demás deben archivarse.
 func (s *Service) DeleteCurrency(ctx context.Context, id string) error {
+	references, err := s.repo.CountReferences(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	inUse := &domain.ResourceInUseError{Resource: "currency", ID: id, References: references, Archivable: true}
+	if inUse.InUse() {
+		return inUse
+	}
+
 	return s.repo.Delete(ctx, id)
 }
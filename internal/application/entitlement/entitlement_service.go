@@ -0,0 +1,95 @@
+package entitlement
+
+import (
+	"context"
+	"fmt"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// Service resuelve los derechos de uso de un usuario a partir de su suscripción activa
+// e implementa el puerto app.EntitlementGuard
+type Service struct {
+	subscriptionRepo app.UserSubscriptionRepository
+	planRepo         app.PlanRepository
+}
+
+// NewService crea una nueva instancia del servicio de derechos de uso
+func NewService(subscriptionRepo app.UserSubscriptionRepository, planRepo app.PlanRepository) *Service {
+	return &Service{
+		subscriptionRepo: subscriptionRepo,
+		planRepo:         planRepo,
+	}
+}
+
+// GetUserEntitlements obtiene los derechos de uso vigentes de un usuario.
+// Sin suscripción activa se aplican los derechos del nivel gratuito.
+func (s *Service) GetUserEntitlements(ctx context.Context, userID string) (*domain.UserEntitlements, error) {
+	subscription, err := s.subscriptionRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener suscripción activa: %w", err)
+	}
+	if subscription == nil {
+		return &domain.UserEntitlements{UserID: userID, Entitlements: domain.DefaultEntitlements()}, nil
+	}
+
+	plan, err := s.planRepo.GetByID(ctx, subscription.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plan de la suscripción: %w", err)
+	}
+
+	return &domain.UserEntitlements{
+		UserID:       userID,
+		PlanID:       plan.ID,
+		Entitlements: plan.Entitlements,
+	}, nil
+}
+
+// CheckLimit verifica que el uso actual permita agregar un elemento más
+func (s *Service) CheckLimit(ctx context.Context, userID string, entitlement domain.Entitlement, usage int) error {
+	resolved, err := s.GetUserEntitlements(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	limit := resolved.Entitlements.Limit(entitlement)
+	if limit == nil || usage < *limit {
+		return nil
+	}
+
+	return &domain.UpgradeRequiredError{
+		Entitlement: entitlement,
+		Limit:       limit,
+		Current:     &usage,
+		PlanID:      resolved.PlanID,
+	}
+}
+
+// CheckFeature verifica que una funcionalidad esté habilitada
+func (s *Service) CheckFeature(ctx context.Context, userID string, entitlement domain.Entitlement) error {
+	resolved, err := s.GetUserEntitlements(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if resolved.Entitlements.Allows(entitlement) {
+		return nil
+	}
+
+	return &domain.UpgradeRequiredError{Entitlement: entitlement, PlanID: resolved.PlanID}
+}
+
+// CheckExportFormat verifica que el formato de exportación esté permitido
+func (s *Service) CheckExportFormat(ctx context.Context, userID string, format domain.ExportFormat) error {
+	resolved, err := s.GetUserEntitlements(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if resolved.Entitlements.AllowsExport(format) {
+		return nil
+	}
+
+	return &domain.UpgradeRequiredError{Entitlement: domain.EntitlementExportFormats, PlanID: resolved.PlanID}
+}
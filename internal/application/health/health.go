@@ -0,0 +1,179 @@
+// Package health ejecuta las comprobaciones de las dependencias de la aplicación (base de datos,
+// migraciones, tareas de fondo) y resume su resultado para las sondas de disponibilidad.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de una comprobación
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Status es el estado de una comprobación o del conjunto
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"   // Todo funciona
+	StatusDegraded  Status = "degraded"  // Falla una comprobación no crítica; se sigue sirviendo tráfico
+	StatusUnhealthy Status = "unhealthy" // Falla una comprobación crítica; no se debe enviar tráfico
+)
+
+// Check es una comprobación registrada
+type Check struct {
+	Name     string
+	Critical bool          // Si falla, la instancia no está lista; si no, solo queda degradada
+	Timeout  time.Duration // Tiempo máximo de Run; DefaultTimeout si es 0
+	CacheTTL time.Duration // Durante cuánto se reutiliza el último resultado; DefaultCacheTTL si es 0
+	Run      func(ctx context.Context) error
+}
+
+// CheckResult es el resultado de una comprobación
+type CheckResult struct {
+	Name       string    `json:"name"`
+	Status     Status    `json:"status"`
+	Critical   bool      `json:"critical"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report es el resultado de todas las comprobaciones, en el orden en que se registraron
+type Report struct {
+	Status    Status        `json:"status"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// registeredCheck guarda el último resultado de una comprobación. mu evita que varias sondas
+// simultáneas ejecuten la misma comprobación a la vez.
+type registeredCheck struct {
+	Check
+
+	mu     sync.Mutex
+	last   CheckResult
+	cached bool
+}
+
+// Registry contiene las comprobaciones de la aplicación
+type Registry struct {
+	mu     sync.RWMutex
+	checks []*registeredCheck
+}
+
+// NewRegistry crea un registro de comprobaciones vacío
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register añade una comprobación. Falla si no tiene nombre o función, o si el nombre está repetido.
+func (r *Registry) Register(check Check) error {
+	if check.Name == "" || check.Run == nil {
+		return fmt.Errorf("la comprobación debe tener nombre y función")
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultTimeout
+	}
+	if check.CacheTTL <= 0 {
+		check.CacheTTL = DefaultCacheTTL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.checks {
+		if existing.Name == check.Name {
+			return fmt.Errorf("la comprobación %q ya está registrada", check.Name)
+		}
+	}
+	r.checks = append(r.checks, &registeredCheck{Check: check})
+	return nil
+}
+
+// Run ejecuta en paralelo las comprobaciones cuyo resultado no está en caché y resume el estado:
+// unhealthy si falla alguna crítica, degraded si falla alguna no crítica y healthy si no falla ninguna
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]*registeredCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	report := Report{
+		Status:    StatusHealthy,
+		CheckedAt: time.Now(),
+		Checks:    make([]CheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *registeredCheck) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		switch {
+		case result.Status == StatusHealthy:
+		case result.Critical:
+			report.Status = StatusUnhealthy
+		case report.Status == StatusHealthy:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run ejecuta check con su timeout, o devuelve su último resultado si sigue vigente
+func (r *Registry) run(ctx context.Context, check *registeredCheck) CheckResult {
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	if check.cached && time.Since(check.last.CheckedAt) < check.CacheTTL {
+		return check.last
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runWithTimeout(checkCtx, check.Run)
+	result := CheckResult{
+		Name:       check.Name,
+		Status:     StatusHealthy,
+		Critical:   check.Critical,
+		DurationMS: time.Since(start).Milliseconds(),
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		if !check.Critical {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+	}
+
+	// Si la sonda se canceló el fallo no es de la dependencia; no se guarda
+	if ctx.Err() == nil {
+		check.last, check.cached = result, true
+	}
+	return result
+}
+
+// runWithTimeout espera a fn hasta que venza ctx, aunque fn no respete la cancelación
+func runWithTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("la comprobación no respondió a tiempo: %w", ctx.Err())
+	}
+}
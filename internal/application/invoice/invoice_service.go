@@ -0,0 +1,101 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// Service implementa la lógica de negocio de las facturas
+type Service struct {
+	invoiceRepo app.InvoiceRepository
+	taxRate     float64
+}
+
+// NewService crea una nueva instancia del servicio de facturas.
+// taxRate es la tasa de impuesto incluida en los precios (0.15 = 15%).
+func NewService(invoiceRepo app.InvoiceRepository, taxRate float64) *Service {
+	return &Service{
+		invoiceRepo: invoiceRepo,
+		taxRate:     taxRate,
+	}
+}
+
+// IssueInvoice calcula impuestos, numera y guarda la factura. El cobro ya se hizo en la pasarela,
+// así que si la factura no se puede guardar queda pendiente y el ciclo de vida de suscripciones
+// la vuelve a emitir. Solo devuelve error si tampoco se pudo guardar como pendiente.
+func (s *Service) IssueInvoice(ctx context.Context, invoice *domain.Invoice) error {
+	invoice.ApplyTax(s.taxRate)
+
+	if err := invoice.Validate(); err != nil {
+		return fmt.Errorf("error de validación: %w", err)
+	}
+
+	err := s.invoiceRepo.Create(ctx, invoice)
+	if err == nil {
+		return nil
+	}
+
+	invoice.Sequence, invoice.Number = 0, ""
+	if pendingErr := s.invoiceRepo.CreatePending(ctx, invoice, err.Error()); pendingErr != nil {
+		return fmt.Errorf("error al emitir factura: %w", errors.Join(err, pendingErr))
+	}
+	slog.WarnContext(ctx, "factura pendiente de emitir", "invoice_id", invoice.ID, "subscription_id", invoice.SubscriptionID, "error", err)
+	return nil
+}
+
+// IssuePendingInvoices vuelve a emitir hasta limit facturas pendientes, de la más antigua a la
+// más reciente, y devuelve cuántas emitió. Las que vuelven a fallar quedan para la próxima ejecución.
+func (s *Service) IssuePendingInvoices(ctx context.Context, limit int) (int, error) {
+	pending, err := s.invoiceRepo.GetPending(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener facturas pendientes: %w", err)
+	}
+
+	issued := 0
+	var errs []error
+	for _, item := range pending {
+		if issueErr := s.invoiceRepo.IssuePending(ctx, item); issueErr != nil {
+			errs = append(errs, fmt.Errorf("error al emitir factura pendiente %s: %w", item.Invoice.ID, issueErr))
+			if err := s.invoiceRepo.RecordPendingFailure(ctx, item.Invoice.ID, issueErr.Error()); err != nil {
+				slog.ErrorContext(ctx, "error al registrar intento de factura pendiente", "invoice_id", item.Invoice.ID, "error", err)
+			}
+			continue
+		}
+		issued++
+	}
+
+	return issued, errors.Join(errs...)
+}
+
+// GetInvoiceByID obtiene una factura por su ID
+func (s *Service) GetInvoiceByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener factura: %w", err)
+	}
+	return invoice, nil
+}
+
+// GetSubscriptionInvoices obtiene el historial de facturas de una suscripción
+func (s *Service) GetSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]*domain.Invoice, error) {
+	invoices, err := s.invoiceRepo.GetBySubscriptionID(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener facturas de la suscripción: %w", err)
+	}
+	return invoices, nil
+}
+
+// GetUserInvoices obtiene las facturas de un usuario emitidas en el rango [from, to)
+func (s *Service) GetUserInvoices(ctx context.Context, userID string, from, to time.Time) ([]*domain.Invoice, error) {
+	invoices, err := s.invoiceRepo.GetByUserID(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener facturas del usuario: %w", err)
+	}
+	return invoices, nil
+}
@@ -0,0 +1,196 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// htmlTemplate es la plantilla usada para mostrar una factura en el navegador
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": formatMoney,
+	"date":  func(t interface{ Format(string) string }) string { return t.Format("2006-01-02") },
+	"pct":   func(rate float64) string { return fmt.Sprintf("%.2f%%", rate*100) },
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Factura {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 40px; }
+h1 { margin-bottom: 0; }
+table { border-collapse: collapse; width: 100%; margin-top: 24px; }
+th, td { border-bottom: 1px solid #ddd; padding: 8px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+.totals td { border: none; }
+.status { text-transform: uppercase; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>MyMoney</h1>
+<p>Factura <strong>{{.Number}}</strong> &middot; Emitida el {{date .IssuedAt}}</p>
+<p>Tipo: {{.Type}} &middot; Estado: <span class="status">{{.Status}}</span></p>
+<p>Plan: {{.Plan.Name}} ({{.Plan.DescribeInterval}}) &middot; Suscripción {{.SubscriptionID}}</p>
+{{if .FailureReason}}<p>Motivo del rechazo: {{.FailureReason}}</p>{{end}}
+<table>
+<thead><tr><th>Descripción</th><th>Período</th><th class="amount">Cant.</th><th class="amount">Precio</th><th class="amount">Importe</th></tr></thead>
+<tbody>
+{{range .LineItems}}<tr>
+<td>{{.Description}}</td>
+<td>{{if .PeriodStart}}{{date .PeriodStart}} - {{date .PeriodEnd}}{{end}}</td>
+<td class="amount">{{.Quantity}}</td>
+<td class="amount">{{money .UnitAmount}}</td>
+<td class="amount">{{money .Amount}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<table class="totals">
+<tr><td class="amount">Subtotal</td><td class="amount">{{money .Subtotal}} {{.Currency}}</td></tr>
+<tr><td class="amount">Impuesto ({{pct .TaxRate}})</td><td class="amount">{{money .Tax}} {{.Currency}}</td></tr>
+<tr><td class="amount"><strong>Total</strong></td><td class="amount"><strong>{{money .Total}} {{.Currency}}</strong></td></tr>
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML genera la representación HTML de una factura
+func RenderHTML(invoice *domain.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, invoice); err != nil {
+		return nil, fmt.Errorf("error al generar HTML de la factura: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF genera un PDF de una página con el contenido de la factura.
+// Usa las fuentes estándar de PDF, por lo que no necesita dependencias externas.
+func RenderPDF(invoice *domain.Invoice) ([]byte, error) {
+	page := &pdfPage{}
+
+	page.text(50, 790, 20, true, "MyMoney")
+	page.text(50, 760, 14, true, "Factura "+invoice.Number)
+	page.text(50, 740, 10, false, "Emitida el "+invoice.IssuedAt.Format("2006-01-02"))
+	page.text(50, 725, 10, false, fmt.Sprintf("Tipo: %s    Estado: %s", invoice.Type, strings.ToUpper(string(invoice.Status))))
+	page.text(50, 710, 10, false, fmt.Sprintf("Plan: %s (%s)", invoice.Plan.Name, invoice.Plan.DescribeInterval()))
+	page.text(50, 695, 10, false, "Suscripción: "+invoice.SubscriptionID)
+	y := 680.0
+	if invoice.FailureReason != "" {
+		page.text(50, y, 10, false, "Motivo del rechazo: "+truncate(invoice.FailureReason, 80))
+		y -= 15
+	}
+
+	y -= 20
+	page.text(50, y, 10, true, "Descripción")
+	page.text(330, y, 10, true, "Cant.")
+	page.text(390, y, 10, true, "Precio")
+	page.text(480, y, 10, true, "Importe")
+	y -= 18
+
+	for _, item := range invoice.LineItems {
+		page.text(50, y, 10, false, truncate(item.Description, 50))
+		page.text(330, y, 10, false, fmt.Sprintf("%d", item.Quantity))
+		page.text(390, y, 10, false, formatMoney(item.UnitAmount))
+		page.text(480, y, 10, false, formatMoney(item.Amount))
+		if item.PeriodStart != nil && item.PeriodEnd != nil {
+			y -= 13
+			page.text(60, y, 8, false, item.PeriodStart.Format("2006-01-02")+" - "+item.PeriodEnd.Format("2006-01-02"))
+		}
+		y -= 18
+		if y < 120 {
+			break // Las facturas de suscripción tienen pocas líneas; no se pagina
+		}
+	}
+
+	y -= 10
+	page.text(330, y, 10, false, "Subtotal")
+	page.text(480, y, 10, false, formatMoney(invoice.Subtotal)+" "+invoice.Currency)
+	y -= 15
+	page.text(330, y, 10, false, fmt.Sprintf("Impuesto (%.2f%%)", invoice.TaxRate*100))
+	page.text(480, y, 10, false, formatMoney(invoice.Tax)+" "+invoice.Currency)
+	y -= 15
+	page.text(330, y, 11, true, "Total")
+	page.text(480, y, 11, true, formatMoney(invoice.Total)+" "+invoice.Currency)
+
+	return page.bytes(), nil
+}
+
+// pdfPage acumula las instrucciones de dibujo de una página PDF
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// text dibuja una línea de texto en la posición indicada (origen abajo a la izquierda)
+func (p *pdfPage) text(x, y, size float64, bold bool, value string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(value))
+}
+
+// bytes genera el documento PDF completo con una página A4
+func (p *pdfPage) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return doc.Bytes()
+}
+
+// pdfString codifica un texto en WinAnsi y escapa los caracteres especiales de PDF
+func pdfString(value string) string {
+	var buf strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			buf.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			// Latin-1 coincide con WinAnsi en este rango (acentos, ñ, etc.)
+			fmt.Fprintf(&buf, "\\%03o", r)
+		default:
+			buf.WriteByte('?')
+		}
+	}
+	return buf.String()
+}
+
+// formatMoney da formato a un importe con dos decimales
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// truncate recorta un texto a un máximo de caracteres
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,330 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// tracer crea los spans de los casos de uso
+var tracer = otel.Tracer("MyMoneyBackend/application/notification")
+
+// Service implementa la lógica de negocio de las notificaciones
+type Service struct {
+	notifiers      map[domain.NotificationChannel]app.Notifier
+	inboxRepo      app.NotificationRepository
+	preferenceRepo app.NotificationPreferenceRepository
+	templateRepo   app.NotificationTemplateRepository
+	userRepo       app.UserRepository
+}
+
+// NewService crea una nueva instancia del servicio de notificaciones
+func NewService(
+	inboxRepo app.NotificationRepository,
+	preferenceRepo app.NotificationPreferenceRepository,
+	templateRepo app.NotificationTemplateRepository,
+	userRepo app.UserRepository,
+	notifiers ...app.Notifier,
+) *Service {
+	registered := make(map[domain.NotificationChannel]app.Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		registered[notifier.Channel()] = notifier
+	}
+
+	return &Service{
+		notifiers:      registered,
+		inboxRepo:      inboxRepo,
+		preferenceRepo: preferenceRepo,
+		templateRepo:   templateRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// Publish entrega un evento por todos los canales habilitados del usuario.
+// Un fallo en un canal no impide la entrega por el resto; los errores se devuelven combinados.
+func (s *Service) Publish(ctx context.Context, userID string, event domain.NotificationEvent, data map[string]string) error {
+	ctx, span := tracer.Start(ctx, "notification.Publish")
+	defer span.End()
+
+	if !event.IsValid() {
+		return domain.NewValidationError("event", fmt.Sprintf("evento de notificación no válido: %s", event))
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error al obtener usuario: %w", err)
+	}
+
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	templateData := make(map[string]string, len(data)+2)
+	for key, value := range data {
+		templateData[key] = value
+	}
+	templateData["user_name"] = user.Name
+	templateData["user_email"] = user.Email
+
+	var errs []error
+	for _, preference := range preferences {
+		if preference.Event != event || !preference.Enabled {
+			continue
+		}
+
+		notifier, ok := s.notifiers[preference.Channel]
+		if !ok {
+			continue
+		}
+
+		message, err := s.render(ctx, event, preference.Channel, templateData)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		message.UserID = userID
+		message.Data = data
+
+		switch preference.Channel {
+		case domain.NotificationChannelEmail:
+			message.Recipient = user.Email
+			if preference.Target != "" {
+				message.Recipient = preference.Target
+			}
+		case domain.NotificationChannelWebhook:
+			message.Recipient = preference.Target
+		default:
+			message.Recipient = userID
+		}
+
+		if err := notifier.Send(ctx, message); err != nil {
+			slog.ErrorContext(ctx, "error al enviar notificación", "channel", preference.Channel, "event", event, "user_id", userID, "error", err)
+			errs = append(errs, fmt.Errorf("error al enviar notificación por %s: %w", preference.Channel, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetInbox obtiene las notificaciones en la bandeja de entrada de un usuario
+func (s *Service) GetInbox(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error) {
+	notifications, err := s.inboxRepo.GetByUserID(ctx, userID, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener notificaciones: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkAsRead marca una notificación del usuario como leída
+func (s *Service) MarkAsRead(ctx context.Context, userID, id string) error {
+	notification, err := s.inboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error al obtener notificación: %w", err)
+	}
+	if notification == nil || notification.UserID != userID {
+		return domain.NewNotFoundError("notification", "notificación no encontrada con id: %s", id)
+	}
+	if notification.IsRead() {
+		return nil
+	}
+
+	if err := s.inboxRepo.MarkAsRead(ctx, id); err != nil {
+		return fmt.Errorf("error al marcar notificación como leída: %w", err)
+	}
+	return nil
+}
+
+// MarkAllAsRead marca todas las notificaciones del usuario como leídas
+func (s *Service) MarkAllAsRead(ctx context.Context, userID string) error {
+	if err := s.inboxRepo.MarkAllAsRead(ctx, userID); err != nil {
+		return fmt.Errorf("error al marcar notificaciones como leídas: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences obtiene las preferencias efectivas de un usuario para todos los eventos y canales.
+// Los pares evento/canal sin preferencia guardada usan los valores por defecto.
+func (s *Service) GetPreferences(ctx context.Context, userID string) ([]*domain.NotificationPreference, error) {
+	stored, err := s.preferenceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener preferencias de notificación: %w", err)
+	}
+
+	byKey := make(map[string]*domain.NotificationPreference, len(stored))
+	for _, preference := range stored {
+		byKey[string(preference.Event)+"/"+string(preference.Channel)] = preference
+	}
+
+	var preferences []*domain.NotificationPreference
+	for _, event := range domain.NotificationEvents {
+		for _, channel := range channelOrder {
+			if preference, ok := byKey[string(event)+"/"+string(channel)]; ok {
+				preferences = append(preferences, preference)
+				continue
+			}
+			preferences = append(preferences, &domain.NotificationPreference{
+				UserID:  userID,
+				Event:   event,
+				Channel: channel,
+				Enabled: defaultChannels[channel],
+			})
+		}
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreference crea o actualiza la preferencia de un usuario para un evento y canal
+func (s *Service) UpdatePreference(
+	ctx context.Context,
+	userID string,
+	event domain.NotificationEvent,
+	channel domain.NotificationChannel,
+	enabled bool,
+	target string,
+) (*domain.NotificationPreference, error) {
+	preference := &domain.NotificationPreference{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Event:     event,
+		Channel:   channel,
+		Enabled:   enabled,
+		Target:    target,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := preference.Validate(); err != nil {
+		return nil, fmt.Errorf("error de validación: %w", err)
+	}
+
+	if err := s.preferenceRepo.Upsert(ctx, preference); err != nil {
+		return nil, fmt.Errorf("error al guardar preferencia de notificación: %w", err)
+	}
+
+	return preference, nil
+}
+
+// GetTemplates obtiene las plantillas efectivas de todos los eventos y canales
+func (s *Service) GetTemplates(ctx context.Context) ([]*domain.NotificationTemplate, error) {
+	var templates []*domain.NotificationTemplate
+	for _, event := range domain.NotificationEvents {
+		for _, channel := range channelOrder {
+			tmpl, err := s.getTemplate(ctx, event, channel)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, tmpl)
+		}
+	}
+	return templates, nil
+}
+
+// UpdateTemplate crea o actualiza la plantilla de un evento y canal
+func (s *Service) UpdateTemplate(
+	ctx context.Context,
+	event domain.NotificationEvent,
+	channel domain.NotificationChannel,
+	subject, body string,
+) (*domain.NotificationTemplate, error) {
+	tmpl := &domain.NotificationTemplate{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Channel:   channel,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := tmpl.Validate(); err != nil {
+		return nil, fmt.Errorf("error de validación: %w", err)
+	}
+
+	// Verificar que la plantilla sea válida antes de guardarla
+	if _, err := template.New("subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("el asunto de la plantilla no es válido: %w", err)
+	}
+	if _, err := template.New("body").Parse(body); err != nil {
+		return nil, fmt.Errorf("el cuerpo de la plantilla no es válido: %w", err)
+	}
+
+	if err := s.templateRepo.Upsert(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("error al guardar plantilla de notificación: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// getTemplate obtiene la plantilla guardada o la plantilla por defecto del evento
+func (s *Service) getTemplate(ctx context.Context, event domain.NotificationEvent, channel domain.NotificationChannel) (*domain.NotificationTemplate, error) {
+	tmpl, err := s.templateRepo.Get(ctx, event, channel)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plantilla de notificación: %w", err)
+	}
+	if tmpl != nil {
+		return tmpl, nil
+	}
+
+	fallback := defaultTemplates[event]
+	return &domain.NotificationTemplate{
+		Event:   event,
+		Channel: channel,
+		Subject: fallback.Subject,
+		Body:    fallback.Body,
+	}, nil
+}
+
+// render genera el mensaje de un evento para un canal a partir de su plantilla
+func (s *Service) render(
+	ctx context.Context,
+	event domain.NotificationEvent,
+	channel domain.NotificationChannel,
+	data map[string]string,
+) (*domain.NotificationMessage, error) {
+	tmpl, err := s.getTemplate(ctx, event, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := execute(tmpl.Subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("error al renderizar asunto de %s: %w", event, err)
+	}
+	body, err := execute(tmpl.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("error al renderizar cuerpo de %s: %w", event, err)
+	}
+
+	return &domain.NotificationMessage{
+		Event:     event,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// execute renderiza una plantilla de texto con los datos del evento
+func execute(text string, data map[string]string) (string, error) {
+	tmpl, err := template.New("notification").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
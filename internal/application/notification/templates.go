@@ -0,0 +1,46 @@
+package notification
+
+import "MyMoneyBackend/internal/domain"
+
+// defaultTemplates contiene las plantillas usadas cuando no hay una personalizada en la base de datos.
+// Los datos disponibles en cada plantilla son los del evento más user_name y user_email.
+var defaultTemplates = map[domain.NotificationEvent]domain.NotificationTemplate{
+	domain.NotificationEventBudgetExceeded: {
+		Subject: "Has superado tu presupuesto {{.budget_name}}",
+		Body:    "Hola {{.user_name}}, tus gastos en {{.budget_name}} suman {{.spent}} {{.currency}} este mes y superan el límite de {{.limit}} {{.currency}}.",
+	},
+	domain.NotificationEventSubscriptionExpiring: {
+		Subject: "Tu suscripción {{.plan_name}} está por expirar",
+		Body:    "Hola {{.user_name}}, tu suscripción al plan {{.plan_name}} expira el {{.end_date}}. Renuévala para no perder el acceso.",
+	},
+	domain.NotificationEventPaymentFailed: {
+		Subject: "No pudimos procesar tu pago",
+		Body:    "Hola {{.user_name}}, el cobro de {{.amount}} {{.currency}} para tu plan {{.plan_name}} falló: {{.reason}}. Volveremos a intentarlo el {{.next_attempt}}.",
+	},
+	domain.NotificationEventPasswordChanged: {
+		Subject: "Tu contraseña fue cambiada",
+		Body:    "Hola {{.user_name}}, la contraseña de tu cuenta {{.user_email}} fue cambiada el {{.changed_at}}. Si no fuiste tú, contacta a soporte.",
+	},
+	domain.NotificationEventTrialEnded: {
+		Subject: "Tu prueba gratuita de {{.plan_name}} terminó",
+		Body:    "Hola {{.user_name}}, tu prueba gratuita del plan {{.plan_name}} terminó el {{.trial_end_date}}. Agrega un método de pago y suscríbete para seguir usándolo.",
+	},
+	domain.NotificationEventPlanMigrationScheduled: {
+		Subject: "Cambios en tu plan {{.plan_name}}",
+		Body:    "Hola {{.user_name}}, tu plan {{.plan_name}} se actualizará a partir de tu renovación posterior al {{.effective_at}}. El precio pasará de {{.old_price}} a {{.new_price}} {{.currency}}. Puedes cambiar de plan o cancelar antes de esa fecha.",
+	},
+}
+
+// defaultChannels indica qué canales están habilitados cuando el usuario no ha configurado preferencias
+var defaultChannels = map[domain.NotificationChannel]bool{
+	domain.NotificationChannelInApp:   true,
+	domain.NotificationChannelEmail:   true,
+	domain.NotificationChannelWebhook: false,
+}
+
+// channelOrder define el orden en que se entregan las notificaciones
+var channelOrder = []domain.NotificationChannel{
+	domain.NotificationChannelInApp,
+	domain.NotificationChannelEmail,
+	domain.NotificationChannelWebhook,
+}
@@ -12,13 +12,15 @@ import (
 
 // Service maneja la lógica de negocio relacionada con métodos de pago
 type Service struct {
-	repo app.PaymentMethodRepository
+	repo    app.PaymentMethodRepository
+	gateway app.PaymentGateway
 }
 
 // NewService crea un nuevo servicio de métodos de pago
-func NewService(repo app.PaymentMethodRepository) *Service {
+func NewService(repo app.PaymentMethodRepository, gateway app.PaymentGateway) *Service {
 	return &Service{
-		repo: repo,
+		repo:    repo,
+		gateway: gateway,
 	}
 }
 
@@ -81,6 +83,38 @@ func (s *Service) UpdatePaymentMethod(ctx context.Context, id, name, description
 	return paymentMethod, nil
 }
 
+// AttachCard tokeniza una tarjeta en la pasarela de pago y la asocia al método de pago.
+// Solo se guardan el token, la marca y los últimos dígitos; nunca los datos de la tarjeta.
+func (s *Service) AttachCard(ctx context.Context, id string, card *domain.CardDetails) (*domain.PaymentMethod, error) {
+	paymentMethod, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if paymentMethod == nil {
+		return nil, domain.NewNotFoundError("payment_method", "método de pago no encontrado con ID: %s", id)
+	}
+
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, err := s.gateway.Tokenize(ctx, card)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentMethod.CardToken = token.Token
+	paymentMethod.CardBrand = token.Brand
+	paymentMethod.CardLast4 = token.Last4
+	paymentMethod.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, paymentMethod); err != nil {
+		return nil, err
+	}
+
+	return paymentMethod, nil
+}
+
 // DeletePaymentMethod elimina un método de pago
 func (s *Service) DeletePaymentMethod(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
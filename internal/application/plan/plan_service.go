@@ -2,7 +2,6 @@ package plan
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,15 +12,21 @@ import (
 
 // Service encapsula la lógica de negocio relacionada con los planes
 type Service struct {
-	planRepo     app.PlanRepository
-	currencyRepo app.CurrencyRepository
+	planRepo         app.PlanRepository
+	currencyRepo     app.CurrencyRepository
+	subscriptionRepo app.UserSubscriptionRepository
 }
 
 // NewService crea una nueva instancia del servicio de planes
-func NewService(planRepo app.PlanRepository, currencyRepo app.CurrencyRepository) *Service {
+func NewService(
+	planRepo app.PlanRepository,
+	currencyRepo app.CurrencyRepository,
+	subscriptionRepo app.UserSubscriptionRepository,
+) *Service {
 	return &Service{
-		planRepo:     planRepo,
-		currencyRepo: currencyRepo,
+		planRepo:         planRepo,
+		currencyRepo:     currencyRepo,
+		subscriptionRepo: subscriptionRepo,
 	}
 }
 
@@ -32,52 +37,70 @@ func (s *Service) CreatePlan(
 	price float64,
 	currencyID string,
 	interval domain.PlanInterval,
+	intervalCount int,
 	features []domain.PlanFeature,
+	trialDays int,
+	entitlements domain.Entitlements,
 	isActive, isPublic bool,
 	sortOrder int,
 ) (*domain.Plan, error) {
 	// Validar datos básicos
 	if name == "" {
-		return nil, errors.New("el nombre del plan es obligatorio")
+		return nil, domain.NewValidationError("name", "el nombre del plan es obligatorio")
 	}
 	if description == "" {
-		return nil, errors.New("la descripción del plan es obligatoria")
+		return nil, domain.NewValidationError("description", "la descripción del plan es obligatoria")
 	}
 	if price < 0 {
-		return nil, errors.New("el precio no puede ser negativo")
+		return nil, domain.NewValidationError("price", "el precio no puede ser negativo")
 	}
 	if currencyID == "" {
-		return nil, errors.New("la moneda es obligatoria")
+		return nil, domain.NewValidationError("currency_id", "la moneda es obligatoria")
+	}
+	if trialDays < 0 {
+		return nil, domain.NewValidationError("trial_days", "los días de prueba no pueden ser negativos")
+	}
+	if err := entitlements.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Validar que la moneda exista
 	currency, err := s.currencyRepo.GetByID(ctx, currencyID)
 	if err != nil {
-		return nil, errors.New("moneda no válida: " + err.Error())
+		return nil, domain.NewValidationError("currency_id", "moneda no válida: "+err.Error())
 	}
 	if !currency.IsActive {
-		return nil, errors.New("la moneda seleccionada no está activa")
+		return nil, domain.NewValidationError("currency_id", "la moneda seleccionada no está activa")
 	}
 
 	// Validar intervalo
-	if interval != domain.PlanIntervalMonthly && interval != domain.PlanIntervalYearly {
-		return nil, errors.New("el intervalo de facturación debe ser mensual o anual")
+	if !interval.IsValid() {
+		return nil, domain.NewValidationError("interval", "el intervalo de facturación no es válido")
+	}
+	if interval == domain.PlanIntervalLifetime {
+		intervalCount = 1
+	}
+	if intervalCount < 1 {
+		return nil, domain.NewValidationError("interval_count", "la cantidad del intervalo debe ser al menos 1")
 	}
 
 	// Crear el nuevo plan
 	plan := &domain.Plan{
-		ID:          uuid.New().String(),
-		Name:        name,
-		Description: description,
-		Price:       price,
-		CurrencyID:  currencyID,
-		Interval:    interval,
-		Features:    features,
-		IsActive:    isActive,
-		IsPublic:    isPublic,
-		SortOrder:   sortOrder,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:            uuid.New().String(),
+		Name:          name,
+		Description:   description,
+		Price:         price,
+		CurrencyID:    currencyID,
+		Interval:      interval,
+		IntervalCount: intervalCount,
+		Features:      features,
+		TrialDays:     trialDays,
+		Entitlements:  entitlements,
+		IsActive:      isActive,
+		IsPublic:      isPublic,
+		SortOrder:     sortOrder,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Guardar el plan en la base de datos
@@ -108,78 +131,212 @@ func (s *Service) GetActivePlans(ctx context.Context) ([]*domain.Plan, error) {
 	return s.planRepo.GetAllActive(ctx)
 }
 
-// UpdatePlan actualiza un plan existente
+// UpdatePlan crea una nueva versión del plan con los datos indicados.
+// Las suscripciones existentes conservan la versión que contrataron hasta que se las migre.
 func (s *Service) UpdatePlan(
 	ctx context.Context,
 	id, name, description string,
 	price float64,
 	currencyID string,
 	interval domain.PlanInterval,
+	intervalCount int,
 	features []domain.PlanFeature,
+	trialDays int,
+	entitlements domain.Entitlements,
 	isActive, isPublic bool,
 	sortOrder int,
 ) (*domain.Plan, error) {
 	// Validar datos básicos
 	if id == "" {
-		return nil, errors.New("el ID del plan es obligatorio")
+		return nil, domain.NewValidationError("id", "el ID del plan es obligatorio")
 	}
 	if name == "" {
-		return nil, errors.New("el nombre del plan es obligatorio")
+		return nil, domain.NewValidationError("name", "el nombre del plan es obligatorio")
 	}
 	if description == "" {
-		return nil, errors.New("la descripción del plan es obligatoria")
+		return nil, domain.NewValidationError("description", "la descripción del plan es obligatoria")
 	}
 	if price < 0 {
-		return nil, errors.New("el precio no puede ser negativo")
+		return nil, domain.NewValidationError("price", "el precio no puede ser negativo")
 	}
 	if currencyID == "" {
-		return nil, errors.New("la moneda es obligatoria")
+		return nil, domain.NewValidationError("currency_id", "la moneda es obligatoria")
+	}
+	if trialDays < 0 {
+		return nil, domain.NewValidationError("trial_days", "los días de prueba no pueden ser negativos")
+	}
+	if err := entitlements.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Validar que la moneda exista
 	currency, err := s.currencyRepo.GetByID(ctx, currencyID)
 	if err != nil {
-		return nil, errors.New("moneda no válida: " + err.Error())
+		return nil, domain.NewValidationError("currency_id", "moneda no válida: "+err.Error())
 	}
 	if !currency.IsActive {
-		return nil, errors.New("la moneda seleccionada no está activa")
+		return nil, domain.NewValidationError("currency_id", "la moneda seleccionada no está activa")
 	}
 
 	// Validar intervalo
-	if interval != domain.PlanIntervalMonthly && interval != domain.PlanIntervalYearly {
-		return nil, errors.New("el intervalo de facturación debe ser mensual o anual")
+	if !interval.IsValid() {
+		return nil, domain.NewValidationError("interval", "el intervalo de facturación no es válido")
+	}
+	if interval == domain.PlanIntervalLifetime {
+		intervalCount = 1
+	}
+	if intervalCount < 1 {
+		return nil, domain.NewValidationError("interval_count", "la cantidad del intervalo debe ser al menos 1")
 	}
 
-	// Obtener el plan existente
-	plan, err := s.planRepo.GetByID(ctx, id)
+	// Obtener la versión vigente del plan
+	current, err := s.planRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if !current.IsLatestVersion() {
+		return nil, domain.NewConflictError("plan_superseded", "solo se puede modificar la versión vigente del plan")
+	}
+	if current.IsArchived() {
+		return nil, domain.ErrPlanArchived
+	}
 
-	// Actualizar los datos del plan
+	// Crear la nueva versión; la anterior se conserva para sus suscriptores
+	plan := current.NextVersion()
 	plan.Name = name
 	plan.Description = description
 	plan.Price = price
 	plan.CurrencyID = currencyID
 	plan.Interval = interval
+	plan.IntervalCount = intervalCount
 	plan.Features = features
+	plan.TrialDays = trialDays
+	plan.Entitlements = entitlements
 	plan.IsActive = isActive
 	plan.IsPublic = isPublic
 	plan.SortOrder = sortOrder
-	plan.UpdatedAt = time.Now()
 
-	// Guardar los cambios en la base de datos
-	if err := s.planRepo.Update(ctx, plan); err != nil {
+	// Guardar la nueva versión y marcar la anterior como reemplazada
+	if err := s.planRepo.CreateVersion(ctx, current, plan); err != nil {
 		return nil, err
 	}
 
 	return plan, nil
 }
 
-// DeletePlan elimina un plan por su ID
+// GetPlanVersions obtiene todas las versiones de la familia del plan indicado
+func (s *Service) GetPlanVersions(ctx context.Context, id string) ([]*domain.Plan, error) {
+	plan, err := s.planRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.planRepo.GetVersions(ctx, plan.FamilyID)
+}
+
+// GetPlanUsage obtiene la cantidad de suscripciones de cada versión de plan
+func (s *Service) GetPlanUsage(ctx context.Context) ([]*domain.PlanUsage, error) {
+	plans, err := s.planRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.subscriptionRepo.CountByPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*domain.PlanUsage, len(plans))
+	for i, plan := range plans {
+		planUsage := &domain.PlanUsage{PlanID: plan.ID}
+		if count, ok := counts[plan.ID]; ok {
+			planUsage = count
+		}
+		planUsage.FamilyID = plan.FamilyID
+		planUsage.Version = plan.Version
+		planUsage.Name = plan.Name
+		planUsage.Archived = plan.IsArchived()
+		usage[i] = planUsage
+	}
+
+	return usage, nil
+}
+
+// ArchivePlan archiva todas las versiones del plan: deja de ofrecerse a nuevos suscriptores
+// y los actuales lo conservan y siguen renovándolo
+func (s *Service) ArchivePlan(ctx context.Context, id string) (*domain.Plan, error) {
+	plan, err := s.planRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if plan.IsArchived() {
+		return nil, domain.ErrPlanArchived
+	}
+
+	if err := s.planRepo.Archive(ctx, plan.FamilyID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.planRepo.GetByID(ctx, id)
+}
+
+// UnarchivePlan vuelve a ofrecer un plan archivado: su versión vigente queda activa y, si isPublic,
+// visible públicamente. La moneda del plan debe seguir activa.
+func (s *Service) UnarchivePlan(ctx context.Context, id string, isPublic bool) (*domain.Plan, error) {
+	plan, err := s.planRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsArchived() {
+		return nil, domain.NewConflictError("plan_not_archived", "el plan %s no está archivado", id)
+	}
+
+	currency, err := s.currencyRepo.GetByID(ctx, plan.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	if !currency.IsActive {
+		return nil, domain.NewConflictError("currency_archived", "la moneda del plan %s está archivada", id)
+	}
+
+	if err := s.planRepo.Unarchive(ctx, plan.FamilyID, isPublic); err != nil {
+		return nil, err
+	}
+
+	return s.planRepo.GetByID(ctx, id)
+}
+
+// DeletePlan elimina un plan y todas sus versiones. Solo se pueden eliminar los planes
+// que nunca tuvieron suscripciones; los demás deben archivarse.
 func (s *Service) DeletePlan(ctx context.Context, id string) error {
-	// Verificar si hay suscripciones activas con este plan
-	// Esta verificación debería implementarse cuando se cree el repositorio de suscripciones
+	plan, err := s.planRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	versions, err := s.planRepo.GetVersions(ctx, plan.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	// Verificar si alguna versión tiene suscripciones, incluso canceladas o expiradas
+	counts, err := s.subscriptionRepo.CountByPlan(ctx)
+	if err != nil {
+		return err
+	}
+	subscriptions := 0
+	for _, version := range versions {
+		if count, ok := counts[version.ID]; ok {
+			subscriptions += count.TotalSubscriptions
+		}
+	}
+	if subscriptions > 0 {
+		return &domain.ResourceInUseError{
+			Resource:   "plan",
+			ID:         id,
+			References: map[string]int{"user_subscriptions": subscriptions},
+			Archivable: true,
+		}
+	}
 
 	return s.planRepo.Delete(ctx, id)
 }
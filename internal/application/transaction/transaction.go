@@ -2,28 +2,56 @@ package transaction
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"MyMoneyBackend/internal/domain"
 	"MyMoneyBackend/internal/domain/ports/app"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer crea los spans de los casos de uso
+var tracer = otel.Tracer("MyMoneyBackend/application/transaction")
+
 // Service maneja la lógica de negocio relacionada con transacciones
 type Service struct {
-	repo app.TransactionRepository
+	repo         app.TransactionRepository
+	categoryRepo app.CategoryRepository
+	currencyRepo app.CurrencyRepository
+	guard        app.EntitlementGuard
+	notifier     app.NotificationPublisher
+	metrics      app.Metrics
 }
 
-// NewService crea un nuevo servicio de transacciones
-func NewService(repo app.TransactionRepository) *Service {
+// NewService crea un nuevo servicio de transacciones.
+// Si guard es nil no se aplican los límites del plan; si notifier es nil no se avisa de los
+// presupuestos superados; si metrics es nil no se registran métricas.
+func NewService(
+	repo app.TransactionRepository,
+	categoryRepo app.CategoryRepository,
+	currencyRepo app.CurrencyRepository,
+	guard app.EntitlementGuard,
+	notifier app.NotificationPublisher,
+	metrics app.Metrics,
+) *Service {
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		categoryRepo: categoryRepo,
+		currencyRepo: currencyRepo,
+		guard:        guard,
+		notifier:     notifier,
+		metrics:      metrics,
 	}
 }
 
 // CreateTransaction crea una nueva transacción
 func (s *Service) CreateTransaction(ctx context.Context, amount float64, description string, date time.Time, categoryID, paymentMethodID, userID string, currencyID string, transactionType domain.TransactionType) (*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.CreateTransaction")
+	defer span.End()
+
 	transaction := &domain.Transaction{
 		ID:              uuid.New().String(),
 		Amount:          amount,
@@ -42,35 +70,103 @@ func (s *Service) CreateTransaction(ctx context.Context, amount float64, descrip
 		return nil, err
 	}
 
+	if err := s.checkMonthlyLimit(ctx, userID, transaction.CreatedAt); err != nil {
+		return nil, err
+	}
+
 	if err := s.repo.Create(ctx, transaction); err != nil {
 		return nil, err
 	}
 
+	if s.metrics != nil {
+		s.metrics.TransactionCreated()
+	}
+
+	// Avisar si el gasto supera el presupuesto; un fallo no deshace la transacción
+	if err := s.notifyBudgetExceeded(ctx, transaction); err != nil {
+		slog.ErrorContext(ctx, "error al comprobar el presupuesto", "category_id", transaction.CategoryID, "error", err)
+	}
+
 	return transaction, nil
 }
 
+// notifyBudgetExceeded publica budget_exceeded si el gasto hace que la categoría supere su
+// presupuesto en el mes de la transacción. Solo avisa al cruzar el límite, no en cada gasto posterior.
+func (s *Service) notifyBudgetExceeded(ctx context.Context, transaction *domain.Transaction) error {
+	if s.notifier == nil || transaction.Type != domain.TransactionTypeExpense {
+		return nil
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, transaction.CategoryID)
+	if err != nil {
+		return fmt.Errorf("error al obtener la categoría: %w", err)
+	}
+	if category == nil || category.Budget == nil || category.Budget.CurrencyID != transaction.CurrencyID {
+		return nil
+	}
+
+	from := time.Date(transaction.Date.Year(), transaction.Date.Month(), 1, 0, 0, 0, 0, transaction.Date.Location())
+	spent, err := s.repo.SumExpenses(ctx, category.ID, transaction.CurrencyID, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		return err
+	}
+	limit := category.Budget.Amount
+	if spent <= limit || spent-transaction.Amount > limit {
+		return nil
+	}
+
+	currency, err := s.currencyRepo.GetByID(ctx, transaction.CurrencyID)
+	if err != nil {
+		return fmt.Errorf("error al obtener la moneda: %w", err)
+	}
+	data := map[string]string{
+		"budget_name": category.Name,
+		"category_id": category.ID,
+		"month":       from.Format("2006-01"),
+		"spent":       fmt.Sprintf("%.2f", spent),
+		"limit":       fmt.Sprintf("%.2f", limit),
+		"currency":    currency.Code,
+	}
+	return s.notifier.Publish(ctx, transaction.UserID, domain.NotificationEventBudgetExceeded, data)
+}
+
 // GetTransactionByID obtiene una transacción por su ID
 func (s *Service) GetTransactionByID(ctx context.Context, id string) (*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionByID")
+	defer span.End()
+
 	return s.repo.GetByID(ctx, id)
 }
 
 // GetTransactionsByUserID obtiene todas las transacciones de un usuario
 func (s *Service) GetTransactionsByUserID(ctx context.Context, userID string) ([]*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionsByUserID")
+	defer span.End()
+
 	return s.repo.GetByUserID(ctx, userID)
 }
 
 // GetTransactionsByCategoryID obtiene todas las transacciones de una categoría
 func (s *Service) GetTransactionsByCategoryID(ctx context.Context, categoryID string) ([]*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionsByCategoryID")
+	defer span.End()
+
 	return s.repo.GetByCategoryID(ctx, categoryID)
 }
 
 // GetTransactionsByDateRange obtiene todas las transacciones de un usuario en un rango de fechas
 func (s *Service) GetTransactionsByDateRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionsByDateRange")
+	defer span.End()
+
 	return s.repo.GetByDateRange(ctx, userID, startDate, endDate)
 }
 
 // UpdateTransaction actualiza una transacción existente
 func (s *Service) UpdateTransaction(ctx context.Context, id string, amount float64, description string, date time.Time, categoryID, paymentMethodID string, currencyID string, transactionType domain.TransactionType) (*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.UpdateTransaction")
+	defer span.End()
+
 	transaction, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -117,5 +213,45 @@ func (s *Service) UpdateTransaction(ctx context.Context, id string, amount float
 
 // DeleteTransaction elimina una transacción
 func (s *Service) DeleteTransaction(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "transaction.DeleteTransaction")
+	defer span.End()
+
 	return s.repo.Delete(ctx, id)
 }
+
+// ExportTransactions obtiene las transacciones del usuario a exportar si su plan admite el formato
+func (s *Service) ExportTransactions(ctx context.Context, userID string, format domain.ExportFormat) ([]*domain.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ExportTransactions")
+	defer span.End()
+
+	if !format.IsValid() {
+		return nil, domain.NewValidationError("format", fmt.Sprintf("formato de exportación no soportado: %s", format))
+	}
+
+	if s.guard != nil {
+		if err := s.guard.CheckExportFormat(ctx, userID, format); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+// checkMonthlyLimit verifica que el plan del usuario admita otra transacción en el mes en curso.
+// Se cuentan las transacciones registradas este mes y no las fechadas en él, para que cargar
+// transacciones con fechas de otros meses no evite el límite.
+func (s *Service) checkMonthlyLimit(ctx context.Context, userID string, now time.Time) error {
+	if s.guard == nil {
+		return nil
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	count, err := s.repo.CountCreatedBetween(ctx, userID, monthStart, monthEnd)
+	if err != nil {
+		return fmt.Errorf("error al contar transacciones del mes: %w", err)
+	}
+
+	return s.guard.CheckLimit(ctx, userID, domain.EntitlementMaxTransactionsPerMonth, count)
+}
@@ -1,8 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"time"
 
 	"MyMoneyBackend/internal/domain"
@@ -11,32 +12,43 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer crea los spans de los casos de uso
+var tracer = otel.Tracer("MyMoneyBackend/application/user")
+
 // UserService handles user business logic
 type UserService struct {
 	userRepo app.UserRepository
+	notifier app.NotificationPublisher
+	metrics  app.Metrics
 }
 
-// NewUserService creates a new UserService
-func NewUserService(userRepo app.UserRepository) *UserService {
+// NewUserService creates a new UserService. notifier and metrics may be nil.
+func NewUserService(userRepo app.UserRepository, notifier app.NotificationPublisher, metrics app.Metrics) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		notifier: notifier,
+		metrics:  metrics,
 	}
 }
 
 // RegisterUser registers a new user
-func (s *UserService) RegisterUser(email, name, password string) (*domain.User, error) {
+func (s *UserService) RegisterUser(ctx context.Context, email, name, password string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "user.RegisterUser")
+	defer span.End()
+
 	// Check if user already exists
-	existingUser, err := s.userRepo.GetByEmail(email)
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, domain.NewConflictError("email_taken", "user with this email already exists")
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		log.Printf("Error hashing password: %v", err)
+		slog.ErrorContext(ctx, "error al cifrar contraseña", "error", err)
 		return nil, errors.New("error creating user")
 	}
 
@@ -54,8 +66,8 @@ func (s *UserService) RegisterUser(email, name, password string) (*domain.User,
 		return nil, err
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
-		log.Printf("Error creating user: %v", err)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "error al crear usuario", "error", err)
 		return nil, errors.New("error creating user")
 	}
 
@@ -65,8 +77,8 @@ func (s *UserService) RegisterUser(email, name, password string) (*domain.User,
 }
 
 // GetUserByID gets a user by ID
-func (s *UserService) GetUserByID(id string) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -77,8 +89,8 @@ func (s *UserService) GetUserByID(id string) (*domain.User, error) {
 }
 
 // GetUserByEmail gets a user by email
-func (s *UserService) GetUserByEmail(email string) (*domain.User, error) {
-	user, err := s.userRepo.GetByEmail(email)
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +101,11 @@ func (s *UserService) GetUserByEmail(email string) (*domain.User, error) {
 }
 
 // UpdateUser updates a user's information
-func (s *UserService) UpdateUser(id, email, name string) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *UserService) UpdateUser(ctx context.Context, id, email, name string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "user.UpdateUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +118,7 @@ func (s *UserService) UpdateUser(id, email, name string) (*domain.User, error) {
 		user.Name = name
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -113,15 +128,18 @@ func (s *UserService) UpdateUser(id, email, name string) (*domain.User, error) {
 }
 
 // ChangePassword changes a user's password
-func (s *UserService) ChangePassword(id, currentPassword, newPassword string) error {
-	user, err := s.userRepo.GetByID(id)
+func (s *UserService) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	ctx, span := tracer.Start(ctx, "user.ChangePassword")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Verify current password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
-		return errors.New("current password is incorrect")
+		return domain.NewValidationError("current_password", "current password is incorrect")
 	}
 
 	// Hash new password
@@ -132,31 +150,70 @@ func (s *UserService) ChangePassword(id, currentPassword, newPassword string) er
 
 	user.Password = string(hashedPassword)
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return err
 	}
 
+	// Notify the user; a delivery failure must not undo the password change
+	if s.notifier != nil {
+		data := map[string]string{"changed_at": time.Now().Format(time.RFC1123)}
+		if err := s.notifier.Publish(ctx, user.ID, domain.NotificationEventPasswordChanged, data); err != nil {
+			slog.ErrorContext(ctx, "error al notificar cambio de contraseña", "user_id", user.ID, "error", err)
+		}
+	}
+
 	return nil
 }
 
 // AuthenticateUser authenticates a user
-func (s *UserService) AuthenticateUser(email, password string) (*domain.User, error) {
-	user, err := s.userRepo.GetByEmail(email)
+func (s *UserService) AuthenticateUser(ctx context.Context, email, password string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "user.AuthenticateUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		s.recordLogin(false)
+		return nil, domain.NewUnauthorizedError("invalid_credentials", "invalid email or password")
 	}
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid email or password")
+		s.recordLogin(false)
+		return nil, domain.NewUnauthorizedError("invalid_credentials", "invalid email or password")
 	}
+	s.recordLogin(true)
 
 	// Don't return the password
 	user.Password = ""
 	return user, nil
 }
 
-// DeleteUser deletes a user
-func (s *UserService) DeleteUser(id string) error {
-	return s.userRepo.Delete(id)
+// DeleteUser deletes a user. Users with invoices cannot be deleted: the invoices are kept as
+// billing history and reference the user.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "user.DeleteUser")
+	defer span.End()
+
+	references, err := s.userRepo.CountReferences(ctx, id)
+	if err != nil {
+		return err
+	}
+	inUse := &domain.ResourceInUseError{Resource: "user", ID: id, References: references}
+	if inUse.InUse() {
+		return inUse
+	}
+
+	return s.userRepo.Delete(ctx, id)
+}
+
+// recordLogin counts a login attempt when metrics are enabled
+func (s *UserService) recordLogin(succeeded bool) {
+	if s.metrics == nil {
+		return
+	}
+	if succeeded {
+		s.metrics.LoginSucceeded()
+	} else {
+		s.metrics.LoginFailed()
+	}
 }
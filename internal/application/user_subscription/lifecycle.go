@@ -0,0 +1,297 @@
+package user_subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+const (
+	// metadataPaymentAttempts guarda cuántos cobros fallidos lleva el período actual
+	metadataPaymentAttempts = "payment_attempts"
+	// metadataLastPaymentError guarda el motivo del último cobro fallido
+	metadataLastPaymentError = "last_payment_error"
+	// metadataBillingAnchorDay guarda el día del mes (UTC) en que terminan los períodos de la suscripción
+	metadataBillingAnchorDay = "billing_anchor_day"
+	// metadataCancellationReason guarda por qué se canceló la suscripción
+	metadataCancellationReason = "cancellation_reason"
+	// metadataReplacedBy guarda la suscripción que reemplazó a una cancelada al suscribirse a otro plan
+	metadataReplacedBy = "replaced_by"
+	// metadataExpiryNoticeSentFor guarda la fecha de finalización de la que ya se avisó al usuario
+	metadataExpiryNoticeSentFor = "expiry_notice_sent_for"
+
+	// cancellationReasonReplaced es el motivo de las suscripciones canceladas al suscribirse a otro plan
+	cancellationReasonReplaced = "replaced"
+
+	// pendingInvoiceBatch es el máximo de facturas pendientes que se emiten en cada ejecución
+	pendingInvoiceBatch = 100
+)
+
+// DunningSchedule define la espera antes de cada reintento de cobro tras un pago fallido.
+// Cuando se agotan los reintentos la suscripción pasa a expirada.
+type DunningSchedule []time.Duration
+
+// DefaultDunningSchedule reintenta al día, a los tres días y a la semana del fallo anterior
+var DefaultDunningSchedule = DunningSchedule{24 * time.Hour, 72 * time.Hour, 168 * time.Hour}
+
+// ParseDunningSchedule convierte una lista separada por comas ("24h,72h,168h") en un calendario
+func ParseDunningSchedule(value string) (DunningSchedule, error) {
+	var schedule DunningSchedule
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		delay, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("duración de reintento no válida %q: %w", part, err)
+		}
+		if delay <= 0 {
+			return nil, fmt.Errorf("la duración de reintento debe ser positiva: %q", part)
+		}
+		schedule = append(schedule, delay)
+	}
+	if len(schedule) == 0 {
+		return nil, errors.New("el calendario de reintentos está vacío")
+	}
+	return schedule, nil
+}
+
+// LifecycleResult resume el resultado de una ejecución del ciclo de vida de suscripciones
+type LifecycleResult struct {
+	TrialsExpired int // Pruebas gratuitas expiradas por terminar sin método de pago
+	Expired       int // Suscripciones activas expiradas por fecha de finalización
+	Renewed       int // Suscripciones renovadas por fecha de renovación
+	Recovered     int // Suscripciones fallidas recuperadas tras un reintento de cobro
+	Failed        int // Cobros fallidos en esta ejecución
+	Exhausted     int // Suscripciones expiradas tras agotar los reintentos de cobro
+	ExpiryNotices int // Avisos de expiración enviados
+	Invoiced      int // Facturas pendientes emitidas
+}
+
+// RunLifecycle cierra pruebas sin método de pago, renueva, reintenta cobros y expira suscripciones
+// en ese orden. Renovar antes de expirar evita expirar suscripciones que todavía podían renovarse;
+// las pruebas con método de pago se convierten en pagas al renovarse. Después avisa de las que
+// terminan dentro de expiryNotice (0 no avisa) y al final emite las facturas que quedaron
+// pendientes, incluidas las de los cobros de esta ejecución.
+func (s *Service) RunLifecycle(ctx context.Context, schedule DunningSchedule, expiryNotice time.Duration, now time.Time) (LifecycleResult, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.RunLifecycle")
+	defer span.End()
+
+	var result LifecycleResult
+	var errs []error
+
+	trialsExpired, err := s.ExpireUnpaidTrials(ctx, now)
+	result.TrialsExpired = trialsExpired
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	renewed, failed, err := s.RenewDueSubscriptions(ctx, schedule, now)
+	result.Renewed, result.Failed = renewed, failed
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	recovered, failed, exhausted, err := s.RetryFailedPayments(ctx, schedule, now)
+	result.Recovered, result.Exhausted = recovered, exhausted
+	result.Failed += failed
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	expired, err := s.ExpireSubscriptions(ctx, now)
+	result.Expired = expired
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if expiryNotice > 0 {
+		notices, err := s.notifyExpiring(ctx, now, now.Add(expiryNotice))
+		result.ExpiryNotices = notices
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.invoices != nil {
+		invoiced, err := s.invoices.IssuePendingInvoices(ctx, pendingInvoiceBatch)
+		result.Invoiced = invoiced
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// nextPeriodEnd calcula el fin del próximo período de la suscripción con el plan indicado,
+// manteniendo su día de facturación aunque el período actual haya terminado en un mes más corto
+func nextPeriodEnd(subscription *domain.UserSubscription, plan *domain.Plan) time.Time {
+	anchorDay, _ := strconv.Atoi(subscription.Metadata[metadataBillingAnchorDay])
+	return domain.NextPeriodEnd(plan, anchorDay, subscription.EndDate)
+}
+
+// setBillingAnchor toma como día de facturación el de start, el inicio de una nueva serie de períodos
+func setBillingAnchor(subscription *domain.UserSubscription, start time.Time) {
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
+	}
+	subscription.Metadata[metadataBillingAnchorDay] = strconv.Itoa(start.UTC().Day())
+}
+
+// ExpireSubscriptions marca como expiradas las suscripciones activas cuya fecha de finalización ya pasó
+func (s *Service) ExpireSubscriptions(ctx context.Context, now time.Time) (int, error) {
+	subscriptions, err := s.subscriptionRepo.GetExpired(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener suscripciones expiradas: %w", err)
+	}
+
+	expired := 0
+	for _, subscription := range subscriptions {
+		if err := s.subscriptionRepo.UpdateStatus(ctx, subscription.ID, domain.SubscriptionStatusExpired); err != nil {
+			return expired, fmt.Errorf("error al expirar suscripción %s: %w", subscription.ID, err)
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// RenewDueSubscriptions renueva las suscripciones activas cuya fecha de renovación ya pasó.
+// Si el cobro falla la suscripción entra en el calendario de reintentos.
+func (s *Service) RenewDueSubscriptions(ctx context.Context, schedule DunningSchedule, now time.Time) (renewed int, failed int, err error) {
+	subscriptions, err := s.subscriptionRepo.GetPendingRenewals(ctx, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al obtener renovaciones pendientes: %w", err)
+	}
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		plan, err := s.planForNextPeriod(ctx, subscription)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error al obtener plan de suscripción %s: %w", subscription.ID, err))
+			continue
+		}
+
+		_, err = s.RenewSubscription(ctx, subscription.ID, nextPeriodEnd(subscription, plan))
+		if err == nil {
+			renewed++
+			continue
+		}
+
+		if !errors.Is(err, domain.ErrPaymentFailed) {
+			errs = append(errs, fmt.Errorf("error al renovar suscripción %s: %w", subscription.ID, err))
+			continue
+		}
+
+		failed++
+		if _, err := s.recordPaymentFailure(ctx, subscription, plan, schedule, now, err); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return renewed, failed, errors.Join(errs...)
+}
+
+// RetryFailedPayments reintenta el cobro de las suscripciones fallidas cuyo próximo intento ya venció.
+// Tras el último intento del calendario la suscripción pasa a expirada.
+func (s *Service) RetryFailedPayments(ctx context.Context, schedule DunningSchedule, now time.Time) (recovered int, failed int, exhausted int, err error) {
+	subscriptions, err := s.subscriptionRepo.GetDueForPaymentRetry(ctx, now)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("error al obtener reintentos de cobro pendientes: %w", err)
+	}
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		plan, err := s.planForNextPeriod(ctx, subscription)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error al obtener plan de suscripción %s: %w", subscription.ID, err))
+			continue
+		}
+
+		err = s.renew(ctx, subscription, nextPeriodEnd(subscription, plan))
+		if err == nil {
+			delete(subscription.Metadata, metadataPaymentAttempts)
+			delete(subscription.Metadata, metadataLastPaymentError)
+			if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+				errs = append(errs, fmt.Errorf("error al actualizar suscripción %s: %w", subscription.ID, err))
+			}
+			recovered++
+			continue
+		}
+
+		if !errors.Is(err, domain.ErrPaymentFailed) {
+			errs = append(errs, fmt.Errorf("error al reintentar cobro de suscripción %s: %w", subscription.ID, err))
+			continue
+		}
+
+		failed++
+		expired, err := s.recordPaymentFailure(ctx, subscription, plan, schedule, now, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if expired {
+			exhausted++
+		}
+	}
+
+	return recovered, failed, exhausted, errors.Join(errs...)
+}
+
+// recordPaymentFailure registra un cobro fallido y programa el siguiente intento.
+// Devuelve true si se agotaron los reintentos y la suscripción quedó expirada.
+func (s *Service) recordPaymentFailure(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	schedule DunningSchedule,
+	now time.Time,
+	cause error,
+) (bool, error) {
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
+	}
+
+	attempts, _ := strconv.Atoi(subscription.Metadata[metadataPaymentAttempts])
+	attempts++
+	subscription.Metadata[metadataPaymentAttempts] = strconv.Itoa(attempts)
+	subscription.Metadata[metadataLastPaymentError] = cause.Error()
+
+	exhausted := attempts > len(schedule)
+	nextAttempt := ""
+	if exhausted {
+		subscription.Status = domain.SubscriptionStatusExpired
+		subscription.NextPaymentAttempt = nil
+	} else {
+		subscription.Status = domain.SubscriptionStatusFailed
+		subscription.NextPaymentAttempt = timePtr(now.Add(schedule[attempts-1]))
+		nextAttempt = subscription.NextPaymentAttempt.Format("2006-01-02")
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return false, fmt.Errorf("error al registrar cobro fallido de suscripción %s: %w", subscription.ID, err)
+	}
+
+	if s.notifier != nil && !exhausted {
+		data := map[string]string{
+			"subscription_id": subscription.ID,
+			"plan_name":       plan.Name,
+			"amount":          strconv.FormatFloat(plan.Price, 'f', 2, 64),
+			"currency":        plan.CurrencyID,
+			"reason":          cause.Error(),
+			"next_attempt":    nextAttempt,
+		}
+		if err := s.notifier.Publish(ctx, subscription.UserID, domain.NotificationEventPaymentFailed, data); err != nil {
+			slog.ErrorContext(ctx, "error al notificar cobro fallido", "subscription_id", subscription.ID, "error", err)
+		}
+	}
+
+	return exhausted, nil
+}
@@ -0,0 +1,139 @@
+package user_subscription
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// metadataScheduledPlanAt guarda desde cuándo puede aplicarse el plan programado por una migración
+const metadataScheduledPlanAt = "scheduled_plan_effective_at"
+
+// MigratePlanCohort programa la migración de los suscriptores de una versión de plan a otra versión
+// de la misma familia. Cada suscripción conserva su precio hasta la primera renovación posterior a
+// noticeDays días desde now; los suscriptores con otro cambio de plan pendiente se omiten.
+// Si toPlanID está vacío se migra a la versión vigente de la familia.
+func (s *Service) MigratePlanCohort(
+	ctx context.Context,
+	fromPlanID string,
+	toPlanID string,
+	noticeDays int,
+	now time.Time,
+) (*domain.PlanMigrationResult, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.MigratePlanCohort")
+	defer span.End()
+
+	if noticeDays < 0 {
+		return nil, domain.NewValidationError("notice_days", "los días de aviso no pueden ser negativos")
+	}
+
+	fromPlan, err := s.planRepo.GetByID(ctx, fromPlanID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plan de origen: %w", err)
+	}
+
+	var toPlan *domain.Plan
+	if toPlanID == "" {
+		toPlan, err = s.planRepo.GetLatestVersion(ctx, fromPlan.FamilyID)
+	} else {
+		toPlan, err = s.planRepo.GetByID(ctx, toPlanID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plan de destino: %w", err)
+	}
+
+	if toPlan.FamilyID != fromPlan.FamilyID {
+		return nil, domain.NewValidationError("to_plan_id", "solo se puede migrar entre versiones del mismo plan")
+	}
+	if !toPlan.IsLatestVersion() {
+		return nil, domain.NewConflictError("plan_superseded", "solo se puede migrar a la versión vigente del plan")
+	}
+	if toPlan.IsArchived() {
+		return nil, domain.ErrPlanArchived
+	}
+	if toPlan.ID == fromPlan.ID {
+		return nil, domain.NewConflictError("plan_already_current", "el plan de origen ya es la versión vigente")
+	}
+
+	subscriptions, err := s.subscriptionRepo.GetBillableByPlanID(ctx, fromPlan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener suscripciones del plan: %w", err)
+	}
+
+	effectiveAt := now.AddDate(0, 0, noticeDays)
+	result := &domain.PlanMigrationResult{
+		FromPlanID:  fromPlan.ID,
+		ToPlanID:    toPlan.ID,
+		EffectiveAt: effectiveAt,
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.Metadata[metadataScheduledPlanID] != "" {
+			result.Skipped++
+			continue
+		}
+
+		if subscription.Metadata == nil {
+			subscription.Metadata = make(map[string]string)
+		}
+		subscription.Metadata[metadataScheduledPlanID] = toPlan.ID
+		subscription.Metadata[metadataScheduledPlanAt] = effectiveAt.Format(time.RFC3339)
+		if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+			return result, fmt.Errorf("error al actualizar suscripción: %w", err)
+		}
+		result.Scheduled++
+
+		s.notifyPlanMigration(ctx, subscription, fromPlan, toPlan, effectiveAt)
+	}
+
+	return result, nil
+}
+
+// notifyPlanMigration avisa al usuario del nuevo precio y desde cuándo se aplicará
+func (s *Service) notifyPlanMigration(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	fromPlan *domain.Plan,
+	toPlan *domain.Plan,
+	effectiveAt time.Time,
+) {
+	if s.notifier == nil {
+		return
+	}
+
+	currencyCode := toPlan.CurrencyID
+	if currency, err := s.currencyRepo.GetByID(ctx, toPlan.CurrencyID); err == nil && currency != nil {
+		currencyCode = currency.Code
+	}
+
+	data := map[string]string{
+		"subscription_id": subscription.ID,
+		"plan_name":       toPlan.Name,
+		"old_price":       strconv.FormatFloat(fromPlan.Price, 'f', 2, 64),
+		"new_price":       strconv.FormatFloat(toPlan.Price, 'f', 2, 64),
+		"currency":        currencyCode,
+		"effective_at":    effectiveAt.Format("2006-01-02"),
+	}
+	if err := s.notifier.Publish(ctx, subscription.UserID, domain.NotificationEventPlanMigrationScheduled, data); err != nil {
+		slog.ErrorContext(ctx, "error al notificar migración de plan", "subscription_id", subscription.ID, "error", err)
+	}
+}
+
+// scheduledPlanDue indica si el plan programado ya puede aplicarse en la renovación del período
+// que termina en subscription.EndDate. Las reducciones se aplican siempre; las migraciones solo
+// cuando el período termina después del aviso.
+func scheduledPlanDue(subscription *domain.UserSubscription) bool {
+	value := subscription.Metadata[metadataScheduledPlanAt]
+	if value == "" {
+		return true
+	}
+	effectiveAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return true
+	}
+	return !subscription.EndDate.Before(effectiveAt)
+}
@@ -0,0 +1,221 @@
+package user_subscription
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+const (
+	// metadataCouponID guarda el cupón canjeado en la suscripción
+	metadataCouponID = "coupon_id"
+	// metadataCouponPeriodsLeft guarda cuántos cobros quedan con descuento (ausente = para siempre)
+	metadataCouponPeriodsLeft = "coupon_periods_remaining"
+)
+
+// ExpireUnpaidTrials expira las pruebas gratuitas que terminaron sin método de pago.
+// Las pruebas con método de pago se convierten en suscripciones pagas en la renovación normal.
+func (s *Service) ExpireUnpaidTrials(ctx context.Context, now time.Time) (int, error) {
+	subscriptions, err := s.subscriptionRepo.GetPendingRenewals(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener renovaciones pendientes: %w", err)
+	}
+
+	expired := 0
+	for _, subscription := range subscriptions {
+		if !endsTrial(subscription) || subscription.PaymentMethodID != nil {
+			continue
+		}
+
+		subscription.Status = domain.SubscriptionStatusExpired
+		subscription.RenewalDate = nil
+		if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+			return expired, fmt.Errorf("error al expirar prueba de suscripción %s: %w", subscription.ID, err)
+		}
+		expired++
+
+		if s.notifier == nil {
+			continue
+		}
+		planName := subscription.PlanID
+		if plan, err := s.planRepo.GetByID(ctx, subscription.PlanID); err == nil && plan != nil {
+			planName = plan.Name
+		}
+		data := map[string]string{
+			"subscription_id": subscription.ID,
+			"plan_name":       planName,
+			"trial_end_date":  subscription.TrialEndDate.Format("2006-01-02"),
+		}
+		if err := s.notifier.Publish(ctx, subscription.UserID, domain.NotificationEventTrialEnded, data); err != nil {
+			slog.ErrorContext(ctx, "error al notificar fin de prueba", "subscription_id", subscription.ID, "error", err)
+		}
+	}
+
+	return expired, nil
+}
+
+// endsTrial indica si el período actual de la suscripción es su prueba gratuita
+func endsTrial(subscription *domain.UserSubscription) bool {
+	return subscription.TrialEndDate != nil && subscription.EndDate.Equal(*subscription.TrialEndDate)
+}
+
+// inTrial indica si la suscripción sigue en su prueba gratuita en el momento indicado
+func inTrial(subscription *domain.UserSubscription, now time.Time) bool {
+	return subscription.TrialEndDate != nil && now.Before(*subscription.TrialEndDate)
+}
+
+// trialEligible indica si el usuario puede usar la prueba gratuita del plan.
+// Cada usuario tiene una sola prueba gratuita, sin importar el plan.
+func (s *Service) trialEligible(ctx context.Context, userID string, plan *domain.Plan) (bool, error) {
+	if !plan.HasTrial() || s.isPlanFree(plan) {
+		return false, nil
+	}
+
+	subscriptions, err := s.subscriptionRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("error al verificar pruebas anteriores: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.TrialEndDate != nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// redeemCoupon valida y canjea un código promocional para la suscripción y lo deja asociado
+// en sus metadatos. Devuelve una función que deshace el canje si la operación no se completa.
+func (s *Service) redeemCoupon(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	code string,
+	now time.Time,
+) (func(), error) {
+	if s.couponRepo == nil {
+		return nil, fmt.Errorf("%w: los cupones no están habilitados", domain.ErrCouponNotRedeemable)
+	}
+
+	coupon, err := s.couponRepo.GetByCode(ctx, domain.NormalizeCouponCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener cupón: %w", err)
+	}
+	if coupon == nil {
+		return nil, domain.ErrCouponNotRedeemable
+	}
+	if err := coupon.CheckRedeemable(plan, now); err != nil {
+		return nil, err
+	}
+
+	err = s.couponRepo.Redeem(ctx, &domain.CouponRedemption{
+		CouponID:       coupon.ID,
+		UserID:         subscription.UserID,
+		SubscriptionID: subscription.ID,
+		RedeemedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
+	}
+	previousCoupon, hadCoupon := subscription.Metadata[metadataCouponID]
+	previousPeriods, hadPeriods := subscription.Metadata[metadataCouponPeriodsLeft]
+
+	subscription.Metadata[metadataCouponID] = coupon.ID
+	switch coupon.Duration {
+	case domain.CouponDurationOnce:
+		subscription.Metadata[metadataCouponPeriodsLeft] = "1"
+	case domain.CouponDurationRepeating:
+		subscription.Metadata[metadataCouponPeriodsLeft] = strconv.Itoa(coupon.DurationPeriods)
+	default:
+		delete(subscription.Metadata, metadataCouponPeriodsLeft)
+	}
+
+	undo := func() {
+		if err := s.couponRepo.ReleaseRedemption(ctx, coupon.ID, subscription.ID); err != nil {
+			slog.ErrorContext(ctx, "error al liberar canje del cupón", "coupon_code", coupon.Code, "subscription_id", subscription.ID, "error", err)
+		}
+		restoreMetadata(subscription.Metadata, metadataCouponID, previousCoupon, hadCoupon)
+		restoreMetadata(subscription.Metadata, metadataCouponPeriodsLeft, previousPeriods, hadPeriods)
+	}
+
+	return undo, nil
+}
+
+// couponDiscountLine calcula la línea de descuento del cupón de la suscripción sobre el monto
+// facturado por el plan en el período [periodStart, periodEnd].
+// Devuelve nil si la suscripción no tiene cupón o el cupón no aplica al plan.
+func (s *Service) couponDiscountLine(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	amount float64,
+	periodStart time.Time,
+	periodEnd time.Time,
+) *domain.InvoiceLineItem {
+	couponID := subscription.Metadata[metadataCouponID]
+	if couponID == "" || s.couponRepo == nil {
+		return nil
+	}
+
+	coupon, err := s.couponRepo.GetByID(ctx, couponID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error al obtener cupón de suscripción", "coupon_id", couponID, "subscription_id", subscription.ID, "error", err)
+		return nil
+	}
+	if coupon == nil || !coupon.AppliesTo(plan) {
+		return nil
+	}
+
+	discount := coupon.Discount(amount)
+	if discount <= 0 {
+		return nil
+	}
+
+	return &domain.InvoiceLineItem{
+		Description: fmt.Sprintf("Descuento cupón %s", coupon.Code),
+		Quantity:    1,
+		UnitAmount:  -discount,
+		Amount:      -discount,
+		PeriodStart: timePtr(periodStart),
+		PeriodEnd:   timePtr(periodEnd),
+		PlanID:      plan.ID,
+	}
+}
+
+// consumeCouponPeriod descuenta un período del cupón tras un cobro y lo retira al agotarse
+func consumeCouponPeriod(subscription *domain.UserSubscription) {
+	if subscription.Metadata[metadataCouponID] == "" {
+		return
+	}
+
+	value, ok := subscription.Metadata[metadataCouponPeriodsLeft]
+	if !ok {
+		return // Cupón para siempre
+	}
+
+	periods, _ := strconv.Atoi(value)
+	periods--
+	if periods <= 0 {
+		delete(subscription.Metadata, metadataCouponID)
+		delete(subscription.Metadata, metadataCouponPeriodsLeft)
+		return
+	}
+	subscription.Metadata[metadataCouponPeriodsLeft] = strconv.Itoa(periods)
+}
+
+// restoreMetadata devuelve una clave de metadatos a su valor anterior
+func restoreMetadata(metadata map[string]string, key, value string, existed bool) {
+	if existed {
+		metadata[key] = value
+		return
+	}
+	delete(metadata, key)
+}
@@ -0,0 +1,243 @@
+package user_subscription
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+const (
+	// metadataScheduledPlanID guarda el plan al que se cambiará al final del período (reducciones)
+	metadataScheduledPlanID = "scheduled_plan_id"
+	// metadataPendingProration guarda el prorrateo que se cobrará en la próxima renovación
+	metadataPendingProration = "pending_proration_amount"
+)
+
+// PreviewPlanChange calcula el efecto de cambiar de plan sin aplicar ningún cambio
+func (s *Service) PreviewPlanChange(
+	ctx context.Context,
+	id string,
+	newPlanID string,
+	billing domain.ProrationBilling,
+) (*domain.ProrationPreview, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.PreviewPlanChange")
+	defer span.End()
+
+	subscription, currentPlan, newPlan, err := s.loadPlanChange(ctx, id, newPlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if billing == "" {
+		billing = domain.ProrationBillingImmediate
+	}
+
+	paid, err := s.paidInvoices(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	return prorate(subscription, currentPlan, newPlan, paid, billing, time.Now()), nil
+}
+
+// paidInvoices obtiene las facturas de la suscripción para calcular el crédito de un cambio de plan
+func (s *Service) paidInvoices(ctx context.Context, subscription *domain.UserSubscription) ([]*domain.Invoice, error) {
+	if s.invoices == nil {
+		return nil, nil
+	}
+	invoices, err := s.invoices.GetSubscriptionInvoices(ctx, subscription.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener facturas de la suscripción: %w", err)
+	}
+	return invoices, nil
+}
+
+// loadPlanChange obtiene y valida la suscripción y los planes involucrados en un cambio de plan
+func (s *Service) loadPlanChange(
+	ctx context.Context,
+	id string,
+	newPlanID string,
+) (*domain.UserSubscription, *domain.Plan, *domain.Plan, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error al obtener suscripción: %w", err)
+	}
+
+	if subscription.Status != domain.SubscriptionStatusActive {
+		return nil, nil, nil, domain.NewConflictError("subscription_not_active", "solo se puede cambiar el plan de suscripciones activas")
+	}
+
+	// Verificar que el nuevo plan exista
+	newPlan, err := s.planRepo.GetByID(ctx, newPlanID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error al verificar nuevo plan: %w", err)
+	}
+	if newPlan == nil {
+		return nil, nil, nil, domain.NewNotFoundError("plan", "plan no encontrado con ID: %s", newPlanID)
+	}
+	// Solo se puede contratar la versión vigente de un plan; volver a la versión actual sí está permitido
+	if newPlan.ID != subscription.PlanID && !newPlan.IsLatestVersion() {
+		return nil, nil, nil, domain.NewConflictError("plan_superseded", "el plan %s fue reemplazado por una versión más nueva", newPlanID)
+	}
+	if newPlan.ID != subscription.PlanID && newPlan.IsArchived() {
+		return nil, nil, nil, fmt.Errorf("%w: %s", domain.ErrPlanArchived, newPlanID)
+	}
+
+	// Obtener el plan actual
+	currentPlan, err := s.planRepo.GetByID(ctx, subscription.PlanID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error al obtener plan actual: %w", err)
+	}
+
+	// Si pasamos de un plan gratuito a uno de pago, necesitamos un método de pago
+	// (durante la prueba gratuita se pide recién al convertirla en paga)
+	if !s.isPlanFree(newPlan) && subscription.PaymentMethodID == nil && !inTrial(subscription, time.Now()) {
+		return nil, nil, nil, domain.NewValidationError("payment_method_id", "se requiere método de pago para cambiar a un plan no gratuito")
+	}
+
+	return subscription, currentPlan, newPlan, nil
+}
+
+// prorate calcula el crédito por el tiempo no usado del plan actual y el costo del nuevo plan.
+// El crédito sale de lo cobrado por el plan actual en las facturas pagadas, con sus descuentos;
+// solo si no hay facturas del período en curso se calcula sobre el precio de lista.
+// Con el mismo intervalo el período actual se conserva y ambos montos se prorratean;
+// con otro intervalo el nuevo período empieza ahora y el nuevo plan se cobra completo.
+// Si el cambio no requiere pago es una reducción y se aplica al final del período actual.
+// Durante la prueba gratuita cualquier cambio se aplica de inmediato sin cobro.
+func prorate(
+	subscription *domain.UserSubscription,
+	currentPlan *domain.Plan,
+	newPlan *domain.Plan,
+	paid []*domain.Invoice,
+	billing domain.ProrationBilling,
+	now time.Time,
+) *domain.ProrationPreview {
+	periodStart := domain.CurrentPeriodStart(currentPlan, subscription)
+	periodEnd := subscription.EndDate
+
+	// Un plan de por vida ya está pagado y no deja crédito por tiempo no usado
+	fraction := 0.0
+	credit := 0.0
+	if !currentPlan.IsLifetime() {
+		fraction = domain.UnusedFraction(periodStart, periodEnd, now)
+		credit = domain.RoundAmount(currentPlan.Price * fraction)
+		if paidCredit, ok := domain.UnusedCredit(paid, currentPlan.ID, now); ok {
+			credit = paidCredit
+		}
+	}
+
+	preview := &domain.ProrationPreview{
+		SubscriptionID: subscription.ID,
+		CurrentPlanID:  currentPlan.ID,
+		NewPlanID:      newPlan.ID,
+		Billing:        billing,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		UnusedFraction: fraction,
+		Credit:         credit,
+	}
+
+	// Durante la prueba gratuita no hay nada cobrado que acreditar: el plan cambia de inmediato
+	// y la prueba continúa hasta su fecha de fin
+	if inTrial(subscription, now) {
+		preview.Credit = 0
+		preview.ChangeType = domain.PlanChangeUpgrade
+		if newPlan.Price < currentPlan.Price {
+			preview.ChangeType = domain.PlanChangeDowngrade
+		}
+		preview.EffectiveAt = now
+		preview.NewEndDate = periodEnd
+		return preview
+	}
+
+	newEndDate := domain.PeriodEnd(newPlan, now)
+	preview.NewPlanCost = newPlan.Price
+	if newPlan.SameBillingInterval(currentPlan) && !newPlan.IsLifetime() {
+		newEndDate = periodEnd
+		preview.NewPlanCost = domain.RoundAmount(newPlan.Price * fraction)
+	}
+
+	amount := domain.RoundAmount(preview.NewPlanCost - preview.Credit)
+	if amount > 0 {
+		preview.ChangeType = domain.PlanChangeUpgrade
+		preview.AmountDue = amount
+		preview.EffectiveAt = now
+		preview.NewEndDate = newEndDate
+		return preview
+	}
+
+	preview.ChangeType = domain.PlanChangeDowngrade
+	preview.EffectiveAt = periodEnd
+	preview.NewEndDate = nextPeriodEnd(subscription, newPlan)
+	return preview
+}
+
+// prorationLines genera las líneas de factura de un prorrateo: el crédito del plan actual
+// y el costo del nuevo plan hasta el fin del período
+func prorationLines(preview *domain.ProrationPreview, currentPlan, newPlan *domain.Plan) []domain.InvoiceLineItem {
+	var lines []domain.InvoiceLineItem
+	if preview.Credit > 0 {
+		lines = append(lines, domain.InvoiceLineItem{
+			Description: fmt.Sprintf("Crédito por tiempo no usado del plan %s", currentPlan.Name),
+			Quantity:    1,
+			UnitAmount:  -preview.Credit,
+			Amount:      -preview.Credit,
+			PeriodStart: timePtr(preview.EffectiveAt),
+			PeriodEnd:   timePtr(preview.PeriodEnd),
+			PlanID:      currentPlan.ID,
+		})
+	}
+	lines = append(lines, domain.InvoiceLineItem{
+		Description: fmt.Sprintf("Plan %s (prorrateado)", newPlan.Name),
+		Quantity:    1,
+		UnitAmount:  preview.NewPlanCost,
+		Amount:      preview.NewPlanCost,
+		PeriodStart: timePtr(preview.EffectiveAt),
+		PeriodEnd:   timePtr(preview.NewEndDate),
+		PlanID:      newPlan.ID,
+	})
+	return lines
+}
+
+// addPendingProration acumula un prorrateo para cobrarlo en la próxima renovación
+func addPendingProration(subscription *domain.UserSubscription, amount float64) {
+	pending, _ := strconv.ParseFloat(subscription.Metadata[metadataPendingProration], 64)
+	subscription.Metadata[metadataPendingProration] = strconv.FormatFloat(domain.RoundAmount(pending+amount), 'f', 2, 64)
+}
+
+// pendingProrationLines devuelve la línea de factura del prorrateo pendiente de cobro, si lo hay
+func pendingProrationLines(subscription *domain.UserSubscription) []domain.InvoiceLineItem {
+	pending, _ := strconv.ParseFloat(subscription.Metadata[metadataPendingProration], 64)
+	if pending <= 0 {
+		return nil
+	}
+	return []domain.InvoiceLineItem{{
+		Description: "Prorrateo pendiente por cambio de plan",
+		Quantity:    1,
+		UnitAmount:  pending,
+		Amount:      pending,
+	}}
+}
+
+// planForNextPeriod devuelve el plan con el que se facturará el próximo período:
+// el plan programado por una reducción o una migración de versión, si ya corresponde aplicarlo,
+// o el plan actual
+func (s *Service) planForNextPeriod(ctx context.Context, subscription *domain.UserSubscription) (*domain.Plan, error) {
+	planID := subscription.PlanID
+	if scheduled := subscription.Metadata[metadataScheduledPlanID]; scheduled != "" && scheduledPlanDue(subscription) {
+		planID = scheduled
+	}
+
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plan: %w", err)
+	}
+	if plan == nil {
+		return nil, domain.NewNotFoundError("plan", "plan no encontrado con ID: %s", planID)
+	}
+	return plan, nil
+}
@@ -0,0 +1,123 @@
+package user_subscription
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// SchedulerConfig contiene la configuración del planificador de suscripciones
+type SchedulerConfig struct {
+	Interval           time.Duration   // Frecuencia de ejecución del ciclo de vida
+	DunningSchedule    DunningSchedule // Esperas entre reintentos de cobro
+	ExpiryNoticeWindow time.Duration   // Antelación del aviso de expiración; 0 no avisa
+}
+
+// Scheduler ejecuta periódicamente el ciclo de vida de las suscripciones.
+// Solo la instancia que obtiene el bloqueo de líder ejecuta el trabajo.
+type Scheduler struct {
+	service  *Service
+	lock     app.LeaderLock
+	config   SchedulerConfig
+	lastTick atomic.Int64 // Inicio de la última pasada en nanosegundos Unix; 0 si aún no corrió
+}
+
+// NewScheduler crea un nuevo planificador de suscripciones
+func NewScheduler(service *Service, lock app.LeaderLock, config SchedulerConfig) *Scheduler {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if len(config.DunningSchedule) == 0 {
+		config.DunningSchedule = DefaultDunningSchedule
+	}
+
+	return &Scheduler{
+		service: service,
+		lock:    lock,
+		config:  config,
+	}
+}
+
+// Run ejecuta el ciclo de vida al iniciar y luego en cada intervalo hasta que se cancele el contexto
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	defer func() {
+		// El contexto ya está cancelado; usar uno nuevo para liberar el bloqueo
+		if err := s.lock.Release(context.Background()); err != nil {
+			slog.Error("error al liberar el bloqueo del planificador de suscripciones", "error", err)
+		}
+	}()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Interval devuelve la frecuencia de ejecución del planificador
+func (s *Scheduler) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// LastTick devuelve cuándo empezó la última pasada, o el instante cero si aún no corrió
+func (s *Scheduler) LastTick() time.Time {
+	nanos := s.lastTick.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// tick ejecuta una pasada del ciclo de vida si esta instancia es la líder
+func (s *Scheduler) tick(ctx context.Context) {
+	s.lastTick.Store(time.Now().UnixNano())
+
+	ctx, span := tracer.Start(ctx, "user_subscription.Scheduler.tick")
+	defer span.End()
+
+	leader, err := s.lock.TryAcquire(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error al tomar el bloqueo del planificador de suscripciones", "error", err)
+		s.recordRun(app.SchedulerRunFailed)
+		return
+	}
+	if !leader {
+		s.recordRun(app.SchedulerRunSkipped)
+		return
+	}
+
+	result, err := s.service.RunLifecycle(ctx, s.config.DunningSchedule, s.config.ExpiryNoticeWindow, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "ciclo de vida de suscripciones terminado con errores", "error", err)
+		s.recordRun(app.SchedulerRunFailed)
+	} else {
+		s.recordRun(app.SchedulerRunSucceeded)
+	}
+	slog.InfoContext(ctx, "ciclo de vida de suscripciones",
+		"trials_expired", result.TrialsExpired,
+		"renewed", result.Renewed,
+		"recovered", result.Recovered,
+		"failed", result.Failed,
+		"exhausted", result.Exhausted,
+		"expired", result.Expired,
+		"expiry_notices", result.ExpiryNotices,
+		"invoiced", result.Invoiced,
+	)
+}
+
+// recordRun cuenta una ejecución con las métricas del servicio, si las tiene
+func (s *Scheduler) recordRun(outcome string) {
+	if s.service.metrics != nil {
+		s.service.metrics.SchedulerRun(outcome)
+	}
+}
@@ -3,33 +3,67 @@ package user_subscription
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+
 	"MyMoneyBackend/internal/domain"
 	"MyMoneyBackend/internal/domain/ports/app"
 )
 
+// tracer crea los spans de los casos de uso
+var tracer = otel.Tracer("MyMoneyBackend/application/user_subscription")
+
 // Service implementa la lógica de negocio para las suscripciones de usuarios
 type Service struct {
-	subscriptionRepo app.UserSubscriptionRepository
-	planRepo         app.PlanRepository
-	userRepo         app.UserRepository
+	subscriptionRepo  app.UserSubscriptionRepository
+	planRepo          app.PlanRepository
+	userRepo          app.UserRepository
+	paymentMethodRepo app.PaymentMethodRepository
+	currencyRepo      app.CurrencyRepository
+	couponRepo        app.CouponRepository
+	gateway           app.PaymentGateway
+	invoices          app.InvoiceIssuer
+	notifier          app.NotificationPublisher
+	uow               app.UnitOfWork
+	metrics           app.Metrics
 }
 
-// NewService crea una nueva instancia del servicio de suscripciones
+// NewService crea una nueva instancia del servicio de suscripciones. Si metrics es nil no se
+// registran métricas.
 func NewService(
 	subscriptionRepo app.UserSubscriptionRepository,
 	planRepo app.PlanRepository,
 	userRepo app.UserRepository,
+	paymentMethodRepo app.PaymentMethodRepository,
+	currencyRepo app.CurrencyRepository,
+	couponRepo app.CouponRepository,
+	gateway app.PaymentGateway,
+	invoices app.InvoiceIssuer,
+	notifier app.NotificationPublisher,
+	uow app.UnitOfWork,
+	metrics app.Metrics,
 ) *Service {
 	return &Service{
-		subscriptionRepo: subscriptionRepo,
-		planRepo:         planRepo,
-		userRepo:         userRepo,
+		subscriptionRepo:  subscriptionRepo,
+		planRepo:          planRepo,
+		userRepo:          userRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		currencyRepo:      currencyRepo,
+		couponRepo:        couponRepo,
+		gateway:           gateway,
+		invoices:          invoices,
+		notifier:          notifier,
+		uow:               uow,
+		metrics:           metrics,
 	}
 }
 
-// CreateSubscription crea una nueva suscripción para un usuario
+// CreateSubscription crea una nueva suscripción para un usuario.
+// Si el plan ofrece prueba gratuita y el usuario no usó una antes, el primer período es la prueba
+// y el cobro se hace al terminar. El código promocional, si se indica, se canjea antes de cobrar.
 func (s *Service) CreateSubscription(
 	ctx context.Context,
 	userID string,
@@ -37,15 +71,19 @@ func (s *Service) CreateSubscription(
 	startDate time.Time,
 	endDate time.Time,
 	paymentMethodID *string,
+	couponCode string,
 	metadata map[string]string,
 ) (*domain.UserSubscription, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.CreateSubscription")
+	defer span.End()
+
 	// Verificar que el usuario exista
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error al verificar usuario: %w", err)
 	}
 	if user == nil {
-		return nil, fmt.Errorf("usuario no encontrado con ID: %s", userID)
+		return nil, domain.NewNotFoundError("user", "usuario no encontrado con ID: %s", userID)
 	}
 
 	// Verificar que el plan exista
@@ -54,7 +92,13 @@ func (s *Service) CreateSubscription(
 		return nil, fmt.Errorf("error al verificar plan: %w", err)
 	}
 	if plan == nil {
-		return nil, fmt.Errorf("plan no encontrado con ID: %s", planID)
+		return nil, domain.NewNotFoundError("plan", "plan no encontrado con ID: %s", planID)
+	}
+	if !plan.IsLatestVersion() {
+		return nil, domain.NewConflictError("plan_superseded", "el plan %s fue reemplazado por una versión más nueva", planID)
+	}
+	if plan.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPlanArchived, planID)
 	}
 
 	// Verificar si ya existe una suscripción activa
@@ -63,45 +107,59 @@ func (s *Service) CreateSubscription(
 		return nil, fmt.Errorf("error al verificar suscripción activa: %w", err)
 	}
 
-	// Si ya tiene una suscripción activa, cancelarla antes de crear la nueva
-	if activeSubscription != nil {
-		err = s.CancelSubscription(ctx, activeSubscription.ID, "Reemplazada por nueva suscripción")
-		if err != nil {
-			return nil, fmt.Errorf("error al cancelar suscripción existente: %w", err)
-		}
+	// Sin fecha de fin el primer período dura el intervalo del plan; los planes de por vida no terminan.
+	// Los períodos siguientes terminan el mismo día del mes que el primero.
+	billingAnchor := endDate
+	if endDate.IsZero() || plan.IsLifetime() {
+		endDate = domain.PeriodEnd(plan, startDate)
+		billingAnchor = startDate
 	}
 
-	// Calcular fecha de renovación
-	var renewalDate *time.Time
-	if plan.Interval == domain.PlanIntervalMonthly || plan.Interval == domain.PlanIntervalYearly {
-		renewal := endDate.AddDate(0, -1, 0) // Por defecto, renovar un mes antes de finalizar
-		if plan.Interval == domain.PlanIntervalYearly {
-			renewal = endDate.AddDate(0, -1, 0) // Para planes anuales, renovar un mes antes
-		}
-		renewalDate = &renewal
+	// La prueba gratuita reemplaza el primer período: termina tras los días de prueba
+	trial, err := s.trialEligible(ctx, userID, plan)
+	if err != nil {
+		return nil, err
+	}
+	var trialEndDate *time.Time
+	if trial {
+		trialEndDate = timePtr(domain.AddInterval(startDate, domain.PlanIntervalDay, plan.TrialDays))
+		endDate = *trialEndDate
+		billingAnchor = endDate
 	}
 
+	// Calcular fecha de renovación
+	renewalDate := domain.RenewalDate(plan, endDate)
+
 	// Para planes gratuitos, no necesitamos método de pago
 	if s.isPlanFree(plan) && paymentMethodID != nil {
 		paymentMethodID = nil // Ignorar método de pago para planes gratuitos
 	}
 
-	// Para planes pagos, requerimos método de pago
-	if !s.isPlanFree(plan) && paymentMethodID == nil {
-		return nil, fmt.Errorf("se requiere método de pago para planes no gratuitos")
+	// Para planes pagos, requerimos método de pago (durante la prueba es opcional)
+	if !s.isPlanFree(plan) && !trial && paymentMethodID == nil {
+		return nil, domain.NewValidationError("payment_method_id", "se requiere método de pago para planes no gratuitos")
+	}
+
+	// Los planes pagos quedan pendientes hasta que se confirme el cobro
+	status := domain.SubscriptionStatusActive
+	if !s.isPlanFree(plan) && !trial {
+		status = domain.SubscriptionStatusPending
 	}
 
 	// Crear la suscripción
 	subscription := &domain.UserSubscription{
+		ID:              uuid.New().String(),
 		UserID:          userID,
 		PlanID:          planID,
-		Status:          domain.SubscriptionStatusActive,
+		Status:          status,
 		StartDate:       startDate,
 		EndDate:         endDate,
 		RenewalDate:     renewalDate,
+		TrialEndDate:    trialEndDate,
 		PaymentMethodID: paymentMethodID,
 		Metadata:        metadata,
 	}
+	setBillingAnchor(subscription, billingAnchor)
 
 	// Validar la suscripción
 	if err := subscription.Validate(); err != nil {
@@ -113,6 +171,65 @@ func (s *Service) CreateSubscription(
 		return nil, fmt.Errorf("error al crear suscripción: %w", err)
 	}
 
+	// Canjear el cupón antes del primer cobro para que se aplique el descuento. El canje referencia
+	// a la suscripción, así que se hace después de guardarla y, si falla, se descarta la suscripción.
+	releaseCoupon := func() {}
+	if couponCode != "" {
+		releaseCoupon, err = s.redeemCoupon(ctx, subscription, plan, couponCode, time.Now())
+		if err != nil {
+			if err := s.subscriptionRepo.Delete(ctx, subscription.ID); err != nil {
+				slog.ErrorContext(ctx, "error al descartar suscripción", "subscription_id", subscription.ID, "error", err)
+			}
+			return nil, fmt.Errorf("error al canjear cupón: %w", err)
+		}
+	}
+
+	// Cobrar el primer período salvo durante la prueba; si el cobro falla la suscripción
+	// queda fallida y la suscripción activa anterior se conserva
+	var charge *domain.Charge
+	if !trial {
+		charge, err = s.charge(ctx, subscription, plan, startDate, endDate, "create:"+subscription.ID)
+		if err != nil {
+			releaseCoupon()
+			s.markFailed(ctx, subscription, err)
+			return nil, fmt.Errorf("error al cobrar suscripción: %w", err)
+		}
+	}
+
+	// Activar la suscripción y guardar el cobro y los períodos de cupón consumidos
+	subscription.Status = domain.SubscriptionStatusActive
+	if charge != nil {
+		subscription.LastPaymentDate = timePtr(charge.CreatedAt)
+	}
+
+	// Cancelar la suscripción activa anterior y activar la nueva en una sola transacción:
+	// si algo falla el usuario conserva la suscripción anterior
+	err = s.uow.Do(ctx, func(repos app.TxRepositories) error {
+		if activeSubscription != nil {
+			activeSubscription.Metadata = withMetadata(activeSubscription.Metadata, metadataReplacedBy, subscription.ID)
+			if err := s.cancel(ctx, repos.Subscriptions, activeSubscription, cancellationReasonReplaced); err != nil {
+				return fmt.Errorf("error al cancelar suscripción existente: %w", err)
+			}
+		}
+		if err := repos.Subscriptions.Update(ctx, subscription); err != nil {
+			return fmt.Errorf("error al activar suscripción: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.refund(ctx, subscription, plan, charge)
+		releaseCoupon()
+		s.markFailed(ctx, subscription, err)
+		return nil, err
+	}
+
+	if s.metrics != nil {
+		s.metrics.SubscriptionCreated()
+		if activeSubscription != nil {
+			s.metrics.SubscriptionCancelled()
+		}
+	}
+
 	return subscription, nil
 }
 
@@ -154,14 +271,71 @@ func (s *Service) GetSubscriptionsByStatus(ctx context.Context, status domain.Su
 
 // GetExpiringSubscriptions obtiene suscripciones que expirarán pronto
 func (s *Service) GetExpiringSubscriptions(ctx context.Context, daysFromNow int) ([]*domain.UserSubscription, error) {
-	beforeDate := time.Now().AddDate(0, 0, daysFromNow)
-	subscriptions, err := s.subscriptionRepo.GetExpiringSubscriptions(ctx, beforeDate)
+	now := time.Now()
+	subscriptions, err := s.subscriptionRepo.GetExpiringSubscriptions(ctx, now, now.AddDate(0, 0, daysFromNow))
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener suscripciones por expirar: %w", err)
 	}
 	return subscriptions, nil
 }
 
+// NotifyExpiringSubscriptions avisa a los usuarios cuyas suscripciones expirarán en los próximos días.
+// Cada suscripción se notifica una sola vez por fecha de finalización; devuelve cuántas se notificaron.
+func (s *Service) NotifyExpiringSubscriptions(ctx context.Context, daysFromNow int) (int, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.NotifyExpiringSubscriptions")
+	defer span.End()
+
+	now := time.Now()
+	return s.notifyExpiring(ctx, now, now.AddDate(0, 0, daysFromNow))
+}
+
+// notifyExpiring avisa de las suscripciones activas que terminan después de now y no más tarde de beforeDate.
+// Las ya avisadas para su fecha de finalización actual se omiten.
+func (s *Service) notifyExpiring(ctx context.Context, now, beforeDate time.Time) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	subscriptions, err := s.subscriptionRepo.GetExpiringSubscriptions(ctx, now, beforeDate)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener suscripciones por expirar: %w", err)
+	}
+
+	notified := 0
+	for _, subscription := range subscriptions {
+		endDate := subscription.EndDate.Format(time.RFC3339)
+		if subscription.Metadata[metadataExpiryNoticeSentFor] == endDate {
+			continue
+		}
+
+		planName := subscription.PlanID
+		if plan, err := s.planRepo.GetByID(ctx, subscription.PlanID); err == nil && plan != nil {
+			planName = plan.Name
+		}
+
+		data := map[string]string{
+			"subscription_id": subscription.ID,
+			"plan_name":       planName,
+			"end_date":        subscription.EndDate.Format("2006-01-02"),
+		}
+		if err := s.notifier.Publish(ctx, subscription.UserID, domain.NotificationEventSubscriptionExpiring, data); err != nil {
+			slog.ErrorContext(ctx, "error al notificar expiración de suscripción", "subscription_id", subscription.ID, "error", err)
+			continue
+		}
+
+		if subscription.Metadata == nil {
+			subscription.Metadata = make(map[string]string)
+		}
+		subscription.Metadata[metadataExpiryNoticeSentFor] = endDate
+		if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+			return notified, fmt.Errorf("error al actualizar suscripción: %w", err)
+		}
+		notified++
+	}
+
+	return notified, nil
+}
+
 // GetPendingRenewals obtiene suscripciones pendientes de renovación
 func (s *Service) GetPendingRenewals(ctx context.Context, daysFromNow int) ([]*domain.UserSubscription, error) {
 	beforeDate := time.Now().AddDate(0, 0, daysFromNow)
@@ -174,6 +348,9 @@ func (s *Service) GetPendingRenewals(ctx context.Context, daysFromNow int) ([]*d
 
 // RenewSubscription renueva una suscripción existente
 func (s *Service) RenewSubscription(ctx context.Context, id string, newEndDate time.Time) (*domain.UserSubscription, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.RenewSubscription")
+	defer span.End()
+
 	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener suscripción: %w", err)
@@ -181,140 +358,221 @@ func (s *Service) RenewSubscription(ctx context.Context, id string, newEndDate t
 
 	// Verificar que la suscripción esté activa
 	if subscription.Status != domain.SubscriptionStatusActive {
-		return nil, fmt.Errorf("solo se pueden renovar suscripciones activas")
+		return nil, domain.NewConflictError("subscription_not_active", "solo se pueden renovar suscripciones activas")
 	}
 
-	// Obtener el plan para calcular el intervalo de renovación
-	plan, err := s.planRepo.GetByID(ctx, subscription.PlanID)
+	if err := s.renew(ctx, subscription, newEndDate); err != nil {
+		return nil, err
+	}
+
+	if s.metrics != nil {
+		s.metrics.SubscriptionRenewed()
+	}
+
+	return subscription, nil
+}
+
+// renew extiende la suscripción hasta newEndDate y la deja activa.
+// Lo usan tanto la renovación normal como los reintentos de cobro de suscripciones fallidas.
+// Al comenzar el nuevo período se aplica el cambio de plan programado que ya corresponda y se cobra
+// el prorrateo pendiente junto con el precio del plan.
+func (s *Service) renew(ctx context.Context, subscription *domain.UserSubscription, newEndDate time.Time) error {
+	// Obtener el plan del nuevo período para calcular el intervalo de renovación
+	plan, err := s.planForNextPeriod(ctx, subscription)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener plan: %w", err)
+		return err
+	}
+
+	// Cobrar el nuevo período; la clave incluye la fecha para no cobrar dos veces la misma renovación
+	// y el número de intento para que cada reintento de cobro llegue a la pasarela
+	periodStart := subscription.EndDate
+	previousPlanID := subscription.PlanID
+	subscription.PlanID = plan.ID
+	charge, err := s.charge(
+		ctx,
+		subscription,
+		plan,
+		periodStart,
+		newEndDate,
+		fmt.Sprintf("renew:%s:%d:%s", subscription.ID, newEndDate.Unix(), subscription.Metadata[metadataPaymentAttempts]),
+		pendingProrationLines(subscription)...,
+	)
+	if err != nil {
+		subscription.PlanID = previousPlanID
+		return fmt.Errorf("error al cobrar renovación: %w", err)
 	}
 
-	// Calcular nueva fecha de renovación
-	var renewalDate *time.Time
-	if plan.Interval == domain.PlanIntervalMonthly || plan.Interval == domain.PlanIntervalYearly {
-		renewal := newEndDate.AddDate(0, -1, 0)
-		if plan.Interval == domain.PlanIntervalYearly {
-			renewal = newEndDate.AddDate(0, -1, 0)
-		}
-		renewalDate = &renewal
+	// Las suscripciones creadas sin día de facturación lo toman del inicio de este período
+	if _, ok := subscription.Metadata[metadataBillingAnchorDay]; !ok {
+		setBillingAnchor(subscription, periodStart)
 	}
 
 	// Actualizar fechas
+	subscription.Status = domain.SubscriptionStatusActive
 	subscription.EndDate = newEndDate
-	subscription.RenewalDate = renewalDate
+	subscription.RenewalDate = domain.RenewalDate(plan, newEndDate)
 	subscription.LastPaymentDate = timePtr(time.Now())
 	subscription.NextPaymentAttempt = nil // Resetear el próximo intento de pago
+	if plan.ID == subscription.Metadata[metadataScheduledPlanID] {
+		delete(subscription.Metadata, metadataScheduledPlanID)
+		delete(subscription.Metadata, metadataScheduledPlanAt)
+	}
+	delete(subscription.Metadata, metadataPendingProration)
 
 	// Guardar los cambios
 	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
-		return nil, fmt.Errorf("error al actualizar suscripción: %w", err)
+		s.refund(ctx, subscription, plan, charge)
+		return fmt.Errorf("error al actualizar suscripción: %w", err)
 	}
 
-	return subscription, nil
+	return nil
 }
 
 // CancelSubscription cancela una suscripción
 func (s *Service) CancelSubscription(ctx context.Context, id string, reason string) error {
+	ctx, span := tracer.Start(ctx, "user_subscription.CancelSubscription")
+	defer span.End()
+
 	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("error al obtener suscripción: %w", err)
 	}
 
 	if subscription.Status == domain.SubscriptionStatusCancelled {
-		return fmt.Errorf("la suscripción ya está cancelada")
+		return domain.NewConflictError("subscription_already_cancelled", "la suscripción ya está cancelada")
 	}
 
-	// Guardar el motivo de cancelación en los metadatos
-	if subscription.Metadata == nil {
-		subscription.Metadata = make(map[string]string)
+	if err := s.cancel(ctx, s.subscriptionRepo, subscription, reason); err != nil {
+		return fmt.Errorf("error al cancelar suscripción: %w", err)
 	}
-	subscription.Metadata["cancellation_reason"] = reason
 
-	// Actualizar la suscripción
-	cancellationDate := time.Now()
-	err = s.subscriptionRepo.CancelSubscription(ctx, id, cancellationDate)
-	if err != nil {
-		return fmt.Errorf("error al cancelar suscripción: %w", err)
+	if s.metrics != nil {
+		s.metrics.SubscriptionCancelled()
 	}
 
 	return nil
 }
 
-// ChangeSubscriptionPlan cambia el plan de una suscripción
-func (s *Service) ChangeSubscriptionPlan(ctx context.Context, id string, newPlanID string) (*domain.UserSubscription, error) {
-	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("error al obtener suscripción: %w", err)
+// cancel cancela la suscripción con repo, que puede ser el de una transacción, y guarda el motivo
+// en sus metadatos. La métrica la cuenta quien llama, cuando la cancelación ya es definitiva.
+func (s *Service) cancel(ctx context.Context, repo app.UserSubscriptionRepository, subscription *domain.UserSubscription, reason string) error {
+	subscription.Metadata = withMetadata(subscription.Metadata, metadataCancellationReason, reason)
+	cancellationDate := time.Now()
+	if err := repo.CancelSubscription(ctx, subscription.ID, cancellationDate, subscription.Metadata); err != nil {
+		return err
 	}
+	subscription.Status = domain.SubscriptionStatusCancelled
+	subscription.CancellationDate = &cancellationDate
+	return nil
+}
 
-	if subscription.Status != domain.SubscriptionStatusActive {
-		return nil, fmt.Errorf("solo se puede cambiar el plan de suscripciones activas")
+// withMetadata guarda value en key, creando los metadatos si no existen
+func withMetadata(metadata map[string]string, key, value string) map[string]string {
+	if metadata == nil {
+		metadata = make(map[string]string)
 	}
+	metadata[key] = value
+	return metadata
+}
 
-	// Verificar que el nuevo plan exista
-	newPlan, err := s.planRepo.GetByID(ctx, newPlanID)
+// ChangeSubscriptionPlan cambia el plan de una suscripción con prorrateo.
+// Las mejoras se aplican de inmediato y cobran la diferencia ahora o en la próxima renovación;
+// las reducciones se programan para el final del período actual sin cobros ni reembolsos.
+// El código promocional, si se indica, reemplaza al cupón actual y descuenta el nuevo plan.
+func (s *Service) ChangeSubscriptionPlan(
+	ctx context.Context,
+	id string,
+	newPlanID string,
+	billing domain.ProrationBilling,
+	couponCode string,
+) (*domain.UserSubscription, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.ChangeSubscriptionPlan")
+	defer span.End()
+
+	subscription, currentPlan, newPlan, err := s.loadPlanChange(ctx, id, newPlanID)
 	if err != nil {
-		return nil, fmt.Errorf("error al verificar nuevo plan: %w", err)
+		return nil, err
 	}
-	if newPlan == nil {
-		return nil, fmt.Errorf("plan no encontrado con ID: %s", newPlanID)
+
+	if billing == "" {
+		billing = domain.ProrationBillingImmediate
 	}
 
-	// Obtener el plan actual
-	currentPlan, err := s.planRepo.GetByID(ctx, subscription.PlanID)
+	paid, err := s.paidInvoices(ctx, subscription)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener plan actual: %w", err)
+		return nil, err
 	}
 
-	// Si el nuevo plan es gratuito, no necesitamos método de pago
-	if s.isPlanFree(newPlan) {
-		subscription.PaymentMethodID = nil
-	} else if !s.isPlanFree(newPlan) && subscription.PaymentMethodID == nil {
-		// Si pasamos de un plan gratuito a uno de pago, necesitamos un método de pago
-		return nil, fmt.Errorf("se requiere método de pago para cambiar a un plan no gratuito")
+	now := time.Now()
+	preview := prorate(subscription, currentPlan, newPlan, paid, billing, now)
+
+	// Un nuevo cambio reemplaza cualquier reducción o migración programada
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
 	}
+	delete(subscription.Metadata, metadataScheduledPlanID)
+	delete(subscription.Metadata, metadataScheduledPlanAt)
 
-	// Calcular nuevas fechas según el intervalo del plan
-	now := time.Now()
-	var newEndDate time.Time
+	releaseCoupon := func() {}
+	if couponCode != "" {
+		releaseCoupon, err = s.redeemCoupon(ctx, subscription, newPlan, couponCode, now)
+		if err != nil {
+			return nil, fmt.Errorf("error al canjear cupón: %w", err)
+		}
+	}
 
-	// Mantener la misma duración relativa
-	remaining := subscription.EndDate.Sub(now)
+	var charge *domain.Charge
+	switch {
+	case newPlan.ID == currentPlan.ID:
+		// Volver al plan actual solo cancela la reducción programada
 
-	if currentPlan.Interval != newPlan.Interval {
-		// Si cambiamos de tipo de intervalo, ajustar según el nuevo intervalo
-		if newPlan.Interval == domain.PlanIntervalMonthly {
-			newEndDate = now.AddDate(0, 1, 0)
-		} else if newPlan.Interval == domain.PlanIntervalYearly {
-			newEndDate = now.AddDate(1, 0, 0)
+	case preview.EffectiveAt.After(now):
+		subscription.Metadata[metadataScheduledPlanID] = newPlan.ID
+
+	default:
+		lines := prorationLines(preview, currentPlan, newPlan)
+		if discount := s.couponDiscountLine(ctx, subscription, newPlan, preview.NewPlanCost, preview.EffectiveAt, preview.NewEndDate); discount != nil {
+			lines = append(lines, *discount)
+		}
+
+		if billing == domain.ProrationBillingImmediate {
+			charge, err = s.collect(
+				ctx,
+				subscription,
+				newPlan,
+				domain.InvoiceTypeProration,
+				fmt.Sprintf("change:%s:%s:%d", subscription.ID, newPlan.ID, now.Unix()),
+				lines...,
+			)
+			if err != nil {
+				releaseCoupon()
+				return nil, fmt.Errorf("error al cobrar cambio de plan: %w", err)
+			}
+			if charge != nil {
+				subscription.LastPaymentDate = timePtr(now)
+			}
 		} else {
-			// Para otros intervalos, usar la fecha actual más un mes como predeterminado
-			newEndDate = now.AddDate(0, 1, 0)
+			addPendingProration(subscription, sumLines(lines))
 		}
-	} else {
-		// Si mantenemos el mismo tipo de intervalo, conservar tiempo restante
-		newEndDate = now.Add(remaining)
-	}
 
-	// Calcular nueva fecha de renovación
-	var renewalDate *time.Time
-	if newPlan.Interval == domain.PlanIntervalMonthly || newPlan.Interval == domain.PlanIntervalYearly {
-		renewal := newEndDate.AddDate(0, -1, 0)
-		if newPlan.Interval == domain.PlanIntervalYearly {
-			renewal = newEndDate.AddDate(0, -1, 0)
+		// Si el nuevo plan es gratuito, no necesitamos método de pago
+		if s.isPlanFree(newPlan) {
+			subscription.PaymentMethodID = nil
 		}
-		renewalDate = &renewal
-	}
 
-	// Actualizar la suscripción
-	subscription.PlanID = newPlanID
-	subscription.EndDate = newEndDate
-	subscription.RenewalDate = renewalDate
-	subscription.LastPaymentDate = timePtr(now)
+		// Un plan con otro intervalo comienza un nuevo período con su propio día de facturación
+		if !preview.NewEndDate.Equal(subscription.EndDate) {
+			setBillingAnchor(subscription, preview.EffectiveAt)
+		}
+		subscription.PlanID = newPlan.ID
+		subscription.EndDate = preview.NewEndDate
+		subscription.RenewalDate = domain.RenewalDate(newPlan, preview.NewEndDate)
+	}
 
 	// Guardar los cambios
 	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		s.refund(ctx, subscription, newPlan, charge)
+		releaseCoupon()
 		return nil, fmt.Errorf("error al actualizar suscripción: %w", err)
 	}
 
@@ -323,6 +581,9 @@ func (s *Service) ChangeSubscriptionPlan(ctx context.Context, id string, newPlan
 
 // UpdatePaymentMethod actualiza el método de pago de una suscripción
 func (s *Service) UpdatePaymentMethod(ctx context.Context, id string, paymentMethodID string) (*domain.UserSubscription, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.UpdatePaymentMethod")
+	defer span.End()
+
 	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener suscripción: %w", err)
@@ -330,7 +591,7 @@ func (s *Service) UpdatePaymentMethod(ctx context.Context, id string, paymentMet
 
 	// Verificar que la suscripción esté activa
 	if subscription.Status != domain.SubscriptionStatusActive {
-		return nil, fmt.Errorf("solo se puede actualizar el método de pago de suscripciones activas")
+		return nil, domain.NewConflictError("subscription_not_active", "solo se puede actualizar el método de pago de suscripciones activas")
 	}
 
 	// Obtener el plan para verificar si es gratuito
@@ -341,7 +602,7 @@ func (s *Service) UpdatePaymentMethod(ctx context.Context, id string, paymentMet
 
 	// Si el plan es gratuito, no permitir establecer un método de pago
 	if s.isPlanFree(plan) {
-		return nil, fmt.Errorf("no se permite establecer método de pago para planes gratuitos")
+		return nil, domain.NewValidationError("payment_method_id", "no se permite establecer método de pago para planes gratuitos")
 	}
 
 	// Actualizar el método de pago
@@ -357,6 +618,9 @@ func (s *Service) UpdatePaymentMethod(ctx context.Context, id string, paymentMet
 
 // UpdateSubscriptionStatus actualiza el estado de una suscripción
 func (s *Service) UpdateSubscriptionStatus(ctx context.Context, id string, status domain.SubscriptionStatus) (*domain.UserSubscription, error) {
+	ctx, span := tracer.Start(ctx, "user_subscription.UpdateSubscriptionStatus")
+	defer span.End()
+
 	// Verificar que la suscripción exista
 	_, err := s.subscriptionRepo.GetByID(ctx, id)
 	if err != nil {
@@ -365,7 +629,7 @@ func (s *Service) UpdateSubscriptionStatus(ctx context.Context, id string, statu
 
 	// No permitir cambiar a cancelado (hay un método específico para eso)
 	if status == domain.SubscriptionStatusCancelled {
-		return nil, fmt.Errorf("use CancelSubscription para cancelar suscripciones")
+		return nil, domain.NewValidationError("status", "use CancelSubscription para cancelar suscripciones")
 	}
 
 	// Actualizar el estado
@@ -383,11 +647,212 @@ func (s *Service) UpdateSubscriptionStatus(ctx context.Context, id string, statu
 	return updatedSubscription, nil
 }
 
+// charge cobra el precio del plan al método de pago de la suscripción y emite la factura
+// del período [periodStart, periodEnd], aplicando el descuento del cupón de la suscripción.
+// Las líneas extra (por ejemplo, prorrateos pendientes) se suman al mismo cobro.
+// Si no hay nada que cobrar devuelve un cobro nil.
+func (s *Service) charge(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	periodStart time.Time,
+	periodEnd time.Time,
+	idempotencyKey string,
+	extra ...domain.InvoiceLineItem,
+) (*domain.Charge, error) {
+	var lines []domain.InvoiceLineItem
+	if !s.isPlanFree(plan) {
+		lines = append(lines, domain.InvoiceLineItem{
+			Description: fmt.Sprintf("Plan %s", plan.Name),
+			Quantity:    1,
+			UnitAmount:  plan.Price,
+			Amount:      plan.Price,
+			PeriodStart: timePtr(periodStart),
+			PeriodEnd:   timePtr(periodEnd),
+			PlanID:      plan.ID,
+		})
+		if discount := s.couponDiscountLine(ctx, subscription, plan, plan.Price, periodStart, periodEnd); discount != nil {
+			lines = append(lines, *discount)
+		}
+	}
+	lines = append(lines, extra...)
+
+	charge, err := s.collect(ctx, subscription, plan, domain.InvoiceTypeCharge, idempotencyKey, lines...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cada período cobrado consume un período del cupón
+	if !s.isPlanFree(plan) {
+		consumeCouponPeriod(subscription)
+	}
+
+	return charge, nil
+}
+
+// collect cobra la suma de las líneas al método de pago de la suscripción y emite su factura.
+// Si la suma no es positiva no se cobra nada y devuelve un cobro nil.
+func (s *Service) collect(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	invoiceType domain.InvoiceType,
+	idempotencyKey string,
+	lines ...domain.InvoiceLineItem,
+) (*domain.Charge, error) {
+	amount := sumLines(lines)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	if subscription.PaymentMethodID == nil {
+		return nil, fmt.Errorf("%w: la suscripción no tiene método de pago", domain.ErrPaymentFailed)
+	}
+
+	paymentMethod, err := s.paymentMethodRepo.GetByID(ctx, *subscription.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener método de pago: %w", err)
+	}
+	if paymentMethod == nil || paymentMethod.UserID != subscription.UserID {
+		return nil, domain.NewNotFoundError("payment_method", "método de pago no encontrado con ID: %s", *subscription.PaymentMethodID)
+	}
+	if !paymentMethod.IsActive {
+		return nil, fmt.Errorf("%w: el método de pago está inactivo", domain.ErrPaymentFailed)
+	}
+	if !paymentMethod.HasCard() {
+		return nil, fmt.Errorf("%w: el método de pago no tiene una tarjeta asociada", domain.ErrPaymentFailed)
+	}
+
+	currency, err := s.currencyRepo.GetByID(ctx, plan.CurrencyID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener moneda del plan: %w", err)
+	}
+	if currency == nil {
+		return nil, domain.NewNotFoundError("currency", "moneda no encontrada con ID: %s", plan.CurrencyID)
+	}
+
+	charge, err := s.gateway.Charge(ctx, &domain.ChargeRequest{
+		Token:          paymentMethod.CardToken,
+		Amount:         amount,
+		Currency:       currency.Code,
+		Description:    fmt.Sprintf("Suscripción %s", plan.Name),
+		IdempotencyKey: idempotencyKey,
+		Metadata: map[string]string{
+			"subscription_id": subscription.ID,
+			"plan_id":         plan.ID,
+		},
+	})
+
+	// Cada intento que llega a la pasarela queda facturado, incluso si fue rechazado
+	if charge != nil {
+		status := domain.InvoiceStatusPaid
+		if err != nil {
+			status = domain.InvoiceStatusFailed
+		}
+		s.issueInvoice(ctx, subscription, plan, invoiceType, status, currency.Code, charge, lines...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
+	}
+	subscription.Metadata["last_charge_id"] = charge.ID
+
+	return charge, nil
+}
+
+// refund devuelve un cobro cuando la operación que lo originó no pudo completarse
+// y emite la nota de crédito correspondiente
+func (s *Service) refund(ctx context.Context, subscription *domain.UserSubscription, plan *domain.Plan, charge *domain.Charge) {
+	if charge == nil {
+		return
+	}
+
+	refund, err := s.gateway.Refund(ctx, charge.ID, 0, charge.Currency)
+	if err != nil {
+		slog.ErrorContext(ctx, "error al reembolsar cobro", "charge_id", charge.ID, "subscription_id", subscription.ID, "error", err)
+		return
+	}
+
+	line := domain.InvoiceLineItem{
+		Description: fmt.Sprintf("Reembolso del cobro %s", charge.ID),
+		Quantity:    1,
+		UnitAmount:  -refund.Amount,
+		Amount:      -refund.Amount,
+	}
+	s.issueInvoice(ctx, subscription, plan, domain.InvoiceTypeRefund, domain.InvoiceStatusRefunded, charge.Currency, charge, line)
+}
+
+// issueInvoice emite la factura de una operación de cobro. El dinero ya se movió en la pasarela,
+// por lo que un error al facturar no revierte la operación: la factura queda pendiente y la emite
+// el ciclo de vida. Solo se registra el error si tampoco se pudo guardar como pendiente.
+func (s *Service) issueInvoice(
+	ctx context.Context,
+	subscription *domain.UserSubscription,
+	plan *domain.Plan,
+	invoiceType domain.InvoiceType,
+	status domain.InvoiceStatus,
+	currency string,
+	charge *domain.Charge,
+	lines ...domain.InvoiceLineItem,
+) {
+	if s.invoices == nil {
+		return
+	}
+
+	invoice := &domain.Invoice{
+		UserID:         subscription.UserID,
+		SubscriptionID: subscription.ID,
+		Type:           invoiceType,
+		Status:         status,
+		Plan: domain.InvoicePlanSnapshot{
+			ID:            plan.ID,
+			Name:          plan.Name,
+			Price:         plan.Price,
+			CurrencyID:    plan.CurrencyID,
+			Interval:      plan.Interval,
+			IntervalCount: plan.IntervalCount,
+		},
+		LineItems:     lines,
+		Currency:      currency,
+		ChargeID:      charge.ID,
+		FailureReason: charge.FailureMessage,
+	}
+
+	if err := s.invoices.IssueInvoice(ctx, invoice); err != nil {
+		slog.ErrorContext(ctx, "error al emitir factura", "subscription_id", subscription.ID, "error", err)
+	}
+}
+
+// markFailed deja una suscripción recién creada como fallida junto con el motivo
+func (s *Service) markFailed(ctx context.Context, subscription *domain.UserSubscription, cause error) {
+	if subscription.Metadata == nil {
+		subscription.Metadata = make(map[string]string)
+	}
+	subscription.Status = domain.SubscriptionStatusFailed
+	subscription.Metadata[metadataLastPaymentError] = cause.Error()
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		slog.ErrorContext(ctx, "error al marcar suscripción como fallida", "subscription_id", subscription.ID, "error", err)
+	}
+}
+
 // isPlanFree determina si un plan es gratuito
 func (s *Service) isPlanFree(plan *domain.Plan) bool {
 	return plan.Price == 0
 }
 
+// sumLines suma los importes de las líneas de una factura
+func sumLines(lines []domain.InvoiceLineItem) float64 {
+	amount := 0.0
+	for _, line := range lines {
+		amount += line.Amount
+	}
+	return domain.RoundAmount(amount)
+}
+
 // timePtr devuelve un puntero a un tiempo
 func timePtr(t time.Time) *time.Time {
 	return &t
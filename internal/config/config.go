@@ -1,67 +1,311 @@
+// Package config construye la configuración de la aplicación a partir de valores por defecto,
+// un archivo YAML opcional, variables de entorno y flags de la línea de comandos, en ese orden de
+// prioridad creciente.
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 
 	"MyMoneyBackend/internal/domain"
 )
 
-// Load carga la configuración desde variables de entorno
-func Load() (*domain.Config, error) {
-	// Valores por defecto
-	serverPort := 30000
-	dbHost := "localhost"
-	dbPort := 5432
-	dbUser := "postgres"
-	dbPassword := "postgres"
-	dbName := "mi_app"
-	dbSSLMode := "disable"
-
-	// Sobreescribir con variables de entorno si existen
-	if port := os.Getenv("SERVER_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			serverPort = p
+// Default devuelve la configuración por defecto
+func Default() *domain.Config {
+	return &domain.Config{
+		Server: domain.ServerConfig{
+			Port:            8080,
+			Mode:            "debug",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Log: domain.LogConfig{
+			Level: "info",
+		},
+		Tracing: domain.TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "http://localhost:4318",
+			ServiceName:  "mymoney-backend",
+			SampleRatio:  1,
+		},
+		Database: domain.DatabaseConfig{
+			Driver:       "postgres",
+			Port:         5432,
+			SSLMode:      "require",
+			MaxOpenConns: 25,
+			SQLitePath:   "mymoney.db",
+		},
+		CORS: domain.CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "traceparent", "tracestate"},
+			ExposedHeaders: []string{
+				"Content-Length", "X-Request-ID",
+				"X-Total-Count", "Link", // Paginación
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		RateLimit: domain.RateLimitConfig{
+			Enabled: true,
+			Store:   "memory",
+			Auth:    domain.RateLimitRule{Requests: 10, Period: time.Minute, By: "ip"},
+			Login:   domain.RateLimitRule{Requests: 5, Period: 15 * time.Minute},
+			Write:   domain.RateLimitRule{Requests: 60, Period: time.Minute, By: "user"},
+		},
+		Payment: domain.PaymentConfig{
+			Provider: "fake",
+		},
+		Scheduler: domain.SchedulerConfig{
+			Enabled:            true,
+			Interval:           time.Hour,
+			DunningSchedule:    []time.Duration{24 * time.Hour, 72 * time.Hour, 168 * time.Hour},
+			ExpiryNoticeWindow: 72 * time.Hour,
+		},
+	}
+}
+
+// Load construye la configuración y devuelve los argumentos que no son flags (el subcomando).
+// El archivo YAML se indica con -config o CONFIG_FILE. No valida la configuración: cada comando
+// valida la parte que necesita.
+func Load(args []string) (*domain.Config, []string, error) {
+	cfg := Default()
+
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "archivo de configuración YAML")
+	port := flags.Int("port", 0, "puerto del servidor HTTP (PORT)")
+	dbDriver := flags.String("db-driver", "", "base de datos: postgres o sqlite (DB_DRIVER)")
+	sqlitePath := flags.String("sqlite-path", "", "archivo de la base de datos SQLite (SQLITE_PATH)")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	if host := os.Getenv("DB_HOST"); host != "" {
-		dbHost = host
+	if err := loadEnv(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db-driver":
+			cfg.Database.Driver = *dbDriver
+		case "sqlite-path":
+			cfg.Database.SQLitePath = *sqlitePath
+		}
+	})
+
+	return cfg, flags.Args(), nil
+}
+
+// loadFile sobrescribe cfg con las claves presentes en el archivo YAML path
+func loadFile(cfg *domain.Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de configuración: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error al leer el archivo de configuración %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv sobrescribe cfg con las variables de entorno definidas
+func loadEnv(cfg *domain.Config) error {
+	env := &envLoader{}
+
+	env.int("PORT", &cfg.Server.Port)
+	env.string("GIN_MODE", &cfg.Server.Mode)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	env.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	env.list("TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	env.string("LOG_LEVEL", &cfg.Log.Level)
+
+	env.string("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	env.string("OTEL_EXPORTER_OTLP_ENDPOINT", &cfg.Tracing.OTLPEndpoint)
+	env.string("OTEL_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	env.float("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio)
+
+	env.string("DB_DRIVER", &cfg.Database.Driver)
+	env.string("SUPABASE_HOST", &cfg.Database.Host)
+	env.int("SUPABASE_PORT", &cfg.Database.Port)
+	env.string("SUPABASE_USER", &cfg.Database.User)
+	env.string("SUPABASE_PASSWORD", &cfg.Database.Password)
+	env.string("SUPABASE_DBNAME", &cfg.Database.Name)
+	env.string("SUPABASE_SSLMODE", &cfg.Database.SSLMode)
+	env.string("SUPABASE_API_KEY", &cfg.Database.APIKey)
+	env.int("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	env.string("SQLITE_PATH", &cfg.Database.SQLitePath)
+
+	env.string("JWT_SECRET", &cfg.Auth.JWTSecret)
+	env.string("JWT_REFRESH_SECRET", &cfg.Auth.JWTRefreshSecret)
+
+	env.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	env.list("CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	env.list("CORS_ALLOWED_HEADERS", &cfg.CORS.AllowedHeaders)
+	env.list("CORS_EXPOSED_HEADERS", &cfg.CORS.ExposedHeaders)
+	env.bool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	env.duration("CORS_MAX_AGE", &cfg.CORS.MaxAge)
+
+	env.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	env.string("RATE_LIMIT_STORE", &cfg.RateLimit.Store)
+	env.int("RATE_LIMIT_AUTH_REQUESTS", &cfg.RateLimit.Auth.Requests)
+	env.duration("RATE_LIMIT_AUTH_PERIOD", &cfg.RateLimit.Auth.Period)
+	env.string("RATE_LIMIT_AUTH_BY", &cfg.RateLimit.Auth.By)
+	env.int("RATE_LIMIT_LOGIN_REQUESTS", &cfg.RateLimit.Login.Requests)
+	env.duration("RATE_LIMIT_LOGIN_PERIOD", &cfg.RateLimit.Login.Period)
+	env.int("RATE_LIMIT_WRITE_REQUESTS", &cfg.RateLimit.Write.Requests)
+	env.duration("RATE_LIMIT_WRITE_PERIOD", &cfg.RateLimit.Write.Period)
+	env.string("RATE_LIMIT_WRITE_BY", &cfg.RateLimit.Write.By)
+
+	env.string("SMTP_HOST", &cfg.SMTP.Host)
+	env.string("SMTP_PORT", &cfg.SMTP.Port)
+	env.string("SMTP_USERNAME", &cfg.SMTP.Username)
+	env.string("SMTP_PASSWORD", &cfg.SMTP.Password)
+	env.string("SMTP_FROM", &cfg.SMTP.From)
+
+	env.string("WEBHOOK_SIGNING_SECRET", &cfg.Webhook.SigningSecret)
+
+	env.string("PAYMENT_PROVIDER", &cfg.Payment.Provider)
+	env.string("STRIPE_SECRET_KEY", &cfg.Payment.StripeSecretKey)
+	env.string("STRIPE_API_BASE", &cfg.Payment.StripeAPIBase)
+
+	env.float("INVOICE_TAX_RATE", &cfg.Invoice.TaxRate)
+
+	env.bool("SUBSCRIPTION_SCHEDULER_ENABLED", &cfg.Scheduler.Enabled)
+	env.duration("SUBSCRIPTION_SCHEDULER_INTERVAL", &cfg.Scheduler.Interval)
+	env.durations("DUNNING_SCHEDULE", &cfg.Scheduler.DunningSchedule)
+	env.duration("EXPIRY_NOTICE_WINDOW", &cfg.Scheduler.ExpiryNoticeWindow)
+
+	return errors.Join(env.errs...)
+}
+
+// Write escribe la configuración en YAML con los secretos ocultos
+func Write(w io.Writer, cfg *domain.Config) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return fmt.Errorf("error al escribir la configuración: %w", err)
+	}
+	return encoder.Close()
+}
+
+// envLoader lee variables de entorno no vacías y acumula los errores de conversión
+type envLoader struct {
+	errs []error
+}
+
+// lookup devuelve el valor de la variable si está definida y no está vacía
+func (e *envLoader) lookup(name string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(name))
+	return value, value != ""
+}
+
+func (e *envLoader) fail(name, value string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("valor no válido en %s=%q: %w", name, value, err))
+}
+
+func (e *envLoader) string(name string, dest *string) {
+	if value, ok := e.lookup(name); ok {
+		*dest = value
 	}
+}
 
-	if port := os.Getenv("DB_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			dbPort = p
+func (e *envLoader) int(name string, dest *int) {
+	if value, ok := e.lookup(name); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			e.fail(name, value, err)
+			return
 		}
+		*dest = parsed
 	}
+}
 
-	if user := os.Getenv("DB_USER"); user != "" {
-		dbUser = user
+func (e *envLoader) float(name string, dest *float64) {
+	if value, ok := e.lookup(name); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			e.fail(name, value, err)
+			return
+		}
+		*dest = parsed
 	}
+}
 
-	if password := os.Getenv("DB_PASSWORD"); password != "" {
-		dbPassword = password
+func (e *envLoader) bool(name string, dest *bool) {
+	if value, ok := e.lookup(name); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			e.fail(name, value, err)
+			return
+		}
+		*dest = parsed
 	}
+}
 
-	if name := os.Getenv("DB_NAME"); name != "" {
-		dbName = name
+func (e *envLoader) duration(name string, dest *time.Duration) {
+	if value, ok := e.lookup(name); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			e.fail(name, value, err)
+			return
+		}
+		*dest = parsed
 	}
+}
 
-	if sslMode := os.Getenv("DB_SSL_MODE"); sslMode != "" {
-		dbSSLMode = sslMode
+// list lee una lista separada por comas
+func (e *envLoader) list(name string, dest *[]string) {
+	if value, ok := e.lookup(name); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dest = items
 	}
+}
 
-	return &domain.Config{
-		Server: domain.ServerConfig{
-			Port: serverPort,
-		},
-		Database: domain.DatabaseConfig{
-			Host:     dbHost,
-			Port:     dbPort,
-			User:     dbUser,
-			Password: dbPassword,
-			Name:     dbName,
-			SSLMode:  dbSSLMode,
-		},
-	}, nil
+// durations lee una lista de duraciones separadas por comas ("24h,72h")
+func (e *envLoader) durations(name string, dest *[]time.Duration) {
+	if value, ok := e.lookup(name); ok {
+		var items []time.Duration
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(item)
+			if err != nil {
+				e.fail(name, value, err)
+				return
+			}
+			items = append(items, parsed)
+		}
+		*dest = items
+	}
 }
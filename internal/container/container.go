@@ -0,0 +1,248 @@
+// Package container es la raíz de composición de la aplicación: construye una sola vez, sobre un
+// único pool de conexiones, los repositorios, servicios, handlers y tareas de fondo.
+package container
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/internal/application/auth"
+	categoryService "MyMoneyBackend/internal/application/category"
+	couponService "MyMoneyBackend/internal/application/coupon"
+	currencyService "MyMoneyBackend/internal/application/currency"
+	entitlementService "MyMoneyBackend/internal/application/entitlement"
+	invoiceService "MyMoneyBackend/internal/application/invoice"
+	notificationService "MyMoneyBackend/internal/application/notification"
+	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
+	planService "MyMoneyBackend/internal/application/plan"
+	transactionService "MyMoneyBackend/internal/application/transaction"
+	userService "MyMoneyBackend/internal/application/user"
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+	categoryHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/category"
+	couponHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/coupon"
+	currencyHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/currency"
+	entitlementHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/entitlement"
+	healthHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/health"
+	invoiceHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/invoice"
+	notificationHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/notification"
+	paymentMethodHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/paymentmethod"
+	planHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/plan"
+	transactionHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/transaction"
+	userHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/user"
+	userSubscriptionHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/user_subscription"
+	middlewares "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/routers"
+	"MyMoneyBackend/internal/infraestructure/outbound/lock"
+	"MyMoneyBackend/internal/infraestructure/outbound/metrics"
+	"MyMoneyBackend/internal/infraestructure/outbound/notifier"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+	"MyMoneyBackend/internal/infraestructure/outbound/ratelimit"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+)
+
+// Container contiene los componentes de la aplicación ya conectados entre sí
+type Container struct {
+	DB          *sql.DB
+	Handlers    routers.Handlers
+	Middlewares routers.Middlewares
+	Scheduler   *userSubscriptionService.Scheduler // nil si el planificador está desactivado
+
+	conn    *config.Connection
+	workers sync.WaitGroup
+}
+
+// New construye la aplicación sobre la conexión conn. Falla si la conexión no está abierta,
+// para no servir rutas que fallarían en la primera petición.
+func New(cfg *domain.Config, conn *config.Connection) (*Container, error) {
+	if conn == nil || conn.GetDB() == nil {
+		return nil, errors.New("se requiere una conexión a la base de datos abierta")
+	}
+	db := conn.GetDB()
+
+	// Repositorios
+	userRepo := repository.NewUserRepository(db)
+	var categoryRepo app.CategoryRepository = repository.NewCategoryRepository(db)
+	var paymentMethodRepo app.PaymentMethodRepository = repository.NewPaymentMethodRepository(db)
+	var transactionRepo app.TransactionRepository = repository.NewTransactionRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationPreferenceRepo := repository.NewNotificationPreferenceRepository(db)
+	notificationTemplateRepo := repository.NewNotificationTemplateRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	userSubscriptionRepo := repository.NewUserSubscriptionRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	couponRepo := repository.NewCouponRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+
+	// Adaptadores externos
+	appMetrics := metrics.NewPrometheus(db)
+	notifiers := newNotifiers(cfg, notificationRepo)
+	paymentGateway := newPaymentGateway(cfg.Payment)
+
+	// Servicios
+	tokenSvc := auth.NewTokenService(cfg.Auth)
+	notificationSvc := notificationService.NewService(
+		notificationRepo,
+		notificationPreferenceRepo,
+		notificationTemplateRepo,
+		userRepo,
+		notifiers...,
+	)
+	userSvc := userService.NewUserService(userRepo, notificationSvc, appMetrics)
+	entitlementSvc := entitlementService.NewService(userSubscriptionRepo, planRepo)
+	categorySvc := categoryService.NewService(categoryRepo, entitlementSvc)
+	paymentMethodSvc := paymentMethodService.NewService(paymentMethodRepo, paymentGateway)
+	transactionSvc := transactionService.NewService(transactionRepo, categoryRepo, currencyRepo, entitlementSvc, notificationSvc, appMetrics)
+	currencySvc := currencyService.NewService(currencyRepo)
+	planSvc := planService.NewService(planRepo, currencyRepo, userSubscriptionRepo)
+
+	// Los precios de los planes incluyen impuestos; la tasa solo se usa para desglosarlos en las facturas
+	invoiceSvc := invoiceService.NewService(invoiceRepo, cfg.Invoice.TaxRate)
+	couponSvc := couponService.NewService(couponRepo, planRepo)
+
+	userSubscriptionSvc := userSubscriptionService.NewService(
+		userSubscriptionRepo,
+		planRepo,
+		userRepo,
+		paymentMethodRepo,
+		currencyRepo,
+		couponRepo,
+		paymentGateway,
+		invoiceSvc,
+		notificationSvc,
+		unitOfWork,
+		appMetrics,
+	)
+
+	// Planificador de suscripciones (renovaciones, reintentos de cobro y expiraciones)
+	var scheduler *userSubscriptionService.Scheduler
+	if cfg.Scheduler.Enabled {
+		// Con SQLite solo corre una instancia, que siempre es la líder
+		var leaderLock app.LeaderLock = lock.NewAdvisoryLock(db, lock.SubscriptionSchedulerKey)
+		if conn.Driver() == config.DriverSQLite {
+			leaderLock = lock.NewLocalLock()
+		}
+
+		scheduler = userSubscriptionService.NewScheduler(
+			userSubscriptionSvc,
+			leaderLock,
+			userSubscriptionService.SchedulerConfig{
+				Interval:           cfg.Scheduler.Interval,
+				DunningSchedule:    cfg.Scheduler.DunningSchedule,
+				ExpiryNoticeWindow: cfg.Scheduler.ExpiryNoticeWindow,
+			},
+		)
+	}
+
+	// Límite de peticiones: por IP en las rutas de autenticación y por usuario en las escrituras
+	authMiddleware := middlewares.NewAuthMiddleware(tokenSvc)
+	var rateLimitMiddleware *middlewares.RateLimitMiddleware
+	if cfg.RateLimit.Enabled {
+		rateLimitMiddleware = middlewares.NewRateLimitMiddleware(newRateLimitStore(cfg.RateLimit, db), cfg.RateLimit)
+		authMiddleware = middlewares.NewAuthMiddleware(tokenSvc, rateLimitMiddleware.Writes())
+	}
+
+	// Comprobaciones de /readyz sobre el mismo pool
+	checks, err := newHealthChecks(conn, scheduler)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Container{
+		DB:        db,
+		Scheduler: scheduler,
+		conn:      conn,
+		Handlers: routers.Handlers{
+			User:             userHandler.NewUserHandler(*userSvc, *tokenSvc),
+			Category:         categoryHandler.NewCategoryHandler(categorySvc),
+			PaymentMethod:    paymentMethodHandler.NewPaymentMethodHandler(paymentMethodSvc),
+			Transaction:      transactionHandler.NewTransactionHandler(transactionSvc),
+			Currency:         currencyHandler.NewCurrencyHandler(currencySvc),
+			Plan:             planHandler.NewPlanHandler(planSvc),
+			UserSubscription: userSubscriptionHandler.NewUserSubscriptionHandler(userSubscriptionSvc, invoiceSvc),
+			Invoice:          invoiceHandler.NewInvoiceHandler(invoiceSvc),
+			Coupon:           couponHandler.NewCouponHandler(couponSvc),
+			Entitlement:      entitlementHandler.NewEntitlementHandler(entitlementSvc),
+			Notification:     notificationHandler.NewNotificationHandler(notificationSvc),
+			Health:           healthHandler.NewHealthHandler(db, checks),
+			Metrics:          appMetrics.Handler(),
+		},
+		Middlewares: routers.Middlewares{
+			CORS:      middlewares.NewCORSMiddleware(cfg.CORS),
+			Auth:      authMiddleware,
+			Admin:     middlewares.NewAdminMiddleware(),
+			Metrics:   middlewares.NewMetricsMiddleware(appMetrics),
+			RateLimit: rateLimitMiddleware,
+		},
+	}
+
+	return c, nil
+}
+
+// Start lanza las tareas de fondo; se detienen al cancelarse ctx
+func (c *Container) Start(ctx context.Context) {
+	if c.Scheduler != nil {
+		c.workers.Add(1)
+		go func() {
+			defer c.workers.Done()
+			c.Scheduler.Run(ctx)
+		}()
+	}
+}
+
+// Close espera a que terminen las tareas de fondo y cierra la conexión a la base de datos.
+// El contexto pasado a Start debe estar cancelado antes de llamarlo.
+func (c *Container) Close() error {
+	c.workers.Wait()
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("error al cerrar la conexión a la base de datos: %w", err)
+	}
+	return nil
+}
+
+// newNotifiers crea los canales de notificación configurados; in-app siempre está activo
+func newNotifiers(cfg *domain.Config, notificationRepo app.NotificationRepository) []app.Notifier {
+	notifiers := []app.Notifier{notifier.NewInAppNotifier(notificationRepo)}
+	if cfg.SMTP.Host != "" {
+		notifiers = append(notifiers, notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		}))
+	} else {
+		slog.Warn("SMTP_HOST no definido, notificaciones por email desactivadas")
+	}
+	if cfg.Webhook.SigningSecret != "" {
+		notifiers = append(notifiers, notifier.NewWebhookNotifier(cfg.Webhook.SigningSecret, nil))
+	} else {
+		slog.Warn("WEBHOOK_SIGNING_SECRET no definido, notificaciones por webhook desactivadas")
+	}
+	return notifiers
+}
+
+// newRateLimitStore crea el almacén del limitador: en memoria cuenta por instancia y en la base
+// de datos se comparte entre todas
+func newRateLimitStore(cfg domain.RateLimitConfig, db *sql.DB) app.RateLimitStore {
+	if cfg.Store == "database" {
+		return ratelimit.NewDatabaseStore(db)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// newPaymentGateway crea la pasarela de pago configurada
+func newPaymentGateway(cfg domain.PaymentConfig) app.PaymentGateway {
+	if cfg.Provider == "stripe" {
+		return payment.NewStripeGateway(cfg.StripeAPIBase, cfg.StripeSecretKey, nil)
+	}
+	slog.Warn("usando la pasarela de pago simulada, no se harán cobros reales")
+	return payment.NewFakeGateway()
+}
@@ -0,0 +1,85 @@
+package container
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/internal/application/health"
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+)
+
+// migrationsCacheTTL espacia la verificación de migraciones: solo cambian al desplegar
+const migrationsCacheTTL = time.Minute
+
+// newHealthChecks registra las comprobaciones de /readyz: la base de datos y las migraciones son
+// críticas; el planificador, si está activo, solo degrada la instancia porque sin él se sigue
+// atendiendo peticiones
+func newHealthChecks(conn *config.Connection, scheduler *userSubscriptionService.Scheduler) (*health.Registry, error) {
+	db := conn.GetDB()
+	dialect, fsys := migrate.Postgres, fs.FS(migrations.FS)
+	if conn.Driver() == config.DriverSQLite {
+		dialect, fsys = migrate.SQLite, migrations.SQLite
+	}
+	migrator, err := migrate.New(db, dialect, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar las migraciones: %w", err)
+	}
+
+	checks := []health.Check{
+		{
+			Name:     "database",
+			Critical: true,
+			Run:      pingCheck(db),
+		},
+		{
+			Name:     "migrations",
+			Critical: true,
+			CacheTTL: migrationsCacheTTL,
+			Run:      migrator.Verify,
+		},
+	}
+	if scheduler != nil {
+		checks = append(checks, health.Check{
+			Name: "subscription_scheduler",
+			Run:  schedulerCheck(scheduler),
+		})
+	}
+
+	registry := health.NewRegistry()
+	for _, check := range checks {
+		if err := registry.Register(check); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// pingCheck comprueba que el pool de conexiones llegue a la base de datos
+func pingCheck(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("la base de datos no responde: %w", err)
+		}
+		return nil
+	}
+}
+
+// schedulerCheck comprueba que el planificador haya empezado una pasada en los dos últimos intervalos
+func schedulerCheck(scheduler *userSubscriptionService.Scheduler) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		lastTick := scheduler.LastTick()
+		if lastTick.IsZero() {
+			return fmt.Errorf("el planificador no se ha iniciado")
+		}
+		if since := time.Since(lastTick); since > 2*scheduler.Interval() {
+			return fmt.Errorf("el planificador no se ejecuta desde hace %s", since.Round(time.Second))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LifetimeEndDate es la fecha de finalización de las suscripciones de por vida
+var LifetimeEndDate = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// IsLifetime indica si el plan se paga una sola vez y no se renueva
+func (p *Plan) IsLifetime() bool {
+	return p.Interval == PlanIntervalLifetime
+}
+
+// SameBillingInterval indica si dos planes facturan con el mismo período
+func (p *Plan) SameBillingInterval(other *Plan) bool {
+	return p.Interval == other.Interval && intervalCount(p) == intervalCount(other)
+}
+
+// DescribeInterval devuelve el intervalo de facturación del plan en lenguaje natural
+func (p *Plan) DescribeInterval() string {
+	count := intervalCount(p)
+	switch p.Interval {
+	case PlanIntervalLifetime:
+		return "pago único"
+	case PlanIntervalDay:
+		if count == 1 {
+			return "diario"
+		}
+		return fmt.Sprintf("cada %d días", count)
+	case PlanIntervalWeek:
+		if count == 1 {
+			return "semanal"
+		}
+		return fmt.Sprintf("cada %d semanas", count)
+	case PlanIntervalMonth:
+		switch count {
+		case 1:
+			return "mensual"
+		case 3:
+			return "trimestral"
+		case 6:
+			return "semestral"
+		}
+		return fmt.Sprintf("cada %d meses", count)
+	case PlanIntervalYear:
+		if count == 1 {
+			return "anual"
+		}
+		return fmt.Sprintf("cada %d años", count)
+	default:
+		return string(p.Interval)
+	}
+}
+
+// PeriodEnd calcula el fin del período de facturación del plan que comienza en start.
+// Los planes de por vida terminan en LifetimeEndDate.
+func PeriodEnd(plan *Plan, start time.Time) time.Time {
+	return AddInterval(start, plan.Interval, intervalCount(plan))
+}
+
+// NextPeriodEnd calcula el fin del período que sigue al que termina en end, para una suscripción
+// que factura el día anchorDay del mes. A diferencia de PeriodEnd no arrastra el ajuste de los meses
+// cortos: con anchorDay 31 los períodos terminan el 29 de febrero, el 31 de marzo y el 30 de abril.
+// Para planes que no son mensuales ni anuales, o si end no cae en anchorDay, equivale a PeriodEnd.
+func NextPeriodEnd(plan *Plan, anchorDay int, end time.Time) time.Time {
+	months := intervalCount(plan)
+	switch plan.Interval {
+	case PlanIntervalMonth:
+	case PlanIntervalYear:
+		months *= 12
+	default:
+		return PeriodEnd(plan, end)
+	}
+
+	end = end.UTC()
+	day, lastDay := end.Day(), daysInMonth(end)
+	if day != anchorDay && (day > anchorDay || day != lastDay) {
+		return PeriodEnd(plan, end)
+	}
+	return addMonthsOnDay(end, months, anchorDay)
+}
+
+// PeriodStart calcula el inicio del período de facturación del plan que termina en end
+func PeriodStart(plan *Plan, end time.Time) time.Time {
+	if plan.IsLifetime() {
+		return time.Time{}
+	}
+	return AddInterval(end, plan.Interval, -intervalCount(plan))
+}
+
+// RenewalDate calcula la fecha de renovación de un período que termina en end.
+// La renovación se cobra al terminar el período; los planes de por vida no se renuevan.
+func RenewalDate(plan *Plan, end time.Time) *time.Time {
+	if plan.IsLifetime() {
+		return nil
+	}
+	return &end
+}
+
+// UnusedFraction calcula la fracción del período [start, end) que queda sin usar en now,
+// entre 0 y 1
+func UnusedFraction(start, end, now time.Time) float64 {
+	total := end.Sub(start)
+	if total <= 0 || !now.Before(end) {
+		return 0
+	}
+	if now.Before(start) {
+		return 1
+	}
+	return float64(end.Sub(now)) / float64(total)
+}
+
+// AddInterval suma count unidades de intervalo a from (o resta si count es negativo).
+// Al sumar meses o años el día se ajusta al último día del mes de destino en lugar de
+// desbordar al mes siguiente: 31 de enero + 1 mes es 28 (o 29) de febrero.
+// El cálculo se hace en UTC, de modo que el mismo instante produce la misma fecha de renovación
+// sin importar la zona horaria en que se exprese.
+func AddInterval(from time.Time, unit PlanInterval, count int) time.Time {
+	from = from.UTC()
+	switch unit {
+	case PlanIntervalDay:
+		return from.AddDate(0, 0, count)
+	case PlanIntervalWeek:
+		return from.AddDate(0, 0, 7*count)
+	case PlanIntervalMonth:
+		return addMonthsClamped(from, count)
+	case PlanIntervalYear:
+		return addMonthsClamped(from, 12*count)
+	case PlanIntervalLifetime:
+		return LifetimeEndDate
+	default:
+		return from
+	}
+}
+
+// addMonthsClamped suma meses ajustando el día al último día del mes de destino
+func addMonthsClamped(from time.Time, months int) time.Time {
+	return addMonthsOnDay(from, months, from.Day())
+}
+
+// addMonthsOnDay suma meses y ubica el resultado en el día indicado, o en el último día del mes
+// de destino si es más corto. Conserva la hora de from.
+func addMonthsOnDay(from time.Time, months, day int) time.Time {
+	year, month, _ := from.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, from.Location())
+	if lastDay := daysInMonth(firstOfTarget); day > lastDay {
+		day = lastDay
+	}
+	hour, minute, second := from.Clock()
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, hour, minute, second, from.Nanosecond(), from.Location())
+}
+
+// daysInMonth devuelve la cantidad de días del mes de t
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// intervalCount devuelve la cantidad de unidades del período, tratando 0 como 1
+func intervalCount(plan *Plan) int {
+	if plan.IntervalCount < 1 {
+		return 1
+	}
+	return plan.IntervalCount
+}
+
+// CurrentPeriodStart calcula el inicio del período de facturación en curso de la suscripción,
+// sin ser anterior a su fecha de inicio
+func CurrentPeriodStart(plan *Plan, subscription *UserSubscription) time.Time {
+	start := PeriodStart(plan, subscription.EndDate)
+	if start.Before(subscription.StartDate) {
+		return subscription.StartDate
+	}
+	return start
+}
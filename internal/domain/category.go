@@ -6,14 +6,21 @@ import (
 
 // Category representa una categoría en el sistema
 type Category struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Color       string    `json:"color"`
-	Icon        string    `json:"icon"`
-	UserID      string    `json:"user_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Color       string          `json:"color"`
+	Icon        string          `json:"icon"`
+	UserID      string          `json:"user_id"`
+	Budget      *CategoryBudget `json:"budget,omitempty"` // Presupuesto mensual; nil si no tiene
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CategoryBudget es el gasto máximo por mes de una categoría. Solo cuentan los gastos en su moneda.
+type CategoryBudget struct {
+	Amount     float64 `json:"amount"`      // Gasto máximo por mes
+	CurrencyID string  `json:"currency_id"` // Moneda del presupuesto
 }
 
 // Validate valida que los campos obligatorios estén presentes
@@ -24,6 +31,14 @@ func (c *Category) Validate() error {
 	if c.UserID == "" {
 		return ErrEmptyUserID
 	}
+	if c.Budget != nil {
+		if c.Budget.Amount <= 0 {
+			return NewValidationError("amount", "el presupuesto debe ser mayor que cero")
+		}
+		if c.Budget.CurrencyID == "" {
+			return NewValidationError("currency_id", "la moneda del presupuesto es obligatoria")
+		}
+	}
 	return nil
 }
 
@@ -42,3 +57,9 @@ type UpdateCategoryRequest struct {
 	Icon        string `json:"icon"`
 	Color       string `json:"color"`
 }
+
+// SetCategoryBudgetRequest representa la petición para fijar o quitar el presupuesto de una categoría
+type SetCategoryBudgetRequest struct {
+	Amount     *float64 `json:"amount"` // null quita el presupuesto
+	CurrencyID string   `json:"currency_id"`
+}
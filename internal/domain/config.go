@@ -1,22 +1,402 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// redactedSecret reemplaza a los secretos al mostrar la configuración
+const redactedSecret = "****"
+
 // Config contiene toda la configuración de la aplicación
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server    ServerConfig    `yaml:"server"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	CORS      CORSConfig      `yaml:"cors"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	SMTP      SMTPConfig      `yaml:"smtp"`
+	Webhook   WebhookConfig   `yaml:"webhook"`
+	Payment   PaymentConfig   `yaml:"payment"`
+	Invoice   InvoiceConfig   `yaml:"invoice"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
 }
 
 // ServerConfig contiene la configuración del servidor HTTP
 type ServerConfig struct {
-	Port int
+	Port            int           `yaml:"port"`
+	Mode            string        `yaml:"mode"` // Modo de Gin: debug, release o test
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // Espera máxima para terminar las peticiones en curso
+	TrustedProxies  []string      `yaml:"trusted_proxies"`  // IPs o CIDR cuyo X-Forwarded-For se acepta; vacío usa la IP de conexión
+}
+
+// LogConfig contiene la configuración de los logs estructurados
+type LogConfig struct {
+	Level string `yaml:"level"` // debug, info, warn o error
+}
+
+// TracingConfig contiene la exportación de trazas de OpenTelemetry
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter"`      // none, stdout u otlp
+	OTLPEndpoint string  `yaml:"otlp_endpoint"` // URL base del collector OTLP/HTTP
+	ServiceName  string  `yaml:"service_name"`
+	SampleRatio  float64 `yaml:"sample_ratio"` // Fracción de trazas nuevas que se guardan (0 a 1)
 }
 
 // DatabaseConfig contiene la configuración de la base de datos
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Driver       string `yaml:"driver"` // postgres o sqlite
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	User         string `yaml:"user"`
+	Password     string `yaml:"password"`
+	Name         string `yaml:"name"`
+	SSLMode      string `yaml:"ssl_mode"`
+	APIKey       string `yaml:"api_key"` // API key de Supabase (opcional)
+	MaxOpenConns int    `yaml:"max_open_conns"`
+	SQLitePath   string `yaml:"sqlite_path"`
+}
+
+// AuthConfig contiene los secretos para firmar los tokens JWT
+type AuthConfig struct {
+	JWTSecret        string `yaml:"jwt_secret"`
+	JWTRefreshSecret string `yaml:"jwt_refresh_secret"` // Si está vacío se usa JWTSecret
+}
+
+// CORSConfig contiene la política CORS: qué orígenes pueden llamar a la API desde el navegador
+// y qué métodos y cabeceras pueden usar y leer
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"` // Orígenes exactos, patrones (https://*.example.com) o *
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"` // Cabeceras de la respuesta legibles desde el navegador
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"` // Cuánto cachea el navegador la respuesta preflight
+}
+
+// RateLimitConfig contiene los límites de peticiones por grupo de rutas
+type RateLimitConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Store   string        `yaml:"store"` // memory (por instancia) o database (compartido entre instancias)
+	Auth    RateLimitRule `yaml:"auth"`  // Rutas /auth: inicio de sesión, registro y renovación del token
+	Login   RateLimitRule `yaml:"login"` // Intentos de inicio de sesión por email, además del límite de auth; By no se usa
+	Write   RateLimitRule `yaml:"write"` // Peticiones autenticadas que modifican datos (POST, PUT, PATCH, DELETE)
+}
+
+// RateLimitRule permite Requests peticiones cada Period a cada IP o a cada usuario
+type RateLimitRule struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	By       string        `yaml:"by"` // ip o user; sin usuario autenticado se limita por IP
+}
+
+// SMTPConfig contiene el servidor de correo para las notificaciones por email
+type SMTPConfig struct {
+	Host     string `yaml:"host"` // Vacío desactiva las notificaciones por email
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// WebhookConfig contiene el secreto para firmar los webhooks de notificación
+type WebhookConfig struct {
+	SigningSecret string `yaml:"signing_secret"` // Vacío desactiva los webhooks
+}
+
+// PaymentConfig contiene la pasarela de pago de las suscripciones
+type PaymentConfig struct {
+	Provider        string `yaml:"provider"` // fake o stripe
+	StripeSecretKey string `yaml:"stripe_secret_key"`
+	StripeAPIBase   string `yaml:"stripe_api_base"`
+}
+
+// InvoiceConfig contiene la configuración de facturación
+type InvoiceConfig struct {
+	TaxRate float64 `yaml:"tax_rate"` // Tasa incluida en los precios (0.15 = 15%)
+}
+
+// SchedulerConfig contiene la configuración del planificador de suscripciones
+type SchedulerConfig struct {
+	Enabled         bool            `yaml:"enabled"`
+	Interval        time.Duration   `yaml:"interval"`
+	DunningSchedule []time.Duration `yaml:"dunning_schedule"` // Esperas entre reintentos de cobro
+	// Antelación con que se avisa de las suscripciones por expirar; 0 desactiva el aviso
+	ExpiryNoticeWindow time.Duration `yaml:"expiry_notice_window"`
+}
+
+// Validate comprueba toda la configuración y devuelve todos los errores encontrados juntos
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port debe estar entre 1 y 65535, es %d", c.Server.Port))
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Errorf("server.mode debe ser debug, release o test, es %q", c.Server.Mode))
+	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s debe ser positivo, es %s", timeout.name, timeout.value))
+		}
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level debe ser debug, info, warn o error, es %q", c.Log.Level))
+	}
+
+	errs = append(errs, c.Tracing.Validate())
+	errs = append(errs, c.Database.Validate())
+
+	if c.Auth.JWTSecret == "" {
+		errs = append(errs, errors.New("auth.jwt_secret es obligatorio (JWT_SECRET)"))
+	}
+
+	errs = append(errs, c.CORS.Validate(c.Server.Mode))
+
+	errs = append(errs, c.RateLimit.Validate())
+
+	switch c.Payment.Provider {
+	case "fake":
+		// La pasarela falsa aprueba cualquier tarjeta sin cobrar: solo sirve para desarrollo y pruebas
+		if c.Server.Mode == "release" {
+			errs = append(errs, errors.New("payment.provider no puede ser fake con server.mode=release"))
+		}
+	case "stripe":
+		if c.Payment.StripeSecretKey == "" {
+			errs = append(errs, errors.New("payment.stripe_secret_key es obligatorio con payment.provider=stripe (STRIPE_SECRET_KEY)"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("payment.provider debe ser fake o stripe, es %q", c.Payment.Provider))
+	}
+
+	if c.Invoice.TaxRate < 0 || c.Invoice.TaxRate >= 1 {
+		errs = append(errs, fmt.Errorf("invoice.tax_rate debe estar entre 0 y 1, es %v", c.Invoice.TaxRate))
+	}
+
+	if c.Scheduler.Enabled {
+		if c.Scheduler.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("scheduler.interval debe ser positivo, es %s", c.Scheduler.Interval))
+		}
+		if len(c.Scheduler.DunningSchedule) == 0 {
+			errs = append(errs, errors.New("scheduler.dunning_schedule no puede estar vacío"))
+		}
+		for _, delay := range c.Scheduler.DunningSchedule {
+			if delay <= 0 {
+				errs = append(errs, fmt.Errorf("scheduler.dunning_schedule solo admite duraciones positivas, tiene %s", delay))
+			}
+		}
+		if c.Scheduler.ExpiryNoticeWindow < 0 {
+			errs = append(errs, fmt.Errorf("scheduler.expiry_notice_window no puede ser negativo, es %s", c.Scheduler.ExpiryNoticeWindow))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate comprueba la configuración de trazas según su exportador
+func (c *TracingConfig) Validate() error {
+	var errs []error
+	switch c.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		endpoint, err := url.Parse(c.OTLPEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			errs = append(errs, fmt.Errorf("tracing.otlp_endpoint debe ser una URL http o https, es %q", c.OTLPEndpoint))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter debe ser none, stdout u otlp, es %q", c.Exporter))
+	}
+	if c.Exporter != "none" && c.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name es obligatorio al exportar trazas (OTEL_SERVICE_NAME)"))
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio debe estar entre 0 y 1, es %v", c.SampleRatio))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate comprueba la política CORS. En modo release no se admite el origen *: cada entorno
+// debe declarar sus orígenes.
+func (c *CORSConfig) Validate(mode string) error {
+	var errs []error
+	if len(c.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowed_origins no puede estar vacío"))
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errs = append(errs, errors.New("cors.allowed_origins no puede incluir * con cors.allow_credentials"))
+			}
+			if mode == "release" {
+				errs = append(errs, errors.New("cors.allowed_origins no puede incluir * con server.mode=release"))
+			}
+			continue
+		}
+		if !validOriginPattern(origin) {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins debe contener orígenes como https://app.example.com o https://*.example.com, tiene %q", origin))
+		}
+	}
+	if len(c.AllowedMethods) == 0 {
+		errs = append(errs, errors.New("cors.allowed_methods no puede estar vacío"))
+	}
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cors.max_age no puede ser negativo, es %s", c.MaxAge))
+	}
+	return errors.Join(errs...)
+}
+
+// validOriginPattern indica si origin es esquema://host[:puerto]. Se admite * como puerto o como
+// primera etiqueta del host seguida de un dominio registrable según la lista de sufijos públicos
+// (https://*.example.com), para que un comodín no pueda admitir cualquier origen ni todo un sufijo
+// público como .com o .co.uk.
+func validOriginPattern(origin string) bool {
+	scheme, authority, ok := strings.Cut(origin, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return false
+	}
+	host, port := authority, ""
+	if i := strings.LastIndex(authority, ":"); i >= 0 {
+		host, port = authority[:i], authority[i+1:]
+	}
+	if port == "*" {
+		port = "1"
+	} else if strings.Contains(port, "*") {
+		return false
+	}
+
+	if strings.Contains(host, "*") {
+		domain, ok := strings.CutPrefix(host, "*.")
+		if !ok || strings.Contains(domain, "*") {
+			return false
+		}
+		for _, label := range strings.Split(domain, ".") {
+			if label == "" {
+				return false
+			}
+		}
+		// El dominio tras el comodín debe ser registrable o un subdominio suyo, no un sufijo público
+		registrable, err := publicsuffix.EffectiveTLDPlusOne(domain)
+		if err != nil || len(registrable) > len(domain) {
+			return false
+		}
+		host = "x." + domain
+	}
+
+	if port != "" {
+		host += ":" + port
+	}
+	parsed, err := url.Parse(scheme + "://" + host)
+	return err == nil && parsed.Host != "" && parsed.Path == "" && parsed.RawQuery == "" && parsed.User == nil
+}
+
+// Validate comprueba el almacén y las reglas del limitador de peticiones si está activo
+func (c *RateLimitConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	switch c.Store {
+	case "memory", "database":
+	default:
+		errs = append(errs, fmt.Errorf("rate_limit.store debe ser memory o database, es %q", c.Store))
+	}
+	rules := []struct {
+		name string
+		rule RateLimitRule
+	}{
+		{"rate_limit.auth", c.Auth},
+		{"rate_limit.login", c.Login},
+		{"rate_limit.write", c.Write},
+	}
+	for _, r := range rules {
+		if r.rule.Requests <= 0 {
+			errs = append(errs, fmt.Errorf("%s.requests debe ser positivo, es %d", r.name, r.rule.Requests))
+		}
+		if r.rule.Period <= 0 {
+			errs = append(errs, fmt.Errorf("%s.period debe ser positivo, es %s", r.name, r.rule.Period))
+		}
+		// El límite de inicio de sesión siempre cuenta por email
+		if r.name != "rate_limit.login" && r.rule.By != "ip" && r.rule.By != "user" {
+			errs = append(errs, fmt.Errorf("%s.by debe ser ip o user, es %q", r.name, r.rule.By))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate comprueba la configuración de la base de datos según su driver
+func (c *DatabaseConfig) Validate() error {
+	var errs []error
+	switch c.Driver {
+	case "postgres":
+		if c.Host == "" {
+			errs = append(errs, errors.New("database.host es obligatorio con database.driver=postgres (SUPABASE_HOST)"))
+		}
+		if c.Port < 1 || c.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database.port debe estar entre 1 y 65535, es %d", c.Port))
+		}
+		if c.User == "" {
+			errs = append(errs, errors.New("database.user es obligatorio con database.driver=postgres (SUPABASE_USER)"))
+		}
+		if c.Password == "" {
+			errs = append(errs, errors.New("database.password es obligatorio con database.driver=postgres (SUPABASE_PASSWORD)"))
+		}
+		if c.Name == "" {
+			errs = append(errs, errors.New("database.name es obligatorio con database.driver=postgres (SUPABASE_DBNAME)"))
+		}
+		switch c.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			errs = append(errs, fmt.Errorf("database.ssl_mode no es un modo de PostgreSQL válido: %q", c.SSLMode))
+		}
+		if c.MaxOpenConns < 1 {
+			errs = append(errs, fmt.Errorf("database.max_open_conns debe ser al menos 1, es %d", c.MaxOpenConns))
+		}
+	case "sqlite":
+		if c.SQLitePath == "" {
+			errs = append(errs, errors.New("database.sqlite_path es obligatorio con database.driver=sqlite (SQLITE_PATH)"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver debe ser postgres o sqlite, es %q", c.Driver))
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted devuelve una copia de la configuración con los secretos ocultos, para mostrarla
+func (c Config) Redacted() Config {
+	redact := func(secret *string) {
+		if *secret != "" {
+			*secret = redactedSecret
+		}
+	}
+	redact(&c.Database.Password)
+	redact(&c.Database.APIKey)
+	redact(&c.Auth.JWTSecret)
+	redact(&c.Auth.JWTRefreshSecret)
+	redact(&c.SMTP.Password)
+	redact(&c.Webhook.SigningSecret)
+	redact(&c.Payment.StripeSecretKey)
+	return c
 }
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CouponDiscountType define cómo se calcula el descuento de un cupón
+type CouponDiscountType string
+
+const (
+	// CouponDiscountPercent descuenta un porcentaje del precio del plan
+	CouponDiscountPercent CouponDiscountType = "percent"
+	// CouponDiscountAmount descuenta un monto fijo en la moneda del cupón
+	CouponDiscountAmount CouponDiscountType = "amount"
+)
+
+// CouponDuration define durante cuántos períodos de facturación se aplica un cupón
+type CouponDuration string
+
+const (
+	// CouponDurationOnce aplica el descuento solo al primer cobro
+	CouponDurationOnce CouponDuration = "once"
+	// CouponDurationRepeating aplica el descuento durante DurationPeriods cobros
+	CouponDurationRepeating CouponDuration = "repeating"
+	// CouponDurationForever aplica el descuento a todos los cobros
+	CouponDurationForever CouponDuration = "forever"
+)
+
+// Coupon representa un código promocional de descuento sobre los planes de suscripción
+type Coupon struct {
+	ID              string             `json:"id"`
+	Code            string             `json:"code"`             // Código que introduce el usuario (se guarda en mayúsculas)
+	Description     string             `json:"description"`      // Descripción del descuento
+	DiscountType    CouponDiscountType `json:"discount_type"`    // Porcentaje o monto fijo
+	PercentOff      float64            `json:"percent_off"`      // Porcentaje de descuento (1-100)
+	AmountOff       float64            `json:"amount_off"`       // Monto de descuento
+	CurrencyID      *string            `json:"currency_id"`      // Moneda del monto de descuento
+	Duration        CouponDuration     `json:"duration"`         // Durante cuántos cobros se aplica
+	DurationPeriods int                `json:"duration_periods"` // Cobros con descuento cuando la duración es repeating
+	PlanIDs         []string           `json:"plan_ids"`         // Familias de planes a las que aplica (vacío = todos)
+	MaxRedemptions  *int               `json:"max_redemptions"`  // Límite de canjes (null = sin límite)
+	TimesRedeemed   int                `json:"times_redeemed"`   // Canjes realizados
+	ExpiresAt       *time.Time         `json:"expires_at"`       // Fecha límite para canjear el cupón
+	IsActive        bool               `json:"is_active"`        // Indica si el cupón puede canjearse
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// NormalizeCouponCode normaliza un código promocional para compararlo sin distinguir mayúsculas
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate valida que la entidad Coupon tenga todos los campos requeridos
+func (c *Coupon) Validate() error {
+	if c.Code == "" {
+		return NewValidationError("code", "el código del cupón es obligatorio")
+	}
+
+	switch c.DiscountType {
+	case CouponDiscountPercent:
+		if c.PercentOff <= 0 || c.PercentOff > 100 {
+			return NewValidationError("percent_off", "el porcentaje de descuento debe estar entre 0 y 100")
+		}
+	case CouponDiscountAmount:
+		if c.AmountOff <= 0 {
+			return NewValidationError("amount_off", "el monto de descuento debe ser positivo")
+		}
+		if c.CurrencyID == nil || *c.CurrencyID == "" {
+			return NewValidationError("currency_id", "la moneda es obligatoria para descuentos de monto fijo")
+		}
+	default:
+		return NewValidationError("discount_type", "el tipo de descuento debe ser percent o amount")
+	}
+
+	switch c.Duration {
+	case CouponDurationOnce, CouponDurationForever:
+	case CouponDurationRepeating:
+		if c.DurationPeriods <= 0 {
+			return NewValidationError("duration_periods", "la cantidad de períodos es obligatoria para cupones repetitivos")
+		}
+	default:
+		return NewValidationError("duration", "la duración debe ser once, repeating o forever")
+	}
+
+	if c.MaxRedemptions != nil && *c.MaxRedemptions <= 0 {
+		return NewValidationError("max_redemptions", "el límite de canjes debe ser positivo")
+	}
+
+	return nil
+}
+
+// CheckRedeemable verifica que el cupón pueda canjearse para el plan indicado
+func (c *Coupon) CheckRedeemable(plan *Plan, now time.Time) error {
+	if !c.IsActive {
+		return ErrCouponNotRedeemable
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return fmt.Errorf("%w: el cupón expiró", ErrCouponNotRedeemable)
+	}
+	if c.MaxRedemptions != nil && c.TimesRedeemed >= *c.MaxRedemptions {
+		return fmt.Errorf("%w: el cupón alcanzó su límite de canjes", ErrCouponNotRedeemable)
+	}
+	if !c.AppliesTo(plan) {
+		return fmt.Errorf("%w: el cupón no aplica a este plan", ErrCouponNotRedeemable)
+	}
+	return nil
+}
+
+// AppliesTo indica si el cupón puede descontar el precio del plan. PlanIDs guarda familias de
+// planes, así que el cupón sigue aplicando a las nuevas versiones de un plan modificado.
+func (c *Coupon) AppliesTo(plan *Plan) bool {
+	if c.DiscountType == CouponDiscountAmount && (c.CurrencyID == nil || *c.CurrencyID != plan.CurrencyID) {
+		return false
+	}
+	if len(c.PlanIDs) == 0 {
+		return true
+	}
+	for _, id := range c.PlanIDs {
+		if id == plan.ID || id == plan.FamilyID {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount calcula el descuento sobre un monto; nunca supera el monto
+func (c *Coupon) Discount(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+
+	discount := c.AmountOff
+	if c.DiscountType == CouponDiscountPercent {
+		discount = amount * c.PercentOff / 100
+	}
+	if discount > amount {
+		discount = amount
+	}
+	return RoundAmount(discount)
+}
+
+// CouponRedemption registra el canje de un cupón por un usuario en una suscripción
+type CouponRedemption struct {
+	ID             string    `json:"id"`
+	CouponID       string    `json:"coupon_id"`
+	UserID         string    `json:"user_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	RedeemedAt     time.Time `json:"redeemed_at"`
+}
+
+// CouponRequest representa la solicitud para crear o actualizar un cupón
+type CouponRequest struct {
+	Code            string             `json:"code" binding:"required"`
+	Description     string             `json:"description"`
+	DiscountType    CouponDiscountType `json:"discount_type" binding:"required"`
+	PercentOff      float64            `json:"percent_off"`
+	AmountOff       float64            `json:"amount_off"`
+	CurrencyID      *string            `json:"currency_id"`
+	Duration        CouponDuration     `json:"duration" binding:"required"`
+	DurationPeriods int                `json:"duration_periods"`
+	PlanIDs         []string           `json:"plan_ids"`
+	MaxRedemptions  *int               `json:"max_redemptions"`
+	ExpiresAt       *time.Time         `json:"expires_at"`
+	IsActive        bool               `json:"is_active"`
+}
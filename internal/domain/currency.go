@@ -1,9 +1,6 @@
 package domain
 
-import (
-	"errors"
-	"time"
-)
+import "time"
 
 // Currency representa una moneda en el sistema
 type Currency struct {
@@ -19,13 +16,13 @@ type Currency struct {
 // Validate valida que la entidad Currency tenga todos los campos requeridos
 func (c *Currency) Validate() error {
 	if c.Code == "" {
-		return errors.New("el código de la moneda es obligatorio")
+		return NewValidationError("code", "el código de la moneda es obligatorio")
 	}
 	if c.Name == "" {
-		return errors.New("el nombre de la moneda es obligatorio")
+		return NewValidationError("name", "el nombre de la moneda es obligatorio")
 	}
 	if c.Symbol == "" {
-		return errors.New("el símbolo de la moneda es obligatorio")
+		return NewValidationError("symbol", "el símbolo de la moneda es obligatorio")
 	}
 	return nil
 }
@@ -0,0 +1,129 @@
+package domain
+
+import "fmt"
+
+// Entitlement identifica un derecho de uso que un plan puede limitar
+type Entitlement string
+
+const (
+	// EntitlementMaxCategories limita la cantidad de categorías de un usuario
+	EntitlementMaxCategories Entitlement = "max_categories"
+	// EntitlementMaxTransactionsPerMonth limita las transacciones registradas por mes calendario
+	EntitlementMaxTransactionsPerMonth Entitlement = "max_transactions_per_month"
+	// EntitlementAttachments habilita adjuntar comprobantes a las transacciones
+	EntitlementAttachments Entitlement = "attachments"
+	// EntitlementExportFormats define los formatos en que se pueden exportar los datos
+	EntitlementExportFormats Entitlement = "export_formats"
+)
+
+// ExportFormat representa un formato de exportación de datos
+type ExportFormat string
+
+const (
+	// ExportFormatCSV exporta en valores separados por comas
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSON exporta en JSON
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// IsValid indica si el formato de exportación es soportado
+func (f ExportFormat) IsValid() bool {
+	return f == ExportFormatCSV || f == ExportFormatJSON
+}
+
+// Entitlements contiene los derechos de uso legibles por máquina de un plan.
+// Un límite nil significa ilimitado.
+type Entitlements struct {
+	MaxCategories           *int           `json:"max_categories"`             // Máximo de categorías (nil = ilimitado)
+	MaxTransactionsPerMonth *int           `json:"max_transactions_per_month"` // Máximo de transacciones por mes (nil = ilimitado)
+	Attachments             bool           `json:"attachments"`                // Permite adjuntar comprobantes
+	ExportFormats           []ExportFormat `json:"export_formats"`             // Formatos de exportación permitidos
+}
+
+// DefaultEntitlements devuelve los derechos de uso de un usuario sin suscripción activa
+func DefaultEntitlements() Entitlements {
+	maxCategories := 10
+	maxTransactions := 100
+	return Entitlements{
+		MaxCategories:           &maxCategories,
+		MaxTransactionsPerMonth: &maxTransactions,
+		Attachments:             false,
+		ExportFormats:           []ExportFormat{ExportFormatCSV},
+	}
+}
+
+// Validate valida que los límites y formatos sean coherentes
+func (e *Entitlements) Validate() error {
+	if e.MaxCategories != nil && *e.MaxCategories < 0 {
+		return NewValidationError("max_categories", "el máximo de categorías no puede ser negativo")
+	}
+	if e.MaxTransactionsPerMonth != nil && *e.MaxTransactionsPerMonth < 0 {
+		return NewValidationError("max_transactions_per_month", "el máximo de transacciones por mes no puede ser negativo")
+	}
+	for _, format := range e.ExportFormats {
+		if !format.IsValid() {
+			return NewValidationError("export_formats", fmt.Sprintf("formato de exportación no soportado: %s", format))
+		}
+	}
+	return nil
+}
+
+// Limit devuelve el límite numérico de un derecho de uso (nil = ilimitado)
+func (e *Entitlements) Limit(entitlement Entitlement) *int {
+	switch entitlement {
+	case EntitlementMaxCategories:
+		return e.MaxCategories
+	case EntitlementMaxTransactionsPerMonth:
+		return e.MaxTransactionsPerMonth
+	default:
+		return nil
+	}
+}
+
+// Allows indica si un derecho de uso booleano está habilitado
+func (e *Entitlements) Allows(entitlement Entitlement) bool {
+	switch entitlement {
+	case EntitlementAttachments:
+		return e.Attachments
+	default:
+		return false
+	}
+}
+
+// AllowsExport indica si el formato de exportación está permitido
+func (e *Entitlements) AllowsExport(format ExportFormat) bool {
+	for _, allowed := range e.ExportFormats {
+		if allowed == format {
+			return true
+		}
+	}
+	return false
+}
+
+// UpgradeRequiredError indica que la operación supera los derechos de uso del plan actual
+type UpgradeRequiredError struct {
+	Entitlement Entitlement `json:"entitlement"`       // Derecho de uso que se superó
+	Limit       *int        `json:"limit,omitempty"`   // Límite del plan, si es numérico
+	Current     *int        `json:"current,omitempty"` // Uso actual, si es numérico
+	PlanID      string      `json:"plan_id,omitempty"` // Plan actual (vacío = sin suscripción)
+}
+
+// Error implementa la interfaz error
+func (e *UpgradeRequiredError) Error() string {
+	if e.Limit != nil {
+		return fmt.Sprintf("%s: se alcanzó el límite de %s (%d)", ErrUpgradeRequired, e.Entitlement, *e.Limit)
+	}
+	return fmt.Sprintf("%s: %s no está incluido en el plan", ErrUpgradeRequired, e.Entitlement)
+}
+
+// Unwrap permite comparar con errors.Is(err, ErrUpgradeRequired)
+func (e *UpgradeRequiredError) Unwrap() error {
+	return ErrUpgradeRequired
+}
+
+// UserEntitlements representa los derechos de uso vigentes de un usuario
+type UserEntitlements struct {
+	UserID       string       `json:"user_id"`
+	PlanID       string       `json:"plan_id,omitempty"` // Vacío si el usuario no tiene suscripción activa
+	Entitlements Entitlements `json:"entitlements"`
+}
@@ -1,18 +1,113 @@
 package domain
 
-import "errors"
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorKind clasifica un error de dominio. La capa HTTP traduce cada tipo a un código de estado;
+// un error sin tipo se considera interno.
+//
+// ErrorKind implementa error para poder comparar por tipo: errors.Is(err, domain.KindNotFound).
+type ErrorKind string
+
+const (
+	KindNotFound      ErrorKind = "not_found"      // El recurso no existe o no pertenece al usuario
+	KindConflict      ErrorKind = "conflict"       // El estado actual del recurso impide la operación
+	KindValidation    ErrorKind = "validation"     // Los datos de entrada no son válidos
+	KindUnauthorized  ErrorKind = "unauthorized"   // Faltan credenciales o no son válidas
+	KindForbidden     ErrorKind = "forbidden"      // El usuario no puede operar sobre el recurso
+	KindPlanLimit     ErrorKind = "plan_limit"     // La operación supera los derechos de uso del plan
+	KindPaymentFailed ErrorKind = "payment_failed" // El proveedor de pagos rechazó el cobro
+)
+
+// Error implementa la interfaz error
+func (k ErrorKind) Error() string {
+	return string(k)
+}
+
+// FieldError describe un campo no válido de un error de validación
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error es un error de dominio tipado. Code es un identificador estable pensado para los clientes
+// (transaction_not_found, validation_failed); Message es el detalle legible.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError // Solo en errores de validación
+}
+
+// Error implementa la interfaz error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is permite comparar por tipo con errors.Is(err, domain.KindNotFound)
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(ErrorKind)
+	return ok && kind == e.Kind
+}
+
+// NewNotFoundError crea un error de recurso inexistente con código <resource>_not_found
+func NewNotFoundError(resource, format string, args ...any) *Error {
+	return &Error{Kind: KindNotFound, Code: resource + "_not_found", Message: fmt.Sprintf(format, args...)}
+}
+
+// NewConflictError crea un error de conflicto con el estado actual del recurso
+func NewConflictError(code, format string, args ...any) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewUnauthorizedError crea un error de credenciales ausentes o no válidas
+func NewUnauthorizedError(code, format string, args ...any) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewForbiddenError crea un error de operación no permitida para el usuario
+func NewForbiddenError(code, format string, args ...any) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewValidationError crea un error de validación de un campo. field puede estar vacío si el error
+// no corresponde a un campo concreto.
+func NewValidationError(field, message string) *Error {
+	err := &Error{Kind: KindValidation, Code: "validation_failed", Message: message}
+	if field != "" {
+		err.Fields = []FieldError{{Field: field, Message: message}}
+	}
+	return err
+}
+
+// NewValidationErrors agrupa varios campos no válidos en un solo error de validación
+func NewValidationErrors(fields ...FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: strings.Join(messages, "; "), Fields: fields}
+}
 
 // Errores comunes para entidades
 var (
-	ErrEmptyID             = errors.New("el ID no puede estar vacío")
-	ErrEmptyName           = errors.New("el nombre no puede estar vacío")
-	ErrEmptyUserID         = errors.New("el ID de usuario no puede estar vacío")
-	ErrEmptyEmail          = errors.New("el email no puede estar vacío")
-	ErrInvalidEmail        = errors.New("el email no es válido")
-	ErrEmptyPassword       = errors.New("la contraseña no puede estar vacía")
-	ErrPasswordTooShort    = errors.New("la contraseña debe tener al menos 6 caracteres")
-	ErrInvalidAmount       = errors.New("el monto debe ser mayor que cero")
-	ErrEmptyCategoryID     = errors.New("el ID de categoría no puede estar vacío")
-	ErrEmptyCategoryType   = errors.New("el tipo de categoría no puede estar vacío")
-	ErrInvalidCategoryType = errors.New("tipo de categoría inválido")
+	ErrEmptyID             = NewValidationError("id", "el ID no puede estar vacío")
+	ErrEmptyName           = NewValidationError("name", "el nombre no puede estar vacío")
+	ErrEmptyUserID         = NewValidationError("user_id", "el ID de usuario no puede estar vacío")
+	ErrEmptyEmail          = NewValidationError("email", "el email no puede estar vacío")
+	ErrInvalidEmail        = NewValidationError("email", "el email no es válido")
+	ErrEmptyPassword       = NewValidationError("password", "la contraseña no puede estar vacía")
+	ErrPasswordTooShort    = NewValidationError("password", "la contraseña debe tener al menos 6 caracteres")
+	ErrInvalidAmount       = NewValidationError("amount", "el monto debe ser mayor que cero")
+	ErrEmptyCategoryID     = NewValidationError("category_id", "el ID de categoría no puede estar vacío")
+	ErrEmptyCategoryType   = NewValidationError("type", "el tipo de categoría no puede estar vacío")
+	ErrInvalidCategoryType = NewValidationError("type", "tipo de categoría inválido")
+	ErrUnauthenticated     = NewUnauthorizedError("unauthorized", "usuario no autenticado")
+	ErrPaymentFailed       = &Error{Kind: KindPaymentFailed, Code: "payment_failed", Message: "el pago no pudo ser procesado"}
+	ErrCouponNotRedeemable = &Error{Kind: KindValidation, Code: "coupon_not_redeemable", Message: "el cupón no es válido"}
+	ErrUpgradeRequired     = &Error{Kind: KindPlanLimit, Code: "upgrade_required", Message: "se requiere mejorar el plan"}
+	ErrResourceInUse       = NewConflictError("resource_in_use", "el recurso está en uso")
+	ErrPlanArchived        = NewConflictError("plan_archived", "el plan está archivado")
 )
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// InvoiceType define la operación que originó una factura
+type InvoiceType string
+
+const (
+	// InvoiceTypeCharge representa el cobro de un período de suscripción
+	InvoiceTypeCharge InvoiceType = "charge"
+	// InvoiceTypeProration representa el cobro o crédito proporcional de un cambio de plan
+	InvoiceTypeProration InvoiceType = "proration"
+	// InvoiceTypeRefund representa la devolución de un cobro (nota de crédito)
+	InvoiceTypeRefund InvoiceType = "refund"
+)
+
+// InvoiceStatus define el estado de una factura. Una factura no cambia de estado:
+// cada cobro, reembolso o intento fallido genera su propia factura.
+type InvoiceStatus string
+
+const (
+	// InvoiceStatusPaid representa una factura cobrada
+	InvoiceStatusPaid InvoiceStatus = "paid"
+	// InvoiceStatusFailed representa un intento de cobro rechazado
+	InvoiceStatusFailed InvoiceStatus = "failed"
+	// InvoiceStatusRefunded representa una devolución emitida
+	InvoiceStatusRefunded InvoiceStatus = "refunded"
+)
+
+// InvoiceLineItem representa una línea de una factura
+type InvoiceLineItem struct {
+	Description string     `json:"description"`            // Descripción del concepto
+	Quantity    int        `json:"quantity"`               // Cantidad
+	UnitAmount  float64    `json:"unit_amount"`            // Precio unitario (impuestos incluidos)
+	Amount      float64    `json:"amount"`                 // Importe de la línea (impuestos incluidos)
+	PeriodStart *time.Time `json:"period_start,omitempty"` // Inicio del período facturado
+	PeriodEnd   *time.Time `json:"period_end,omitempty"`   // Fin del período facturado
+	PlanID      string     `json:"plan_id,omitempty"`      // Plan que factura la línea, si corresponde a uno
+}
+
+// InvoicePlanSnapshot guarda los datos del plan al momento de facturar,
+// para que la factura no cambie si el plan se modifica después
+type InvoicePlanSnapshot struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	Price         float64      `json:"price"`
+	CurrencyID    string       `json:"currency_id"`
+	Interval      PlanInterval `json:"interval"`
+	IntervalCount int          `json:"interval_count,omitempty"`
+}
+
+// DescribeInterval devuelve el intervalo de facturación del plan facturado en lenguaje natural
+func (s InvoicePlanSnapshot) DescribeInterval() string {
+	plan := Plan{Interval: s.Interval, IntervalCount: s.IntervalCount}
+	return plan.DescribeInterval()
+}
+
+// Invoice representa una factura inmutable de una suscripción
+type Invoice struct {
+	ID             string              `json:"id"`
+	Number         string              `json:"number"`          // Número de factura visible
+	Sequence       int64               `json:"sequence"`        // Número secuencial dentro de la cuenta del usuario
+	UserID         string              `json:"user_id"`         // ID del usuario facturado
+	SubscriptionID string              `json:"subscription_id"` // ID de la suscripción
+	Type           InvoiceType         `json:"type"`            // Operación que originó la factura
+	Status         InvoiceStatus       `json:"status"`          // Estado de la factura
+	Plan           InvoicePlanSnapshot `json:"plan"`            // Plan al momento de facturar
+	LineItems      []InvoiceLineItem   `json:"line_items"`      // Líneas de la factura
+	Currency       string              `json:"currency"`        // Código ISO de la moneda
+	Subtotal       float64             `json:"subtotal"`        // Importe sin impuestos
+	TaxRate        float64             `json:"tax_rate"`        // Tasa de impuesto aplicada (0.15 = 15%)
+	Tax            float64             `json:"tax"`             // Importe del impuesto
+	Total          float64             `json:"total"`           // Importe total (negativo en devoluciones)
+	ChargeID       string              `json:"charge_id"`       // ID del cobro en la pasarela de pago
+	FailureReason  string              `json:"failure_reason,omitempty"`
+	IssuedAt       time.Time           `json:"issued_at"` // Fecha de emisión
+}
+
+// PendingInvoice es una factura de un cobro ya hecho en la pasarela que no se pudo guardar.
+// Se vuelve a emitir con el mismo ID y fecha de emisión; el número se asigna al emitirla.
+type PendingInvoice struct {
+	Invoice   *Invoice  // Factura con impuestos aplicados, sin número
+	Attempts  int       // Intentos de emisión fallidos
+	LastError string    // Motivo del último intento fallido
+	CreatedAt time.Time // Fecha en que quedó pendiente
+}
+
+// Validate valida que la entidad Invoice tenga todos los campos requeridos
+func (i *Invoice) Validate() error {
+	if i.UserID == "" {
+		return ErrEmptyUserID
+	}
+	if i.SubscriptionID == "" {
+		return NewValidationError("subscription_id", "el ID de la suscripción es obligatorio")
+	}
+	if i.Type != InvoiceTypeCharge && i.Type != InvoiceTypeProration && i.Type != InvoiceTypeRefund {
+		return NewValidationError("type", "tipo de factura no válido")
+	}
+	if i.Status != InvoiceStatusPaid && i.Status != InvoiceStatusFailed && i.Status != InvoiceStatusRefunded {
+		return NewValidationError("status", "estado de factura no válido")
+	}
+	if i.Currency == "" {
+		return NewValidationError("currency", "la moneda de la factura es obligatoria")
+	}
+	if len(i.LineItems) == 0 {
+		return NewValidationError("line_items", "la factura debe tener al menos una línea")
+	}
+	return nil
+}
+
+// ApplyTax calcula el total a partir de las líneas y desglosa el impuesto.
+// Los precios de los planes incluyen impuestos, por lo que el total coincide con lo cobrado.
+func (i *Invoice) ApplyTax(rate float64) {
+	total := 0.0
+	for _, item := range i.LineItems {
+		total += item.Amount
+	}
+
+	i.TaxRate = rate
+	i.Total = RoundAmount(total)
+	i.Subtotal = RoundAmount(total / (1 + rate))
+	i.Tax = RoundAmount(i.Total - i.Subtotal)
+}
+
+// FormatInvoiceNumber genera el número visible de una factura a partir de su secuencia
+func FormatInvoiceNumber(sequence int64) string {
+	return fmt.Sprintf("INV-%06d", sequence)
+}
+
+// RoundAmount redondea un importe a dos decimales
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
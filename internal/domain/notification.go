@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NotificationEvent define los eventos que pueden generar una notificación
+type NotificationEvent string
+
+const (
+	// NotificationEventBudgetExceeded se emite cuando los gastos del mes superan el presupuesto de una categoría
+	NotificationEventBudgetExceeded NotificationEvent = "budget_exceeded"
+	// NotificationEventSubscriptionExpiring se emite cuando una suscripción está por expirar
+	NotificationEventSubscriptionExpiring NotificationEvent = "subscription_expiring"
+	// NotificationEventPaymentFailed se emite cuando falla el cobro de una suscripción
+	NotificationEventPaymentFailed NotificationEvent = "payment_failed"
+	// NotificationEventPasswordChanged se emite cuando el usuario cambia su contraseña
+	NotificationEventPasswordChanged NotificationEvent = "password_changed"
+	// NotificationEventTrialEnded se emite cuando termina una prueba gratuita sin método de pago
+	NotificationEventTrialEnded NotificationEvent = "trial_ended"
+	// NotificationEventPlanMigrationScheduled se emite cuando se programa la migración a una nueva versión del plan
+	NotificationEventPlanMigrationScheduled NotificationEvent = "plan_migration_scheduled"
+)
+
+// NotificationEvents enumera todos los eventos soportados
+var NotificationEvents = []NotificationEvent{
+	NotificationEventBudgetExceeded,
+	NotificationEventSubscriptionExpiring,
+	NotificationEventPaymentFailed,
+	NotificationEventPasswordChanged,
+	NotificationEventTrialEnded,
+	NotificationEventPlanMigrationScheduled,
+}
+
+// IsValid verifica si el evento es uno de los soportados
+func (e NotificationEvent) IsValid() bool {
+	for _, event := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel define el canal por el que se envía una notificación
+type NotificationChannel string
+
+const (
+	// NotificationChannelEmail envía la notificación por correo electrónico
+	NotificationChannelEmail NotificationChannel = "email"
+	// NotificationChannelWebhook envía la notificación a un webhook firmado
+	NotificationChannelWebhook NotificationChannel = "webhook"
+	// NotificationChannelInApp guarda la notificación en la bandeja de entrada del usuario
+	NotificationChannelInApp NotificationChannel = "in_app"
+)
+
+// IsValid verifica si el canal es uno de los soportados
+func (c NotificationChannel) IsValid() bool {
+	return c == NotificationChannelEmail || c == NotificationChannelWebhook || c == NotificationChannelInApp
+}
+
+// NotificationMessage representa un mensaje ya renderizado listo para enviarse por un canal
+type NotificationMessage struct {
+	UserID    string            `json:"user_id"`    // ID del usuario destinatario
+	Recipient string            `json:"recipient"`  // Email, URL del webhook o ID del usuario según el canal
+	Event     NotificationEvent `json:"event"`      // Evento que originó el mensaje
+	Subject   string            `json:"subject"`    // Asunto del mensaje
+	Body      string            `json:"body"`       // Cuerpo del mensaje
+	Data      map[string]string `json:"data"`       // Datos del evento
+	CreatedAt time.Time         `json:"created_at"` // Fecha de generación
+}
+
+// Notification representa una notificación guardada en la bandeja de entrada del usuario
+type Notification struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`    // ID del usuario
+	Event     NotificationEvent `json:"event"`      // Evento que originó la notificación
+	Subject   string            `json:"subject"`    // Asunto
+	Body      string            `json:"body"`       // Cuerpo
+	Data      map[string]string `json:"data"`       // Datos del evento
+	ReadAt    *time.Time        `json:"read_at"`    // Fecha de lectura (nil si no se ha leído)
+	CreatedAt time.Time         `json:"created_at"` // Fecha de creación
+}
+
+// IsRead verifica si la notificación ya fue leída
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference representa la preferencia de un usuario para un evento y canal
+type NotificationPreference struct {
+	ID        string              `json:"id"`
+	UserID    string              `json:"user_id"`    // ID del usuario
+	Event     NotificationEvent   `json:"event"`      // Evento
+	Channel   NotificationChannel `json:"channel"`    // Canal
+	Enabled   bool                `json:"enabled"`    // Indica si el canal está habilitado para el evento
+	Target    string              `json:"target"`     // Destino opcional (URL del webhook o email alternativo)
+	CreatedAt time.Time           `json:"created_at"` // Fecha de creación
+	UpdatedAt time.Time           `json:"updated_at"` // Fecha de actualización
+}
+
+// Validate valida que la preferencia tenga todos los campos requeridos
+func (p *NotificationPreference) Validate() error {
+	if p.UserID == "" {
+		return ErrEmptyUserID
+	}
+	if !p.Event.IsValid() {
+		return NewValidationError("event", "el evento de notificación no es válido")
+	}
+	if !p.Channel.IsValid() {
+		return NewValidationError("channel", "el canal de notificación no es válido")
+	}
+	if p.Channel == NotificationChannelWebhook && p.Enabled && p.Target == "" {
+		return NewValidationError("target", "la URL del webhook es obligatoria")
+	}
+	if p.Target == "" {
+		return nil
+	}
+	switch p.Channel {
+	case NotificationChannelWebhook:
+		return ValidateWebhookURL(p.Target)
+	case NotificationChannelEmail:
+		if address, err := mail.ParseAddress(p.Target); err != nil || address.Address != p.Target {
+			return NewValidationError("target", ErrInvalidEmail.Message)
+		}
+	}
+	return nil
+}
+
+// nonPublicNetworks son los rangos reservados que net.IP no clasifica como privados
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// IsPublicIP indica si ip es una dirección enrutable en Internet: rechaza loopback, redes privadas,
+// link-local (como el servicio de metadatos 169.254.169.254), multicast y rangos reservados
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhookURL valida que la URL de un webhook sea https absoluta y no apunte a la propia
+// máquina ni a la red interna. Los nombres de host se comprueban de nuevo al conectar, con la
+// dirección resuelta.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Hostname() == "" {
+		return NewValidationError("target", "la URL del webhook debe ser absoluta")
+	}
+	if u.Scheme != "https" {
+		return NewValidationError("target", "la URL del webhook debe usar https")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return NewValidationError("target", "la URL del webhook no puede apuntar a una dirección interna")
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return NewValidationError("target", "la URL del webhook no puede apuntar a una dirección interna")
+	}
+	return nil
+}
+
+// NotificationTemplate representa la plantilla de un evento para un canal
+type NotificationTemplate struct {
+	ID        string              `json:"id"`
+	Event     NotificationEvent   `json:"event"`      // Evento
+	Channel   NotificationChannel `json:"channel"`    // Canal
+	Subject   string              `json:"subject"`    // Asunto (text/template)
+	Body      string              `json:"body"`       // Cuerpo (text/template)
+	CreatedAt time.Time           `json:"created_at"` // Fecha de creación
+	UpdatedAt time.Time           `json:"updated_at"` // Fecha de actualización
+}
+
+// Validate valida que la plantilla tenga todos los campos requeridos
+func (t *NotificationTemplate) Validate() error {
+	if !t.Event.IsValid() {
+		return NewValidationError("event", "el evento de notificación no es válido")
+	}
+	if !t.Channel.IsValid() {
+		return NewValidationError("channel", "el canal de notificación no es válido")
+	}
+	if t.Body == "" {
+		return NewValidationError("body", "el cuerpo de la plantilla es obligatorio")
+	}
+	return nil
+}
+
+// UpdateNotificationPreferenceRequest representa la solicitud para actualizar una preferencia
+type UpdateNotificationPreferenceRequest struct {
+	Event   string `json:"event" binding:"required"`
+	Channel string `json:"channel" binding:"required"`
+	Enabled bool   `json:"enabled"`
+	Target  string `json:"target"`
+}
+
+// UpdateNotificationTemplateRequest representa la solicitud para actualizar una plantilla
+type UpdateNotificationTemplateRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body" binding:"required"`
+}
@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// ChargeStatus define el estado de un cobro en la pasarela de pago
+type ChargeStatus string
+
+const (
+	// ChargeStatusSucceeded representa un cobro aprobado
+	ChargeStatusSucceeded ChargeStatus = "succeeded"
+	// ChargeStatusFailed representa un cobro rechazado
+	ChargeStatusFailed ChargeStatus = "failed"
+)
+
+// CardDetails representa los datos de una tarjeta que se envían a la pasarela para tokenizarla.
+// Nunca se guardan en la base de datos.
+type CardDetails struct {
+	Number   string `json:"number" binding:"required"`    // Número de la tarjeta
+	ExpMonth int    `json:"exp_month" binding:"required"` // Mes de expiración (1-12)
+	ExpYear  int    `json:"exp_year" binding:"required"`  // Año de expiración
+	CVC      string `json:"cvc" binding:"required"`       // Código de seguridad
+}
+
+// Validate valida que los datos de la tarjeta tengan un formato correcto
+func (c *CardDetails) Validate() error {
+	if len(c.Number) < 12 || len(c.Number) > 19 {
+		return NewValidationError("number", "el número de tarjeta no es válido")
+	}
+	for _, digit := range c.Number {
+		if digit < '0' || digit > '9' {
+			return NewValidationError("number", "el número de tarjeta solo puede contener dígitos")
+		}
+	}
+	if c.ExpMonth < 1 || c.ExpMonth > 12 {
+		return NewValidationError("exp_month", "el mes de expiración no es válido")
+	}
+	if c.ExpYear < 2000 {
+		return NewValidationError("exp_year", "el año de expiración no es válido")
+	}
+	if len(c.CVC) < 3 || len(c.CVC) > 4 {
+		return NewValidationError("cvc", "el código de seguridad no es válido")
+	}
+	return nil
+}
+
+// CardToken representa una tarjeta tokenizada por la pasarela de pago
+type CardToken struct {
+	Token string `json:"token"` // Token reutilizable de la pasarela
+	Brand string `json:"brand"` // Marca de la tarjeta (visa, mastercard, etc.)
+	Last4 string `json:"last4"` // Últimos cuatro dígitos
+}
+
+// ChargeRequest representa un cobro a realizar en la pasarela de pago
+type ChargeRequest struct {
+	Token          string            // Token de la tarjeta a cobrar
+	Amount         float64           // Monto a cobrar
+	Currency       string            // Código ISO de la moneda
+	Description    string            // Descripción del cobro
+	IdempotencyKey string            // Clave para no cobrar dos veces la misma operación
+	Metadata       map[string]string // Metadatos adicionales
+}
+
+// Charge representa el resultado de un cobro en la pasarela de pago
+type Charge struct {
+	ID             string       `json:"id"`
+	Status         ChargeStatus `json:"status"`
+	Amount         float64      `json:"amount"`
+	Currency       string       `json:"currency"`
+	FailureCode    string       `json:"failure_code,omitempty"`
+	FailureMessage string       `json:"failure_message,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// Refund representa la devolución total o parcial de un cobro
+type Refund struct {
+	ID        string    `json:"id"`
+	ChargeID  string    `json:"charge_id"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -11,10 +11,18 @@ type PaymentMethod struct {
 	Description string    `json:"description"`
 	IsActive    bool      `json:"is_active"`
 	UserID      string    `json:"user_id"`
+	CardBrand   string    `json:"card_brand,omitempty"` // Marca de la tarjeta asociada
+	CardLast4   string    `json:"card_last4,omitempty"` // Últimos dígitos de la tarjeta asociada
+	CardToken   string    `json:"-"`                    // Token de la tarjeta en la pasarela de pago
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// HasCard indica si el método de pago tiene una tarjeta tokenizada para cobros
+func (p *PaymentMethod) HasCard() bool {
+	return p.CardToken != ""
+}
+
 // Validate valida que los campos obligatorios estén presentes
 func (p *PaymentMethod) Validate() error {
 	if p.Name == "" {
@@ -45,6 +53,8 @@ type PaymentMethodResponse struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	IsActive    bool      `json:"is_active"`
+	CardBrand   string    `json:"card_brand,omitempty"`
+	CardLast4   string    `json:"card_last4,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -1,20 +1,71 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
 	"time"
 )
 
-// PlanInterval define el intervalo de facturación de un plan
+// PlanInterval define la unidad del intervalo de facturación de un plan.
+// El período de facturación dura IntervalCount unidades.
 type PlanInterval string
 
 const (
-	// PlanIntervalMonthly representa un intervalo mensual
-	PlanIntervalMonthly PlanInterval = "monthly"
-	// PlanIntervalYearly representa un intervalo anual
-	PlanIntervalYearly PlanInterval = "yearly"
+	// PlanIntervalDay representa un intervalo en días
+	PlanIntervalDay PlanInterval = "day"
+	// PlanIntervalWeek representa un intervalo en semanas
+	PlanIntervalWeek PlanInterval = "week"
+	// PlanIntervalMonth representa un intervalo en meses
+	PlanIntervalMonth PlanInterval = "month"
+	// PlanIntervalYear representa un intervalo en años
+	PlanIntervalYear PlanInterval = "year"
+	// PlanIntervalLifetime representa un pago único sin renovaciones
+	PlanIntervalLifetime PlanInterval = "lifetime"
 )
 
+// IsValid indica si la unidad de intervalo es soportada
+func (i PlanInterval) IsValid() bool {
+	switch i {
+	case PlanIntervalDay, PlanIntervalWeek, PlanIntervalMonth, PlanIntervalYear, PlanIntervalLifetime:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParsePlanInterval convierte el intervalo de una solicitud en unidad y cantidad.
+// Acepta las unidades (day, week, month, year, lifetime) y los alias weekly, monthly,
+// quarterly y yearly; una cantidad mayor a uno multiplica la del alias.
+func ParsePlanInterval(value string, count int) (PlanInterval, int, error) {
+	if count < 0 {
+		return "", 0, NewValidationError("interval_count", "la cantidad del intervalo no puede ser negativa")
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	unit, base := PlanInterval(value), 1
+	switch value {
+	case "daily":
+		unit = PlanIntervalDay
+	case "weekly":
+		unit = PlanIntervalWeek
+	case "monthly":
+		unit = PlanIntervalMonth
+	case "quarterly":
+		unit, base = PlanIntervalMonth, 3
+	case "yearly", "annual":
+		unit = PlanIntervalYear
+	}
+
+	if !unit.IsValid() {
+		return "", 0, NewValidationError("interval", fmt.Sprintf("intervalo de facturación no válido: %s", value))
+	}
+	if unit == PlanIntervalLifetime {
+		return unit, 1, nil
+	}
+	return unit, base * count, nil
+}
+
 // PlanFeature representa una característica disponible en un plan
 type PlanFeature struct {
 	Name        string `json:"name"`        // Nombre de la característica
@@ -23,45 +74,89 @@ type PlanFeature struct {
 	Included    bool   `json:"included"`    // Indica si la característica está incluida en el plan
 }
 
-// Plan representa un plan de suscripción
+// Plan representa una versión de un plan de suscripción. Las versiones son inmutables:
+// modificar un plan crea una nueva versión con su propio ID y las suscripciones
+// conservan la versión que contrataron hasta que se las migre.
 type Plan struct {
-	ID          string        `json:"id"`
-	Name        string        `json:"name"`        // Nombre del plan (e.g. "Gratis", "Pro")
-	Description string        `json:"description"` // Descripción del plan
-	Price       float64       `json:"price"`       // Precio del plan
-	CurrencyID  string        `json:"currency_id"` // ID de la moneda
-	Interval    PlanInterval  `json:"interval"`    // Intervalo de facturación
-	Features    []PlanFeature `json:"features"`    // Características del plan
-	IsActive    bool          `json:"is_active"`   // Indica si el plan está activo
-	IsPublic    bool          `json:"is_public"`   // Indica si el plan es visible públicamente
-	SortOrder   int           `json:"sort_order"`  // Orden de visualización
-	CreatedAt   time.Time     `json:"created_at"`  // Fecha de creación
-	UpdatedAt   time.Time     `json:"updated_at"`  // Fecha de actualización
+	ID            string        `json:"id"`
+	FamilyID      string        `json:"family_id"`               // ID común a todas las versiones del plan
+	Version       int           `json:"version"`                 // Número de versión dentro de la familia
+	SupersededAt  *time.Time    `json:"superseded_at,omitempty"` // Fecha en que una versión más nueva la reemplazó
+	ArchivedAt    *time.Time    `json:"archived_at,omitempty"`   // Fecha en que se archivó; no admite nuevas suscripciones
+	Name          string        `json:"name"`                    // Nombre del plan (e.g. "Gratis", "Pro")
+	Description   string        `json:"description"`             // Descripción del plan
+	Price         float64       `json:"price"`                   // Precio del plan
+	CurrencyID    string        `json:"currency_id"`             // ID de la moneda
+	Interval      PlanInterval  `json:"interval"`                // Unidad del intervalo de facturación
+	IntervalCount int           `json:"interval_count"`          // Cantidad de unidades por período (1 para lifetime)
+	Features      []PlanFeature `json:"features"`                // Características del plan
+	TrialDays     int           `json:"trial_days"`              // Días de prueba gratuita para nuevas suscripciones (0 = sin prueba)
+	Entitlements  Entitlements  `json:"entitlements"`            // Derechos de uso que el plan otorga
+	IsActive      bool          `json:"is_active"`               // Indica si el plan está activo
+	IsPublic      bool          `json:"is_public"`               // Indica si el plan es visible públicamente
+	SortOrder     int           `json:"sort_order"`              // Orden de visualización
+	CreatedAt     time.Time     `json:"created_at"`              // Fecha de creación
+	UpdatedAt     time.Time     `json:"updated_at"`              // Fecha de actualización
 }
 
 // Validate valida que la entidad Plan tenga todos los campos requeridos
 func (p *Plan) Validate() error {
 	if p.Name == "" {
-		return errors.New("el nombre del plan es obligatorio")
+		return NewValidationError("name", "el nombre del plan es obligatorio")
 	}
 	if p.Description == "" {
-		return errors.New("la descripción del plan es obligatoria")
+		return NewValidationError("description", "la descripción del plan es obligatoria")
 	}
 	if p.Price < 0 {
-		return errors.New("el precio no puede ser negativo")
+		return NewValidationError("price", "el precio no puede ser negativo")
 	}
 	if p.CurrencyID == "" {
-		return errors.New("la moneda es obligatoria")
+		return NewValidationError("currency_id", "la moneda es obligatoria")
+	}
+	if p.TrialDays < 0 {
+		return NewValidationError("trial_days", "los días de prueba no pueden ser negativos")
+	}
+	if err := p.Entitlements.Validate(); err != nil {
+		return err
 	}
 	if p.Interval == "" {
-		return errors.New("el intervalo de facturación es obligatorio")
+		return NewValidationError("interval", "el intervalo de facturación es obligatorio")
+	}
+	if !p.Interval.IsValid() {
+		return NewValidationError("interval", fmt.Sprintf("intervalo de facturación no válido: %s", p.Interval))
 	}
-	if p.Interval != PlanIntervalMonthly && p.Interval != PlanIntervalYearly {
-		return errors.New("el intervalo de facturación debe ser mensual o anual")
+	if p.IntervalCount < 1 {
+		return NewValidationError("interval_count", "la cantidad del intervalo debe ser al menos 1")
 	}
 	return nil
 }
 
+// IsLatestVersion indica si es la versión vigente del plan, la única que admite nuevas suscripciones
+func (p *Plan) IsLatestVersion() bool {
+	return p.SupersededAt == nil
+}
+
+// IsArchived indica si el plan fue archivado. Sus suscriptores lo conservan pero nadie más puede contratarlo.
+func (p *Plan) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// NextVersion crea una copia del plan como su siguiente versión, sin ID ni fechas
+func (p *Plan) NextVersion() *Plan {
+	next := *p
+	next.ID = ""
+	next.Version = p.Version + 1
+	next.SupersededAt = nil
+	next.CreatedAt = time.Time{}
+	next.UpdatedAt = time.Time{}
+	return &next
+}
+
+// HasTrial indica si el plan ofrece un período de prueba gratuito
+func (p *Plan) HasTrial() bool {
+	return p.TrialDays > 0
+}
+
 // PlanFeatureRequest representa una característica en la solicitud
 type PlanFeatureRequest struct {
 	Name        string `json:"name"`
@@ -72,28 +167,39 @@ type PlanFeatureRequest struct {
 
 // CreatePlanRequest representa la solicitud para crear un nuevo plan
 type CreatePlanRequest struct {
-	Name        string               `json:"name" binding:"required"`
-	Description string               `json:"description" binding:"required"`
-	Price       float64              `json:"price"`
-	CurrencyID  string               `json:"currency_id" binding:"required"`
-	Interval    string               `json:"interval" binding:"required"`
-	Features    []PlanFeatureRequest `json:"features"`
-	IsActive    bool                 `json:"is_active"`
-	IsPublic    bool                 `json:"is_public"`
-	SortOrder   int                  `json:"sort_order"`
+	Name          string               `json:"name" binding:"required"`
+	Description   string               `json:"description" binding:"required"`
+	Price         float64              `json:"price"`
+	CurrencyID    string               `json:"currency_id" binding:"required"`
+	Interval      string               `json:"interval" binding:"required"`
+	IntervalCount int                  `json:"interval_count"`
+	Features      []PlanFeatureRequest `json:"features"`
+	TrialDays     int                  `json:"trial_days"`
+	Entitlements  Entitlements         `json:"entitlements"`
+	IsActive      bool                 `json:"is_active"`
+	IsPublic      bool                 `json:"is_public"`
+	SortOrder     int                  `json:"sort_order"`
 }
 
 // UpdatePlanRequest representa la solicitud para actualizar un plan existente
 type UpdatePlanRequest struct {
-	Name        string               `json:"name" binding:"required"`
-	Description string               `json:"description" binding:"required"`
-	Price       float64              `json:"price"`
-	CurrencyID  string               `json:"currency_id" binding:"required"`
-	Interval    string               `json:"interval" binding:"required"`
-	Features    []PlanFeatureRequest `json:"features"`
-	IsActive    bool                 `json:"is_active"`
-	IsPublic    bool                 `json:"is_public"`
-	SortOrder   int                  `json:"sort_order"`
+	Name          string               `json:"name" binding:"required"`
+	Description   string               `json:"description" binding:"required"`
+	Price         float64              `json:"price"`
+	CurrencyID    string               `json:"currency_id" binding:"required"`
+	Interval      string               `json:"interval" binding:"required"`
+	IntervalCount int                  `json:"interval_count"`
+	Features      []PlanFeatureRequest `json:"features"`
+	TrialDays     int                  `json:"trial_days"`
+	Entitlements  Entitlements         `json:"entitlements"`
+	IsActive      bool                 `json:"is_active"`
+	IsPublic      bool                 `json:"is_public"`
+	SortOrder     int                  `json:"sort_order"`
+}
+
+// UnarchivePlanRequest representa la solicitud para volver a ofrecer un plan archivado
+type UnarchivePlanRequest struct {
+	IsPublic *bool `json:"is_public" binding:"required"` // Si la versión vigente vuelve a ser visible públicamente
 }
 
 // PlanFeatureResponse representa una característica en la respuesta
@@ -106,16 +212,39 @@ type PlanFeatureResponse struct {
 
 // PlanResponse representa la respuesta con los datos de un plan
 type PlanResponse struct {
-	ID          string                `json:"id"`
-	Name        string                `json:"name"`
-	Description string                `json:"description"`
-	Price       float64               `json:"price"`
-	CurrencyID  string                `json:"currency_id"`
-	Interval    string                `json:"interval"`
-	Features    []PlanFeatureResponse `json:"features"`
-	IsActive    bool                  `json:"is_active"`
-	IsPublic    bool                  `json:"is_public"`
-	SortOrder   int                   `json:"sort_order"`
-	CreatedAt   string                `json:"created_at"`
-	UpdatedAt   string                `json:"updated_at"`
+	ID            string                `json:"id"`
+	FamilyID      string                `json:"family_id"`
+	Version       int                   `json:"version"`
+	SupersededAt  *string               `json:"superseded_at,omitempty"`
+	ArchivedAt    *string               `json:"archived_at,omitempty"`
+	Name          string                `json:"name"`
+	Description   string                `json:"description"`
+	Price         float64               `json:"price"`
+	CurrencyID    string                `json:"currency_id"`
+	Interval      string                `json:"interval"`
+	IntervalCount int                   `json:"interval_count"`
+	Features      []PlanFeatureResponse `json:"features"`
+	TrialDays     int                   `json:"trial_days"`
+	Entitlements  Entitlements          `json:"entitlements"`
+	IsActive      bool                  `json:"is_active"`
+	IsPublic      bool                  `json:"is_public"`
+	SortOrder     int                   `json:"sort_order"`
+	CreatedAt     string                `json:"created_at"`
+	UpdatedAt     string                `json:"updated_at"`
+}
+
+// PlanMigrationRequest representa la solicitud para migrar los suscriptores de una versión de plan a otra
+type PlanMigrationRequest struct {
+	FromPlanID string `json:"from_plan_id" binding:"required"` // Versión de la que se migra
+	ToPlanID   string `json:"to_plan_id"`                      // Versión destino (vacío = versión vigente de la familia)
+	NoticeDays int    `json:"notice_days"`                     // Días de aviso antes de aplicar el cambio
+}
+
+// PlanMigrationResult resume una migración de suscriptores entre versiones de un plan
+type PlanMigrationResult struct {
+	FromPlanID  string    `json:"from_plan_id"`
+	ToPlanID    string    `json:"to_plan_id"`
+	EffectiveAt time.Time `json:"effective_at"` // Desde cuándo se aplica: en la primera renovación posterior
+	Scheduled   int       `json:"scheduled"`    // Suscripciones con la migración programada
+	Skipped     int       `json:"skipped"`      // Suscripciones con otro cambio de plan pendiente
 }
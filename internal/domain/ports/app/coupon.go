@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// CouponRepository es la interfaz que define los métodos para el repositorio de cupones
+type CouponRepository interface {
+	// Create crea un nuevo cupón en la base de datos
+	Create(ctx context.Context, coupon *domain.Coupon) error
+
+	// GetByID obtiene un cupón por su ID
+	GetByID(ctx context.Context, id string) (*domain.Coupon, error)
+
+	// GetByCode obtiene un cupón por su código normalizado
+	GetByCode(ctx context.Context, code string) (*domain.Coupon, error)
+
+	// GetAll obtiene todos los cupones
+	GetAll(ctx context.Context) ([]*domain.Coupon, error)
+
+	// Update actualiza un cupón existente
+	Update(ctx context.Context, coupon *domain.Coupon) error
+
+	// Delete elimina un cupón por su ID
+	Delete(ctx context.Context, id string) error
+
+	// Redeem registra el canje de forma atómica respetando el límite de canjes, la expiración
+	// y un único canje por usuario. Devuelve domain.ErrCouponNotRedeemable si no es posible.
+	Redeem(ctx context.Context, redemption *domain.CouponRedemption) error
+
+	// ReleaseRedemption deshace el canje de una suscripción cuyo cobro no se completó
+	ReleaseRedemption(ctx context.Context, couponID, subscriptionID string) error
+}
@@ -26,6 +26,9 @@ type CurrencyRepository interface {
 	// Update actualiza una moneda existente
 	Update(ctx context.Context, currency *domain.Currency) error
 
+	// CountReferences cuenta los registros de cada tabla que referencian a la moneda
+	CountReferences(ctx context.Context, id string) (map[string]int, error)
+
 	// Delete elimina una moneda por su ID
 	Delete(ctx context.Context, id string) error
 }
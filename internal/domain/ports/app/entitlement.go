@@ -0,0 +1,20 @@
+package app
+
+import (
+	"context"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// EntitlementGuard verifica que una operación esté dentro de los derechos de uso del plan del usuario.
+// Las verificaciones devuelven *domain.UpgradeRequiredError cuando el plan no alcanza.
+type EntitlementGuard interface {
+	// CheckLimit verifica que el uso actual permita agregar un elemento más
+	CheckLimit(ctx context.Context, userID string, entitlement domain.Entitlement, usage int) error
+
+	// CheckFeature verifica que una funcionalidad esté habilitada
+	CheckFeature(ctx context.Context, userID string, entitlement domain.Entitlement) error
+
+	// CheckExportFormat verifica que el formato de exportación esté permitido
+	CheckExportFormat(ctx context.Context, userID string, format domain.ExportFormat) error
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// InvoiceRepository define las operaciones para el repositorio de facturas.
+// Las facturas son inmutables: no hay operaciones de actualización ni de borrado.
+type InvoiceRepository interface {
+	// Create guarda una nueva factura asignándole el siguiente número de la cuenta del usuario
+	Create(ctx context.Context, invoice *domain.Invoice) error
+
+	// GetByID obtiene una factura por su ID
+	GetByID(ctx context.Context, id string) (*domain.Invoice, error)
+
+	// GetBySubscriptionID obtiene las facturas de una suscripción
+	GetBySubscriptionID(ctx context.Context, subscriptionID string) ([]*domain.Invoice, error)
+
+	// GetByUserID obtiene las facturas de un usuario emitidas en el rango [from, to)
+	GetByUserID(ctx context.Context, userID string, from, to time.Time) ([]*domain.Invoice, error)
+
+	// CreatePending guarda una factura que no se pudo emitir junto con el motivo
+	CreatePending(ctx context.Context, invoice *domain.Invoice, cause string) error
+
+	// GetPending obtiene las facturas pendientes más antiguas, hasta limit
+	GetPending(ctx context.Context, limit int) ([]*domain.PendingInvoice, error)
+
+	// IssuePending emite una factura pendiente y la quita de las pendientes en la misma transacción.
+	// Si la factura ya existe solo la quita de las pendientes.
+	IssuePending(ctx context.Context, pending *domain.PendingInvoice) error
+
+	// RecordPendingFailure registra un intento fallido de emitir una factura pendiente
+	RecordPendingFailure(ctx context.Context, id string, cause string) error
+}
+
+// InvoiceIssuer emite facturas para las operaciones de cobro de las suscripciones
+// y consulta las ya emitidas
+type InvoiceIssuer interface {
+	// IssueInvoice calcula impuestos, numera y guarda la factura. Si no se puede guardar
+	// queda pendiente para volver a emitirla.
+	IssueInvoice(ctx context.Context, invoice *domain.Invoice) error
+
+	// IssuePendingInvoices vuelve a emitir hasta limit facturas pendientes y devuelve cuántas emitió
+	IssuePendingInvoices(ctx context.Context, limit int) (int, error)
+
+	// GetSubscriptionInvoices obtiene el historial de facturas de una suscripción
+	GetSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]*domain.Invoice, error)
+}
@@ -0,0 +1,12 @@
+package app
+
+import "context"
+
+// LeaderLock es el puerto para elegir una única instancia que ejecute tareas programadas
+type LeaderLock interface {
+	// TryAcquire intenta obtener el bloqueo sin esperar; devuelve true si esta instancia lo tiene
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release libera el bloqueo si esta instancia lo tiene
+	Release(ctx context.Context) error
+}
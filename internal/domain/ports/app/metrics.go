@@ -0,0 +1,26 @@
+package app
+
+// Resultados de una ejecución del planificador de suscripciones
+const (
+	SchedulerRunSucceeded = "success" // Ciclo de vida completado sin errores
+	SchedulerRunFailed    = "error"   // Error al tomar el bloqueo o durante el ciclo de vida
+	SchedulerRunSkipped   = "skipped" // Otra instancia tiene el bloqueo de líder
+)
+
+// Metrics es el puerto para registrar métricas de negocio
+type Metrics interface {
+	// TransactionCreated cuenta una transacción creada
+	TransactionCreated()
+
+	// LoginSucceeded y LoginFailed cuentan los intentos de inicio de sesión
+	LoginSucceeded()
+	LoginFailed()
+
+	// SubscriptionCreated, SubscriptionCancelled y SubscriptionRenewed cuentan los cambios de suscripción
+	SubscriptionCreated()
+	SubscriptionCancelled()
+	SubscriptionRenewed()
+
+	// SchedulerRun cuenta una ejecución del planificador con su resultado (SchedulerRun*)
+	SchedulerRun(outcome string)
+}
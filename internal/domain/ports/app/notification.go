@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// Notifier es el puerto para enviar un mensaje por un canal de notificación
+type Notifier interface {
+	// Channel devuelve el canal que atiende el notificador
+	Channel() domain.NotificationChannel
+
+	// Send envía el mensaje al destinatario indicado en el mensaje
+	Send(ctx context.Context, message *domain.NotificationMessage) error
+}
+
+// NotificationPublisher publica eventos de notificación para un usuario
+type NotificationPublisher interface {
+	// Publish entrega el evento por todos los canales habilitados del usuario
+	Publish(ctx context.Context, userID string, event domain.NotificationEvent, data map[string]string) error
+}
+
+// NotificationRepository define las operaciones para la bandeja de entrada de notificaciones
+type NotificationRepository interface {
+	// Create guarda una nueva notificación
+	Create(ctx context.Context, notification *domain.Notification) error
+
+	// GetByID obtiene una notificación por su ID
+	GetByID(ctx context.Context, id string) (*domain.Notification, error)
+
+	// GetByUserID obtiene las notificaciones de un usuario
+	GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error)
+
+	// MarkAsRead marca una notificación como leída
+	MarkAsRead(ctx context.Context, id string) error
+
+	// MarkAllAsRead marca todas las notificaciones de un usuario como leídas
+	MarkAllAsRead(ctx context.Context, userID string) error
+}
+
+// NotificationPreferenceRepository define las operaciones para las preferencias de notificación
+type NotificationPreferenceRepository interface {
+	// GetByUserID obtiene todas las preferencias de un usuario
+	GetByUserID(ctx context.Context, userID string) ([]*domain.NotificationPreference, error)
+
+	// GetByUserAndEvent obtiene las preferencias de un usuario para un evento
+	GetByUserAndEvent(ctx context.Context, userID string, event domain.NotificationEvent) ([]*domain.NotificationPreference, error)
+
+	// Upsert crea o actualiza la preferencia de un usuario para un evento y canal
+	Upsert(ctx context.Context, preference *domain.NotificationPreference) error
+}
+
+// NotificationTemplateRepository define las operaciones para las plantillas de notificación
+type NotificationTemplateRepository interface {
+	// Get obtiene la plantilla de un evento y canal
+	Get(ctx context.Context, event domain.NotificationEvent, channel domain.NotificationChannel) (*domain.NotificationTemplate, error)
+
+	// GetAll obtiene todas las plantillas
+	GetAll(ctx context.Context) ([]*domain.NotificationTemplate, error)
+
+	// Upsert crea o actualiza la plantilla de un evento y canal
+	Upsert(ctx context.Context, template *domain.NotificationTemplate) error
+}
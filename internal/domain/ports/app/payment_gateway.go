@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// PaymentGateway es el puerto para cobrar a través de un proveedor de pagos externo.
+// Los cobros rechazados devuelven el cobro fallido junto con un error que envuelve domain.ErrPaymentFailed.
+type PaymentGateway interface {
+	// Tokenize registra una tarjeta en el proveedor y devuelve un token reutilizable
+	Tokenize(ctx context.Context, card *domain.CardDetails) (*domain.CardToken, error)
+
+	// Charge cobra el monto indicado a una tarjeta tokenizada
+	Charge(ctx context.Context, request *domain.ChargeRequest) (*domain.Charge, error)
+
+	// Refund devuelve total o parcialmente un cobro; amount cero devuelve el total.
+	// currency es la moneda del cobro, en la que se expresa amount.
+	Refund(ctx context.Context, chargeID string, amount float64, currency string) (*domain.Refund, error)
+}
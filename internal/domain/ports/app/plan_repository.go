@@ -2,30 +2,46 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"MyMoneyBackend/internal/domain"
 )
 
 // PlanRepository es la interfaz que define los métodos para el repositorio de planes
 type PlanRepository interface {
-	// Create crea un nuevo plan en la base de datos
+	// Create crea un nuevo plan en la base de datos como la primera versión de su familia
 	Create(ctx context.Context, plan *domain.Plan) error
 
-	// GetByID obtiene un plan por su ID
+	// CreateVersion guarda next como la nueva versión vigente y marca previous como reemplazada
+	CreateVersion(ctx context.Context, previous *domain.Plan, next *domain.Plan) error
+
+	// GetByID obtiene una versión de plan por su ID
 	GetByID(ctx context.Context, id string) (*domain.Plan, error)
 
-	// GetAll obtiene todos los planes
+	// GetLatestVersion obtiene la versión vigente de una familia de planes
+	GetLatestVersion(ctx context.Context, familyID string) (*domain.Plan, error)
+
+	// GetVersions obtiene todas las versiones de una familia de planes
+	GetVersions(ctx context.Context, familyID string) ([]*domain.Plan, error)
+
+	// GetAll obtiene todos los planes, incluidas las versiones reemplazadas
 	GetAll(ctx context.Context) ([]*domain.Plan, error)
 
-	// GetAllPublic obtiene todos los planes públicos
+	// GetAllPublic obtiene la versión vigente de todos los planes públicos
 	GetAllPublic(ctx context.Context) ([]*domain.Plan, error)
 
-	// GetAllActive obtiene todos los planes activos
+	// GetAllActive obtiene la versión vigente de todos los planes activos
 	GetAllActive(ctx context.Context) ([]*domain.Plan, error)
 
 	// Update actualiza un plan existente
 	Update(ctx context.Context, plan *domain.Plan) error
 
-	// Delete elimina un plan por su ID
+	// Archive archiva todas las versiones de una familia de planes
+	Archive(ctx context.Context, familyID string, archivedAt time.Time) error
+
+	// Unarchive quita el archivado de una familia de planes y reactiva su versión vigente
+	Unarchive(ctx context.Context, familyID string, isPublic bool) error
+
+	// Delete elimina todas las versiones de la familia del plan indicado
 	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// RateLimitResult es la decisión del limitador sobre una petición
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // Peticiones permitidas por período
+	Remaining  int           // Peticiones que quedan en el período actual
+	Reset      time.Duration // Tiempo hasta recuperar el límite completo
+	RetryAfter time.Duration // Espera antes de reintentar; solo si no se permitió
+}
+
+// RateLimitStore es el puerto para contar las peticiones de cada clave
+type RateLimitStore interface {
+	// Take consume una petición de key según rule y devuelve si se permite
+	Take(ctx context.Context, key string, rule domain.RateLimitRule) (RateLimitResult, error)
+}
@@ -24,6 +24,13 @@ type TransactionRepository interface {
 	// GetByDateRange obtiene todas las transacciones de un usuario en un rango de fechas
 	GetByDateRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]*domain.Transaction, error)
 
+	// CountCreatedBetween cuenta las transacciones de un usuario registradas en el rango [from, to),
+	// sin importar la fecha de la transacción
+	CountCreatedBetween(ctx context.Context, userID string, from, to time.Time) (int, error)
+
+	// SumExpenses suma los gastos de una categoría en una moneda con fecha en el rango [from, to)
+	SumExpenses(ctx context.Context, categoryID, currencyID string, from, to time.Time) (float64, error)
+
 	// Update actualiza una transacción existente
 	Update(ctx context.Context, transaction *domain.Transaction) error
 
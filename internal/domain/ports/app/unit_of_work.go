@@ -0,0 +1,23 @@
+package app
+
+import "context"
+
+// TxRepositories agrupa repositorios que comparten una misma transacción
+type TxRepositories struct {
+	Users          UserRepository
+	Categories     CategoryRepository
+	PaymentMethods PaymentMethodRepository
+	Transactions   TransactionRepository
+	Currencies     CurrencyRepository
+	Plans          PlanRepository
+	Subscriptions  UserSubscriptionRepository
+	Coupons        CouponRepository
+	Invoices       InvoiceRepository
+}
+
+// UnitOfWork es el puerto para ejecutar operaciones de varios repositorios de forma atómica
+type UnitOfWork interface {
+	// Do ejecuta fn con repositorios ligados a una transacción. La transacción se confirma si fn
+	// devuelve nil y se revierte si devuelve un error o entra en pánico.
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
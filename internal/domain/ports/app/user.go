@@ -1,12 +1,18 @@
 package app
 
-import "MyMoneyBackend/internal/domain"
+import (
+	"context"
+
+	"MyMoneyBackend/internal/domain"
+)
 
 // UserRepository defines methods for user persistence
 type UserRepository interface {
-	Create(user *domain.User) error
-	GetByID(id string) (*domain.User, error)
-	GetByEmail(email string) (*domain.User, error)
-	Update(user *domain.User) error
-	Delete(id string) error
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id string) error
+	// CountReferences cuenta los registros de cada tabla que impiden eliminar al usuario
+	CountReferences(ctx context.Context, id string) (map[string]int, error)
 }
@@ -24,20 +24,32 @@ type UserSubscriptionRepository interface {
 	// GetByStatus obtiene suscripciones por estado
 	GetByStatus(ctx context.Context, status domain.SubscriptionStatus) ([]*domain.UserSubscription, error)
 
-	// GetExpiringSubscriptions obtiene suscripciones que expirarán pronto
-	GetExpiringSubscriptions(ctx context.Context, beforeDate time.Time) ([]*domain.UserSubscription, error)
+	// GetExpiringSubscriptions obtiene las suscripciones activas que terminan después de now y no más tarde de beforeDate
+	GetExpiringSubscriptions(ctx context.Context, now, beforeDate time.Time) ([]*domain.UserSubscription, error)
 
 	// GetPendingRenewals obtiene suscripciones pendientes de renovación
 	GetPendingRenewals(ctx context.Context, beforeDate time.Time) ([]*domain.UserSubscription, error)
 
+	// GetExpired obtiene suscripciones activas cuya fecha de finalización ya pasó
+	GetExpired(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error)
+
+	// GetDueForPaymentRetry obtiene suscripciones con pago fallido cuyo próximo intento ya venció
+	GetDueForPaymentRetry(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error)
+
+	// GetBillableByPlanID obtiene las suscripciones activas o en reintento de cobro de una versión de plan
+	GetBillableByPlanID(ctx context.Context, planID string) ([]*domain.UserSubscription, error)
+
+	// CountByPlan cuenta las suscripciones de cada versión de plan; solo completa PlanID y los contadores
+	CountByPlan(ctx context.Context) (map[string]*domain.PlanUsage, error)
+
 	// Update actualiza una suscripción existente
 	Update(ctx context.Context, subscription *domain.UserSubscription) error
 
 	// UpdateStatus actualiza el estado de una suscripción
 	UpdateStatus(ctx context.Context, id string, status domain.SubscriptionStatus) error
 
-	// CancelSubscription cancela una suscripción
-	CancelSubscription(ctx context.Context, id string, cancellationDate time.Time) error
+	// CancelSubscription cancela una suscripción y guarda sus metadatos, que incluyen el motivo
+	CancelSubscription(ctx context.Context, id string, cancellationDate time.Time, metadata map[string]string) error
 
 	// Delete elimina una suscripción por su ID
 	Delete(ctx context.Context, id string) error
@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// ProrationBilling define cuándo se cobra la diferencia de una mejora de plan
+type ProrationBilling string
+
+const (
+	// ProrationBillingImmediate cobra la diferencia en el momento del cambio
+	ProrationBillingImmediate ProrationBilling = "immediate"
+	// ProrationBillingNextRenewal suma la diferencia al cobro de la próxima renovación
+	ProrationBillingNextRenewal ProrationBilling = "next_renewal"
+)
+
+// IsValid indica si el modo de cobro del prorrateo es conocido
+func (b ProrationBilling) IsValid() bool {
+	return b == ProrationBillingImmediate || b == ProrationBillingNextRenewal
+}
+
+// PlanChangeType indica si un cambio de plan es una mejora o una reducción
+type PlanChangeType string
+
+const (
+	// PlanChangeUpgrade se aplica de inmediato y cobra la diferencia prorrateada
+	PlanChangeUpgrade PlanChangeType = "upgrade"
+	// PlanChangeDowngrade se aplica al final del período actual sin cobros ni reembolsos
+	PlanChangeDowngrade PlanChangeType = "downgrade"
+)
+
+// ProrationPreview describe el efecto de un cambio de plan antes de confirmarlo
+type ProrationPreview struct {
+	SubscriptionID string           `json:"subscription_id"`
+	CurrentPlanID  string           `json:"current_plan_id"`
+	NewPlanID      string           `json:"new_plan_id"`
+	ChangeType     PlanChangeType   `json:"change_type"`
+	Billing        ProrationBilling `json:"billing"`
+	PeriodStart    time.Time        `json:"period_start"`    // Inicio del período actual
+	PeriodEnd      time.Time        `json:"period_end"`      // Fin del período actual
+	UnusedFraction float64          `json:"unused_fraction"` // Parte del período que queda por usar (0-1)
+	Credit         float64          `json:"credit"`          // Crédito por el tiempo no usado del plan actual
+	NewPlanCost    float64          `json:"new_plan_cost"`   // Costo del nuevo plan hasta NewEndDate
+	AmountDue      float64          `json:"amount_due"`      // Monto a cobrar (0 en reducciones)
+	EffectiveAt    time.Time        `json:"effective_at"`    // Momento en que se aplica el nuevo plan
+	NewEndDate     time.Time        `json:"new_end_date"`    // Fin del período con el nuevo plan
+}
+
+// Validate valida la solicitud de cambio de plan
+func (r *ChangePlanRequest) Validate() error {
+	if r.Billing != "" && !r.Billing.IsValid() {
+		return NewValidationError("billing", "modo de cobro no válido, use immediate o next_renewal")
+	}
+	return nil
+}
+
+// UnusedCredit calcula el crédito por el tiempo no usado de un plan a partir de lo que realmente
+// se cobró, descuentos incluidos: cada línea pagada del plan cuyo período está en curso aporta su
+// importe por la parte de ese período que queda por usar. Devuelve false si ninguna factura pagada
+// tiene líneas del plan para el período en curso.
+func UnusedCredit(invoices []*Invoice, planID string, now time.Time) (float64, bool) {
+	credit := 0.0
+	found := false
+	for _, invoice := range invoices {
+		if invoice.Status != InvoiceStatusPaid {
+			continue
+		}
+		for _, line := range invoice.LineItems {
+			if line.PlanID != planID || line.PeriodStart == nil || line.PeriodEnd == nil {
+				continue
+			}
+			if now.Before(*line.PeriodStart) || !now.Before(*line.PeriodEnd) {
+				continue
+			}
+			credit += line.Amount * UnusedFraction(*line.PeriodStart, *line.PeriodEnd, now)
+			found = true
+		}
+	}
+	if credit < 0 {
+		credit = 0
+	}
+	return RoundAmount(credit), found
+}
@@ -1,9 +1,6 @@
 package domain
 
-import (
-	"errors"
-	"time"
-)
+import "time"
 
 // TransactionType representa el tipo de transacción (ingreso o gasto)
 type TransactionType string
@@ -42,13 +39,13 @@ func (t *Transaction) Validate() error {
 		return ErrEmptyUserID
 	}
 	if t.Date.IsZero() {
-		return errors.New("la fecha no puede estar vacía")
+		return NewValidationError("date", "la fecha no puede estar vacía")
 	}
 	if t.Type == "" {
-		return errors.New("el tipo de transacción no puede estar vacío")
+		return NewValidationError("type", "el tipo de transacción no puede estar vacío")
 	}
 	if t.Type != TransactionTypeIncome && t.Type != TransactionTypeExpense {
-		return errors.New("tipo de transacción inválido")
+		return NewValidationError("type", "tipo de transacción inválido")
 	}
 	return nil
 }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlanUsage resume cuántas suscripciones usan una versión de plan
+type PlanUsage struct {
+	PlanID             string `json:"plan_id"`
+	FamilyID           string `json:"family_id"`
+	Version            int    `json:"version"`
+	Name               string `json:"name"`
+	Archived           bool   `json:"archived"`
+	ActiveSubscribers  int    `json:"active_subscribers"`  // Suscripciones activas, pendientes o en reintento de cobro
+	TotalSubscriptions int    `json:"total_subscriptions"` // Todas las suscripciones, incluidas las canceladas y expiradas
+}
+
+// ResourceInUseError indica que un recurso no puede eliminarse porque otros registros lo referencian.
+// Si es Archivable puede archivarse en su lugar.
+type ResourceInUseError struct {
+	Resource   string         `json:"resource"`   // Tipo de recurso (plan, currency, user)
+	ID         string         `json:"id"`         // ID del recurso
+	References map[string]int `json:"references"` // Cantidad de referencias por tabla
+	Archivable bool           `json:"archivable"` // Si el recurso puede archivarse en lugar de eliminarse
+}
+
+// Error implementa la interfaz error
+func (e *ResourceInUseError) Error() string {
+	tables := make([]string, 0, len(e.References))
+	for table := range e.References {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	parts := make([]string, 0, len(tables))
+	for _, table := range tables {
+		parts = append(parts, fmt.Sprintf("%s: %d", table, e.References[table]))
+	}
+	message := fmt.Sprintf("%s: %s %s está referenciado (%s)", ErrResourceInUse, e.Resource, e.ID, strings.Join(parts, ", "))
+	if e.Archivable {
+		message += "; archívalo en su lugar"
+	}
+	return message
+}
+
+// Unwrap permite comparar con errors.Is(err, ErrResourceInUse)
+func (e *ResourceInUseError) Unwrap() error {
+	return ErrResourceInUse
+}
+
+// InUse indica si alguna tabla referencia al recurso
+func (e *ResourceInUseError) InUse() bool {
+	for _, count := range e.References {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,6 @@
 package domain
 
-import (
-	"errors"
-	"time"
-)
+import "time"
 
 // User represents a user in the system
 type User struct {
@@ -18,13 +15,13 @@ type User struct {
 // Validate validates the user data
 func (u *User) Validate() error {
 	if u.Email == "" {
-		return errors.New("email is required")
+		return NewValidationError("email", "email is required")
 	}
 	if u.Name == "" {
-		return errors.New("name is required")
+		return NewValidationError("name", "name is required")
 	}
 	if u.Password == "" {
-		return errors.New("password is required")
+		return NewValidationError("password", "password is required")
 	}
 	return nil
 }
@@ -1,9 +1,6 @@
 package domain
 
-import (
-	"errors"
-	"time"
-)
+import "time"
 
 // SubscriptionStatus define el estado de una suscripción
 type SubscriptionStatus string
@@ -33,6 +30,7 @@ type UserSubscription struct {
 	CancellationDate   *time.Time         `json:"cancellation_date"`    // Fecha de cancelación
 	LastPaymentDate    *time.Time         `json:"last_payment_date"`    // Fecha del último pago
 	NextPaymentAttempt *time.Time         `json:"next_payment_attempt"` // Fecha del próximo intento de pago
+	TrialEndDate       *time.Time         `json:"trial_end_date"`       // Fin del período de prueba (null si no tuvo prueba)
 	PaymentMethodID    *string            `json:"payment_method_id"`    // ID del método de pago (null para planes gratuitos)
 	Metadata           map[string]string  `json:"metadata"`             // Metadatos adicionales
 	CreatedAt          time.Time          `json:"created_at"`           // Fecha de creación
@@ -42,19 +40,19 @@ type UserSubscription struct {
 // Validate valida que la entidad UserSubscription tenga todos los campos requeridos
 func (s *UserSubscription) Validate() error {
 	if s.UserID == "" {
-		return errors.New("el ID del usuario es obligatorio")
+		return NewValidationError("user_id", "el ID del usuario es obligatorio")
 	}
 	if s.PlanID == "" {
-		return errors.New("el ID del plan es obligatorio")
+		return NewValidationError("plan_id", "el ID del plan es obligatorio")
 	}
 	if s.Status == "" {
-		return errors.New("el estado de la suscripción es obligatorio")
+		return NewValidationError("status", "el estado de la suscripción es obligatorio")
 	}
 	if s.StartDate.IsZero() {
-		return errors.New("la fecha de inicio es obligatoria")
+		return NewValidationError("start_date", "la fecha de inicio es obligatoria")
 	}
 	if s.EndDate.IsZero() {
-		return errors.New("la fecha de finalización es obligatoria")
+		return NewValidationError("end_date", "la fecha de finalización es obligatoria")
 	}
 
 	// Validar estado de suscripción
@@ -67,20 +65,20 @@ func (s *UserSubscription) Validate() error {
 	}
 
 	if !validStatus[s.Status] {
-		return errors.New("el estado de la suscripción no es válido")
+		return NewValidationError("status", "el estado de la suscripción no es válido")
 	}
 
 	// Validar fechas
 	if s.EndDate.Before(s.StartDate) {
-		return errors.New("la fecha de finalización debe ser posterior a la fecha de inicio")
+		return NewValidationError("end_date", "la fecha de finalización debe ser posterior a la fecha de inicio")
 	}
 
 	if s.RenewalDate != nil && s.RenewalDate.Before(s.StartDate) {
-		return errors.New("la fecha de renovación debe ser posterior a la fecha de inicio")
+		return NewValidationError("renewal_date", "la fecha de renovación debe ser posterior a la fecha de inicio")
 	}
 
 	if s.CancellationDate != nil && (s.CancellationDate.Before(s.StartDate) || s.CancellationDate.After(s.EndDate)) {
-		return errors.New("la fecha de cancelación debe estar entre la fecha de inicio y finalización")
+		return NewValidationError("cancellation_date", "la fecha de cancelación debe estar entre la fecha de inicio y finalización")
 	}
 
 	return nil
@@ -91,6 +89,11 @@ func (s *UserSubscription) IsActive() bool {
 	return s.Status == SubscriptionStatusActive && time.Now().Before(s.EndDate)
 }
 
+// IsTrialing verifica si la suscripción está en su período de prueba gratuito
+func (s *UserSubscription) IsTrialing() bool {
+	return s.Status == SubscriptionStatusActive && s.TrialEndDate != nil && time.Now().Before(*s.TrialEndDate)
+}
+
 // HasExpired verifica si la suscripción ha expirado
 func (s *UserSubscription) HasExpired() bool {
 	return time.Now().After(s.EndDate)
@@ -115,6 +118,7 @@ type CreateSubscriptionRequest struct {
 	StartDate       time.Time         `json:"start_date"`
 	EndDate         time.Time         `json:"end_date"`
 	PaymentMethodID *string           `json:"payment_method_id"`
+	CouponCode      string            `json:"coupon_code"` // Código promocional a aplicar (opcional)
 	Metadata        map[string]string `json:"metadata"`
 }
 
@@ -130,7 +134,9 @@ type CancelSubscriptionRequest struct {
 
 // ChangePlanRequest representa la solicitud para cambiar de plan
 type ChangePlanRequest struct {
-	PlanID string `json:"plan_id" binding:"required"`
+	PlanID     string           `json:"plan_id" binding:"required"`
+	Billing    ProrationBilling `json:"billing"`     // Cuándo cobrar la diferencia de una mejora (immediate por defecto)
+	CouponCode string           `json:"coupon_code"` // Código promocional a aplicar (opcional)
 }
 
 // SubscriptionResponse representa la respuesta de una suscripción
@@ -145,11 +151,13 @@ type SubscriptionResponse struct {
 	CancellationDate   *time.Time        `json:"cancellation_date,omitempty"`
 	LastPaymentDate    *time.Time        `json:"last_payment_date,omitempty"`
 	NextPaymentAttempt *time.Time        `json:"next_payment_attempt,omitempty"`
+	TrialEndDate       *time.Time        `json:"trial_end_date,omitempty"`
 	PaymentMethodID    *string           `json:"payment_method_id,omitempty"`
 	Metadata           map[string]string `json:"metadata,omitempty"`
 	CreatedAt          time.Time         `json:"created_at"`
 	UpdatedAt          time.Time         `json:"updated_at"`
 	IsActive           bool              `json:"is_active"`
+	IsTrialing         bool              `json:"is_trialing"`
 }
 
 // mapSubscriptionToResponse mapea una entidad de suscripción a una respuesta HTTP
@@ -165,10 +173,12 @@ func MapSubscriptionToResponse(subscription *UserSubscription) SubscriptionRespo
 		CancellationDate:   subscription.CancellationDate,
 		LastPaymentDate:    subscription.LastPaymentDate,
 		NextPaymentAttempt: subscription.NextPaymentAttempt,
+		TrialEndDate:       subscription.TrialEndDate,
 		PaymentMethodID:    subscription.PaymentMethodID,
 		Metadata:           subscription.Metadata,
 		CreatedAt:          subscription.CreatedAt,
 		UpdatedAt:          subscription.UpdatedAt,
 		IsActive:           subscription.IsActive(),
+		IsTrialing:         subscription.IsTrialing(),
 	}
 }
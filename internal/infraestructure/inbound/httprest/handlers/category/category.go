@@ -1,11 +1,11 @@
 package api
 
 import (
-	"context"
 	"net/http"
 
 	"MyMoneyBackend/internal/application/category"
 	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,24 +31,25 @@ func NewCategoryHandler(categoryService *category.Service) *CategoryHandler {
 // @Security Bearer
 // @Param category body domain.CreateCategoryRequest true "Datos de la categoría a crear"
 // @Success 201 {object} domain.Category
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
 // @Router /api/categories [post]
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req domain.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	category, err := h.categoryService.CreateCategory(
-		context.Background(),
+		c.Request.Context(),
 		req.Name,
 		req.Description,
 		req.Icon,
@@ -56,7 +57,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		userID,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -70,19 +71,19 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {array} domain.Category
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /api/categories [get]
 func (h *CategoryHandler) GetUserCategories(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
-	categories, err := h.categoryService.GetCategoriesByUserID(context.Background(), userID)
+	categories, err := h.categoryService.GetCategoriesByUserID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error retrieving categories"})
+		c.Error(err)
 		return
 	}
 
@@ -97,32 +98,32 @@ func (h *CategoryHandler) GetUserCategories(c *gin.Context) {
 // @Security Bearer
 // @Param id path string true "ID de la categoría"
 // @Success 200 {object} domain.Category
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /api/categories/{id} [get]
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	categoryID := c.Param("id")
 	if categoryID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "category ID is required"})
+		c.Error(domain.NewValidationError("id", "category ID is required"))
 		return
 	}
 
-	category, err := h.categoryService.GetCategoryByID(context.Background(), categoryID)
+	category, err := h.categoryService.GetCategoryByID(c.Request.Context(), categoryID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		c.Error(err)
 		return
 	}
 
 	// Check if the category belongs to the user
 	if category.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		c.Error(domain.NewForbiddenError("access_denied", "access denied"))
 		return
 	}
 
@@ -139,31 +140,31 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 // @Param id path string true "ID de la categoría"
 // @Param category body domain.UpdateCategoryRequest true "Datos de la categoría a actualizar"
 // @Success 200 {object} domain.Category
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /api/categories/{id} [put]
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	categoryID := c.Param("id")
 	if categoryID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "category ID is required"})
+		c.Error(domain.NewValidationError("id", "category ID is required"))
 		return
 	}
 
 	var req domain.UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	category, err := h.categoryService.UpdateCategory(
-		context.Background(),
+		c.Request.Context(),
 		categoryID,
 		req.Name,
 		req.Description,
@@ -171,7 +172,65 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		req.Color,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// SetCategoryBudget sets or clears the monthly budget of a category
+// @Summary Fijar el presupuesto de una categoría
+// @Description Fija el gasto máximo por mes de una categoría; al superarlo se notifica budget_exceeded. Un importe null quita el presupuesto
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "ID de la categoría"
+// @Param budget body domain.SetCategoryBudgetRequest true "Presupuesto mensual"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /api/categories/{id}/budget [put]
+func (h *CategoryHandler) SetCategoryBudget(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	categoryID := c.Param("id")
+	if categoryID == "" {
+		c.Error(domain.NewValidationError("id", "category ID is required"))
+		return
+	}
+
+	var req domain.SetCategoryBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	existing, err := h.categoryService.GetCategoryByID(c.Request.Context(), categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if existing.UserID != userID {
+		c.Error(domain.NewForbiddenError("access_denied", "access denied"))
+		return
+	}
+
+	var budget *domain.CategoryBudget
+	if req.Amount != nil {
+		budget = &domain.CategoryBudget{Amount: *req.Amount, CurrencyID: req.CurrencyID}
+	}
+
+	category, err := h.categoryService.SetCategoryBudget(c.Request.Context(), categoryID, budget)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -185,25 +244,25 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 // @Security Bearer
 // @Param id path string true "ID de la categoría"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /api/categories/{id} [delete]
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	categoryID := c.Param("id")
 	if categoryID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "category ID is required"})
+		c.Error(domain.NewValidationError("id", "category ID is required"))
 		return
 	}
 
-	err := h.categoryService.DeleteCategory(context.Background(), categoryID)
+	err := h.categoryService.DeleteCategory(c.Request.Context(), categoryID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,179 @@
+package coupon
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/application/coupon"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// Handler maneja las solicitudes HTTP relacionadas con los cupones promocionales
+type Handler struct {
+	service *coupon.Service
+}
+
+// NewCouponHandler crea una nueva instancia del controlador de cupones
+func NewCouponHandler(service *coupon.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ValidateCoupon godoc
+// @Summary Validar un cupón
+// @Description Verifica si un código promocional puede canjearse para un plan, sin canjearlo
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param code query string true "Código promocional"
+// @Param plan_id query string true "ID del plan"
+// @Security Bearer
+// @Success 200 {object} domain.Coupon
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /coupons/validate [get]
+func (h *Handler) ValidateCoupon(c *gin.Context) {
+	code := c.Query("code")
+	planID := c.Query("plan_id")
+	if code == "" || planID == "" {
+		c.Error(domain.NewValidationError("", "Se requieren los parámetros code y plan_id"))
+		return
+	}
+
+	result, err := h.service.ValidateCoupon(c.Request.Context(), code, planID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCoupons godoc
+// @Summary Listar cupones
+// @Description Retorna todos los cupones promocionales
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.Coupon
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /coupons/admin [get]
+func (h *Handler) GetCoupons(c *gin.Context) {
+	coupons, err := h.service.GetAllCoupons(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if coupons == nil {
+		coupons = []*domain.Coupon{}
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// GetCouponByID godoc
+// @Summary Obtener un cupón
+// @Description Retorna un cupón promocional por su ID
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del cupón"
+// @Security Bearer
+// @Success 200 {object} domain.Coupon
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /coupons/admin/{id} [get]
+func (h *Handler) GetCouponByID(c *gin.Context) {
+	result, err := h.service.GetCouponByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCoupon godoc
+// @Summary Crear un cupón
+// @Description Crea un cupón promocional con descuento porcentual o de monto fijo
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param coupon body domain.CouponRequest true "Datos del cupón"
+// @Security Bearer
+// @Success 201 {object} domain.Coupon
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Router /coupons/admin [post]
+func (h *Handler) CreateCoupon(c *gin.Context) {
+	var req domain.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	result, err := h.service.CreateCoupon(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateCoupon godoc
+// @Summary Actualizar un cupón
+// @Description Actualiza un cupón promocional existente
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del cupón"
+// @Param coupon body domain.CouponRequest true "Datos del cupón"
+// @Security Bearer
+// @Success 200 {object} domain.Coupon
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Router /coupons/admin/{id} [put]
+func (h *Handler) UpdateCoupon(c *gin.Context) {
+	var req domain.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	result, err := h.service.UpdateCoupon(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteCoupon godoc
+// @Summary Eliminar un cupón
+// @Description Elimina un cupón promocional; las suscripciones que ya lo canjearon dejan de recibir el descuento
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del cupón"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /coupons/admin/{id} [delete]
+func (h *Handler) DeleteCoupon(c *gin.Context) {
+	if err := h.service.DeleteCoupon(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -7,6 +7,7 @@ import (
 
 	"MyMoneyBackend/internal/application/currency"
 	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 )
 
 // Handler maneja las solicitudes HTTP relacionadas con las monedas
@@ -47,7 +48,7 @@ func (h *Handler) GetAllCurrencies(c *gin.Context) {
 
 	currencies, err := h.service.GetAllCurrencies(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener monedas: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -72,7 +73,7 @@ func (h *Handler) GetActiveCurrencies(c *gin.Context) {
 
 	currencies, err := h.service.GetActiveCurrencies(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener monedas activas: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -92,7 +93,7 @@ func (h *Handler) GetActiveCurrencies(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID de la moneda"
 // @Success 200 {object} domain.CurrencyResponse
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} middleware.Problem
 // @Router /currencies/{id} [get]
 func (h *Handler) GetCurrencyByID(c *gin.Context) {
 	id := c.Param("id")
@@ -100,7 +101,7 @@ func (h *Handler) GetCurrencyByID(c *gin.Context) {
 
 	currency, err := h.service.GetCurrencyByID(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Moneda no encontrada: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -115,7 +116,7 @@ func (h *Handler) GetCurrencyByID(c *gin.Context) {
 // @Produce json
 // @Param code path string true "Código de la moneda"
 // @Success 200 {object} domain.CurrencyResponse
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} middleware.Problem
 // @Router /currencies/code/{code} [get]
 func (h *Handler) GetCurrencyByCode(c *gin.Context) {
 	code := c.Param("code")
@@ -123,7 +124,7 @@ func (h *Handler) GetCurrencyByCode(c *gin.Context) {
 
 	currency, err := h.service.GetCurrencyByCode(ctx, code)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Moneda no encontrada: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -139,21 +140,21 @@ func (h *Handler) GetCurrencyByCode(c *gin.Context) {
 // @Param currency body domain.CreateCurrencyRequest true "Datos de la moneda"
 // @Security Bearer
 // @Success 201 {object} domain.CurrencyResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 409 {object} middleware.Problem
 // @Router /currencies [post]
 func (h *Handler) CreateCurrency(c *gin.Context) {
 	var request domain.CreateCurrencyRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	ctx := c.Request.Context()
 	currency, err := h.service.CreateCurrency(ctx, request.Code, request.Name, request.Symbol, request.IsActive)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Error al crear moneda: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -170,24 +171,49 @@ func (h *Handler) CreateCurrency(c *gin.Context) {
 // @Param currency body domain.UpdateCurrencyRequest true "Datos actualizados de la moneda"
 // @Security Bearer
 // @Success 200 {object} domain.CurrencyResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /currencies/{id} [put]
 func (h *Handler) UpdateCurrency(c *gin.Context) {
 	id := c.Param("id")
 
 	var request domain.UpdateCurrencyRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	ctx := c.Request.Context()
 	currency, err := h.service.UpdateCurrency(ctx, id, request.Code, request.Name, request.Symbol, request.IsActive)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Error al actualizar moneda: " + err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapCurrencyToCurrencyResponse(currency))
+}
+
+// ArchiveCurrency godoc
+// @Summary Archivar una moneda
+// @Description Desactiva una moneda para que no pueda usarse en nuevos planes, conservándola para los registros existentes
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la moneda"
+// @Security Bearer
+// @Success 200 {object} domain.CurrencyResponse
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Router /currencies/{id}/archive [post]
+func (h *Handler) ArchiveCurrency(c *gin.Context) {
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	currency, err := h.service.ArchiveCurrency(ctx, id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -196,15 +222,16 @@ func (h *Handler) UpdateCurrency(c *gin.Context) {
 
 // DeleteCurrency godoc
 // @Summary Eliminar una moneda
-// @Description Elimina una moneda basado en su ID
+// @Description Elimina una moneda que no usan planes, transacciones ni cupones. Las monedas en uso deben archivarse.
 // @Tags currencies
 // @Accept json
 // @Produce json
 // @Param id path string true "ID de la moneda"
 // @Security Bearer
 // @Success 204 "No Content"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "La moneda está en uso"
 // @Router /currencies/{id} [delete]
 func (h *Handler) DeleteCurrency(c *gin.Context) {
 	id := c.Param("id")
@@ -212,7 +239,7 @@ func (h *Handler) DeleteCurrency(c *gin.Context) {
 
 	err := h.service.DeleteCurrency(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Error al eliminar moneda: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
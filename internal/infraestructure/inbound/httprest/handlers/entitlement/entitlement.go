@@ -0,0 +1,50 @@
+package entitlement
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/application/entitlement"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// Handler maneja las solicitudes HTTP relacionadas con los derechos de uso
+type Handler struct {
+	service *entitlement.Service
+}
+
+// NewEntitlementHandler crea una nueva instancia del controlador de derechos de uso
+func NewEntitlementHandler(service *entitlement.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetEntitlements godoc
+// @Summary Obtener derechos de uso
+// @Description Retorna los límites y funcionalidades vigentes del usuario autenticado según su plan
+// @Tags entitlements
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} domain.UserEntitlements
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /entitlements [get]
+func (h *Handler) GetEntitlements(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	entitlements, err := h.service.GetUserEntitlements(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entitlements)
+}
@@ -1,23 +1,48 @@
 package health
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"log/slog"
 	"net/http"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
-	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/internal/application/health"
 )
 
+// dbCheckTimeout limits how long the health check waits for the database
+const dbCheckTimeout = 2 * time.Second
+
 // Handler handles health check requests
-type Handler struct{}
+type Handler struct {
+	db     *sql.DB
+	checks *health.Registry
+	ready  atomic.Bool
+}
+
+// NewHealthHandler creates a new health check handler that pings db and runs
+// checks on /readyz. The handler starts ready; SetReady(false) marks the
+// instance as shutting down.
+func NewHealthHandler(db *sql.DB, checks *health.Registry) *Handler {
+	h := &Handler{db: db, checks: checks}
+	h.ready.Store(true)
+	return h
+}
+
+// SetReady flips the readiness flag. While not ready the health endpoints answer
+// 503 so load balancers stop routing new traffic before in-flight requests drain.
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
 
-// NewHealthHandler creates a new health check handler
-func NewHealthHandler() *Handler {
-	return &Handler{}
+// Ready reports whether the instance accepts new traffic
+func (h *Handler) Ready() bool {
+	return h.ready.Load()
 }
 
 // HealthResponse represents the health check response
@@ -44,12 +69,13 @@ type InfoData struct {
 // @Accept json
 // @Produce json
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
 // @Router /health [get]
 func (h *Handler) Status(c *gin.Context) {
 	startTime := time.Now()
 
 	// Test database connection
-	dbOK := testDBConnection()
+	dbOK := h.testDBConnection(c.Request.Context())
 
 	// Get environment
 	env := "development"
@@ -57,9 +83,15 @@ func (h *Handler) Status(c *gin.Context) {
 		env = "production"
 	}
 
+	// Report shutting down so no new traffic is routed here
+	status, code := "ok", http.StatusOK
+	if !h.Ready() {
+		status, code = "shutting_down", http.StatusServiceUnavailable
+	}
+
 	// Create response
 	response := HealthResponse{
-		Status:      "ok",
+		Status:      status,
 		Version:     "1.0.0",
 		Environment: env,
 		Timestamp:   time.Now(),
@@ -71,7 +103,7 @@ func (h *Handler) Status(c *gin.Context) {
 		},
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(code, response)
 }
 
 // Check godoc
@@ -81,25 +113,76 @@ func (h *Handler) Status(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /health/check [get]
 func (h *Handler) Check(c *gin.Context) {
+	if !h.Ready() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "shutting_down",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 	})
 }
 
-// testDBConnection tests the database connection
-func testDBConnection() bool {
-	client, err := config.NewSupabaseClient()
-	if err != nil {
-		log.Printf("Error connecting to database: %v", err)
-		return false
+// Livez godoc
+// @Summary Liveness probe
+// @Description Returns 200 while the process is able to serve requests. It does not check dependencies, so a failing database never restarts the instance.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /livez [get]
+func (h *Handler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{
+		Status:    health.StatusHealthy,
+		CheckedAt: time.Now(),
+		Checks:    []health.CheckResult{},
+	})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Runs the registered dependency checks. Returns 200 when healthy or degraded (a non-critical check failed) and 503 when a critical check failed or the instance is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	if !h.Ready() {
+		now := time.Now()
+		c.JSON(http.StatusServiceUnavailable, health.Report{
+			Status:    health.StatusUnhealthy,
+			CheckedAt: now,
+			Checks: []health.CheckResult{{
+				Name:      "shutdown",
+				Status:    health.StatusUnhealthy,
+				Critical:  true,
+				Error:     "la instancia se está apagando",
+				CheckedAt: now,
+			}},
+		})
+		return
+	}
+
+	report := h.checks.Run(c.Request.Context())
+	code := http.StatusOK
+	if report.Status == health.StatusUnhealthy {
+		code = http.StatusServiceUnavailable
 	}
-	defer client.Close()
+	c.JSON(code, report)
+}
+
+// testDBConnection tests the database connection
+func (h *Handler) testDBConnection(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, dbCheckTimeout)
+	defer cancel()
 
-	_, err = client.ExecuteQuery()
-	if err != nil {
-		log.Printf("Error executing test query: %v", err)
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.WarnContext(ctx, "health check: la base de datos no responde", "error", err)
 		return false
 	}
 
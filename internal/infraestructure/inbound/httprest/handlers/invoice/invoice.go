@@ -0,0 +1,154 @@
+package invoice
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/application/invoice"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// Handler maneja las solicitudes HTTP relacionadas con facturas
+type Handler struct {
+	service *invoice.Service
+}
+
+// NewInvoiceHandler crea una nueva instancia del controlador de facturas
+func NewInvoiceHandler(service *invoice.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetInvoices godoc
+// @Summary Obtener historial de facturación
+// @Description Retorna las facturas del usuario autenticado emitidas en un año (por defecto el actual)
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param year query int false "Año de emisión"
+// @Security Bearer
+// @Success 200 {array} domain.Invoice
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Router /invoices [get]
+func (h *Handler) GetInvoices(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	year := time.Now().Year()
+	if value := c.Query("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 2000 || parsed > 9999 {
+			c.Error(domain.NewValidationError("year", "El parámetro year no es válido"))
+			return
+		}
+		year = parsed
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	invoices, err := h.service.GetUserInvoices(c.Request.Context(), userID.(string), from, from.AddDate(1, 0, 0))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if invoices == nil {
+		invoices = []*domain.Invoice{}
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoiceByID godoc
+// @Summary Obtener una factura
+// @Description Retorna una factura del usuario autenticado
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la factura"
+// @Security Bearer
+// @Success 200 {object} domain.Invoice
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /invoices/{id} [get]
+func (h *Handler) GetInvoiceByID(c *gin.Context) {
+	invoice, ok := h.getOwnedInvoice(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// RenderInvoice godoc
+// @Summary Descargar una factura
+// @Description Genera la factura en HTML o PDF
+// @Tags invoices
+// @Produce html
+// @Produce application/pdf
+// @Param id path string true "ID de la factura"
+// @Param format query string false "Formato del documento (html o pdf)" default(html)
+// @Security Bearer
+// @Success 200 {file} file
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /invoices/{id}/document [get]
+func (h *Handler) RenderInvoice(c *gin.Context) {
+	inv, ok := h.getOwnedInvoice(c)
+	if !ok {
+		return
+	}
+
+	switch format := c.DefaultQuery("format", "html"); format {
+	case "html":
+		document, err := invoice.RenderHTML(inv)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", document)
+	case "pdf":
+		document, err := invoice.RenderPDF(inv)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+inv.Number+`.pdf"`)
+		c.Data(http.StatusOK, "application/pdf", document)
+	default:
+		c.Error(domain.NewValidationError("format", "Formato no soportado: "+format))
+	}
+}
+
+// getOwnedInvoice obtiene la factura del path y verifica que pertenezca al usuario autenticado.
+// Si no, responde con el error correspondiente y devuelve false.
+func (h *Handler) getOwnedInvoice(c *gin.Context) (*domain.Invoice, bool) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return nil, false
+	}
+
+	invoice, err := h.service.GetInvoiceByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	// Las facturas de otros usuarios se reportan como inexistentes
+	if invoice == nil || invoice.UserID != userID.(string) {
+		c.Error(domain.NewNotFoundError("invoice", "Factura no encontrada"))
+		return nil, false
+	}
+
+	return invoice, true
+}
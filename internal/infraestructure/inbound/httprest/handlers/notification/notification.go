@@ -0,0 +1,233 @@
+package notification
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/application/notification"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// Handler maneja las solicitudes HTTP relacionadas con notificaciones
+type Handler struct {
+	service *notification.Service
+}
+
+// NewNotificationHandler crea una nueva instancia del controlador de notificaciones
+func NewNotificationHandler(service *notification.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetInbox godoc
+// @Summary Obtener notificaciones
+// @Description Retorna la bandeja de entrada del usuario autenticado
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param unread query bool false "Solo notificaciones no leídas"
+// @Security Bearer
+// @Success 200 {array} domain.Notification
+// @Failure 401 {object} middleware.Problem
+// @Router /notifications [get]
+func (h *Handler) GetInbox(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.service.GetInbox(c.Request.Context(), userID.(string), unreadOnly)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkAsRead godoc
+// @Summary Marcar notificación como leída
+// @Description Marca una notificación del usuario autenticado como leída
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la notificación"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /notifications/{id}/read [put]
+func (h *Handler) MarkAsRead(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	if err := h.service.MarkAsRead(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllAsRead godoc
+// @Summary Marcar todas las notificaciones como leídas
+// @Description Marca todas las notificaciones del usuario autenticado como leídas
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 401 {object} middleware.Problem
+// @Router /notifications/read-all [put]
+func (h *Handler) MarkAllAsRead(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	if err := h.service.MarkAllAsRead(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences godoc
+// @Summary Obtener preferencias de notificación
+// @Description Retorna las preferencias de notificación por evento y canal del usuario autenticado
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.NotificationPreference
+// @Failure 401 {object} middleware.Problem
+// @Router /notifications/preferences [get]
+func (h *Handler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	preferences, err := h.service.GetPreferences(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreference godoc
+// @Summary Actualizar preferencia de notificación
+// @Description Habilita o deshabilita un canal para un evento del usuario autenticado
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param preference body domain.UpdateNotificationPreferenceRequest true "Preferencia"
+// @Security Bearer
+// @Success 200 {object} domain.NotificationPreference
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Router /notifications/preferences [put]
+func (h *Handler) UpdatePreference(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	var req domain.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	preference, err := h.service.UpdatePreference(
+		c.Request.Context(),
+		userID.(string),
+		domain.NotificationEvent(req.Event),
+		domain.NotificationChannel(req.Channel),
+		req.Enabled,
+		req.Target,
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// GetTemplates godoc
+// @Summary Obtener plantillas de notificación
+// @Description Retorna las plantillas de todos los eventos y canales (solo para administradores)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.NotificationTemplate
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /notifications/admin/templates [get]
+func (h *Handler) GetTemplates(c *gin.Context) {
+	templates, err := h.service.GetTemplates(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// UpdateTemplate godoc
+// @Summary Actualizar plantilla de notificación
+// @Description Personaliza la plantilla de un evento y canal (solo para administradores)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param event path string true "Evento"
+// @Param channel path string true "Canal"
+// @Param template body domain.UpdateNotificationTemplateRequest true "Plantilla"
+// @Security Bearer
+// @Success 200 {object} domain.NotificationTemplate
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /notifications/admin/templates/{event}/{channel} [put]
+func (h *Handler) UpdateTemplate(c *gin.Context) {
+	var req domain.UpdateNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	tmpl, err := h.service.UpdateTemplate(
+		c.Request.Context(),
+		domain.NotificationEvent(c.Param("event")),
+		domain.NotificationChannel(c.Param("channel")),
+		req.Subject,
+		req.Body,
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tmpl)
+}
@@ -34,28 +34,28 @@ func NewPaymentMethodHandler(service *payment_method.Service) *PaymentMethodHand
 // @Param method body domain.CreatePaymentMethodRequest true "Datos del método de pago"
 // @Security Bearer
 // @Success 201 {object} domain.PaymentMethod
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /payment-methods [post]
 func (h *PaymentMethodHandler) Create(c *gin.Context) {
 	var req domain.CreatePaymentMethodRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Obtener el ID de usuario del contexto (establecido por middleware de autenticación)
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	paymentMethod, err := h.service.CreatePaymentMethod(c.Request.Context(), req.Name, req.Description, userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -71,34 +71,34 @@ func (h *PaymentMethodHandler) Create(c *gin.Context) {
 // @Param id path string true "ID del método de pago"
 // @Security Bearer
 // @Success 200 {object} domain.PaymentMethod
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /payment-methods/{id} [get]
 func (h *PaymentMethodHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID no proporcionado"))
 		return
 	}
 
 	paymentMethod, err := h.service.GetPaymentMethodByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if paymentMethod == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Método de pago no encontrado"})
+		c.Error(domain.NewNotFoundError("payment_method", "Método de pago no encontrado"))
 		return
 	}
 
 	// Verificar que el método de pago pertenece al usuario autenticado
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists || paymentMethod.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para acceder a este método de pago"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tienes permiso para acceder a este método de pago"))
 		return
 	}
 
@@ -113,19 +113,19 @@ func (h *PaymentMethodHandler) GetByID(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {array} domain.PaymentMethod
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /payment-methods [get]
 func (h *PaymentMethodHandler) GetAll(c *gin.Context) {
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	paymentMethods, err := h.service.GetPaymentMethodsByUserID(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -142,41 +142,41 @@ func (h *PaymentMethodHandler) GetAll(c *gin.Context) {
 // @Param method body domain.UpdatePaymentMethodRequest true "Datos actualizados del método de pago"
 // @Security Bearer
 // @Success 200 {object} domain.PaymentMethod
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /payment-methods/{id} [put]
 func (h *PaymentMethodHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID no proporcionado"))
 		return
 	}
 
 	var req domain.UpdatePaymentMethodRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Verificar que el método de pago existe y pertenece al usuario
 	existingPaymentMethod, err := h.service.GetPaymentMethodByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if existingPaymentMethod == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Método de pago no encontrado"})
+		c.Error(domain.NewNotFoundError("payment_method", "Método de pago no encontrado"))
 		return
 	}
 
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists || existingPaymentMethod.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para modificar este método de pago"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tienes permiso para modificar este método de pago"))
 		return
 	}
 
@@ -189,13 +189,76 @@ func (h *PaymentMethodHandler) Update(c *gin.Context) {
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedPaymentMethod)
 }
 
+// AttachCard godoc
+// @Summary Asociar una tarjeta a un método de pago
+// @Description Tokeniza una tarjeta en la pasarela de pago y la asocia al método de pago para cobrar suscripciones
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del método de pago"
+// @Param card body domain.CardDetails true "Datos de la tarjeta"
+// @Security Bearer
+// @Success 200 {object} domain.PaymentMethod
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 402 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /payment-methods/{id}/card [post]
+func (h *PaymentMethodHandler) AttachCard(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.Error(domain.NewValidationError("id", "ID no proporcionado"))
+		return
+	}
+
+	var req domain.CardDetails
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Verificar que el método de pago existe y pertenece al usuario
+	existingPaymentMethod, err := h.service.GetPaymentMethodByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if existingPaymentMethod == nil {
+		c.Error(domain.NewNotFoundError("payment_method", "Método de pago no encontrado"))
+		return
+	}
+
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists || existingPaymentMethod.UserID != userID.(string) {
+		c.Error(domain.NewForbiddenError("access_denied", "No tienes permiso para modificar este método de pago"))
+		return
+	}
+
+	paymentMethod, err := h.service.AttachCard(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentMethod)
+}
+
 // Delete godoc
 // @Summary Eliminar un método de pago
 // @Description Elimina un método de pago existente
@@ -205,39 +268,39 @@ func (h *PaymentMethodHandler) Update(c *gin.Context) {
 // @Param id path string true "ID del método de pago"
 // @Security Bearer
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /payment-methods/{id} [delete]
 func (h *PaymentMethodHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID no proporcionado"))
 		return
 	}
 
 	// Verificar que el método de pago existe y pertenece al usuario
 	existingPaymentMethod, err := h.service.GetPaymentMethodByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if existingPaymentMethod == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Método de pago no encontrado"})
+		c.Error(domain.NewNotFoundError("payment_method", "Método de pago no encontrado"))
 		return
 	}
 
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists || existingPaymentMethod.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para eliminar este método de pago"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tienes permiso para eliminar este método de pago"))
 		return
 	}
 
 	if err := h.service.DeletePaymentMethod(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"MyMoneyBackend/internal/application/plan"
 	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 )
 
 // Handler maneja las solicitudes HTTP relacionadas con los planes
@@ -51,19 +52,37 @@ func mapFeaturesToResponse(features []domain.PlanFeature) []domain.PlanFeatureRe
 
 // mapPlanToPlanResponse convierte un objeto Plan a PlanResponse
 func mapPlanToPlanResponse(plan *domain.Plan) domain.PlanResponse {
+	var supersededAt *string
+	if plan.SupersededAt != nil {
+		formatted := plan.SupersededAt.Format("2006-01-02T15:04:05Z07:00")
+		supersededAt = &formatted
+	}
+	var archivedAt *string
+	if plan.ArchivedAt != nil {
+		formatted := plan.ArchivedAt.Format("2006-01-02T15:04:05Z07:00")
+		archivedAt = &formatted
+	}
+
 	return domain.PlanResponse{
-		ID:          plan.ID,
-		Name:        plan.Name,
-		Description: plan.Description,
-		Price:       plan.Price,
-		CurrencyID:  plan.CurrencyID,
-		Interval:    string(plan.Interval),
-		Features:    mapFeaturesToResponse(plan.Features),
-		IsActive:    plan.IsActive,
-		IsPublic:    plan.IsPublic,
-		SortOrder:   plan.SortOrder,
-		CreatedAt:   plan.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   plan.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:            plan.ID,
+		FamilyID:      plan.FamilyID,
+		Version:       plan.Version,
+		SupersededAt:  supersededAt,
+		ArchivedAt:    archivedAt,
+		Name:          plan.Name,
+		Description:   plan.Description,
+		Price:         plan.Price,
+		CurrencyID:    plan.CurrencyID,
+		Interval:      string(plan.Interval),
+		IntervalCount: plan.IntervalCount,
+		Features:      mapFeaturesToResponse(plan.Features),
+		TrialDays:     plan.TrialDays,
+		Entitlements:  plan.Entitlements,
+		IsActive:      plan.IsActive,
+		IsPublic:      plan.IsPublic,
+		SortOrder:     plan.SortOrder,
+		CreatedAt:     plan.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     plan.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
@@ -80,7 +99,7 @@ func (h *Handler) GetAllPlans(c *gin.Context) {
 
 	plans, err := h.service.GetAllPlans(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener planes: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -105,7 +124,7 @@ func (h *Handler) GetPublicPlans(c *gin.Context) {
 
 	plans, err := h.service.GetPublicPlans(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener planes públicos: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -130,7 +149,7 @@ func (h *Handler) GetActivePlans(c *gin.Context) {
 
 	plans, err := h.service.GetActivePlans(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener planes activos: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -150,7 +169,7 @@ func (h *Handler) GetActivePlans(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID del plan"
 // @Success 200 {object} domain.PlanResponse
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} middleware.Problem
 // @Router /plans/{id} [get]
 func (h *Handler) GetPlanByID(c *gin.Context) {
 	id := c.Param("id")
@@ -158,7 +177,7 @@ func (h *Handler) GetPlanByID(c *gin.Context) {
 
 	plan, err := h.service.GetPlanByID(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Plan no encontrado: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -174,26 +193,21 @@ func (h *Handler) GetPlanByID(c *gin.Context) {
 // @Param plan body domain.CreatePlanRequest true "Datos del plan"
 // @Security Bearer
 // @Success 201 {object} domain.PlanResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 409 {object} middleware.Problem
 // @Router /plans [post]
 func (h *Handler) CreatePlan(c *gin.Context) {
 	var request domain.CreatePlanRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Convertir el intervalo
-	var interval domain.PlanInterval
-	switch request.Interval {
-	case "monthly":
-		interval = domain.PlanIntervalMonthly
-	case "yearly":
-		interval = domain.PlanIntervalYearly
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Intervalo de facturación inválido. Debe ser 'monthly' o 'yearly'"})
+	interval, intervalCount, err := domain.ParsePlanInterval(request.Interval, request.IntervalCount)
+	if err != nil {
+		c.Error(domain.NewValidationError("interval", "Intervalo de facturación inválido. Debe ser day, week, month, year, lifetime o un alias como 'monthly', 'quarterly' o 'yearly'"))
 		return
 	}
 
@@ -208,14 +222,17 @@ func (h *Handler) CreatePlan(c *gin.Context) {
 		request.Price,
 		request.CurrencyID,
 		interval,
+		intervalCount,
 		features,
+		request.TrialDays,
+		request.Entitlements,
 		request.IsActive,
 		request.IsPublic,
 		request.SortOrder,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al crear plan: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -223,37 +240,32 @@ func (h *Handler) CreatePlan(c *gin.Context) {
 }
 
 // UpdatePlan godoc
-// @Summary Actualizar un plan existente
-// @Description Actualiza los datos de un plan existente basado en su ID
+// @Summary Publicar una nueva versión de un plan
+// @Description Crea una nueva versión inmutable del plan con los datos indicados. Los suscriptores existentes conservan la versión que contrataron hasta que se los migre.
 // @Tags plans
 // @Accept json
 // @Produce json
 // @Param id path string true "ID del plan"
 // @Param plan body domain.UpdatePlanRequest true "Datos actualizados del plan"
 // @Security Bearer
-// @Success 200 {object} domain.PlanResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Success 201 {object} domain.PlanResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /plans/{id} [put]
 func (h *Handler) UpdatePlan(c *gin.Context) {
 	id := c.Param("id")
 	var request domain.UpdatePlanRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Convertir el intervalo
-	var interval domain.PlanInterval
-	switch request.Interval {
-	case "monthly":
-		interval = domain.PlanIntervalMonthly
-	case "yearly":
-		interval = domain.PlanIntervalYearly
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Intervalo de facturación inválido. Debe ser 'monthly' o 'yearly'"})
+	interval, intervalCount, err := domain.ParsePlanInterval(request.Interval, request.IntervalCount)
+	if err != nil {
+		c.Error(domain.NewValidationError("interval", "Intervalo de facturación inválido. Debe ser day, week, month, year, lifetime o un alias como 'monthly', 'quarterly' o 'yearly'"))
 		return
 	}
 
@@ -269,18 +281,125 @@ func (h *Handler) UpdatePlan(c *gin.Context) {
 		request.Price,
 		request.CurrencyID,
 		interval,
+		intervalCount,
 		features,
+		request.TrialDays,
+		request.Entitlements,
 		request.IsActive,
 		request.IsPublic,
 		request.SortOrder,
 	)
 
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "plan no encontrado" || err.Error() == "plan no encontrado con id: "+id {
-			statusCode = http.StatusNotFound
-		}
-		c.JSON(statusCode, gin.H{"error": "Error al actualizar plan: " + err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapPlanToPlanResponse(plan))
+}
+
+// GetPlanVersions godoc
+// @Summary Obtener las versiones de un plan
+// @Description Retorna todas las versiones de la familia del plan, de la más nueva a la más antigua
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de cualquier versión del plan"
+// @Success 200 {array} domain.PlanResponse
+// @Failure 404 {object} middleware.Problem
+// @Router /plans/{id}/versions [get]
+func (h *Handler) GetPlanVersions(c *gin.Context) {
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	plans, err := h.service.GetPlanVersions(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]domain.PlanResponse, len(plans))
+	for i, plan := range plans {
+		response[i] = mapPlanToPlanResponse(plan)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPlanUsage godoc
+// @Summary Obtener suscriptores por plan
+// @Description Retorna la cantidad de suscripciones activas y totales de cada versión de plan
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.PlanUsage
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Router /plans/usage [get]
+func (h *Handler) GetPlanUsage(c *gin.Context) {
+	usage, err := h.service.GetPlanUsage(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// ArchivePlan godoc
+// @Summary Archivar un plan
+// @Description Archiva todas las versiones del plan: deja de ofrecerse y los suscriptores actuales lo conservan
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del plan"
+// @Security Bearer
+// @Success 200 {object} domain.PlanResponse
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "El plan ya está archivado"
+// @Router /plans/{id}/archive [post]
+func (h *Handler) ArchivePlan(c *gin.Context) {
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	plan, err := h.service.ArchivePlan(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapPlanToPlanResponse(plan))
+}
+
+// UnarchivePlan godoc
+// @Summary Desarchivar un plan
+// @Description Vuelve a ofrecer un plan archivado: su versión vigente queda activa y, si se indica, pública
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del plan"
+// @Param request body domain.UnarchivePlanRequest true "Visibilidad del plan"
+// @Security Bearer
+// @Success 200 {object} domain.PlanResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "El plan no está archivado o su moneda está archivada"
+// @Router /plans/{id}/unarchive [post]
+func (h *Handler) UnarchivePlan(c *gin.Context) {
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	var req domain.UnarchivePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	plan, err := h.service.UnarchivePlan(ctx, id, *req.IsPublic)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -289,22 +408,23 @@ func (h *Handler) UpdatePlan(c *gin.Context) {
 
 // DeletePlan godoc
 // @Summary Eliminar un plan
-// @Description Elimina un plan basado en su ID
+// @Description Elimina un plan y todas sus versiones si nunca tuvo suscripciones. Los planes con suscripciones deben archivarse.
 // @Tags plans
 // @Accept json
 // @Produce json
 // @Param id path string true "ID del plan"
 // @Security Bearer
 // @Success 204 "No Content"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "El plan tiene suscripciones"
 // @Router /plans/{id} [delete]
 func (h *Handler) DeletePlan(c *gin.Context) {
 	id := c.Param("id")
 	ctx := c.Request.Context()
 
 	if err := h.service.DeletePlan(ctx, id); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Error al eliminar plan: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
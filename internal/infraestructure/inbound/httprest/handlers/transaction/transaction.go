@@ -1,11 +1,14 @@
 package api
 
 import (
+	"encoding/csv"
 	"net/http"
+	"strconv"
 	"time"
 
 	transaction "MyMoneyBackend/internal/application/transaction"
 	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -38,19 +41,20 @@ func NewTransactionHandler(transactionService *transaction.Service) *Transaction
 // @Security Bearer
 // @Param transaction body domain.CreateTransactionRequest true "Datos de la transacción a crear"
 // @Success 201 {object} domain.Transaction
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
 // @Router /api/transactions [post]
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req domain.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
@@ -74,7 +78,7 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		req.Type,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -88,19 +92,19 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {array} domain.Transaction
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /api/transactions [get]
 func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	transactions, err := h.transactionService.GetTransactionsByUserID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error retrieving transactions"})
+		c.Error(err)
 		return
 	}
 
@@ -115,26 +119,26 @@ func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 // @Security Bearer
 // @Param id path string true "ID de la transacción"
 // @Success 200 {object} domain.Transaction
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /api/transactions/{id} [get]
 func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID is required"})
+		c.Error(domain.NewValidationError("id", "transaction ID is required"))
 		return
 	}
 
 	transaction, err := h.transactionService.GetTransactionByID(c.Request.Context(), transactionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		c.Error(err)
 		return
 	}
 
@@ -151,26 +155,26 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 // @Param id path string true "ID de la transacción"
 // @Param transaction body domain.UpdateTransactionRequest true "Datos de la transacción a actualizar"
 // @Success 200 {object} domain.Transaction
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /api/transactions/{id} [put]
 func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID is required"})
+		c.Error(domain.NewValidationError("id", "transaction ID is required"))
 		return
 	}
 
 	var req domain.UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
@@ -195,7 +199,7 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 		req.Type,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -209,25 +213,25 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 // @Security Bearer
 // @Param id path string true "ID de la transacción"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /api/transactions/{id} [delete]
 func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "transaction ID is required"})
+		c.Error(domain.NewValidationError("id", "transaction ID is required"))
 		return
 	}
 
 	err := h.transactionService.DeleteTransaction(c.Request.Context(), transactionID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -242,25 +246,25 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 // @Security Bearer
 // @Param categoryId path string true "ID de la categoría"
 // @Success 200 {array} domain.Transaction
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /api/transactions/category/{categoryId} [get]
 func (h *TransactionHandler) GetTransactionsByCategory(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	categoryID := c.Param("categoryId")
 	if categoryID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "category ID is required"})
+		c.Error(domain.NewValidationError("category_id", "category ID is required"))
 		return
 	}
 
 	transactions, err := h.transactionService.GetTransactionsByCategoryID(c.Request.Context(), categoryID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -276,32 +280,32 @@ func (h *TransactionHandler) GetTransactionsByCategory(c *gin.Context) {
 // @Param start_date query string true "Fecha de inicio (formato YYYY-MM-DD)"
 // @Param end_date query string true "Fecha de fin (formato YYYY-MM-DD)"
 // @Success 200 {array} domain.Transaction
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /api/transactions/date-range [get]
 func (h *TransactionHandler) GetTransactionsByDateRange(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req domain.DateRangeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date format, use YYYY-MM-DD"})
+		c.Error(domain.NewValidationError("start_date", "invalid start date format, use YYYY-MM-DD"))
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date format, use YYYY-MM-DD"})
+		c.Error(domain.NewValidationError("end_date", "invalid end date format, use YYYY-MM-DD"))
 		return
 	}
 
@@ -310,9 +314,70 @@ func (h *TransactionHandler) GetTransactionsByDateRange(c *gin.Context) {
 
 	transactions, err := h.transactionService.GetTransactionsByDateRange(c.Request.Context(), userID, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error retrieving transactions"})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, transactions)
 }
+
+// ExportTransactions exports all transactions of the current user
+// @Summary Exportar transacciones
+// @Description Exporta todas las transacciones del usuario autenticado en un formato permitido por su plan
+// @Tags transactions
+// @Produce json
+// @Produce text/csv
+// @Security Bearer
+// @Param format query string false "Formato de exportación (csv, json)" default(csv)
+// @Success 200 {array} domain.Transaction
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /api/transactions/export [get]
+func (h *TransactionHandler) ExportTransactions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	format := domain.ExportFormat(c.DefaultQuery("format", string(domain.ExportFormatCSV)))
+	if !format.IsValid() {
+		c.Error(domain.NewValidationError("format", "invalid format, use csv or json"))
+		return
+	}
+
+	transactions, err := h.transactionService.ExportTransactions(c.Request.Context(), userID, format)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := "transactions-" + time.Now().Format("2006-01-02") + "." + string(format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if format == domain.ExportFormatJSON {
+		c.JSON(http.StatusOK, transactions)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"id", "date", "type", "amount", "currency_id", "category_id", "payment_method_id", "description"})
+	for _, t := range transactions {
+		_ = writer.Write([]string{
+			t.ID,
+			t.Date.Format("2006-01-02"),
+			string(t.Type),
+			strconv.FormatFloat(t.Amount, 'f', 2, 64),
+			t.CurrencyID,
+			t.CategoryID,
+			t.PaymentMethodID,
+			t.Description,
+		})
+	}
+	writer.Flush()
+}
@@ -5,6 +5,8 @@ import (
 
 	"MyMoneyBackend/internal/application/auth"
 	services "MyMoneyBackend/internal/application/user"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -80,26 +82,26 @@ type RefreshTokenResponse struct {
 // @Produce json
 // @Param user body RegisterRequest true "Datos del usuario"
 // @Success 201 {object} LoginResponse
-// @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /auth/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
-	user, err := h.userService.RegisterUser(req.Email, req.Name, req.Password)
+	user, err := h.userService.RegisterUser(c.Request.Context(), req.Email, req.Name, req.Password)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Generate JWT token pair
 	tokenPair, err := h.authService.GenerateTokenPair(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error generating token"})
+		c.Error(err)
 		return
 	}
 
@@ -122,26 +124,26 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Produce json
 // @Param credentials body LoginRequest true "Access credentials"
 // @Success 200 {object} LoginResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
-	user, err := h.userService.AuthenticateUser(req.Email, req.Password)
+	user, err := h.userService.AuthenticateUser(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+		c.Error(err)
 		return
 	}
 
 	// Generate JWT token pair
 	tokenPair, err := h.authService.GenerateTokenPair(user.ID, user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error generating token"})
+		c.Error(err)
 		return
 	}
 
@@ -164,19 +166,19 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {object} UserResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /users/me [get]
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(err)
 		return
 	}
 
@@ -196,27 +198,27 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 // @Param userData body UpdateUserRequest true "Updated user data"
 // @Security Bearer
 // @Success 200 {object} UserResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /users/update [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
-	user, err := h.userService.UpdateUser(userID, req.Email, req.Name)
+	user, err := h.userService.UpdateUser(c.Request.Context(), userID, req.Email, req.Name)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -236,26 +238,26 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Param passwordData body ChangePasswordRequest true "Password data"
 // @Security Bearer
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /users/change-password [put]
 func (h *UserHandler) ChangePassword(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
-	err := h.userService.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
+	err := h.userService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -270,20 +272,20 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 // @Produce json
 // @Param refreshToken body RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} RefreshTokenResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /auth/refresh-token [post]
 func (h *UserHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Generate new access token
 	newAccessToken, err := h.authService.RefreshAccessToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		c.Error(err)
 		return
 	}
 
@@ -2,10 +2,12 @@ package user_subscription
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"MyMoneyBackend/internal/application/invoice"
 	"MyMoneyBackend/internal/application/user_subscription"
 	"MyMoneyBackend/internal/domain"
 	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
@@ -13,13 +15,15 @@ import (
 
 // Handler maneja las solicitudes HTTP relacionadas con suscripciones de usuarios
 type Handler struct {
-	service *user_subscription.Service
+	service        *user_subscription.Service
+	invoiceService *invoice.Service
 }
 
 // NewUserSubscriptionHandler crea una nueva instancia del controlador de suscripciones
-func NewUserSubscriptionHandler(service *user_subscription.Service) *Handler {
+func NewUserSubscriptionHandler(service *user_subscription.Service, invoiceService *invoice.Service) *Handler {
 	return &Handler{
-		service: service,
+		service:        service,
+		invoiceService: invoiceService,
 	}
 }
 
@@ -32,34 +36,30 @@ func NewUserSubscriptionHandler(service *user_subscription.Service) *Handler {
 // @Param subscription body domain.CreateSubscriptionRequest true "Datos de la suscripción"
 // @Security Bearer
 // @Success 201 {object} domain.SubscriptionResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 402 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /subscriptions [post]
 func (h *Handler) CreateSubscription(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	var req domain.CreateSubscriptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos de solicitud inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
-	// Si no se proporcionan fechas, establecer valores predeterminados
-	now := time.Now()
+	// Si no se proporciona la fecha de inicio, la suscripción comienza ahora.
+	// Sin fecha de fin el servicio usa el intervalo de facturación del plan.
 	if req.StartDate.IsZero() {
-		req.StartDate = now
-	}
-
-	if req.EndDate.IsZero() {
-		// Por defecto, la suscripción dura un mes
-		req.EndDate = now.AddDate(0, 1, 0)
+		req.StartDate = time.Now()
 	}
 
 	// Crear la suscripción
@@ -70,11 +70,12 @@ func (h *Handler) CreateSubscription(c *gin.Context) {
 		req.StartDate,
 		req.EndDate,
 		req.PaymentMethodID,
+		req.CouponCode,
 		req.Metadata,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al crear suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -86,19 +87,19 @@ func (h *Handler) GetActiveSubscription(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener la suscripción activa
 	subscription, err := h.service.GetActiveSubscription(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if subscription == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No hay suscripción activa"})
+		c.Error(domain.NewNotFoundError("subscription", "No hay suscripción activa"))
 		return
 	}
 
@@ -113,20 +114,20 @@ func (h *Handler) GetActiveSubscription(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {array} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
 // @Router /subscriptions [get]
 func (h *Handler) GetUserSubscriptions(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener las suscripciones
 	subscriptions, err := h.service.GetUserSubscriptions(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripciones: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -148,40 +149,93 @@ func (h *Handler) GetUserSubscriptions(c *gin.Context) {
 // @Param id path string true "ID de la suscripción"
 // @Security Bearer
 // @Success 200 {object} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /subscriptions/{id} [get]
 func (h *Handler) GetSubscriptionByID(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener el ID de la suscripción
 	subscriptionID := c.Param("id")
 	if subscriptionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de suscripción no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
 		return
 	}
 
 	// Obtener la suscripción
 	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Verificar que la suscripción pertenezca al usuario
 	if subscription.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tiene permiso para acceder a esta suscripción"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para acceder a esta suscripción"))
 		return
 	}
 
 	c.JSON(http.StatusOK, domain.MapSubscriptionToResponse(subscription))
 }
 
+// GetInvoices godoc
+// @Summary Obtener facturas de una suscripción
+// @Description Retorna el historial de facturas (cobros, prorrateos y reembolsos) de una suscripción del usuario autenticado
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la suscripción"
+// @Security Bearer
+// @Success 200 {array} domain.Invoice
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /subscriptions/{id}/invoices [get]
+func (h *Handler) GetInvoices(c *gin.Context) {
+	// Obtener el usuario del contexto
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	// Obtener el ID de la suscripción
+	subscriptionID := c.Param("id")
+	if subscriptionID == "" {
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
+		return
+	}
+
+	// Obtener la suscripción para verificar pertenencia
+	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Verificar que la suscripción pertenezca al usuario
+	if subscription.UserID != userID.(string) {
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para acceder a esta suscripción"))
+		return
+	}
+
+	invoices, err := h.invoiceService.GetSubscriptionInvoices(c.Request.Context(), subscriptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if invoices == nil {
+		invoices = []*domain.Invoice{}
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
 // CancelSubscription godoc
 // @Summary Cancelar una suscripción
 // @Description Cancela una suscripción activa
@@ -191,35 +245,35 @@ func (h *Handler) GetSubscriptionByID(c *gin.Context) {
 // @Param id path string true "ID de la suscripción"
 // @Security Bearer
 // @Success 200 {object} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /subscriptions/{id}/cancel [put]
 func (h *Handler) CancelSubscription(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener el ID de la suscripción
 	subscriptionID := c.Param("id")
 	if subscriptionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de suscripción no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
 		return
 	}
 
 	// Obtener la suscripción para verificar pertenencia
 	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Verificar que la suscripción pertenezca al usuario
 	if subscription.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tiene permiso para cancelar esta suscripción"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para cancelar esta suscripción"))
 		return
 	}
 
@@ -233,7 +287,7 @@ func (h *Handler) CancelSubscription(c *gin.Context) {
 	// Cancelar la suscripción
 	err = h.service.CancelSubscription(c.Request.Context(), subscriptionID, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al cancelar suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -245,87 +299,155 @@ func (h *Handler) ChangePlan(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener el ID de la suscripción
 	subscriptionID := c.Param("id")
 	if subscriptionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de suscripción no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
 		return
 	}
 
 	// Obtener la suscripción para verificar pertenencia
 	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Verificar que la suscripción pertenezca al usuario
 	if subscription.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tiene permiso para modificar esta suscripción"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para modificar esta suscripción"))
 		return
 	}
 
 	// Parsear la solicitud de cambio de plan
 	var req domain.ChangePlanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos de solicitud inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
 		return
 	}
 
 	// Cambiar el plan
-	updatedSubscription, err := h.service.ChangeSubscriptionPlan(c.Request.Context(), subscriptionID, req.PlanID)
+	updatedSubscription, err := h.service.ChangeSubscriptionPlan(c.Request.Context(), subscriptionID, req.PlanID, req.Billing, req.CouponCode)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al cambiar plan: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, domain.MapSubscriptionToResponse(updatedSubscription))
 }
 
+// PreviewPlanChange muestra el prorrateo de un cambio de plan sin aplicarlo
+// @Summary Previsualizar cambio de plan
+// @Description Calcula el crédito, el costo y el monto a cobrar de un cambio de plan sin aplicarlo
+// @Tags suscripciones
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID de la suscripción"
+// @Param plan_id query string true "ID del nuevo plan"
+// @Param billing query string false "Modo de cobro: immediate (por defecto) o next_renewal"
+// @Success 200 {object} domain.ProrationPreview
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /subscriptions/{id}/plan/preview [get]
+func (h *Handler) PreviewPlanChange(c *gin.Context) {
+	// Obtener el usuario del contexto
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.Error(domain.ErrUnauthenticated)
+		return
+	}
+
+	// Obtener el ID de la suscripción
+	subscriptionID := c.Param("id")
+	if subscriptionID == "" {
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
+		return
+	}
+
+	req := domain.ChangePlanRequest{
+		PlanID:  c.Query("plan_id"),
+		Billing: domain.ProrationBilling(c.Query("billing")),
+	}
+	if req.PlanID == "" {
+		c.Error(domain.NewValidationError("plan_id", "ID del nuevo plan no proporcionado"))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Obtener la suscripción para verificar pertenencia
+	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Verificar que la suscripción pertenezca al usuario
+	if subscription.UserID != userID.(string) {
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para acceder a esta suscripción"))
+		return
+	}
+
+	preview, err := h.service.PreviewPlanChange(c.Request.Context(), subscriptionID, req.PlanID, req.Billing)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 // UpdatePaymentMethod actualiza el método de pago de una suscripción
 func (h *Handler) UpdatePaymentMethod(c *gin.Context) {
 	// Obtener el usuario del contexto
 	userID, exists := c.Get(middleware.UserIDKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Error(domain.ErrUnauthenticated)
 		return
 	}
 
 	// Obtener el ID de la suscripción
 	subscriptionID := c.Param("id")
 	if subscriptionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de suscripción no proporcionado"})
+		c.Error(domain.NewValidationError("id", "ID de suscripción no proporcionado"))
 		return
 	}
 
 	// Obtener la suscripción para verificar pertenencia
 	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripción: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Verificar que la suscripción pertenezca al usuario
 	if subscription.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "No tiene permiso para modificar esta suscripción"})
+		c.Error(domain.NewForbiddenError("access_denied", "No tiene permiso para modificar esta suscripción"))
 		return
 	}
 
 	// Parsear la solicitud
 	var req domain.UpdateSubscriptionPaymentMethodRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos de solicitud inválidos: " + err.Error()})
+		c.Error(middleware.BindingError(err))
 		return
 	}
 
 	// Actualizar el método de pago
 	updatedSubscription, err := h.service.UpdatePaymentMethod(c.Request.Context(), subscriptionID, req.PaymentMethodID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al actualizar método de pago: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -340,28 +462,28 @@ func (h *Handler) UpdatePaymentMethod(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Success 200 {array} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
 // @Router /admin/subscriptions [get]
 func (h *Handler) ListSubscriptionsByStatus(c *gin.Context) {
 	// Verificar si es administrador
 	isAdmin, exists := c.Get(middleware.IsAdminKey)
 	if !exists || !isAdmin.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado. Se requieren permisos de administrador"})
+		c.Error(domain.NewForbiddenError("admin_required", "Acceso denegado. Se requieren permisos de administrador"))
 		return
 	}
 
 	// Obtener el estado de la consulta
 	status := c.Query("status")
 	if status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro de estado requerido"})
+		c.Error(domain.NewValidationError("status", "Parámetro de estado requerido"))
 		return
 	}
 
 	// Obtener las suscripciones
 	subscriptions, err := h.service.GetSubscriptionsByStatus(c.Request.Context(), domain.SubscriptionStatus(status))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripciones: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -379,7 +501,7 @@ func (h *Handler) GetExpiringSubscriptions(c *gin.Context) {
 	// Verificar si es administrador
 	isAdmin, exists := c.Get(middleware.IsAdminKey)
 	if !exists || !isAdmin.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado. Se requieren permisos de administrador"})
+		c.Error(domain.NewForbiddenError("admin_required", "Acceso denegado. Se requieren permisos de administrador"))
 		return
 	}
 
@@ -390,7 +512,7 @@ func (h *Handler) GetExpiringSubscriptions(c *gin.Context) {
 	// Obtener las suscripciones que expirarán pronto
 	subscriptions, err := h.service.GetExpiringSubscriptions(c.Request.Context(), days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener suscripciones: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -403,12 +525,71 @@ func (h *Handler) GetExpiringSubscriptions(c *gin.Context) {
 	c.JSON(http.StatusOK, responses)
 }
 
+// NotifyExpiringSubscriptions avisa a los usuarios con suscripciones por expirar (solo para administradores)
+func (h *Handler) NotifyExpiringSubscriptions(c *gin.Context) {
+	// Verificar si es administrador
+	isAdmin, exists := c.Get(middleware.IsAdminKey)
+	if !exists || !isAdmin.(bool) {
+		c.Error(domain.NewForbiddenError("admin_required", "Acceso denegado. Se requieren permisos de administrador"))
+		return
+	}
+
+	// Parámetro de días (predeterminado: 7 días)
+	days := 7
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(domain.NewValidationError("days", "El parámetro days debe ser un entero positivo"))
+			return
+		}
+		days = parsed
+	}
+
+	notified, err := h.service.NotifyExpiringSubscriptions(c.Request.Context(), days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notified": notified})
+}
+
+// MigratePlanCohort programa la migración de los suscriptores de una versión de plan a otra (solo para administradores)
+func (h *Handler) MigratePlanCohort(c *gin.Context) {
+	// Verificar si es administrador
+	isAdmin, exists := c.Get(middleware.IsAdminKey)
+	if !exists || !isAdmin.(bool) {
+		c.Error(domain.NewForbiddenError("admin_required", "Acceso denegado. Se requieren permisos de administrador"))
+		return
+	}
+
+	var request domain.PlanMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(middleware.BindingError(err))
+		return
+	}
+
+	result, err := h.service.MigratePlanCohort(
+		c.Request.Context(),
+		request.FromPlanID,
+		request.ToPlanID,
+		request.NoticeDays,
+		time.Now(),
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // GetPendingRenewals obtiene suscripciones pendientes de renovación (solo para administradores)
 func (h *Handler) GetPendingRenewals(c *gin.Context) {
 	// Verificar si es administrador
 	isAdmin, exists := c.Get(middleware.IsAdminKey)
 	if !exists || !isAdmin.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado. Se requieren permisos de administrador"})
+		c.Error(domain.NewForbiddenError("admin_required", "Acceso denegado. Se requieren permisos de administrador"))
 		return
 	}
 
@@ -419,7 +600,7 @@ func (h *Handler) GetPendingRenewals(c *gin.Context) {
 	// Obtener las suscripciones pendientes de renovación
 	subscriptions, err := h.service.GetPendingRenewals(c.Request.Context(), days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener renovaciones pendientes: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -441,9 +622,9 @@ func (h *Handler) GetPendingRenewals(c *gin.Context) {
 // @Param status path string true "Estado de suscripción (active, expired, cancelled)"
 // @Security Bearer
 // @Success 200 {array} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 400 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 400 {object} middleware.Problem
 // @Router /admin/subscriptions/status/{status} [get]
 func (h *Handler) GetSubscriptionsByStatus(c *gin.Context) {
 	// ... existing code ...
@@ -458,9 +639,9 @@ func (h *Handler) GetSubscriptionsByStatus(c *gin.Context) {
 // @Param id path string true "ID de la suscripción"
 // @Security Bearer
 // @Success 200 {object} domain.SubscriptionResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /subscriptions/{id}/renew [put]
 func (h *Handler) RenewSubscription(c *gin.Context) {
 	// ... existing code ...
@@ -23,7 +23,7 @@ func (m *AdminMiddleware) RequireAdmin() gin.HandlerFunc {
 
 		_, exists := c.Get(UserIDKey)
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+			abortWithProblem(c, newProblem(http.StatusUnauthorized, CodeUnauthorized, "Usuario no autenticado"))
 			return
 		}
 
@@ -12,12 +12,16 @@ import (
 // AuthMiddleware handles authentication
 type AuthMiddleware struct {
 	tokenService *auth.TokenService
+	afterAuth    []gin.HandlerFunc
 }
 
-// NewAuthMiddleware creates a new AuthMiddleware
-func NewAuthMiddleware(tokenService *auth.TokenService) *AuthMiddleware {
+// NewAuthMiddleware creates a new AuthMiddleware. The afterAuth handlers run once
+// the user is identified, before the route handler (e.g. per-user rate limits);
+// they stop the request by aborting it.
+func NewAuthMiddleware(tokenService *auth.TokenService, afterAuth ...gin.HandlerFunc) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenService: tokenService,
+		afterAuth:    afterAuth,
 	}
 }
 
@@ -27,14 +31,14 @@ func (m *AuthMiddleware) Authorize() gin.HandlerFunc {
 		// Get authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
+			abortWithProblem(c, newProblem(http.StatusUnauthorized, CodeUnauthorized, "authorization header is required"))
 			return
 		}
 
 		// Check for Bearer prefix
 		parts := strings.SplitN(authHeader, " ", 2)
 		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization header format must be Bearer {token}"})
+			abortWithProblem(c, newProblem(http.StatusUnauthorized, CodeUnauthorized, "authorization header format must be Bearer {token}"))
 			return
 		}
 
@@ -42,7 +46,7 @@ func (m *AuthMiddleware) Authorize() gin.HandlerFunc {
 		tokenString := parts[1]
 		claims, err := m.tokenService.ValidateToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			abortWithProblem(c, newProblem(http.StatusUnauthorized, CodeUnauthorized, err.Error()))
 			return
 		}
 
@@ -50,6 +54,12 @@ func (m *AuthMiddleware) Authorize() gin.HandlerFunc {
 		c.Set(UserIDKey, claims.UserID)
 		c.Set("email", claims.Email)
 
+		for _, handler := range m.afterAuth {
+			if handler(c); c.IsAborted() {
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
@@ -7,4 +7,10 @@ const (
 
 	// IsAdminKey es la clave para el rol de administrador en el contexto
 	IsAdminKey = "is_admin"
+
+	// RequestIDKey es la clave para el ID de la petición en el contexto
+	RequestIDKey = "request_id"
+
+	// RequestIDHeader es la cabecera con la que se recibe y se devuelve el ID de la petición
+	RequestIDHeader = "X-Request-ID"
 )
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// originWildcard es lo que puede sustituir un * en un patrón de origen: una o más etiquetas de
+// dominio (https://*.example.com) o un puerto (http://localhost:*)
+const originWildcard = `[a-z0-9-]+(?:\.[a-z0-9-]+)*`
+
+// CORSMiddleware aplica la política CORS de la configuración
+type CORSMiddleware struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    []*regexp.Regexp
+	credentials bool
+	methods     map[string]bool
+	headers     map[string]bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// NewCORSMiddleware crea un nuevo CORSMiddleware. config debe haberse validado antes.
+func NewCORSMiddleware(config domain.CORSConfig) *CORSMiddleware {
+	m := &CORSMiddleware{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		credentials:   config.AllowCredentials,
+		allowMethods:  strings.Join(config.AllowedMethods, ", "),
+		allowHeaders:  strings.Join(config.AllowedHeaders, ", "),
+		exposeHeaders: strings.Join(config.ExposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(config.MaxAge.Seconds())),
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			m.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			pattern := strings.ReplaceAll(regexp.QuoteMeta(origin), `\*`, originWildcard)
+			m.patterns = append(m.patterns, regexp.MustCompile("^"+pattern+"$"))
+		default:
+			m.origins[origin] = true
+		}
+	}
+	for _, method := range config.AllowedMethods {
+		m.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range config.AllowedHeaders {
+		m.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return m
+}
+
+// Handle es el middleware CORS. Responde las peticiones preflight (204 si se permiten, 403 si no)
+// sin llegar a las rutas; en el resto añade las cabeceras CORS si el origen está permitido y deja
+// que el navegador bloquee la respuesta si no lo está.
+func (m *CORSMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
+		// La respuesta depende del origen: las cachés no deben compartirla entre orígenes
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := m.allowOrigin(origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if m.anyOrigin && !m.credentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if m.credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if m.exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", m.exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		if !m.allowPreflight(c.GetHeader("Access-Control-Request-Method"), c.GetHeader("Access-Control-Request-Headers")) {
+			header.Del("Access-Control-Allow-Origin")
+			header.Del("Access-Control-Allow-Credentials")
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", m.allowMethods)
+		if m.allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", m.allowHeaders)
+		}
+		header.Set("Access-Control-Max-Age", m.maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// allowOrigin indica si origin está en la lista, coincide con un patrón o se permite cualquiera
+func (m *CORSMiddleware) allowOrigin(origin string) bool {
+	if m.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if m.origins[origin] {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowPreflight indica si se permiten el método y todas las cabeceras pedidas en el preflight
+func (m *CORSMiddleware) allowPreflight(method, headers string) bool {
+	if !m.methods[strings.ToUpper(method)] {
+		return false
+	}
+	for _, requested := range strings.Split(headers, ",") {
+		requested = strings.TrimSpace(requested)
+		if requested != "" && !m.headers[http.CanonicalHeaderKey(requested)] {
+			return false
+		}
+	}
+	return true
+}
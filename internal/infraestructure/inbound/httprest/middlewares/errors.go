@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/go-playground/validator/v10"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// ProblemContentType es el tipo de contenido de las respuestas de error (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix antecede al código estable del error en el campo type del problema
+const ProblemTypePrefix = "urn:mymoney:problem:"
+
+// Códigos de los errores que no provienen del dominio
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeUnauthorized   = "unauthorized"
+	CodeRateLimited    = "rate_limited"
+	CodeInternal       = "internal_error"
+)
+
+// Problem es el cuerpo de una respuesta de error según RFC 7807. Code, Errors y Details son
+// miembros de extensión: el código estable, los campos no válidos y los datos del error de dominio
+// (límite del plan alcanzado, referencias que impiden eliminar un recurso).
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+	Details   any                 `json:"details,omitempty"`
+}
+
+// kindStatus traduce cada tipo de error de dominio a un código de estado HTTP
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindNotFound:      http.StatusNotFound,
+	domain.KindConflict:      http.StatusConflict,
+	domain.KindValidation:    http.StatusBadRequest,
+	domain.KindUnauthorized:  http.StatusUnauthorized,
+	domain.KindForbidden:     http.StatusForbidden,
+	domain.KindPlanLimit:     http.StatusForbidden,
+	domain.KindPaymentFailed: http.StatusPaymentRequired,
+}
+
+// ErrorHandler responde con un problema RFC 7807 el último error que los handlers registraron con
+// c.Error, si todavía no escribieron la respuesta. Los errores sin tipo de dominio son errores
+// internos: responden 500 sin detalle y RequestLogger los registra.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		abortWithProblem(c, NewProblem(c.Errors.Last().Err))
+	}
+}
+
+// NewProblem construye el problema que corresponde a err
+func NewProblem(err error) Problem {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return newProblem(http.StatusInternalServerError, CodeInternal, "error interno del servidor")
+	}
+
+	status, ok := kindStatus[domainErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	problem := newProblem(status, domainErr.Code, err.Error())
+	problem.Errors = domainErr.Fields
+
+	var upgradeErr *domain.UpgradeRequiredError
+	var inUseErr *domain.ResourceInUseError
+	switch {
+	case errors.As(err, &upgradeErr):
+		problem.Details = upgradeErr
+	case errors.As(err, &inUseErr):
+		problem.Details = inUseErr
+	}
+	return problem
+}
+
+// abortWithProblem escribe problem como respuesta application/problem+json y detiene la cadena
+func abortWithProblem(c *gin.Context, problem Problem) {
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString(RequestIDKey)
+
+	c.Header("Content-Type", ProblemContentType)
+	c.Render(problem.Status, render.JSON{Data: problem})
+	c.Abort()
+}
+
+// BindingError convierte un error al leer el cuerpo o la query en un error de validación. Los
+// errores del validador se devuelven campo por campo con el nombre en snake_case.
+func BindingError(err error) *domain.Error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return &domain.Error{Kind: domain.KindValidation, Code: CodeInvalidRequest, Message: err.Error()}
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, domain.FieldError{
+			Field:   snakeCase(fieldErr.Field()),
+			Message: fmt.Sprintf("no cumple la regla %s", fieldErr.Tag()),
+		})
+	}
+	return domain.NewValidationErrors(fields...)
+}
+
+// newProblem rellena los campos comunes de un problema
+func newProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   ProblemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// snakeCase convierte el nombre de un campo Go (CurrencyID) al de su JSON (currency_id)
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Separar al empezar una palabra: aB -> a_b y ABc -> a_bc
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger registra cada petición con su ruta, estado, latencia y usuario. Los errores del
+// servidor se registran como error y los del cliente como warn.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		// Ruta registrada (/api/users/:id) en lugar de la URL, para agrupar y no registrar IDs
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if userID, ok := c.Get(UserIDKey); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "petición HTTP", attrs...)
+	}
+}
+
+// Recovery convierte un panic en un problema 500 y lo registra en lugar de escribirlo en stderr
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic al atender la petición",
+			slog.Any("panic", recovered),
+			slog.String("route", c.FullPath()),
+		)
+		abortWithProblem(c, newProblem(http.StatusInternalServerError, CodeInternal, "error interno del servidor"))
+	})
+}
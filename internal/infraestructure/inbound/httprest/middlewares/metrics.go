@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMetrics registra la ruta, el estado y la latencia de cada petición
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// MetricsMiddleware mide las peticiones HTTP
+type MetricsMiddleware struct {
+	metrics HTTPMetrics
+}
+
+// NewMetricsMiddleware crea un nuevo MetricsMiddleware
+func NewMetricsMiddleware(metrics HTTPMetrics) *MetricsMiddleware {
+	return &MetricsMiddleware{metrics: metrics}
+}
+
+// Observe es un middleware que registra cada petición al terminar
+func (m *MetricsMiddleware) Observe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Las rutas no registradas comparten una serie para no crear una por URL
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// Cabeceras de límite de peticiones (borrador IETF RateLimit header fields)
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimitMiddleware limita las peticiones por IP o por usuario en cada grupo de rutas
+type RateLimitMiddleware struct {
+	store  app.RateLimitStore
+	config domain.RateLimitConfig
+}
+
+// NewRateLimitMiddleware crea un nuevo RateLimitMiddleware que cuenta las peticiones en store
+func NewRateLimitMiddleware(store app.RateLimitStore, config domain.RateLimitConfig) *RateLimitMiddleware {
+	return &RateLimitMiddleware{store: store, config: config}
+}
+
+// Auth limita las rutas de autenticación para frenar ataques de fuerza bruta
+func (m *RateLimitMiddleware) Auth() gin.HandlerFunc {
+	return m.Limit("auth", m.config.Auth)
+}
+
+// Login limita los intentos de inicio de sesión de cada cuenta, además del límite por IP de Auth,
+// para frenar los ataques que reparten los intentos contra un mismo email entre muchas IPs.
+// El email se normaliza y se guarda como hash; las peticiones sin email no se cuentan porque el
+// handler las rechaza sin comprobar contraseñas.
+func (m *RateLimitMiddleware) Login() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if email := loginEmail(c); email != "" {
+			m.take(c, "login:email:"+email, m.config.Login)
+		}
+	}
+}
+
+// Writes limita las peticiones que modifican datos. Debe ir después de la autenticación para
+// poder contar por usuario; las lecturas no se limitan.
+func (m *RateLimitMiddleware) Writes() gin.HandlerFunc {
+	limit := m.Limit("write", m.config.Write)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		limit(c)
+	}
+}
+
+// Limit devuelve un middleware que aplica rule al grupo de rutas group. Responde 429 con
+// Retry-After al superar el límite y añade las cabeceras RateLimit-* a todas las respuestas.
+// Si el almacén falla la petición se deja pasar: el limitador no debe tumbar la API.
+func (m *RateLimitMiddleware) Limit(group string, rule domain.RateLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.take(c, group+":"+rateLimitKey(c, rule.By), rule)
+	}
+}
+
+// take consume una petición de key. Si varios límites se aplican a la misma petición las
+// cabeceras RateLimit-* muestran el que tiene menos peticiones restantes.
+func (m *RateLimitMiddleware) take(c *gin.Context, key string, rule domain.RateLimitRule) {
+	ctx := c.Request.Context()
+	result, err := m.store.Take(ctx, key, rule)
+	if err != nil {
+		slog.WarnContext(ctx, "error en el limitador de peticiones, se permite la petición", "key", key, "error", err)
+		return
+	}
+
+	header := c.Writer.Header()
+	if remaining, err := strconv.Atoi(header.Get(RateLimitRemainingHeader)); err != nil || result.Remaining < remaining || !result.Allowed {
+		header.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		header.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		header.Set(RateLimitResetHeader, seconds(result.Reset))
+	}
+
+	if !result.Allowed {
+		header.Set("Retry-After", seconds(result.RetryAfter))
+		abortWithProblem(c, newProblem(http.StatusTooManyRequests, CodeRateLimited, "Demasiadas peticiones, inténtalo más tarde"))
+	}
+}
+
+// rateLimitKey identifica a quien hace la petición: el usuario autenticado si by es user,
+// o su IP si by es ip o no hay usuario
+func rateLimitKey(c *gin.Context, by string) string {
+	if by == "user" {
+		if userID := c.GetString(UserIDKey); userID != "" {
+			return "user:" + userID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// loginEmail devuelve el hash del email normalizado del cuerpo de la petición de inicio de sesión,
+// o "" si no tiene. Deja el cuerpo intacto para el handler.
+func loginEmail(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+// seconds redondea d hacia arriba a segundos enteros, como piden Retry-After y RateLimit-Reset
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/logging"
+)
+
+// validRequestID limita los IDs recibidos a caracteres seguros para logs y cabeceras
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestID acepta el ID de la cabecera X-Request-ID o genera uno nuevo, lo devuelve en la
+// respuesta y lo guarda en el contexto de la petición para que llegue a servicios y repositorios
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
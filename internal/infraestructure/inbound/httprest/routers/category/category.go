@@ -17,6 +17,7 @@ func SetupCategoryRoutes(router *gin.RouterGroup, categoryHandler *handler.Categ
 		categories.GET("", categoryHandler.GetUserCategories)
 		categories.GET("/:id", categoryHandler.GetCategory)
 		categories.PUT("/:id", categoryHandler.UpdateCategory)
+		categories.PUT("/:id/budget", categoryHandler.SetCategoryBudget)
 		categories.DELETE("/:id", categoryHandler.DeleteCategory)
 	}
 }
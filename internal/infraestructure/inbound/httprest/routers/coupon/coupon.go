@@ -0,0 +1,36 @@
+package coupon
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/coupon"
+)
+
+// SetupCouponRoutes configura las rutas para los cupones promocionales
+func SetupCouponRoutes(
+	router *gin.RouterGroup,
+	authMiddleware gin.HandlerFunc,
+	adminMiddleware gin.HandlerFunc,
+	handler *coupon.Handler,
+) {
+	couponRoutes := router.Group("/coupons")
+	{
+		// Rutas que requieren autenticación
+		authRoutes := couponRoutes.Group("")
+		authRoutes.Use(authMiddleware)
+		{
+			authRoutes.GET("/validate", handler.ValidateCoupon)
+		}
+
+		// Rutas administrativas
+		adminRoutes := couponRoutes.Group("/admin")
+		adminRoutes.Use(authMiddleware, adminMiddleware)
+		{
+			adminRoutes.GET("", handler.GetCoupons)
+			adminRoutes.POST("", handler.CreateCoupon)
+			adminRoutes.GET("/:id", handler.GetCouponByID)
+			adminRoutes.PUT("/:id", handler.UpdateCoupon)
+			adminRoutes.DELETE("/:id", handler.DeleteCoupon)
+		}
+	}
+}
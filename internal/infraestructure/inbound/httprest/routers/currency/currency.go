@@ -23,6 +23,7 @@ func SetupCurrencyRoutes(r *gin.RouterGroup, currencyHandler *handler.Handler, a
 		{
 			protected.POST("", currencyHandler.CreateCurrency)
 			protected.PUT("/:id", currencyHandler.UpdateCurrency)
+			protected.POST("/:id/archive", currencyHandler.ArchiveCurrency)
 			protected.DELETE("/:id", currencyHandler.DeleteCurrency)
 		}
 	}
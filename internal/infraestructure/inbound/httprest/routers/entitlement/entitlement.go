@@ -0,0 +1,20 @@
+package entitlement
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/entitlement"
+)
+
+// SetupEntitlementRoutes configura las rutas para los derechos de uso
+func SetupEntitlementRoutes(
+	router *gin.RouterGroup,
+	authMiddleware gin.HandlerFunc,
+	handler *entitlement.Handler,
+) {
+	entitlementRoutes := router.Group("/entitlements")
+	entitlementRoutes.Use(authMiddleware)
+	{
+		entitlementRoutes.GET("", handler.GetEntitlements)
+	}
+}
@@ -15,3 +15,9 @@ func SetupHealthRoutes(r *gin.RouterGroup, healthHandler *health.Handler) {
 		healthRoutes.GET("/check", healthHandler.Check)
 	}
 }
+
+// SetupProbeRoutes configura las sondas de vida y disponibilidad para el orquestador
+func SetupProbeRoutes(r *gin.RouterGroup, healthHandler *health.Handler) {
+	r.GET("/livez", healthHandler.Livez)
+	r.GET("/readyz", healthHandler.Readyz)
+}
@@ -0,0 +1,22 @@
+package invoice
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/invoice"
+)
+
+// SetupInvoiceRoutes configura las rutas para las facturas
+func SetupInvoiceRoutes(
+	router *gin.RouterGroup,
+	authMiddleware gin.HandlerFunc,
+	handler *invoice.Handler,
+) {
+	invoiceRoutes := router.Group("/invoices")
+	invoiceRoutes.Use(authMiddleware)
+	{
+		invoiceRoutes.GET("", handler.GetInvoices)
+		invoiceRoutes.GET("/:id", handler.GetInvoiceByID)
+		invoiceRoutes.GET("/:id/document", handler.RenderInvoice)
+	}
+}
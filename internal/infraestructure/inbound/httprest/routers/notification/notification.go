@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/notification"
+)
+
+// SetupNotificationRoutes configura las rutas para las notificaciones
+func SetupNotificationRoutes(
+	router *gin.RouterGroup,
+	authMiddleware gin.HandlerFunc,
+	adminMiddleware gin.HandlerFunc,
+	handler *notification.Handler,
+) {
+	notificationRoutes := router.Group("/notifications")
+	{
+		// Rutas que requieren autenticación
+		authRoutes := notificationRoutes.Group("")
+		authRoutes.Use(authMiddleware)
+		{
+			authRoutes.GET("", handler.GetInbox)
+			authRoutes.PUT("/read-all", handler.MarkAllAsRead)
+			authRoutes.PUT("/:id/read", handler.MarkAsRead)
+			authRoutes.GET("/preferences", handler.GetPreferences)
+			authRoutes.PUT("/preferences", handler.UpdatePreference)
+		}
+
+		// Rutas administrativas
+		adminRoutes := notificationRoutes.Group("/admin")
+		adminRoutes.Use(authMiddleware, adminMiddleware)
+		{
+			adminRoutes.GET("/templates", handler.GetTemplates)
+			adminRoutes.PUT("/templates/:event/:channel", handler.UpdateTemplate)
+		}
+	}
+}
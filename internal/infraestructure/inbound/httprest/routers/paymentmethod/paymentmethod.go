@@ -17,6 +17,7 @@ func SetupPaymentMethodRoutes(router *gin.RouterGroup, paymentMethodHandler *han
 		paymentMethods.GET("", paymentMethodHandler.GetAll)
 		paymentMethods.GET("/:id", paymentMethodHandler.GetByID)
 		paymentMethods.PUT("/:id", paymentMethodHandler.Update)
+		paymentMethods.POST("/:id/card", paymentMethodHandler.AttachCard)
 		paymentMethods.DELETE("/:id", paymentMethodHandler.Delete)
 	}
 }
@@ -16,6 +16,7 @@ func SetupPlanRoutes(r *gin.RouterGroup, planHandler *handler.Handler, authMiddl
 		planRoutes.GET("/active", planHandler.GetActivePlans)
 		planRoutes.GET("/public", planHandler.GetPublicPlans)
 		planRoutes.GET("/:id", planHandler.GetPlanByID)
+		planRoutes.GET("/:id/versions", planHandler.GetPlanVersions)
 
 		// Rutas protegidas (requieren autenticación)
 		protected := planRoutes.Group("/")
@@ -23,6 +24,9 @@ func SetupPlanRoutes(r *gin.RouterGroup, planHandler *handler.Handler, authMiddl
 		{
 			protected.POST("", planHandler.CreatePlan)
 			protected.PUT("/:id", planHandler.UpdatePlan)
+			protected.GET("/usage", planHandler.GetPlanUsage)
+			protected.POST("/:id/archive", planHandler.ArchivePlan)
+			protected.POST("/:id/unarchive", planHandler.UnarchivePlan)
 			protected.DELETE("/:id", planHandler.DeletePlan)
 		}
 	}
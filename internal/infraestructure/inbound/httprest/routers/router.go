@@ -1,24 +1,17 @@
 package routers
 
 import (
-	"database/sql"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
-	"MyMoneyBackend/db/config"
-	"MyMoneyBackend/internal/application/auth"
-	categoryService "MyMoneyBackend/internal/application/category"
-	currencyService "MyMoneyBackend/internal/application/currency"
-	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
-	planService "MyMoneyBackend/internal/application/plan"
-	transactionService "MyMoneyBackend/internal/application/transaction"
-	userService "MyMoneyBackend/internal/application/user"
-	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
 	categoryHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/category"
+	couponHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/coupon"
 	currencyHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/currency"
+	entitlementHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/entitlement"
 	healthHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/health"
+	invoiceHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/invoice"
+	notificationHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/notification"
 	paymentMethodHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/paymentmethod"
 	planHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/plan"
 	transactionHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/transaction"
@@ -26,104 +19,111 @@ import (
 	userSubscriptionHandler "MyMoneyBackend/internal/infraestructure/inbound/httprest/handlers/user_subscription"
 	middlewares "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
 	categoryRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/category"
+	couponRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/coupon"
 	currencyRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/currency"
+	entitlementRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/entitlement"
 	healthRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/health"
+	invoiceRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/invoice"
+	notificationRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/notification"
 	paymentMethodRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/paymentmethod"
 	planRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/plan"
 	transactionRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/transaction"
 	userRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/user"
 	userSubscriptionRouter "MyMoneyBackend/internal/infraestructure/inbound/httprest/routers/user_subscription"
 	"MyMoneyBackend/internal/infraestructure/inbound/httprest/swagger"
-	"MyMoneyBackend/internal/infraestructure/outbound/repository"
 )
 
-// SetupRouter configura todas las rutas de la API
-func SetupRouter(
-	r *gin.Engine,
-	userSvc *userService.UserService,
-	categorySvc *categoryService.Service,
-	paymentMethodSvc *paymentMethodService.Service,
-	transactionSvc *transactionService.Service,
-	tokenSvc *auth.TokenService,
-) {
-	// Configurar CORS
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+// Handlers agrupa los handlers HTTP ya construidos que expone la API
+type Handlers struct {
+	User             *userHandler.UserHandler
+	Category         *categoryHandler.CategoryHandler
+	PaymentMethod    *paymentMethodHandler.PaymentMethodHandler
+	Transaction      *transactionHandler.TransactionHandler
+	Currency         *currencyHandler.Handler
+	Plan             *planHandler.Handler
+	UserSubscription *userSubscriptionHandler.Handler
+	Invoice          *invoiceHandler.Handler
+	Coupon           *couponHandler.Handler
+	Entitlement      *entitlementHandler.Handler
+	Notification     *notificationHandler.Handler
+	Health           *healthHandler.Handler
+	Metrics          http.Handler // Métricas en formato Prometheus; nil no expone /metrics
+}
 
-	// Configurar Swagger
-	swagger.SetupSwaggerRoutes(r)
+// Middlewares agrupa los middlewares de CORS, autenticación, autorización, límites y métricas de las rutas
+type Middlewares struct {
+	CORS      *middlewares.CORSMiddleware
+	Auth      *middlewares.AuthMiddleware
+	Admin     *middlewares.AdminMiddleware
+	Metrics   *middlewares.MetricsMiddleware   // nil desactiva las métricas HTTP
+	RateLimit *middlewares.RateLimitMiddleware // nil desactiva el límite de las rutas de autenticación
+}
 
-	// Crear middleware de autenticación
-	authMiddleware := middlewares.NewAuthMiddleware(tokenSvc)
-	adminMiddleware := middlewares.NewAdminMiddleware()
-
-	// Crear handlers
-	userHdlr := userHandler.NewUserHandler(*userSvc, *tokenSvc)
-	categoryHdlr := categoryHandler.NewCategoryHandler(categorySvc)
-	paymentMethodHdlr := paymentMethodHandler.NewPaymentMethodHandler(paymentMethodSvc)
-	transactionHdlr := transactionHandler.NewTransactionHandler(transactionSvc)
-	healthHdlr := healthHandler.NewHealthHandler()
-
-	// Obtener conexión a la base de datos para los servicios adicionales
-	var db *sql.DB
-	dbConn, err := config.NewConnection()
-	if err != nil {
-		log.Printf("Error al conectar a la BD: %v", err)
-	} else {
-		db = dbConn.GetDB()
+// SetupRouter configura todas las rutas de la API con los handlers recibidos
+func SetupRouter(r *gin.Engine, handlers Handlers, mw Middlewares) {
+	// Medir todas las peticiones, incluidas las rechazadas por CORS o autenticación
+	if mw.Metrics != nil {
+		r.Use(mw.Metrics.Observe())
 	}
 
-	// Inicializar repositorios
-	currencyRepo := repository.NewCurrencyRepository(db)
-	planRepo := repository.NewPlanRepository(db)
-	userSubscriptionRepo := repository.NewUserSubscriptionRepository(db)
-	userRepo := repository.NewUserRepository(db)
+	// Aplicar la política CORS, incluidas las peticiones preflight a rutas no registradas
+	r.Use(mw.CORS.Handle())
 
-	// Inicializar servicios
-	currencySvc := currencyService.NewService(currencyRepo)
-	planSvc := planService.NewService(planRepo, currencyRepo)
-	userSubscriptionSvc := userSubscriptionService.NewService(userSubscriptionRepo, planRepo, userRepo)
+	// Responder como application/problem+json los errores que los handlers registran con c.Error
+	r.Use(middlewares.ErrorHandler())
 
-	// Inicializar handlers
-	currencyHdlr := currencyHandler.NewCurrencyHandler(currencySvc)
-	planHdlr := planHandler.NewPlanHandler(planSvc)
-	userSubscriptionHdlr := userSubscriptionHandler.NewUserSubscriptionHandler(userSubscriptionSvc)
+	// Exponer métricas para Prometheus (fuera de /api y sin autenticación)
+	if handlers.Metrics != nil {
+		r.GET("/metrics", gin.WrapH(handlers.Metrics))
+	}
+
+	// Configurar Swagger
+	swagger.SetupSwaggerRoutes(r)
+
+	// Limitar los intentos de inicio de sesión, registro y renovación del token por IP,
+	// y los de inicio de sesión también por cuenta
+	var authLimit, loginLimit gin.HandlerFunc
+	if mw.RateLimit != nil {
+		authLimit = mw.RateLimit.Auth()
+		loginLimit = mw.RateLimit.Login()
+	}
 
 	// Configurar grupo base de la API
 	api := r.Group("/api")
 
 	// Configurar rutas
-	userRouter.SetupUserRoutes(api, userHdlr, authMiddleware)
-	categoryRouter.SetupCategoryRoutes(api, categoryHdlr, authMiddleware)
-	paymentMethodRouter.SetupPaymentMethodRoutes(api, paymentMethodHdlr, authMiddleware)
-	transactionRouter.SetupTransactionRoutes(api, transactionHdlr, authMiddleware)
-	currencyRouter.SetupCurrencyRoutes(api, currencyHdlr, authMiddleware)
-	planRouter.SetupPlanRoutes(api, planHdlr, authMiddleware)
+	userRouter.SetupUserRoutes(api, handlers.User, mw.Auth, authLimit, loginLimit)
+	categoryRouter.SetupCategoryRoutes(api, handlers.Category, mw.Auth)
+	paymentMethodRouter.SetupPaymentMethodRoutes(api, handlers.PaymentMethod, mw.Auth)
+	transactionRouter.SetupTransactionRoutes(api, handlers.Transaction, mw.Auth)
+	currencyRouter.SetupCurrencyRoutes(api, handlers.Currency, mw.Auth)
+	planRouter.SetupPlanRoutes(api, handlers.Plan, mw.Auth)
 
 	// Configurar rutas de user_subscription
-	userSubscriptionRouter.SetupUserSubscriptionRoutes(api, authMiddleware.Authorize(), adminMiddleware.RequireAdmin(), userSubscriptionHdlr)
+	userSubscriptionRouter.SetupUserSubscriptionRoutes(api, mw.Auth.Authorize(), mw.Admin.RequireAdmin(), handlers.UserSubscription)
+
+	// Configurar rutas de facturas
+	invoiceRouter.SetupInvoiceRoutes(api, mw.Auth.Authorize(), handlers.Invoice)
+
+	// Configurar rutas de cupones
+	couponRouter.SetupCouponRoutes(api, mw.Auth.Authorize(), mw.Admin.RequireAdmin(), handlers.Coupon)
+
+	// Configurar rutas de derechos de uso
+	entitlementRouter.SetupEntitlementRoutes(api, mw.Auth.Authorize(), handlers.Entitlement)
+
+	// Configurar rutas de notificaciones
+	notificationRouter.SetupNotificationRoutes(api, mw.Auth.Authorize(), mw.Admin.RequireAdmin(), handlers.Notification)
 
 	// Configurar rutas de health check (no requieren autenticación)
-	healthRouter.SetupHealthRoutes(api, healthHdlr)
+	healthRouter.SetupHealthRoutes(api, handlers.Health)
 
 	// Configurar solo algunas rutas seleccionadas en la raíz para compatibilidad con Swagger
 	rootApi := r.Group("")
-	healthRouter.SetupHealthRoutes(rootApi, healthHdlr)
-	currencyRouter.SetupCurrencyRoutes(rootApi, currencyHdlr, authMiddleware)
-	planRouter.SetupPlanRoutes(rootApi, planHdlr, authMiddleware)
-	userRouter.SetupUserRoutes(rootApi, userHdlr, authMiddleware)
+	healthRouter.SetupHealthRoutes(rootApi, handlers.Health)
+	healthRouter.SetupProbeRoutes(rootApi, handlers.Health)
+	currencyRouter.SetupCurrencyRoutes(rootApi, handlers.Currency, mw.Auth)
+	planRouter.SetupPlanRoutes(rootApi, handlers.Plan, mw.Auth)
+	userRouter.SetupUserRoutes(rootApi, handlers.User, mw.Auth, authLimit, loginLimit)
 
 	// Redireccionar peticiones a /categories hacia /api/categories para compatibilidad
 	rootApi.GET("/categories", func(c *gin.Context) {
@@ -159,7 +159,7 @@ func SetupRouter(
 	})
 
 	// No incluir las rutas de categorías en la raíz para evitar respuestas duplicadas
-	// categoryRouter.SetupCategoryRoutes(rootApi, categoryHdlr, authMiddleware)
-	paymentMethodRouter.SetupPaymentMethodRoutes(rootApi, paymentMethodHdlr, authMiddleware)
-	userSubscriptionRouter.SetupUserSubscriptionRoutes(rootApi, authMiddleware.Authorize(), adminMiddleware.RequireAdmin(), userSubscriptionHdlr)
+	// categoryRouter.SetupCategoryRoutes(rootApi, handlers.Category, mw.Auth)
+	paymentMethodRouter.SetupPaymentMethodRoutes(rootApi, handlers.PaymentMethod, mw.Auth)
+	userSubscriptionRouter.SetupUserSubscriptionRoutes(rootApi, mw.Auth.Authorize(), mw.Admin.RequireAdmin(), handlers.UserSubscription)
 }
@@ -15,6 +15,7 @@ func SetupTransactionRoutes(router *gin.RouterGroup, transactionHandler *handler
 	{
 		transactions.POST("", transactionHandler.CreateTransaction)
 		transactions.GET("", transactionHandler.GetUserTransactions)
+		transactions.GET("/export", transactionHandler.ExportTransactions)
 		transactions.GET("/:id", transactionHandler.GetTransaction)
 		transactions.PUT("/:id", transactionHandler.UpdateTransaction)
 		transactions.DELETE("/:id", transactionHandler.DeleteTransaction)
@@ -7,12 +7,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SetupUserRoutes configura las rutas para los usuarios
-func SetupUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, authMiddleware *middleware.AuthMiddleware) {
+// SetupUserRoutes configura las rutas para los usuarios. authLimit, si no es nil, limita las
+// peticiones a las rutas de autenticación; loginLimit, si no es nil, además los intentos de
+// inicio de sesión de cada cuenta.
+func SetupUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, authMiddleware *middleware.AuthMiddleware, authLimit, loginLimit gin.HandlerFunc) {
 	// Rutas públicas (no requieren autenticación)
-	router.POST("/auth/register", userHandler.Register)
-	router.POST("/auth/login", userHandler.Login)
-	router.POST("/auth/refresh-token", userHandler.RefreshToken)
+	authRoutes := router.Group("/auth")
+	if authLimit != nil {
+		authRoutes.Use(authLimit)
+	}
+	{
+		authRoutes.POST("/register", userHandler.Register)
+		if loginLimit != nil {
+			authRoutes.POST("/login", loginLimit, userHandler.Login)
+		} else {
+			authRoutes.POST("/login", userHandler.Login)
+		}
+		authRoutes.POST("/refresh-token", userHandler.RefreshToken)
+	}
 
 	// Rutas protegidas (requieren autenticación)
 	users := router.Group("/users")
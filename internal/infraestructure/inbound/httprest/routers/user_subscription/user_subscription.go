@@ -32,12 +32,18 @@ func SetupUserSubscriptionRoutes(
 			// Obtener una suscripción específica
 			authRoutes.GET("/:id", handler.GetSubscriptionByID)
 
+			// Obtener el historial de facturas de una suscripción
+			authRoutes.GET("/:id/invoices", handler.GetInvoices)
+
 			// Cancelar una suscripción
 			authRoutes.PUT("/:id/cancel", handler.CancelSubscription)
 
 			// Cambiar el plan de una suscripción
 			authRoutes.PUT("/:id/plan", handler.ChangePlan)
 
+			// Previsualizar el prorrateo de un cambio de plan
+			authRoutes.GET("/:id/plan/preview", handler.PreviewPlanChange)
+
 			// Actualizar el método de pago
 			authRoutes.PUT("/:id/payment-method", handler.UpdatePaymentMethod)
 
@@ -55,8 +61,14 @@ func SetupUserSubscriptionRoutes(
 			// Obtener suscripciones que expirarán pronto
 			adminRoutes.GET("/expiring", handler.GetExpiringSubscriptions)
 
+			// Notificar a los usuarios con suscripciones por expirar
+			adminRoutes.POST("/expiring/notify", handler.NotifyExpiringSubscriptions)
+
 			// Obtener suscripciones pendientes de renovación
 			adminRoutes.GET("/pending-renewals", handler.GetPendingRenewals)
+
+			// Migrar los suscriptores de una versión de plan a la versión vigente
+			adminRoutes.POST("/plan-migrations", handler.MigratePlanCohort)
 		}
 	}
 }
@@ -0,0 +1,67 @@
+// Package server ejecuta el servidor HTTP de la API y lo apaga de forma ordenada.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// New crea el servidor HTTP con el puerto y los timeouts de cfg
+func New(cfg domain.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Port),
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// Run escucha en srv.Addr y sirve hasta que se cancele ctx; ver Serve
+func Run(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, beforeShutdown func()) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("error al escuchar en %s: %w", srv.Addr, err)
+	}
+	return Serve(ctx, srv, listener, shutdownTimeout, beforeShutdown)
+}
+
+// Serve sirve peticiones en listener hasta que se cancele ctx. Al cancelarse llama a beforeShutdown
+// (para dejar de anunciarse como listo), deja de aceptar conexiones y espera a las peticiones en
+// curso como máximo shutdownTimeout.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, shutdownTimeout time.Duration, beforeShutdown func()) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("error en el servidor HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("apagando el servidor, esperando a las peticiones en curso")
+	if beforeShutdown != nil {
+		beforeShutdown()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error al apagar el servidor HTTP: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error en el servidor HTTP: %w", err)
+	}
+
+	slog.Info("servidor detenido")
+	return nil
+}
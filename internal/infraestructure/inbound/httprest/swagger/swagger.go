@@ -2,7 +2,7 @@ package swagger
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -47,7 +47,7 @@ func SetupSwaggerRoutes(r *gin.Engine) {
 		path := c.Request.URL.Path
 		method := c.Request.Method
 
-		log.Printf("NoRoute handler: %s %s", method, path)
+		slog.DebugContext(c.Request.Context(), "ruta no encontrada", "method", method, "path", path)
 
 		// Verificar si la ruta ya comienza con /api para no duplicarlo
 		if strings.HasPrefix(path, "/api/") {
@@ -63,7 +63,7 @@ func SetupSwaggerRoutes(r *gin.Engine) {
 		// Si la ruta no comienza con /api, intentamos redirigirla
 		if len(path) > 0 && path[0] == '/' {
 			apiPath := "/api" + path
-			log.Printf("Redirigiendo a: %s", apiPath)
+			slog.DebugContext(c.Request.Context(), "redirigiendo a la ruta con prefijo /api", "path", apiPath)
 
 			// Modificar la URL de la petición para que apunte a /api/...
 			c.Request.URL.Path = apiPath
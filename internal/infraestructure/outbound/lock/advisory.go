@@ -0,0 +1,79 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// SubscriptionSchedulerKey identifica el bloqueo del planificador de suscripciones
+const SubscriptionSchedulerKey int64 = 727001
+
+// AdvisoryLock implementa app.LeaderLock con un advisory lock de PostgreSQL.
+// Los advisory locks pertenecen a la sesión, por lo que se reserva una conexión
+// dedicada del pool mientras el bloqueo esté tomado.
+type AdvisoryLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLock crea un nuevo bloqueo para la clave indicada
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryAcquire intenta tomar el bloqueo. Si ya se tiene, verifica que la sesión siga viva.
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		// Si la conexión se perdió, PostgreSQL ya liberó el bloqueo
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error al obtener conexión para el bloqueo: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("error al tomar el bloqueo: %w", err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release libera el bloqueo y devuelve la conexión al pool
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	l.conn = nil
+	if err != nil {
+		return fmt.Errorf("error al liberar el bloqueo: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package lock
+
+import "context"
+
+// LocalLock implementa app.LeaderLock para una única instancia, como con SQLite, donde la base
+// de datos es un archivo local y no hay advisory locks: la instancia siempre es la líder.
+type LocalLock struct{}
+
+// NewLocalLock crea un nuevo bloqueo local
+func NewLocalLock() *LocalLock {
+	return &LocalLock{}
+}
+
+// TryAcquire siempre toma el bloqueo
+func (l *LocalLock) TryAcquire(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// Release no tiene nada que liberar
+func (l *LocalLock) Release(ctx context.Context) error {
+	return nil
+}
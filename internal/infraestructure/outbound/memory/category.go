@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CategoryRepository implementa la interfaz app.CategoryRepository en memoria
+type CategoryRepository struct {
+	store *Store
+}
+
+// NewCategoryRepository crea un nuevo repositorio de categorías en memoria
+func NewCategoryRepository(store *Store) *CategoryRepository {
+	return &CategoryRepository{store: store}
+}
+
+// Create crea una nueva categoría
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if category.ID == "" {
+		category.ID = uuid.New().String()
+	}
+	if _, ok := r.store.categories[category.ID]; ok {
+		return fmt.Errorf("la categoría %s ya existe", category.ID)
+	}
+
+	now := time.Now()
+	if category.CreatedAt.IsZero() {
+		category.CreatedAt = now
+	}
+	category.UpdatedAt = now
+
+	stored := *category
+	stored.Budget = cloneBudget(category.Budget)
+	r.store.categories[category.ID] = stored
+	return nil
+}
+
+// GetByID obtiene una categoría por su ID. Devuelve nil si no existe.
+func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category, ok := r.store.categories[id]
+	if !ok {
+		return nil, nil // No se encontró la categoría
+	}
+	category.Budget = cloneBudget(category.Budget)
+	return &category, nil
+}
+
+// GetByUserID obtiene todas las categorías de un usuario ordenadas por nombre
+func (r *CategoryRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var categories []*domain.Category
+	for _, category := range r.store.categories {
+		if category.UserID == userID {
+			category := category
+			category.Budget = cloneBudget(category.Budget)
+			categories = append(categories, &category)
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// Update actualiza una categoría existente. No hace nada si la categoría no existe.
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	category.UpdatedAt = time.Now()
+
+	stored, ok := r.store.categories[category.ID]
+	if !ok {
+		return nil
+	}
+	stored.Name = category.Name
+	stored.Description = category.Description
+	stored.Color = category.Color
+	stored.Icon = category.Icon
+	stored.Budget = cloneBudget(category.Budget)
+	stored.UpdatedAt = category.UpdatedAt
+	r.store.categories[category.ID] = stored
+	return nil
+}
+
+// Delete elimina una categoría. Falla si alguna transacción la usa.
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, transaction := range r.store.transactions {
+		if transaction.CategoryID == id {
+			return domain.NewConflictError("category_in_use", "la categoría %s tiene transacciones asociadas", id)
+		}
+	}
+	delete(r.store.categories, id)
+	return nil
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CurrencyRepository implementa el puerto app.CurrencyRepository en memoria
+type CurrencyRepository struct {
+	store *Store
+}
+
+// NewCurrencyRepository crea una nueva instancia de CurrencyRepository en memoria
+func NewCurrencyRepository(store *Store) *CurrencyRepository {
+	return &CurrencyRepository{store: store}
+}
+
+// Create crea una nueva moneda. Falla si el código ya existe.
+func (r *CurrencyRepository) Create(ctx context.Context, currency *domain.Currency) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if currency.ID == "" {
+		currency.ID = uuid.New().String()
+	}
+	if _, ok := r.store.currencies[currency.ID]; ok {
+		return fmt.Errorf("error al crear moneda: la moneda %s ya existe", currency.ID)
+	}
+	if r.codeTaken(currency.Code, currency.ID) {
+		return fmt.Errorf("error al crear moneda: el código %s ya existe", currency.Code)
+	}
+
+	now := time.Now()
+	currency.CreatedAt = now
+	currency.UpdatedAt = now
+
+	r.store.currencies[currency.ID] = *currency
+	return nil
+}
+
+// GetByID obtiene una moneda por su ID
+func (r *CurrencyRepository) GetByID(ctx context.Context, id string) (*domain.Currency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	currency, ok := r.store.currencies[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", id)
+	}
+	return &currency, nil
+}
+
+// GetByCode obtiene una moneda por su código
+func (r *CurrencyRepository) GetByCode(ctx context.Context, code string) (*domain.Currency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, currency := range r.store.currencies {
+		if currency.Code == code {
+			return &currency, nil
+		}
+	}
+	return nil, domain.NewNotFoundError("currency", "moneda no encontrada con código: %s", code)
+}
+
+// GetAll obtiene todas las monedas ordenadas por código
+func (r *CurrencyRepository) GetAll(ctx context.Context) ([]*domain.Currency, error) {
+	return r.filter(func(domain.Currency) bool { return true }), nil
+}
+
+// GetAllActive obtiene todas las monedas activas ordenadas por código
+func (r *CurrencyRepository) GetAllActive(ctx context.Context) ([]*domain.Currency, error) {
+	return r.filter(func(currency domain.Currency) bool { return currency.IsActive }), nil
+}
+
+// Update actualiza una moneda existente
+func (r *CurrencyRepository) Update(ctx context.Context, currency *domain.Currency) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	currency.UpdatedAt = time.Now()
+
+	stored, ok := r.store.currencies[currency.ID]
+	if !ok {
+		return domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", currency.ID)
+	}
+	if r.codeTaken(currency.Code, currency.ID) {
+		return fmt.Errorf("error al actualizar moneda: el código %s ya existe", currency.Code)
+	}
+
+	stored.Code = currency.Code
+	stored.Name = currency.Name
+	stored.Symbol = currency.Symbol
+	stored.IsActive = currency.IsActive
+	stored.UpdatedAt = currency.UpdatedAt
+	r.store.currencies[currency.ID] = stored
+	return nil
+}
+
+// CountReferences cuenta los planes, transacciones y cupones que referencian a la moneda.
+// Los cupones no se guardan en memoria, por lo que siempre cuentan cero.
+func (r *CurrencyRepository) CountReferences(ctx context.Context, id string) (map[string]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	references := map[string]int{
+		"plans":        0,
+		"transactions": 0,
+		"coupons":      0,
+	}
+	for _, plan := range r.store.plans {
+		if plan.CurrencyID == id {
+			references["plans"]++
+		}
+	}
+	for _, transaction := range r.store.transactions {
+		if transaction.CurrencyID == id {
+			references["transactions"]++
+		}
+	}
+	return references, nil
+}
+
+// Delete elimina una moneda por su ID. Falla si algún plan o transacción la usa.
+func (r *CurrencyRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.currencies[id]; !ok {
+		return domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", id)
+	}
+	for _, plan := range r.store.plans {
+		if plan.CurrencyID == id {
+			return fmt.Errorf("error al eliminar moneda: la usa el plan %s", plan.ID)
+		}
+	}
+	for _, transaction := range r.store.transactions {
+		if transaction.CurrencyID == id {
+			return fmt.Errorf("error al eliminar moneda: la usa la transacción %s", transaction.ID)
+		}
+	}
+	delete(r.store.currencies, id)
+	return nil
+}
+
+// codeTaken indica si otra moneda ya usa el código
+func (r *CurrencyRepository) codeTaken(code, exceptID string) bool {
+	for id, currency := range r.store.currencies {
+		if id != exceptID && currency.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// filter devuelve copias de las monedas que cumplen match, ordenadas por código
+func (r *CurrencyRepository) filter(match func(domain.Currency) bool) []*domain.Currency {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var currencies []*domain.Currency
+	for _, currency := range r.store.currencies {
+		if match(currency) {
+			currency := currency
+			currencies = append(currencies, &currency)
+		}
+	}
+
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+	return currencies
+}
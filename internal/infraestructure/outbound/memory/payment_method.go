@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// PaymentMethodRepository implementa la interfaz app.PaymentMethodRepository en memoria
+type PaymentMethodRepository struct {
+	store *Store
+}
+
+// NewPaymentMethodRepository crea un nuevo repositorio de métodos de pago en memoria
+func NewPaymentMethodRepository(store *Store) *PaymentMethodRepository {
+	return &PaymentMethodRepository{store: store}
+}
+
+// Create crea un nuevo método de pago
+func (r *PaymentMethodRepository) Create(ctx context.Context, paymentMethod *domain.PaymentMethod) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if paymentMethod.ID == "" {
+		paymentMethod.ID = uuid.New().String()
+	}
+	if _, ok := r.store.paymentMethods[paymentMethod.ID]; ok {
+		return fmt.Errorf("el método de pago %s ya existe", paymentMethod.ID)
+	}
+
+	now := time.Now()
+	if paymentMethod.CreatedAt.IsZero() {
+		paymentMethod.CreatedAt = now
+	}
+	paymentMethod.UpdatedAt = now
+
+	r.store.paymentMethods[paymentMethod.ID] = *paymentMethod
+	return nil
+}
+
+// GetByID obtiene un método de pago por su ID. Devuelve nil si no existe.
+func (r *PaymentMethodRepository) GetByID(ctx context.Context, id string) (*domain.PaymentMethod, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	paymentMethod, ok := r.store.paymentMethods[id]
+	if !ok {
+		return nil, nil // No se encontró el método de pago
+	}
+	return &paymentMethod, nil
+}
+
+// GetByUserID obtiene todos los métodos de pago de un usuario, del más reciente al más antiguo
+func (r *PaymentMethodRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.PaymentMethod, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var paymentMethods []*domain.PaymentMethod
+	for _, paymentMethod := range r.store.paymentMethods {
+		if paymentMethod.UserID == userID {
+			paymentMethod := paymentMethod
+			paymentMethods = append(paymentMethods, &paymentMethod)
+		}
+	}
+
+	sort.Slice(paymentMethods, func(i, j int) bool {
+		if !paymentMethods[i].CreatedAt.Equal(paymentMethods[j].CreatedAt) {
+			return paymentMethods[i].CreatedAt.After(paymentMethods[j].CreatedAt)
+		}
+		return paymentMethods[i].ID < paymentMethods[j].ID
+	})
+	return paymentMethods, nil
+}
+
+// Update actualiza un método de pago existente. No hace nada si el método de pago no existe.
+func (r *PaymentMethodRepository) Update(ctx context.Context, paymentMethod *domain.PaymentMethod) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	paymentMethod.UpdatedAt = time.Now()
+
+	stored, ok := r.store.paymentMethods[paymentMethod.ID]
+	if !ok {
+		return nil
+	}
+	stored.Name = paymentMethod.Name
+	stored.Description = paymentMethod.Description
+	stored.IsActive = paymentMethod.IsActive
+	stored.CardBrand = paymentMethod.CardBrand
+	stored.CardLast4 = paymentMethod.CardLast4
+	stored.CardToken = paymentMethod.CardToken
+	stored.UpdatedAt = paymentMethod.UpdatedAt
+	r.store.paymentMethods[paymentMethod.ID] = stored
+	return nil
+}
+
+// Delete elimina un método de pago y lo desvincula de las transacciones que lo usaban
+func (r *PaymentMethodRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.paymentMethods, id)
+	for transactionID, transaction := range r.store.transactions {
+		if transaction.PaymentMethodID == id {
+			transaction.PaymentMethodID = ""
+			r.store.transactions[transactionID] = transaction
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// PlanRepository implementa el puerto app.PlanRepository en memoria
+type PlanRepository struct {
+	store *Store
+}
+
+// NewPlanRepository crea una nueva instancia de PlanRepository en memoria
+func NewPlanRepository(store *Store) *PlanRepository {
+	return &PlanRepository{store: store}
+}
+
+// Create crea un nuevo plan como la primera versión de su familia
+func (r *PlanRepository) Create(ctx context.Context, plan *domain.Plan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if plan.ID == "" {
+		plan.ID = uuid.New().String()
+	}
+	if plan.FamilyID == "" {
+		plan.FamilyID = plan.ID
+	}
+	if plan.Version == 0 {
+		plan.Version = 1
+	}
+
+	return r.insert(plan)
+}
+
+// CreateVersion guarda next como la nueva versión vigente y marca previous como reemplazada.
+// Falla si previous ya fue reemplazada por otra versión.
+func (r *PlanRepository) CreateVersion(ctx context.Context, previous *domain.Plan, next *domain.Plan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.plans[previous.ID]
+	if !ok || stored.SupersededAt != nil {
+		return fmt.Errorf("la versión %d del plan %s ya fue reemplazada", previous.Version, previous.FamilyID)
+	}
+
+	if next.ID == "" {
+		next.ID = uuid.New().String()
+	}
+	if err := r.insert(next); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stored.SupersededAt = &now
+	stored.UpdatedAt = now
+
+	previous.SupersededAt = cloneTime(&now)
+	previous.UpdatedAt = now
+	return nil
+}
+
+// insert guarda una versión de plan; debe llamarse con el Store bloqueado
+func (r *PlanRepository) insert(plan *domain.Plan) error {
+	if _, ok := r.store.plans[plan.ID]; ok {
+		return fmt.Errorf("error al crear plan: el plan %s ya existe", plan.ID)
+	}
+	if _, ok := r.store.currencies[plan.CurrencyID]; !ok {
+		return fmt.Errorf("error al crear plan: moneda no encontrada con id: %s", plan.CurrencyID)
+	}
+
+	now := time.Now()
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	r.store.plans[plan.ID] = clonePlan(plan)
+	return nil
+}
+
+// GetByID obtiene una versión de plan por su ID
+func (r *PlanRepository) GetByID(ctx context.Context, id string) (*domain.Plan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	plan, ok := r.store.plans[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("plan", "plan no encontrado con id: %s", id)
+	}
+	return clonePlan(plan), nil
+}
+
+// GetLatestVersion obtiene la versión vigente de una familia de planes
+func (r *PlanRepository) GetLatestVersion(ctx context.Context, familyID string) (*domain.Plan, error) {
+	plans := r.filter(func(plan *domain.Plan) bool {
+		return plan.FamilyID == familyID && plan.SupersededAt == nil
+	}, byVersionDesc)
+	if len(plans) == 0 {
+		return nil, domain.NewNotFoundError("plan", "plan no encontrado con familia: %s", familyID)
+	}
+	return plans[0], nil
+}
+
+// GetVersions obtiene todas las versiones de una familia de planes, de la más nueva a la más antigua
+func (r *PlanRepository) GetVersions(ctx context.Context, familyID string) ([]*domain.Plan, error) {
+	return r.filter(func(plan *domain.Plan) bool {
+		return plan.FamilyID == familyID
+	}, byVersionDesc), nil
+}
+
+// GetAll obtiene todos los planes, incluidas las versiones reemplazadas
+func (r *PlanRepository) GetAll(ctx context.Context) ([]*domain.Plan, error) {
+	return r.filter(func(*domain.Plan) bool { return true }, bySortOrder), nil
+}
+
+// GetAllPublic obtiene la versión vigente de todos los planes públicos
+func (r *PlanRepository) GetAllPublic(ctx context.Context) ([]*domain.Plan, error) {
+	return r.filter(func(plan *domain.Plan) bool {
+		return plan.IsPublic && plan.SupersededAt == nil
+	}, bySortOrder), nil
+}
+
+// GetAllActive obtiene la versión vigente de todos los planes activos
+func (r *PlanRepository) GetAllActive(ctx context.Context) ([]*domain.Plan, error) {
+	return r.filter(func(plan *domain.Plan) bool {
+		return plan.IsActive && plan.SupersededAt == nil
+	}, bySortOrder), nil
+}
+
+// Update actualiza los datos de una versión de plan en el lugar.
+// Los cambios de oferta deben crear una nueva versión con CreateVersion.
+func (r *PlanRepository) Update(ctx context.Context, plan *domain.Plan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	plan.UpdatedAt = time.Now()
+
+	stored, ok := r.store.plans[plan.ID]
+	if !ok {
+		return domain.NewNotFoundError("plan", "plan no encontrado con id: %s", plan.ID)
+	}
+
+	updated := clonePlan(plan)
+	updated.FamilyID = stored.FamilyID
+	updated.Version = stored.Version
+	updated.SupersededAt = stored.SupersededAt
+	updated.ArchivedAt = stored.ArchivedAt
+	updated.CreatedAt = stored.CreatedAt
+	r.store.plans[plan.ID] = updated
+	return nil
+}
+
+// Archive archiva todas las versiones de una familia de planes: dejan de estar activas y públicas
+// pero se conservan para sus suscriptores
+func (r *PlanRepository) Archive(ctx context.Context, familyID string, archivedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	archived := 0
+	for _, plan := range r.store.plans {
+		if plan.FamilyID != familyID || plan.ArchivedAt != nil {
+			continue
+		}
+		plan.ArchivedAt = cloneTime(&archivedAt)
+		plan.IsActive = false
+		plan.IsPublic = false
+		plan.UpdatedAt = archivedAt
+		archived++
+	}
+
+	if archived == 0 {
+		return domain.NewNotFoundError("plan", "plan no encontrado o ya archivado con familia: %s", familyID)
+	}
+	return nil
+}
+
+// Unarchive quita el archivado de todas las versiones de una familia de planes. Solo la versión
+// vigente vuelve a estar activa; las reemplazadas siguen sin admitir nuevas suscripciones.
+func (r *PlanRepository) Unarchive(ctx context.Context, familyID string, isPublic bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	unarchived := 0
+	for _, plan := range r.store.plans {
+		if plan.FamilyID != familyID || plan.ArchivedAt == nil {
+			continue
+		}
+		plan.ArchivedAt = nil
+		if plan.IsLatestVersion() {
+			plan.IsActive = true
+			plan.IsPublic = isPublic
+		}
+		plan.UpdatedAt = now
+		unarchived++
+	}
+
+	if unarchived == 0 {
+		return domain.NewNotFoundError("plan", "plan no encontrado o no archivado con familia: %s", familyID)
+	}
+	return nil
+}
+
+// Delete elimina todas las versiones de la familia del plan indicado.
+// Falla si alguna suscripción usa una de las versiones.
+func (r *PlanRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	plan, ok := r.store.plans[id]
+	if !ok {
+		return domain.NewNotFoundError("plan", "plan no encontrado con id: %s", id)
+	}
+
+	familyID := plan.FamilyID
+	for _, subscription := range r.store.subscriptions {
+		if subscribed, ok := r.store.plans[subscription.PlanID]; ok && subscribed.FamilyID == familyID {
+			return fmt.Errorf("error al eliminar plan: lo usa la suscripción %s", subscription.ID)
+		}
+	}
+	for planID, version := range r.store.plans {
+		if version.FamilyID == familyID {
+			delete(r.store.plans, planID)
+		}
+	}
+	return nil
+}
+
+// filter devuelve copias de los planes que cumplen match, ordenados con less
+func (r *PlanRepository) filter(match func(*domain.Plan) bool, less func(a, b *domain.Plan) bool) []*domain.Plan {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var plans []*domain.Plan
+	for _, plan := range r.store.plans {
+		if match(plan) {
+			plans = append(plans, clonePlan(plan))
+		}
+	}
+
+	sort.Slice(plans, func(i, j int) bool { return less(plans[i], plans[j]) })
+	return plans
+}
+
+// byVersionDesc ordena de la versión más nueva a la más antigua
+func byVersionDesc(a, b *domain.Plan) bool {
+	return a.Version > b.Version
+}
+
+// bySortOrder ordena por orden de visualización, nombre y versión descendente
+func bySortOrder(a, b *domain.Plan) bool {
+	if a.SortOrder != b.SortOrder {
+		return a.SortOrder < b.SortOrder
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	if a.Version != b.Version {
+		return a.Version > b.Version
+	}
+	return a.ID < b.ID
+}
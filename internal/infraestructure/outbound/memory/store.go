@@ -0,0 +1,107 @@
+// Package memory implementa los puertos de repositorio en memoria, para pruebas unitarias de los
+// servicios y para ejecutar la aplicación sin base de datos. Los datos se pierden al terminar el proceso.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// Store guarda los datos de todos los repositorios en memoria. Los repositorios creados con el mismo
+// Store comparten los datos, como las tablas de una base de datos. Es seguro para uso concurrente.
+type Store struct {
+	mu             sync.RWMutex
+	users          map[string]domain.User
+	categories     map[string]domain.Category
+	paymentMethods map[string]domain.PaymentMethod
+	transactions   map[string]domain.Transaction
+	currencies     map[string]domain.Currency
+	plans          map[string]*domain.Plan
+	subscriptions  map[string]*domain.UserSubscription
+}
+
+// NewStore crea un Store vacío
+func NewStore() *Store {
+	return &Store{
+		users:          make(map[string]domain.User),
+		categories:     make(map[string]domain.Category),
+		paymentMethods: make(map[string]domain.PaymentMethod),
+		transactions:   make(map[string]domain.Transaction),
+		currencies:     make(map[string]domain.Currency),
+		plans:          make(map[string]*domain.Plan),
+		subscriptions:  make(map[string]*domain.UserSubscription),
+	}
+}
+
+// clonePlan copia un plan para que el llamador no comparta sus listas con el Store
+func clonePlan(plan *domain.Plan) *domain.Plan {
+	clone := *plan
+	clone.SupersededAt = cloneTime(plan.SupersededAt)
+	clone.ArchivedAt = cloneTime(plan.ArchivedAt)
+	if plan.Features != nil {
+		clone.Features = append([]domain.PlanFeature(nil), plan.Features...)
+	}
+	clone.Entitlements.MaxCategories = cloneInt(plan.Entitlements.MaxCategories)
+	clone.Entitlements.MaxTransactionsPerMonth = cloneInt(plan.Entitlements.MaxTransactionsPerMonth)
+	if plan.Entitlements.ExportFormats != nil {
+		clone.Entitlements.ExportFormats = append([]domain.ExportFormat(nil), plan.Entitlements.ExportFormats...)
+	}
+	return &clone
+}
+
+// cloneSubscription copia una suscripción para que el llamador no comparta sus metadatos con el Store
+func cloneSubscription(subscription *domain.UserSubscription) *domain.UserSubscription {
+	clone := *subscription
+	clone.RenewalDate = cloneTime(subscription.RenewalDate)
+	clone.CancellationDate = cloneTime(subscription.CancellationDate)
+	clone.LastPaymentDate = cloneTime(subscription.LastPaymentDate)
+	clone.NextPaymentAttempt = cloneTime(subscription.NextPaymentAttempt)
+	clone.TrialEndDate = cloneTime(subscription.TrialEndDate)
+	if subscription.PaymentMethodID != nil {
+		paymentMethodID := *subscription.PaymentMethodID
+		clone.PaymentMethodID = &paymentMethodID
+	}
+	clone.Metadata = cloneMetadata(subscription.Metadata)
+	return &clone
+}
+
+// cloneMetadata copia los metadatos de una suscripción; nil se mantiene nil
+func cloneMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		clone[key] = value
+	}
+	return clone
+}
+
+// cloneBudget copia el presupuesto opcional de una categoría
+func cloneBudget(budget *domain.CategoryBudget) *domain.CategoryBudget {
+	if budget == nil {
+		return nil
+	}
+	clone := *budget
+	return &clone
+}
+
+// cloneTime copia una fecha opcional
+func cloneTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t := *value
+	return &t
+}
+
+// cloneInt copia un entero opcional
+func cloneInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	n := *value
+	return &n
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// TransactionRepository implements app.TransactionRepository in memory
+type TransactionRepository struct {
+	store *Store
+}
+
+// NewTransactionRepository creates a new in-memory TransactionRepository
+func NewTransactionRepository(store *Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+// Create stores a new transaction
+func (r *TransactionRepository) Create(ctx context.Context, transaction *domain.Transaction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if transaction.ID == "" {
+		transaction.ID = uuid.New().String()
+	}
+	if _, ok := r.store.transactions[transaction.ID]; ok {
+		return fmt.Errorf("error creating transaction: transaction %s already exists", transaction.ID)
+	}
+
+	now := time.Now()
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+
+	r.store.transactions[transaction.ID] = *transaction
+	return nil
+}
+
+// GetByID retrieves a transaction by ID
+func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*domain.Transaction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	transaction, ok := r.store.transactions[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("transaction", "transaction not found: %s", id)
+	}
+	return &transaction, nil
+}
+
+// GetByUserID retrieves all transactions for a user, newest first
+func (r *TransactionRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.Transaction, error) {
+	return r.filter(func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID
+	}), nil
+}
+
+// GetByCategoryID retrieves all transactions for a category, newest first
+func (r *TransactionRepository) GetByCategoryID(ctx context.Context, categoryID string) ([]*domain.Transaction, error) {
+	return r.filter(func(transaction domain.Transaction) bool {
+		return transaction.CategoryID == categoryID
+	}), nil
+}
+
+// GetByDateRange retrieves all transactions of a user between startDate and endDate, both inclusive
+func (r *TransactionRepository) GetByDateRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]*domain.Transaction, error) {
+	return r.filter(func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID &&
+			!transaction.Date.Before(startDate) &&
+			!transaction.Date.After(endDate)
+	}), nil
+}
+
+// CountCreatedBetween counts the transactions of a user recorded in [from, to), whatever their date
+func (r *TransactionRepository) CountCreatedBetween(ctx context.Context, userID string, from, to time.Time) (int, error) {
+	return len(r.filter(func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID &&
+			!transaction.CreatedAt.Before(from) &&
+			transaction.CreatedAt.Before(to)
+	})), nil
+}
+
+// SumExpenses adds up the expenses of a category in a currency dated in [from, to)
+func (r *TransactionRepository) SumExpenses(ctx context.Context, categoryID, currencyID string, from, to time.Time) (float64, error) {
+	total := 0.0
+	for _, transaction := range r.filter(func(transaction domain.Transaction) bool {
+		return transaction.CategoryID == categoryID &&
+			transaction.CurrencyID == currencyID &&
+			transaction.Type == domain.TransactionTypeExpense &&
+			!transaction.Date.Before(from) &&
+			transaction.Date.Before(to)
+	}) {
+		total += transaction.Amount
+	}
+	return total, nil
+}
+
+// Update updates a transaction's information. It fails if the transaction does not belong to the user.
+func (r *TransactionRepository) Update(ctx context.Context, transaction *domain.Transaction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.transactions[transaction.ID]
+	if !ok || stored.UserID != transaction.UserID {
+		return domain.NewNotFoundError("transaction", "transaction not found or does not belong to the user")
+	}
+
+	now := time.Now()
+	stored.Amount = transaction.Amount
+	stored.Description = transaction.Description
+	stored.CategoryID = transaction.CategoryID
+	stored.Type = transaction.Type
+	stored.PaymentMethodID = transaction.PaymentMethodID
+	stored.CurrencyID = transaction.CurrencyID
+	stored.Date = transaction.Date
+	stored.UpdatedAt = now
+	r.store.transactions[transaction.ID] = stored
+
+	transaction.UpdatedAt = now
+	return nil
+}
+
+// Delete removes a transaction
+func (r *TransactionRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.transactions[id]; !ok {
+		return domain.NewNotFoundError("transaction", "transaction not found")
+	}
+	delete(r.store.transactions, id)
+	return nil
+}
+
+// filter returns copies of the transactions that match, ordered by date descending
+func (r *TransactionRepository) filter(match func(domain.Transaction) bool) []*domain.Transaction {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var transactions []*domain.Transaction
+	for _, transaction := range r.store.transactions {
+		if match(transaction) {
+			transaction := transaction
+			transactions = append(transactions, &transaction)
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.After(transactions[j].Date)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+	return transactions
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// UserRepository implementa la interfaz app.UserRepository en memoria
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository crea un nuevo repositorio de usuarios en memoria
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// Create crea un nuevo usuario. Falla si el email ya está registrado.
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	if _, ok := r.store.users[user.ID]; ok {
+		return errors.New("user already exists")
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return errors.New("email already registered")
+	}
+
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+// GetByID obtiene un usuario por su ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("user", "user not found")
+	}
+	return &user, nil
+}
+
+// GetByEmail obtiene un usuario por su email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, domain.NewNotFoundError("user", "user not found")
+}
+
+// Update actualiza la información de un usuario
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return domain.NewNotFoundError("user", "user not found")
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return errors.New("email already registered")
+	}
+
+	user.UpdatedAt = time.Now()
+	stored.Email = user.Email
+	stored.Name = user.Name
+	stored.Password = user.Password
+	stored.UpdatedAt = user.UpdatedAt
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+// Delete elimina un usuario junto con sus categorías, métodos de pago, transacciones y suscripciones
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return domain.NewNotFoundError("user", "user not found")
+	}
+	delete(r.store.users, id)
+
+	// Replicar el borrado en cascada de las claves foráneas
+	for categoryID, category := range r.store.categories {
+		if category.UserID == id {
+			delete(r.store.categories, categoryID)
+		}
+	}
+	for paymentMethodID, paymentMethod := range r.store.paymentMethods {
+		if paymentMethod.UserID == id {
+			delete(r.store.paymentMethods, paymentMethodID)
+		}
+	}
+	for transactionID, transaction := range r.store.transactions {
+		if transaction.UserID == id {
+			delete(r.store.transactions, transactionID)
+		}
+	}
+	for subscriptionID, subscription := range r.store.subscriptions {
+		if subscription.UserID == id {
+			delete(r.store.subscriptions, subscriptionID)
+		}
+	}
+	return nil
+}
+
+// CountReferences cuenta las facturas del usuario. Las facturas no se guardan en memoria, por lo
+// que siempre cuentan cero.
+func (r *UserRepository) CountReferences(ctx context.Context, id string) (map[string]int, error) {
+	return map[string]int{"invoices": 0}, nil
+}
+
+// emailTaken indica si otro usuario ya usa el email
+func (r *UserRepository) emailTaken(email, exceptID string) bool {
+	for id, user := range r.store.users {
+		if id != exceptID && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
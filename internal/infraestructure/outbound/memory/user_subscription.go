@@ -0,0 +1,256 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// UserSubscriptionRepository implementa el puerto app.UserSubscriptionRepository en memoria
+type UserSubscriptionRepository struct {
+	store *Store
+}
+
+// NewUserSubscriptionRepository crea una nueva instancia de UserSubscriptionRepository en memoria
+func NewUserSubscriptionRepository(store *Store) *UserSubscriptionRepository {
+	return &UserSubscriptionRepository{store: store}
+}
+
+// Create crea una nueva suscripción. Falla si el usuario o el plan no existen.
+func (r *UserSubscriptionRepository) Create(ctx context.Context, subscription *domain.UserSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	if _, ok := r.store.subscriptions[subscription.ID]; ok {
+		return fmt.Errorf("error al crear suscripción: la suscripción %s ya existe", subscription.ID)
+	}
+	if _, ok := r.store.users[subscription.UserID]; !ok {
+		return fmt.Errorf("error al crear suscripción: usuario no encontrado con id: %s", subscription.UserID)
+	}
+	if _, ok := r.store.plans[subscription.PlanID]; !ok {
+		return fmt.Errorf("error al crear suscripción: plan no encontrado con id: %s", subscription.PlanID)
+	}
+
+	now := time.Now().UTC()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	r.store.subscriptions[subscription.ID] = cloneSubscription(subscription)
+	return nil
+}
+
+// GetByID obtiene una suscripción por su ID
+func (r *UserSubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.UserSubscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	subscription, ok := r.store.subscriptions[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
+	}
+	return cloneSubscription(subscription), nil
+}
+
+// GetActiveByUserID obtiene la suscripción activa de un usuario. Devuelve nil si no tiene.
+func (r *UserSubscriptionRepository) GetActiveByUserID(ctx context.Context, userID string) (*domain.UserSubscription, error) {
+	now := time.Now()
+	subscriptions := r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.UserID == userID &&
+			subscription.Status == domain.SubscriptionStatusActive &&
+			subscription.EndDate.After(now)
+	}, func(a, b *domain.UserSubscription) bool {
+		return a.EndDate.After(b.EndDate)
+	})
+
+	if len(subscriptions) == 0 {
+		return nil, nil // No hay suscripción activa
+	}
+	return subscriptions[0], nil
+}
+
+// GetAllByUserID obtiene todas las suscripciones de un usuario
+func (r *UserSubscriptionRepository) GetAllByUserID(ctx context.Context, userID string) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.UserID == userID
+	}, byCreatedAtDesc), nil
+}
+
+// GetByStatus obtiene suscripciones por estado
+func (r *UserSubscriptionRepository) GetByStatus(ctx context.Context, status domain.SubscriptionStatus) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.Status == status
+	}, byCreatedAtDesc), nil
+}
+
+// GetExpiringSubscriptions obtiene suscripciones que expirarán pronto
+func (r *UserSubscriptionRepository) GetExpiringSubscriptions(ctx context.Context, now, beforeDate time.Time) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.Status == domain.SubscriptionStatusActive &&
+			!subscription.EndDate.After(beforeDate) &&
+			subscription.EndDate.After(now)
+	}, byEndDate), nil
+}
+
+// GetPendingRenewals obtiene suscripciones pendientes de renovación
+func (r *UserSubscriptionRepository) GetPendingRenewals(ctx context.Context, beforeDate time.Time) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.Status == domain.SubscriptionStatusActive &&
+			subscription.RenewalDate != nil &&
+			!subscription.RenewalDate.After(beforeDate)
+	}, func(a, b *domain.UserSubscription) bool {
+		return a.RenewalDate.Before(*b.RenewalDate)
+	}), nil
+}
+
+// GetExpired obtiene suscripciones activas cuya fecha de finalización ya pasó
+func (r *UserSubscriptionRepository) GetExpired(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.Status == domain.SubscriptionStatusActive &&
+			!subscription.EndDate.After(now)
+	}, byEndDate), nil
+}
+
+// GetDueForPaymentRetry obtiene suscripciones con pago fallido cuyo próximo intento ya venció
+func (r *UserSubscriptionRepository) GetDueForPaymentRetry(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.Status == domain.SubscriptionStatusFailed &&
+			subscription.NextPaymentAttempt != nil &&
+			!subscription.NextPaymentAttempt.After(now)
+	}, func(a, b *domain.UserSubscription) bool {
+		return a.NextPaymentAttempt.Before(*b.NextPaymentAttempt)
+	}), nil
+}
+
+// GetBillableByPlanID obtiene las suscripciones activas o en reintento de cobro de una versión de plan
+func (r *UserSubscriptionRepository) GetBillableByPlanID(ctx context.Context, planID string) ([]*domain.UserSubscription, error) {
+	return r.filter(func(subscription *domain.UserSubscription) bool {
+		return subscription.PlanID == planID &&
+			(subscription.Status == domain.SubscriptionStatusActive || subscription.Status == domain.SubscriptionStatusFailed)
+	}, byEndDate), nil
+}
+
+// CountByPlan cuenta las suscripciones de cada versión de plan; solo completa PlanID y los contadores
+func (r *UserSubscriptionRepository) CountByPlan(ctx context.Context) (map[string]*domain.PlanUsage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	usage := make(map[string]*domain.PlanUsage)
+	for _, subscription := range r.store.subscriptions {
+		planUsage, ok := usage[subscription.PlanID]
+		if !ok {
+			planUsage = &domain.PlanUsage{PlanID: subscription.PlanID}
+			usage[subscription.PlanID] = planUsage
+		}
+		planUsage.TotalSubscriptions++
+		switch subscription.Status {
+		case domain.SubscriptionStatusActive, domain.SubscriptionStatusPending, domain.SubscriptionStatusFailed:
+			planUsage.ActiveSubscribers++
+		}
+	}
+	return usage, nil
+}
+
+// Update actualiza una suscripción existente
+func (r *UserSubscriptionRepository) Update(ctx context.Context, subscription *domain.UserSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	subscription.UpdatedAt = time.Now().UTC()
+
+	stored, ok := r.store.subscriptions[subscription.ID]
+	if !ok {
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", subscription.ID)
+	}
+
+	updated := cloneSubscription(subscription)
+	updated.CreatedAt = stored.CreatedAt
+	r.store.subscriptions[subscription.ID] = updated
+	return nil
+}
+
+// UpdateStatus actualiza el estado de una suscripción
+func (r *UserSubscriptionRepository) UpdateStatus(ctx context.Context, id string, status domain.SubscriptionStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	subscription, ok := r.store.subscriptions[id]
+	if !ok {
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
+	}
+	subscription.Status = status
+	subscription.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// CancelSubscription cancela una suscripción y guarda sus metadatos
+func (r *UserSubscriptionRepository) CancelSubscription(ctx context.Context, id string, cancellationDate time.Time, metadata map[string]string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	subscription, ok := r.store.subscriptions[id]
+	if !ok {
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
+	}
+	subscription.Status = domain.SubscriptionStatusCancelled
+	subscription.CancellationDate = cloneTime(&cancellationDate)
+	subscription.Metadata = cloneMetadata(metadata)
+	subscription.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Delete elimina una suscripción por su ID
+func (r *UserSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.subscriptions[id]; !ok {
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
+	}
+	delete(r.store.subscriptions, id)
+	return nil
+}
+
+// filter devuelve copias de las suscripciones que cumplen match, ordenadas con less
+func (r *UserSubscriptionRepository) filter(
+	match func(*domain.UserSubscription) bool,
+	less func(a, b *domain.UserSubscription) bool,
+) []*domain.UserSubscription {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var subscriptions []*domain.UserSubscription
+	for _, subscription := range r.store.subscriptions {
+		if match(subscription) {
+			subscriptions = append(subscriptions, cloneSubscription(subscription))
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if less(subscriptions[i], subscriptions[j]) {
+			return true
+		}
+		if less(subscriptions[j], subscriptions[i]) {
+			return false
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions
+}
+
+// byCreatedAtDesc ordena de la suscripción más reciente a la más antigua
+func byCreatedAtDesc(a, b *domain.UserSubscription) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// byEndDate ordena por fecha de finalización ascendente
+func byEndDate(a, b *domain.UserSubscription) bool {
+	return a.EndDate.Before(b.EndDate)
+}
@@ -0,0 +1,141 @@
+// Package metrics implementa app.Metrics con Prometheus y expone las métricas HTTP, las del pool
+// de conexiones de database/sql y las de negocio en un único registro.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// namespace prefija todas las métricas de la aplicación
+const namespace = "mymoney"
+
+// Prometheus implementa app.Metrics y registra también las métricas HTTP
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	transactions  prometheus.Counter
+	logins        *prometheus.CounterVec
+	subscriptions *prometheus.CounterVec
+	schedulerRuns *prometheus.CounterVec
+}
+
+// NewPrometheus crea las métricas en un registro propio, incluidas las del runtime de Go, las del
+// proceso y las del pool de conexiones de db
+func NewPrometheus(db *sql.DB) *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Peticiones HTTP atendidas por método, ruta y estado.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latencia de las peticiones HTTP por método y ruta.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		transactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_created_total",
+			Help:      "Transacciones creadas.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Intentos de inicio de sesión por resultado.",
+		}, []string{"result"}),
+		subscriptions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subscription_events_total",
+			Help:      "Suscripciones creadas, canceladas y renovadas.",
+		}, []string{"event"}),
+		schedulerRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scheduler_runs_total",
+			Help:      "Ejecuciones del planificador de suscripciones por resultado.",
+		}, []string{"outcome"}),
+	}
+
+	// Inicializar las series conocidas para que se expongan en cero desde el arranque
+	for _, result := range []string{"success", "failure"} {
+		p.logins.WithLabelValues(result)
+	}
+	for _, event := range []string{"created", "cancelled", "renewed"} {
+		p.subscriptions.WithLabelValues(event)
+	}
+	for _, outcome := range []string{app.SchedulerRunSucceeded, app.SchedulerRunFailed, app.SchedulerRunSkipped} {
+		p.schedulerRuns.WithLabelValues(outcome)
+	}
+
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, namespace),
+		p.httpRequests,
+		p.httpDuration,
+		p.transactions,
+		p.logins,
+		p.subscriptions,
+		p.schedulerRuns,
+	)
+	return p
+}
+
+// Handler devuelve el handler HTTP que expone las métricas en el formato de Prometheus
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest registra una petición HTTP atendida. route es la ruta registrada
+// (/api/users/:id), no la URL, para acotar el número de series.
+func (p *Prometheus) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	p.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	p.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// TransactionCreated cuenta una transacción creada
+func (p *Prometheus) TransactionCreated() {
+	p.transactions.Inc()
+}
+
+// LoginSucceeded cuenta un inicio de sesión correcto
+func (p *Prometheus) LoginSucceeded() {
+	p.logins.WithLabelValues("success").Inc()
+}
+
+// LoginFailed cuenta un inicio de sesión fallido
+func (p *Prometheus) LoginFailed() {
+	p.logins.WithLabelValues("failure").Inc()
+}
+
+// SubscriptionCreated cuenta una suscripción creada
+func (p *Prometheus) SubscriptionCreated() {
+	p.subscriptions.WithLabelValues("created").Inc()
+}
+
+// SubscriptionCancelled cuenta una suscripción cancelada
+func (p *Prometheus) SubscriptionCancelled() {
+	p.subscriptions.WithLabelValues("cancelled").Inc()
+}
+
+// SubscriptionRenewed cuenta una suscripción renovada
+func (p *Prometheus) SubscriptionRenewed() {
+	p.subscriptions.WithLabelValues("renewed").Inc()
+}
+
+// SchedulerRun cuenta una ejecución del planificador con su resultado
+func (p *Prometheus) SchedulerRun(outcome string) {
+	p.schedulerRuns.WithLabelValues(outcome).Inc()
+}
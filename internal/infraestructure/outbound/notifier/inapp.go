@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// InAppNotifier guarda las notificaciones en la bandeja de entrada del usuario
+type InAppNotifier struct {
+	repo app.NotificationRepository
+}
+
+// NewInAppNotifier crea un nuevo notificador de bandeja de entrada
+func NewInAppNotifier(repo app.NotificationRepository) *InAppNotifier {
+	return &InAppNotifier{
+		repo: repo,
+	}
+}
+
+// Channel devuelve el canal de bandeja de entrada
+func (n *InAppNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelInApp
+}
+
+// Send guarda el mensaje como notificación del usuario
+func (n *InAppNotifier) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	return n.repo.Create(ctx, &domain.Notification{
+		ID:        uuid.New().String(),
+		UserID:    message.UserID,
+		Event:     message.Event,
+		Subject:   message.Subject,
+		Body:      message.Body,
+		Data:      message.Data,
+		CreatedAt: message.CreatedAt,
+	})
+}
@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// SMTPConfig contiene la configuración del servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier envía notificaciones por correo electrónico mediante SMTP
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier crea un nuevo notificador de correo electrónico
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.Port == "" {
+		config.Port = "25"
+	}
+	return &SMTPNotifier{
+		config: config,
+	}
+}
+
+// Channel devuelve el canal de correo electrónico
+func (n *SMTPNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelEmail
+}
+
+// Send envía el mensaje al email indicado como destinatario
+func (n *SMTPNotifier) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	if message.Recipient == "" {
+		return fmt.Errorf("email recipient is required")
+	}
+
+	addr := net.JoinHostPort(n.config.Host, n.config.Port)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error creating SMTP client: %w", err)
+	}
+	defer client.Close()
+
+	// Usar STARTTLS si el servidor lo soporta (los sumideros locales normalmente no)
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("error authenticating with SMTP server: %w", err)
+		}
+	}
+
+	// SMTP_FROM puede incluir un nombre ("MyMoney <no-reply@mymoney.com>"); el sobre solo usa la dirección
+	from := n.config.From
+	if address, err := mail.ParseAddress(from); err == nil {
+		from = address.Address
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	if err := client.Rcpt(message.Recipient); err != nil {
+		return fmt.Errorf("error setting recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message data: %w", err)
+	}
+	if _, err := writer.Write(n.buildMessage(message)); err != nil {
+		writer.Close()
+		return fmt.Errorf("error writing message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage construye el mensaje en formato RFC 5322
+func (n *SMTPNotifier) buildMessage(message *domain.NotificationMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + n.config.From + "\r\n")
+	b.WriteString("To: " + message.Recipient + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	b.WriteString("Date: " + message.CreatedAt.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("X-MyMoney-Event: " + string(message.Event) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
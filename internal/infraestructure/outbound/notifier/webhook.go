@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+const (
+	// SignatureHeader contiene la firma HMAC-SHA256 del cuerpo de la petición
+	SignatureHeader = "X-MyMoney-Signature"
+	// TimestampHeader contiene el timestamp Unix usado en la firma
+	TimestampHeader = "X-MyMoney-Timestamp"
+	// EventHeader contiene el evento que originó la notificación
+	EventHeader = "X-MyMoney-Event"
+)
+
+// ErrBlockedAddress indica que el webhook resolvió a una dirección interna o no enrutable
+var ErrBlockedAddress = errors.New("webhook destination address is not allowed")
+
+// webhookPayload representa el cuerpo enviado al webhook
+type webhookPayload struct {
+	Event     domain.NotificationEvent `json:"event"`
+	UserID    string                   `json:"user_id"`
+	Subject   string                   `json:"subject"`
+	Body      string                   `json:"body"`
+	Data      map[string]string        `json:"data"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+// WebhookNotifier envía notificaciones firmadas a un webhook HTTP
+type WebhookNotifier struct {
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier crea un nuevo notificador de webhooks. Sin client usa NewWebhookClient.
+func NewWebhookNotifier(secret string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = NewWebhookClient()
+	}
+	return &WebhookNotifier{
+		secret: secret,
+		client: client,
+	}
+}
+
+// NewWebhookClient crea el cliente HTTP de los webhooks. Las URLs las eligen los usuarios, así que
+// comprueba cada dirección ya resuelta al conectar (un nombre puede resolver a la red interna),
+// no usa proxies y no sigue redirecciones.
+func NewWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !domain.IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Channel devuelve el canal de webhooks
+func (n *WebhookNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelWebhook
+}
+
+// Send envía el mensaje a la URL indicada como destinatario
+func (n *WebhookNotifier) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	if message.Recipient == "" {
+		return fmt.Errorf("webhook URL is required")
+	}
+	// Las preferencias guardadas antes de validar la URL también se comprueban al enviar
+	if err := domain.ValidateWebhookURL(message.Recipient); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:     message.Event,
+		UserID:    message.UserID,
+		Subject:   message.Subject,
+		Body:      message.Body,
+		Data:      message.Data,
+		CreatedAt: message.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(message.Event))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(n.secret, timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign calcula la firma HMAC-SHA256 de "timestamp.body" con el secreto compartido.
+// Los receptores deben recalcularla para verificar el origen del webhook.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,175 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// Tarjetas de prueba reconocidas por FakeGateway. Siguen los números de prueba de Stripe
+// para que los mismos datos funcionen contra el proveedor falso y contra un servidor de pruebas.
+const (
+	TestCardVisa              = "4242424242424242"
+	TestCardMastercard        = "5555555555554444"
+	TestCardDeclined          = "4000000000000002"
+	TestCardInsufficientFunds = "4000000000009995"
+	TestCardExpired           = "4000000000000069"
+)
+
+// Tokens que devuelve FakeGateway. Codifican el resultado del cobro, de modo que el
+// proveedor falso no necesita guardar estado entre reinicios.
+const (
+	TokenVisa              = "tok_visa"
+	TokenMastercard        = "tok_mastercard"
+	TokenDeclined          = "tok_chargeDeclined"
+	TokenInsufficientFunds = "tok_chargeDeclinedInsufficientFunds"
+	TokenExpired           = "tok_chargeDeclinedExpiredCard"
+)
+
+// fakeDecline describe el rechazo asociado a un token de prueba
+type fakeDecline struct {
+	code    string
+	message string
+}
+
+var fakeDeclines = map[string]fakeDecline{
+	TokenDeclined:          {code: "card_declined", message: "la tarjeta fue rechazada"},
+	TokenInsufficientFunds: {code: "insufficient_funds", message: "la tarjeta no tiene fondos suficientes"},
+	TokenExpired:           {code: "expired_card", message: "la tarjeta está vencida"},
+}
+
+var fakeTokens = map[string]*domain.CardToken{
+	TestCardVisa:              {Token: TokenVisa, Brand: "visa", Last4: "4242"},
+	TestCardMastercard:        {Token: TokenMastercard, Brand: "mastercard", Last4: "4444"},
+	TestCardDeclined:          {Token: TokenDeclined, Brand: "visa", Last4: "0002"},
+	TestCardInsufficientFunds: {Token: TokenInsufficientFunds, Brand: "visa", Last4: "9995"},
+	TestCardExpired:           {Token: TokenExpired, Brand: "visa", Last4: "0069"},
+}
+
+// FakeGateway implementa app.PaymentGateway en memoria con resultados deterministas.
+// Los cobros con tokens de rechazo fallan y el resto se aprueba; se usa en desarrollo y pruebas.
+type FakeGateway struct {
+	mu          sync.Mutex
+	sequence    int
+	charges     map[string]*domain.Charge
+	refunded    map[string]float64
+	idempotency map[string]*domain.Charge
+}
+
+// NewFakeGateway crea una nueva pasarela de pago falsa
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		charges:     make(map[string]*domain.Charge),
+		refunded:    make(map[string]float64),
+		idempotency: make(map[string]*domain.Charge),
+	}
+}
+
+// Tokenize devuelve el token de prueba de la tarjeta; las tarjetas desconocidas se aprueban como visa
+func (g *FakeGateway) Tokenize(ctx context.Context, card *domain.CardDetails) (*domain.CardToken, error) {
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+
+	if token, ok := fakeTokens[card.Number]; ok {
+		result := *token
+		return &result, nil
+	}
+
+	return &domain.CardToken{
+		Token: TokenVisa,
+		Brand: "visa",
+		Last4: card.Number[len(card.Number)-4:],
+	}, nil
+}
+
+// Charge registra un cobro aprobado o rechazado según el token recibido
+func (g *FakeGateway) Charge(ctx context.Context, request *domain.ChargeRequest) (*domain.Charge, error) {
+	if request.Amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	if !strings.HasPrefix(request.Token, "tok_") {
+		return nil, fmt.Errorf("%w: token de tarjeta no válido", domain.ErrPaymentFailed)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if request.IdempotencyKey != "" {
+		if previous, ok := g.idempotency[request.IdempotencyKey]; ok {
+			return g.result(previous)
+		}
+	}
+
+	g.sequence++
+	charge := &domain.Charge{
+		ID:        fmt.Sprintf("ch_fake_%06d", g.sequence),
+		Status:    domain.ChargeStatusSucceeded,
+		Amount:    request.Amount,
+		Currency:  strings.ToUpper(request.Currency),
+		CreatedAt: time.Now(),
+	}
+	if decline, ok := fakeDeclines[request.Token]; ok {
+		charge.Status = domain.ChargeStatusFailed
+		charge.FailureCode = decline.code
+		charge.FailureMessage = decline.message
+	}
+
+	g.charges[charge.ID] = charge
+	if request.IdempotencyKey != "" {
+		g.idempotency[request.IdempotencyKey] = charge
+	}
+
+	return g.result(charge)
+}
+
+// Refund devuelve total o parcialmente un cobro aprobado
+func (g *FakeGateway) Refund(ctx context.Context, chargeID string, amount float64, currency string) (*domain.Refund, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return nil, fmt.Errorf("cobro no encontrado: %s", chargeID)
+	}
+	if charge.Status != domain.ChargeStatusSucceeded {
+		return nil, errors.New("solo se pueden reembolsar cobros aprobados")
+	}
+	if !strings.EqualFold(currency, charge.Currency) {
+		return nil, fmt.Errorf("el reembolso debe hacerse en la moneda del cobro (%s)", charge.Currency)
+	}
+
+	available := charge.Amount - g.refunded[chargeID]
+	if amount == 0 {
+		amount = available
+	}
+	if amount <= 0 || amount > available {
+		return nil, fmt.Errorf("el monto a reembolsar supera lo disponible (%.2f)", available)
+	}
+
+	g.sequence++
+	g.refunded[chargeID] += amount
+
+	return &domain.Refund{
+		ID:        fmt.Sprintf("re_fake_%06d", g.sequence),
+		ChargeID:  chargeID,
+		Amount:    amount,
+		Currency:  charge.Currency,
+		Status:    "succeeded",
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// result devuelve una copia del cobro y el error de rechazo si corresponde
+func (g *FakeGateway) result(charge *domain.Charge) (*domain.Charge, error) {
+	result := *charge
+	if result.Status == domain.ChargeStatusFailed {
+		return &result, fmt.Errorf("%w: %s", domain.ErrPaymentFailed, result.FailureMessage)
+	}
+	return &result, nil
+}
@@ -0,0 +1,310 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// DefaultStripeBaseURL es la URL de la API de Stripe
+const DefaultStripeBaseURL = "https://api.stripe.com"
+
+// zeroDecimalCurrencies son las monedas que Stripe cobra sin decimales
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "JPY": true, "KMF": true,
+	"KRW": true, "MGA": true, "PYG": true, "RWF": true, "UGX": true, "VND": true,
+	"VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// StripeGateway implementa app.PaymentGateway contra la API de Stripe o cualquier servidor compatible
+// (por ejemplo stripe-mock en desarrollo).
+type StripeGateway struct {
+	baseURL   string
+	secretKey string
+	client    *http.Client
+}
+
+// NewStripeGateway crea una nueva pasarela de pago compatible con Stripe
+func NewStripeGateway(baseURL, secretKey string, client *http.Client) *StripeGateway {
+	if baseURL == "" {
+		baseURL = DefaultStripeBaseURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &StripeGateway{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		secretKey: secretKey,
+		client:    client,
+	}
+}
+
+// stripeTokenSeparator separa el cliente y el método de pago en el token que se guarda
+const stripeTokenSeparator = ":"
+
+// stripeError representa el cuerpo de error de la API de Stripe
+type stripeError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+		Charge      string `json:"charge"`
+	} `json:"error"`
+}
+
+// stripePaymentMethod representa un método de pago con tarjeta de la API de Stripe
+type stripePaymentMethod struct {
+	ID   string `json:"id"`
+	Card struct {
+		Brand string `json:"brand"`
+		Last4 string `json:"last4"`
+	} `json:"card"`
+}
+
+// stripeCustomer representa un cliente de la API de Stripe
+type stripeCustomer struct {
+	ID string `json:"id"`
+}
+
+// stripePaymentIntent representa un intento de cobro de la API de Stripe
+type stripePaymentIntent struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	Amount           int64  `json:"amount"`
+	Currency         string `json:"currency"`
+	LatestCharge     string `json:"latest_charge"`
+	Created          int64  `json:"created"`
+	LastPaymentError *struct {
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"last_payment_error"`
+}
+
+// stripeRefund representa un reembolso de la API de Stripe
+type stripeRefund struct {
+	ID       string `json:"id"`
+	Charge   string `json:"charge"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+	Created  int64  `json:"created"`
+}
+
+// Tokenize registra la tarjeta como método de pago de un cliente nuevo de Stripe. Los tokens de
+// tarjeta de Stripe solo sirven para un cobro; el método de pago adjunto a un cliente se puede
+// cobrar en cada renovación. El token devuelto guarda ambos identificadores.
+func (g *StripeGateway) Tokenize(ctx context.Context, card *domain.CardDetails) (*domain.CardToken, error) {
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("type", "card")
+	form.Set("card[number]", card.Number)
+	form.Set("card[exp_month]", strconv.Itoa(card.ExpMonth))
+	form.Set("card[exp_year]", strconv.Itoa(card.ExpYear))
+	form.Set("card[cvc]", card.CVC)
+
+	var paymentMethod stripePaymentMethod
+	if apiErr, err := g.post(ctx, "/v1/payment_methods", form, "", &paymentMethod); err != nil {
+		if apiErr != nil && apiErr.Error.Type == "card_error" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPaymentFailed, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("error al registrar tarjeta: %w", err)
+	}
+
+	// Crear el cliente con el método de pago lo adjunta y lo deja como predeterminado
+	form = url.Values{}
+	form.Set("payment_method", paymentMethod.ID)
+	form.Set("invoice_settings[default_payment_method]", paymentMethod.ID)
+
+	var customer stripeCustomer
+	if apiErr, err := g.post(ctx, "/v1/customers", form, "", &customer); err != nil {
+		if apiErr != nil && apiErr.Error.Type == "card_error" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPaymentFailed, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("error al crear cliente: %w", err)
+	}
+
+	return &domain.CardToken{
+		Token: customer.ID + stripeTokenSeparator + paymentMethod.ID,
+		Brand: strings.ToLower(paymentMethod.Card.Brand),
+		Last4: paymentMethod.Card.Last4,
+	}, nil
+}
+
+// Charge cobra el monto indicado al método de pago guardado del cliente, sin que el usuario esté presente
+func (g *StripeGateway) Charge(ctx context.Context, request *domain.ChargeRequest) (*domain.Charge, error) {
+	if request.Amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	customerID, paymentMethodID, ok := strings.Cut(request.Token, stripeTokenSeparator)
+	if !ok || customerID == "" || paymentMethodID == "" {
+		return nil, fmt.Errorf("%w: token de tarjeta no válido, vuelve a registrar la tarjeta", domain.ErrPaymentFailed)
+	}
+
+	currency := strings.ToUpper(request.Currency)
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(toMinorUnits(request.Amount, currency), 10))
+	form.Set("currency", strings.ToLower(currency))
+	form.Set("customer", customerID)
+	form.Set("payment_method", paymentMethodID)
+	form.Set("confirm", "true")
+	form.Set("off_session", "true")
+	if request.Description != "" {
+		form.Set("description", request.Description)
+	}
+	for key, value := range request.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	var intent stripePaymentIntent
+	apiErr, err := g.post(ctx, "/v1/payment_intents", form, request.IdempotencyKey, &intent)
+	if err != nil && apiErr == nil {
+		return nil, fmt.Errorf("error al realizar cobro: %w", err)
+	}
+
+	// Stripe responde 402 con un error de tipo card_error cuando la tarjeta se rechaza
+	if apiErr != nil {
+		if apiErr.Error.Type != "card_error" {
+			return nil, fmt.Errorf("error al realizar cobro: %w", err)
+		}
+
+		code := apiErr.Error.DeclineCode
+		if code == "" {
+			code = apiErr.Error.Code
+		}
+		failed := &domain.Charge{
+			ID:             apiErr.Error.Charge,
+			Status:         domain.ChargeStatusFailed,
+			Amount:         request.Amount,
+			Currency:       currency,
+			FailureCode:    code,
+			FailureMessage: apiErr.Error.Message,
+			CreatedAt:      time.Now(),
+		}
+		return failed, fmt.Errorf("%w: %s", domain.ErrPaymentFailed, apiErr.Error.Message)
+	}
+
+	// Los reembolsos se piden sobre el cobro, así que se identifica por él y no por el intento
+	result := &domain.Charge{
+		ID:        intent.LatestCharge,
+		Status:    domain.ChargeStatusSucceeded,
+		Amount:    fromMinorUnits(intent.Amount, currency),
+		Currency:  strings.ToUpper(intent.Currency),
+		CreatedAt: time.Unix(intent.Created, 0),
+	}
+	if result.ID == "" {
+		result.ID = intent.ID
+	}
+	if intent.Status != "succeeded" {
+		result.Status = domain.ChargeStatusFailed
+		result.FailureCode = intent.Status
+		result.FailureMessage = fmt.Sprintf("el cobro quedó en estado %s", intent.Status)
+		if paymentErr := intent.LastPaymentError; paymentErr != nil {
+			result.FailureCode = paymentErr.DeclineCode
+			if result.FailureCode == "" {
+				result.FailureCode = paymentErr.Code
+			}
+			result.FailureMessage = paymentErr.Message
+		}
+		return result, fmt.Errorf("%w: %s", domain.ErrPaymentFailed, result.FailureMessage)
+	}
+
+	return result, nil
+}
+
+// Refund devuelve total o parcialmente un cobro; amount cero devuelve el total.
+// Stripe no pide la moneda en el reembolso: currency, la del cobro, solo sirve para convertir
+// el monto parcial a la unidad mínima. El monto devuelto se convierte con la moneda de la respuesta.
+func (g *StripeGateway) Refund(ctx context.Context, chargeID string, amount float64, currency string) (*domain.Refund, error) {
+	currency = strings.ToUpper(currency)
+	form := url.Values{}
+	form.Set("charge", chargeID)
+	if amount > 0 {
+		form.Set("amount", strconv.FormatInt(toMinorUnits(amount, currency), 10))
+	}
+
+	var refund stripeRefund
+	if _, err := g.post(ctx, "/v1/refunds", form, "", &refund); err != nil {
+		return nil, fmt.Errorf("error al reembolsar cobro: %w", err)
+	}
+	if refund.Currency != "" {
+		currency = strings.ToUpper(refund.Currency)
+	}
+
+	return &domain.Refund{
+		ID:        refund.ID,
+		ChargeID:  refund.Charge,
+		Amount:    fromMinorUnits(refund.Amount, currency),
+		Currency:  currency,
+		Status:    refund.Status,
+		CreatedAt: time.Unix(refund.Created, 0),
+	}, nil
+}
+
+// post envía una petición form-urlencoded a la API y decodifica la respuesta en out.
+// Si la API responde con un error se devuelve también su cuerpo decodificado.
+func (g *StripeGateway) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) (*stripeError, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(g.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr stripeError
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Error.Message == "" {
+			return nil, fmt.Errorf("la pasarela respondió con estado %d", resp.StatusCode)
+		}
+		return &apiErr, fmt.Errorf("la pasarela respondió con estado %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("respuesta de la pasarela no válida: %w", err)
+	}
+	return nil, nil
+}
+
+// toMinorUnits convierte un monto a la unidad mínima de la moneda (centavos, salvo monedas sin decimales)
+func toMinorUnits(amount float64, currency string) int64 {
+	if zeroDecimalCurrencies[currency] {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+// fromMinorUnits convierte un monto en unidad mínima a la unidad principal de la moneda
+func fromMinorUnits(amount int64, currency string) float64 {
+	if zeroDecimalCurrencies[currency] {
+		return float64(amount)
+	}
+	return float64(amount) / 100
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// cleanupInterval es cada cuánto se borran las ventanas vencidas de rate_limits
+const cleanupInterval = time.Minute
+
+// DatabaseStore implementa app.RateLimitStore con una ventana fija por clave en la tabla
+// rate_limits, compartida por todas las instancias. Funciona en PostgreSQL y en SQLite.
+type DatabaseStore struct {
+	db *sql.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewDatabaseStore crea un almacén sobre db
+func NewDatabaseStore(db *sql.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+// Take cuenta la petición en la ventana actual de key. El incremento es atómico, así que
+// instancias concurrentes nunca superan el límite entre todas.
+func (s *DatabaseStore) Take(ctx context.Context, key string, rule domain.RateLimitRule) (app.RateLimitResult, error) {
+	now := time.Now()
+	period := rule.Period.Milliseconds()
+	windowStart := now.UnixMilli() / period * period
+	windowEnd := windowStart + period
+	s.cleanup(ctx, now)
+
+	var hits int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO rate_limits (bucket, window_start, window_end, hits)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (bucket, window_start) DO UPDATE SET hits = rate_limits.hits + 1
+		RETURNING hits
+	`, key, windowStart, windowEnd).Scan(&hits)
+	if err != nil {
+		return app.RateLimitResult{}, fmt.Errorf("error al contar la petición: %w", err)
+	}
+
+	reset := time.UnixMilli(windowEnd).Sub(now)
+	result := app.RateLimitResult{
+		Allowed:   hits <= rule.Requests,
+		Limit:     rule.Requests,
+		Remaining: max(rule.Requests-hits, 0),
+		Reset:     reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	return result, nil
+}
+
+// cleanup borra, como mucho una vez por cleanupInterval, las ventanas ya terminadas.
+// Un fallo no impide contar la petición.
+func (s *DatabaseStore) cleanup(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE window_end <= $1`, now.UnixMilli()); err != nil {
+		slog.WarnContext(ctx, "error al borrar las ventanas vencidas del limitador", "error", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Package ratelimit implementa los almacenes del limitador de peticiones: un token bucket en
+// memoria para una sola instancia y una ventana fija en la base de datos compartida entre instancias.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// sweepInterval es cada cuánto se descartan los buckets que ya se llenaron de nuevo
+const sweepInterval = time.Minute
+
+// bucket guarda los tokens disponibles de una clave
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // Cuándo vuelve a estar lleno si no se consume más
+}
+
+// MemoryStore implementa app.RateLimitStore con un token bucket por clave: la capacidad es
+// rule.Requests y se recupera a razón de rule.Requests por rule.Period. Cada instancia cuenta
+// por separado.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore crea un almacén en memoria vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take consume un token de key si hay alguno disponible
+func (s *MemoryStore) Take(_ context.Context, key string, rule domain.RateLimitRule) (app.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	capacity := float64(rule.Requests)
+	perToken := rule.Period / time.Duration(rule.Requests)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	elapsed := now.Sub(b.updated)
+	b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(perToken))
+	b.updated = now
+
+	result := app.RateLimitResult{Limit: rule.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+	b.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep descarta los buckets llenos, que equivalen a uno nuevo, para no crecer sin límite
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -13,13 +13,13 @@ import (
 
 // CategoryRepository implementa la interfaz repositories.CategoryRepository
 type CategoryRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewCategoryRepository crea un nuevo repositorio de categorías
 func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
@@ -36,10 +36,11 @@ func (r *CategoryRepository) Create(ctx context.Context, category *domain.Catego
 	category.UpdatedAt = now
 
 	query := `
-		INSERT INTO categories (id, name, description, color, icon, user_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO categories (id, name, description, color, icon, user_id, monthly_budget, budget_currency_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
+	budget, budgetCurrencyID := budgetColumns(category.Budget)
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
@@ -49,6 +50,8 @@ func (r *CategoryRepository) Create(ctx context.Context, category *domain.Catego
 		category.Color,
 		category.Icon,
 		category.UserID,
+		budget,
+		budgetCurrencyID,
 		category.CreatedAt,
 		category.UpdatedAt,
 	)
@@ -59,23 +62,12 @@ func (r *CategoryRepository) Create(ctx context.Context, category *domain.Catego
 // GetByID obtiene una categoría por su ID
 func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
 	query := `
-		SELECT id, name, description, color, icon, user_id, created_at, updated_at
+		SELECT id, name, description, color, icon, user_id, monthly_budget, budget_currency_id, created_at, updated_at
 		FROM categories
 		WHERE id = $1
 	`
 
-	var category domain.Category
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&category.ID,
-		&category.Name,
-		&category.Description,
-		&category.Color,
-		&category.Icon,
-		&category.UserID,
-		&category.CreatedAt,
-		&category.UpdatedAt,
-	)
-
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // No se encontró la categoría
@@ -83,13 +75,13 @@ func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Ca
 		return nil, err
 	}
 
-	return &category, nil
+	return category, nil
 }
 
 // GetByUserID obtiene todas las categorías de un usuario
 func (r *CategoryRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.Category, error) {
 	query := `
-		SELECT id, name, description, color, icon, user_id, created_at, updated_at
+		SELECT id, name, description, color, icon, user_id, monthly_budget, budget_currency_id, created_at, updated_at
 		FROM categories
 		WHERE user_id = $1
 		ORDER BY name ASC
@@ -103,20 +95,11 @@ func (r *CategoryRepository) GetByUserID(ctx context.Context, userID string) ([]
 
 	var categories []*domain.Category
 	for rows.Next() {
-		var category domain.Category
-		if err := rows.Scan(
-			&category.ID,
-			&category.Name,
-			&category.Description,
-			&category.Color,
-			&category.Icon,
-			&category.UserID,
-			&category.CreatedAt,
-			&category.UpdatedAt,
-		); err != nil {
+		category, err := scanCategory(rows)
+		if err != nil {
 			return nil, err
 		}
-		categories = append(categories, &category)
+		categories = append(categories, category)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -132,10 +115,11 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 
 	query := `
 		UPDATE categories
-		SET name = $1, description = $2, color = $3, icon = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, description = $2, color = $3, icon = $4, monthly_budget = $5, budget_currency_id = $6, updated_at = $7
+		WHERE id = $8
 	`
 
+	budget, budgetCurrencyID := budgetColumns(category.Budget)
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
@@ -143,6 +127,8 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		category.Description,
 		category.Color,
 		category.Icon,
+		budget,
+		budgetCurrencyID,
 		category.UpdatedAt,
 		category.ID,
 	)
@@ -156,3 +142,37 @@ func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
+
+// scanCategory lee una categoría de una fila con las columnas de GetByID
+func scanCategory(row rowScanner) (*domain.Category, error) {
+	var category domain.Category
+	var budget sql.NullFloat64
+	var budgetCurrencyID sql.NullString
+	if err := row.Scan(
+		&category.ID,
+		&category.Name,
+		&category.Description,
+		&category.Color,
+		&category.Icon,
+		&category.UserID,
+		&budget,
+		&budgetCurrencyID,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if budget.Valid {
+		category.Budget = &domain.CategoryBudget{Amount: budget.Float64, CurrencyID: budgetCurrencyID.String}
+	}
+	return &category, nil
+}
+
+// budgetColumns devuelve los valores de las columnas del presupuesto, NULL si no tiene
+func budgetColumns(budget *domain.CategoryBudget) (any, any) {
+	if budget == nil {
+		return nil, nil
+	}
+	return budget.Amount, budget.CurrencyID
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// CouponRepository implementa el puerto app.CouponRepository
+type CouponRepository struct {
+	db DBTX
+}
+
+// NewCouponRepository crea una nueva instancia de CouponRepository
+func NewCouponRepository(db *sql.DB) *CouponRepository {
+	return &CouponRepository{
+		db: traced(db),
+	}
+}
+
+const couponColumns = `
+	id, code, description, discount_type, percent_off, amount_off, currency_id,
+	duration, duration_periods, plan_ids, max_redemptions, times_redeemed,
+	expires_at, is_active, created_at, updated_at
+`
+
+// Create crea un nuevo cupón en la base de datos
+func (r *CouponRepository) Create(ctx context.Context, coupon *domain.Coupon) error {
+	if coupon.ID == "" {
+		coupon.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	coupon.CreatedAt = now
+	coupon.UpdatedAt = now
+
+	planIDsJSON, err := marshalPlanIDs(coupon.PlanIDs)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO coupons (` + couponColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		coupon.ID,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.PercentOff,
+		coupon.AmountOff,
+		coupon.CurrencyID,
+		coupon.Duration,
+		coupon.DurationPeriods,
+		planIDsJSON,
+		coupon.MaxRedemptions,
+		coupon.TimesRedeemed,
+		coupon.ExpiresAt,
+		coupon.IsActive,
+		coupon.CreatedAt,
+		coupon.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear cupón: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID obtiene un cupón por su ID
+func (r *CouponRepository) GetByID(ctx context.Context, id string) (*domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByCode obtiene un cupón por su código normalizado
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE code = $1`
+	return r.getOne(ctx, query, code)
+}
+
+// GetAll obtiene todos los cupones
+func (r *CouponRepository) GetAll(ctx context.Context) ([]*domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var coupons []*domain.Coupon
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear cupón: %w", err)
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar resultados: %w", err)
+	}
+
+	return coupons, nil
+}
+
+// Update actualiza un cupón existente. El contador de canjes no se modifica aquí.
+func (r *CouponRepository) Update(ctx context.Context, coupon *domain.Coupon) error {
+	coupon.UpdatedAt = time.Now()
+
+	planIDsJSON, err := marshalPlanIDs(coupon.PlanIDs)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE coupons
+		SET
+			code = $2,
+			description = $3,
+			discount_type = $4,
+			percent_off = $5,
+			amount_off = $6,
+			currency_id = $7,
+			duration = $8,
+			duration_periods = $9,
+			plan_ids = $10,
+			max_redemptions = $11,
+			expires_at = $12,
+			is_active = $13,
+			updated_at = $14
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		coupon.ID,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.PercentOff,
+		coupon.AmountOff,
+		coupon.CurrencyID,
+		coupon.Duration,
+		coupon.DurationPeriods,
+		planIDsJSON,
+		coupon.MaxRedemptions,
+		coupon.ExpiresAt,
+		coupon.IsActive,
+		coupon.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar cupón: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("coupon", "cupón no encontrado con id: %s", coupon.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina un cupón por su ID
+func (r *CouponRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar cupón: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("coupon", "cupón no encontrado con id: %s", id)
+	}
+
+	return nil
+}
+
+// Redeem registra el canje en una transacción. El contador solo se incrementa si el cupón
+// sigue activo, no expiró y no alcanzó su límite, por lo que dos canjes simultáneos
+// no pueden superar max_redemptions.
+func (r *CouponRepository) Redeem(ctx context.Context, redemption *domain.CouponRedemption) error {
+	if redemption.ID == "" {
+		redemption.ID = uuid.New().String()
+	}
+	if redemption.RedeemedAt.IsZero() {
+		redemption.RedeemedAt = time.Now()
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO coupon_redemptions (id, coupon_id, user_id, subscription_id, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (coupon_id, user_id) DO NOTHING`,
+		redemption.ID,
+		redemption.CouponID,
+		redemption.UserID,
+		redemption.SubscriptionID,
+		redemption.RedeemedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al registrar canje: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("%w: ya canjeaste este cupón", domain.ErrCouponNotRedeemable)
+	}
+
+	result, err = tx.ExecContext(
+		ctx,
+		`UPDATE coupons
+		SET times_redeemed = times_redeemed + 1, updated_at = $3
+		WHERE id = $1
+			AND is_active = TRUE
+			AND (expires_at IS NULL OR expires_at > $2)
+			AND (max_redemptions IS NULL OR times_redeemed < max_redemptions)`,
+		redemption.CouponID,
+		redemption.RedeemedAt,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar canjes del cupón: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("%w: el cupón expiró o alcanzó su límite de canjes", domain.ErrCouponNotRedeemable)
+	}
+
+	return tx.Commit()
+}
+
+// ReleaseRedemption elimina el canje de una suscripción y libera el cupo del cupón
+func (r *CouponRepository) ReleaseRedemption(ctx context.Context, couponID, subscriptionID string) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM coupon_redemptions WHERE coupon_id = $1 AND subscription_id = $2`,
+		couponID,
+		subscriptionID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al eliminar canje: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE coupons
+		SET times_redeemed = CASE WHEN times_redeemed > 0 THEN times_redeemed - 1 ELSE 0 END, updated_at = $2
+		WHERE id = $1`,
+		couponID,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar canjes del cupón: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// getOne ejecuta una consulta que devuelve un único cupón; devuelve nil si no existe
+func (r *CouponRepository) getOne(ctx context.Context, query string, args ...interface{}) (*domain.Coupon, error) {
+	coupon, err := scanCoupon(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener cupón: %w", err)
+	}
+	return coupon, nil
+}
+
+// scanCoupon escanea una fila de la tabla coupons
+func scanCoupon(row rowScanner) (*domain.Coupon, error) {
+	var (
+		coupon         domain.Coupon
+		currencyID     sql.NullString
+		planIDsJSON    []byte
+		maxRedemptions sql.NullInt64
+		expiresAt      sql.NullTime
+	)
+
+	if err := row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Description,
+		&coupon.DiscountType,
+		&coupon.PercentOff,
+		&coupon.AmountOff,
+		&currencyID,
+		&coupon.Duration,
+		&coupon.DurationPeriods,
+		&planIDsJSON,
+		&maxRedemptions,
+		&coupon.TimesRedeemed,
+		&expiresAt,
+		&coupon.IsActive,
+		&coupon.CreatedAt,
+		&coupon.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if currencyID.Valid {
+		coupon.CurrencyID = &currencyID.String
+	}
+	if maxRedemptions.Valid {
+		limit := int(maxRedemptions.Int64)
+		coupon.MaxRedemptions = &limit
+	}
+	if expiresAt.Valid {
+		coupon.ExpiresAt = &expiresAt.Time
+	}
+	if len(planIDsJSON) > 0 {
+		if err := json.Unmarshal(planIDsJSON, &coupon.PlanIDs); err != nil {
+			return nil, fmt.Errorf("error al deserializar planes del cupón: %w", err)
+		}
+	}
+
+	return &coupon, nil
+}
+
+// marshalPlanIDs serializa la lista de planes de un cupón; una lista vacía significa todos los planes
+func marshalPlanIDs(planIDs []string) ([]byte, error) {
+	if planIDs == nil {
+		planIDs = []string{}
+	}
+	planIDsJSON, err := json.Marshal(planIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar planes del cupón: %w", err)
+	}
+	return planIDsJSON, nil
+}
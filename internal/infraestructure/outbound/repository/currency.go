@@ -13,13 +13,13 @@ import (
 
 // CurrencyRepository implementa el puerto app.CurrencyRepository
 type CurrencyRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewCurrencyRepository crea una nueva instancia de CurrencyRepository
 func NewCurrencyRepository(db *sql.DB) *CurrencyRepository {
 	return &CurrencyRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
@@ -83,7 +83,7 @@ func (r *CurrencyRepository) GetByID(ctx context.Context, id string) (*domain.Cu
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("moneda no encontrada con id: %s", id)
+			return nil, domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", id)
 		}
 		return nil, fmt.Errorf("error al obtener moneda por id: %w", err)
 	}
@@ -112,7 +112,7 @@ func (r *CurrencyRepository) GetByCode(ctx context.Context, code string) (*domai
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("moneda no encontrada con código: %s", code)
+			return nil, domain.NewNotFoundError("currency", "moneda no encontrada con código: %s", code)
 		}
 		return nil, fmt.Errorf("error al obtener moneda por código: %w", err)
 	}
@@ -229,12 +229,33 @@ func (r *CurrencyRepository) Update(ctx context.Context, currency *domain.Curren
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("moneda no encontrada con id: %s", currency.ID)
+		return domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", currency.ID)
 	}
 
 	return nil
 }
 
+// CountReferences cuenta los planes, transacciones y cupones que referencian a la moneda
+func (r *CurrencyRepository) CountReferences(ctx context.Context, id string) (map[string]int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM plans WHERE currency_id = $1),
+			(SELECT COUNT(*) FROM transactions WHERE currency_id = $1),
+			(SELECT COUNT(*) FROM coupons WHERE currency_id = $1)
+	`
+
+	var plans, transactions, coupons int
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&plans, &transactions, &coupons); err != nil {
+		return nil, fmt.Errorf("error al contar referencias de la moneda: %w", err)
+	}
+
+	return map[string]int{
+		"plans":        plans,
+		"transactions": transactions,
+		"coupons":      coupons,
+	}, nil
+}
+
 // Delete elimina una moneda por su ID
 func (r *CurrencyRepository) Delete(ctx context.Context, id string) error {
 	query := `
@@ -253,7 +274,7 @@ func (r *CurrencyRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("moneda no encontrada con id: %s", id)
+		return domain.NewNotFoundError("currency", "moneda no encontrada con id: %s", id)
 	}
 
 	return nil
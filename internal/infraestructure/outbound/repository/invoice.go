@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// InvoiceRepository implementa el puerto app.InvoiceRepository
+type InvoiceRepository struct {
+	db DBTX
+}
+
+// NewInvoiceRepository crea una nueva instancia de InvoiceRepository
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{
+		db: traced(db),
+	}
+}
+
+const invoiceColumns = `
+	id, number, sequence, user_id, subscription_id, type, status,
+	plan_snapshot, line_items, currency, subtotal, tax_rate, tax, total,
+	charge_id, failure_reason, issued_at
+`
+
+// Create guarda una nueva factura. El número secuencial se obtiene en la misma transacción
+// bloqueando el contador del usuario, por lo que no hay huecos ni duplicados.
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertInvoice(ctx, tx, invoice); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertInvoice numera y guarda una factura dentro de la transacción tx
+func insertInvoice(ctx context.Context, tx DBTX, invoice *domain.Invoice) error {
+	if invoice.ID == "" {
+		invoice.ID = uuid.New().String()
+	}
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = time.Now()
+	}
+
+	planJSON, err := json.Marshal(invoice.Plan)
+	if err != nil {
+		return fmt.Errorf("error al serializar plan de la factura: %w", err)
+	}
+	lineItemsJSON, err := json.Marshal(invoice.LineItems)
+	if err != nil {
+		return fmt.Errorf("error al serializar líneas de la factura: %w", err)
+	}
+
+	sequenceQuery := `
+		INSERT INTO invoice_sequences (user_id, last_sequence)
+		VALUES ($1, 1)
+		ON CONFLICT (user_id) DO UPDATE SET last_sequence = invoice_sequences.last_sequence + 1
+		RETURNING last_sequence
+	`
+	if err := tx.QueryRowContext(ctx, sequenceQuery, invoice.UserID).Scan(&invoice.Sequence); err != nil {
+		return fmt.Errorf("error al obtener número de factura: %w", err)
+	}
+	invoice.Number = domain.FormatInvoiceNumber(invoice.Sequence)
+
+	query := `INSERT INTO invoices (` + invoiceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		invoice.ID,
+		invoice.Number,
+		invoice.Sequence,
+		invoice.UserID,
+		invoice.SubscriptionID,
+		invoice.Type,
+		invoice.Status,
+		planJSON,
+		lineItemsJSON,
+		invoice.Currency,
+		invoice.Subtotal,
+		invoice.TaxRate,
+		invoice.Tax,
+		invoice.Total,
+		invoice.ChargeID,
+		invoice.FailureReason,
+		invoice.IssuedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear factura: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID obtiene una factura por su ID
+func (r *InvoiceRepository) GetByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener factura: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// GetBySubscriptionID obtiene las facturas de una suscripción
+func (r *InvoiceRepository) GetBySubscriptionID(ctx context.Context, subscriptionID string) ([]*domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE subscription_id = $1 ORDER BY sequence DESC`
+	return r.queryInvoices(ctx, query, subscriptionID)
+}
+
+// GetByUserID obtiene las facturas de un usuario emitidas en el rango [from, to)
+func (r *InvoiceRepository) GetByUserID(ctx context.Context, userID string, from, to time.Time) ([]*domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices
+		WHERE user_id = $1 AND issued_at >= $2 AND issued_at < $3
+		ORDER BY sequence DESC`
+	return r.queryInvoices(ctx, query, userID, from, to)
+}
+
+// CreatePending guarda una factura que no se pudo emitir junto con el motivo
+func (r *InvoiceRepository) CreatePending(ctx context.Context, invoice *domain.Invoice, cause string) error {
+	if invoice.ID == "" {
+		invoice.ID = uuid.New().String()
+	}
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = time.Now()
+	}
+
+	invoiceJSON, err := json.Marshal(invoice)
+	if err != nil {
+		return fmt.Errorf("error al serializar factura pendiente: %w", err)
+	}
+
+	query := `
+		INSERT INTO pending_invoices (id, invoice, last_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+	`
+	if _, err := r.db.ExecContext(ctx, query, invoice.ID, invoiceJSON, cause, time.Now()); err != nil {
+		return fmt.Errorf("error al guardar factura pendiente: %w", err)
+	}
+
+	return nil
+}
+
+// GetPending obtiene las facturas pendientes más antiguas, hasta limit
+func (r *InvoiceRepository) GetPending(ctx context.Context, limit int) ([]*domain.PendingInvoice, error) {
+	query := `
+		SELECT invoice, attempts, last_error, created_at
+		FROM pending_invoices
+		ORDER BY created_at
+		LIMIT $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []*domain.PendingInvoice
+	for rows.Next() {
+		var (
+			item        domain.PendingInvoice
+			invoiceJSON []byte
+		)
+		if err := rows.Scan(&invoiceJSON, &item.Attempts, &item.LastError, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear factura pendiente: %w", err)
+		}
+		if err := json.Unmarshal(invoiceJSON, &item.Invoice); err != nil {
+			return nil, fmt.Errorf("error al deserializar factura pendiente: %w", err)
+		}
+		pending = append(pending, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar resultados: %w", err)
+	}
+
+	return pending, nil
+}
+
+// IssuePending emite una factura pendiente y la quita de las pendientes en la misma transacción.
+// Si un intento anterior llegó a guardar la factura solo la quita de las pendientes.
+func (r *InvoiceRepository) IssuePending(ctx context.Context, pending *domain.PendingInvoice) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM invoices WHERE id = $1)`, pending.Invoice.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("error al comprobar factura: %w", err)
+	}
+	if !exists {
+		if err := insertInvoice(ctx, tx, pending.Invoice); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pending_invoices WHERE id = $1`, pending.Invoice.ID); err != nil {
+		return fmt.Errorf("error al quitar factura pendiente: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// RecordPendingFailure registra un intento fallido de emitir una factura pendiente
+func (r *InvoiceRepository) RecordPendingFailure(ctx context.Context, id string, cause string) error {
+	query := `
+		UPDATE pending_invoices
+		SET attempts = attempts + 1, last_error = $2, updated_at = $3
+		WHERE id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, id, cause, time.Now()); err != nil {
+		return fmt.Errorf("error al registrar intento de factura pendiente: %w", err)
+	}
+	return nil
+}
+
+// queryInvoices ejecuta una consulta que devuelve múltiples facturas
+func (r *InvoiceRepository) queryInvoices(ctx context.Context, query string, args ...interface{}) ([]*domain.Invoice, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear factura: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar resultados: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// scanInvoice escanea una fila de la tabla invoices
+func scanInvoice(row rowScanner) (*domain.Invoice, error) {
+	var (
+		invoice       domain.Invoice
+		planJSON      []byte
+		lineItemsJSON []byte
+	)
+
+	if err := row.Scan(
+		&invoice.ID,
+		&invoice.Number,
+		&invoice.Sequence,
+		&invoice.UserID,
+		&invoice.SubscriptionID,
+		&invoice.Type,
+		&invoice.Status,
+		&planJSON,
+		&lineItemsJSON,
+		&invoice.Currency,
+		&invoice.Subtotal,
+		&invoice.TaxRate,
+		&invoice.Tax,
+		&invoice.Total,
+		&invoice.ChargeID,
+		&invoice.FailureReason,
+		&invoice.IssuedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(planJSON, &invoice.Plan); err != nil {
+		return nil, fmt.Errorf("error al deserializar plan de la factura: %w", err)
+	}
+	if err := json.Unmarshal(lineItemsJSON, &invoice.LineItems); err != nil {
+		return nil, fmt.Errorf("error al deserializar líneas de la factura: %w", err)
+	}
+
+	return &invoice, nil
+}
@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// NotificationRepository implementa el puerto app.NotificationRepository
+type NotificationRepository struct {
+	db DBTX
+}
+
+// NewNotificationRepository crea una nueva instancia de NotificationRepository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: traced(db),
+	}
+}
+
+// Create guarda una nueva notificación en la bandeja de entrada
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	dataJSON, err := json.Marshal(notification.Data)
+	if err != nil {
+		return fmt.Errorf("error al serializar datos de notificación: %w", err)
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, event, subject, body, data, read_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		notification.ID,
+		notification.UserID,
+		notification.Event,
+		notification.Subject,
+		notification.Body,
+		dataJSON,
+		notification.ReadAt,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear notificación: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID obtiene una notificación por su ID
+func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*domain.Notification, error) {
+	query := `
+		SELECT id, user_id, event, subject, body, data, read_at, created_at
+		FROM notifications
+		WHERE id = $1
+	`
+
+	notification, err := scanNotification(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener notificación por id: %w", err)
+	}
+
+	return notification, nil
+}
+
+// GetByUserID obtiene las notificaciones de un usuario, opcionalmente solo las no leídas
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, user_id, event, subject, body, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener notificaciones: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear notificación: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sobre notificaciones: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkAsRead marca una notificación como leída
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, id string) error {
+	query := `UPDATE notifications SET read_at = $2 WHERE id = $1 AND read_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, id, time.Now()); err != nil {
+		return fmt.Errorf("error al marcar notificación como leída: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAllAsRead marca todas las notificaciones de un usuario como leídas
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) error {
+	query := `UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, time.Now()); err != nil {
+		return fmt.Errorf("error al marcar notificaciones como leídas: %w", err)
+	}
+
+	return nil
+}
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanNotification escanea una fila de la tabla notifications
+func scanNotification(row rowScanner) (*domain.Notification, error) {
+	var (
+		notification domain.Notification
+		dataJSON     []byte
+		readAt       sql.NullTime
+	)
+
+	if err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Event,
+		&notification.Subject,
+		&notification.Body,
+		&dataJSON,
+		&readAt,
+		&notification.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+
+	if len(dataJSON) > 0 {
+		if err := json.Unmarshal(dataJSON, &notification.Data); err != nil {
+			return nil, fmt.Errorf("error al deserializar datos de notificación: %w", err)
+		}
+	}
+
+	return &notification, nil
+}
+
+// NotificationPreferenceRepository implementa el puerto app.NotificationPreferenceRepository
+type NotificationPreferenceRepository struct {
+	db DBTX
+}
+
+// NewNotificationPreferenceRepository crea una nueva instancia de NotificationPreferenceRepository
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		db: traced(db),
+	}
+}
+
+// GetByUserID obtiene todas las preferencias de un usuario
+func (r *NotificationPreferenceRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.NotificationPreference, error) {
+	query := `
+		SELECT id, user_id, event, channel, enabled, target, created_at, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY event, channel
+	`
+
+	return r.queryPreferences(ctx, query, userID)
+}
+
+// GetByUserAndEvent obtiene las preferencias de un usuario para un evento
+func (r *NotificationPreferenceRepository) GetByUserAndEvent(ctx context.Context, userID string, event domain.NotificationEvent) ([]*domain.NotificationPreference, error) {
+	query := `
+		SELECT id, user_id, event, channel, enabled, target, created_at, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1 AND event = $2
+		ORDER BY channel
+	`
+
+	return r.queryPreferences(ctx, query, userID, event)
+}
+
+// Upsert crea o actualiza la preferencia de un usuario para un evento y canal
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, preference *domain.NotificationPreference) error {
+	if preference.ID == "" {
+		preference.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if preference.CreatedAt.IsZero() {
+		preference.CreatedAt = now
+	}
+	preference.UpdatedAt = now
+
+	query := `
+		INSERT INTO notification_preferences (id, user_id, event, channel, enabled, target, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, event, channel) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+			target = EXCLUDED.target,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		preference.ID,
+		preference.UserID,
+		preference.Event,
+		preference.Channel,
+		preference.Enabled,
+		preference.Target,
+		preference.CreatedAt,
+		preference.UpdatedAt,
+	).Scan(&preference.ID, &preference.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error al guardar preferencia de notificación: %w", err)
+	}
+
+	return nil
+}
+
+// queryPreferences ejecuta una consulta y devuelve una lista de preferencias
+func (r *NotificationPreferenceRepository) queryPreferences(ctx context.Context, query string, args ...interface{}) ([]*domain.NotificationPreference, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var preferences []*domain.NotificationPreference
+	for rows.Next() {
+		var preference domain.NotificationPreference
+		if err := rows.Scan(
+			&preference.ID,
+			&preference.UserID,
+			&preference.Event,
+			&preference.Channel,
+			&preference.Enabled,
+			&preference.Target,
+			&preference.CreatedAt,
+			&preference.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear preferencia de notificación: %w", err)
+		}
+		preferences = append(preferences, &preference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sobre preferencias de notificación: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// NotificationTemplateRepository implementa el puerto app.NotificationTemplateRepository
+type NotificationTemplateRepository struct {
+	db DBTX
+}
+
+// NewNotificationTemplateRepository crea una nueva instancia de NotificationTemplateRepository
+func NewNotificationTemplateRepository(db *sql.DB) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{
+		db: traced(db),
+	}
+}
+
+// Get obtiene la plantilla de un evento y canal, o nil si no existe
+func (r *NotificationTemplateRepository) Get(ctx context.Context, event domain.NotificationEvent, channel domain.NotificationChannel) (*domain.NotificationTemplate, error) {
+	query := `
+		SELECT id, event, channel, subject, body, created_at, updated_at
+		FROM notification_templates
+		WHERE event = $1 AND channel = $2
+	`
+
+	var tmpl domain.NotificationTemplate
+	err := r.db.QueryRowContext(ctx, query, event, channel).Scan(
+		&tmpl.ID,
+		&tmpl.Event,
+		&tmpl.Channel,
+		&tmpl.Subject,
+		&tmpl.Body,
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener plantilla de notificación: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
+// GetAll obtiene todas las plantillas guardadas
+func (r *NotificationTemplateRepository) GetAll(ctx context.Context) ([]*domain.NotificationTemplate, error) {
+	query := `
+		SELECT id, event, channel, subject, body, created_at, updated_at
+		FROM notification_templates
+		ORDER BY event, channel
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener plantillas de notificación: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*domain.NotificationTemplate
+	for rows.Next() {
+		var tmpl domain.NotificationTemplate
+		if err := rows.Scan(
+			&tmpl.ID,
+			&tmpl.Event,
+			&tmpl.Channel,
+			&tmpl.Subject,
+			&tmpl.Body,
+			&tmpl.CreatedAt,
+			&tmpl.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear plantilla de notificación: %w", err)
+		}
+		templates = append(templates, &tmpl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sobre plantillas de notificación: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Upsert crea o actualiza la plantilla de un evento y canal
+func (r *NotificationTemplateRepository) Upsert(ctx context.Context, tmpl *domain.NotificationTemplate) error {
+	if tmpl.ID == "" {
+		tmpl.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if tmpl.CreatedAt.IsZero() {
+		tmpl.CreatedAt = now
+	}
+	tmpl.UpdatedAt = now
+
+	query := `
+		INSERT INTO notification_templates (id, event, channel, subject, body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (event, channel) DO UPDATE
+		SET subject = EXCLUDED.subject,
+			body = EXCLUDED.body,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		tmpl.ID,
+		tmpl.Event,
+		tmpl.Channel,
+		tmpl.Subject,
+		tmpl.Body,
+		tmpl.CreatedAt,
+		tmpl.UpdatedAt,
+	).Scan(&tmpl.ID, &tmpl.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error al guardar plantilla de notificación: %w", err)
+	}
+
+	return nil
+}
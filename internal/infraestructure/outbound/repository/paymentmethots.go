@@ -13,13 +13,13 @@ import (
 
 // PaymentMethodRepository implementa la interfaz app.PaymentMethodRepository
 type PaymentMethodRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPaymentMethodRepository crea un nuevo repositorio de métodos de pago
 func NewPaymentMethodRepository(db *sql.DB) *PaymentMethodRepository {
 	return &PaymentMethodRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
@@ -36,8 +36,8 @@ func (r *PaymentMethodRepository) Create(ctx context.Context, paymentMethod *dom
 	paymentMethod.UpdatedAt = now
 
 	query := `
-		INSERT INTO payment_methods (id, name, description, is_active, user_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO payment_methods (id, name, description, is_active, user_id, card_brand, card_last4, card_token, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.db.ExecContext(
@@ -48,6 +48,9 @@ func (r *PaymentMethodRepository) Create(ctx context.Context, paymentMethod *dom
 		paymentMethod.Description,
 		paymentMethod.IsActive,
 		paymentMethod.UserID,
+		paymentMethod.CardBrand,
+		paymentMethod.CardLast4,
+		paymentMethod.CardToken,
 		paymentMethod.CreatedAt,
 		paymentMethod.UpdatedAt,
 	)
@@ -58,7 +61,7 @@ func (r *PaymentMethodRepository) Create(ctx context.Context, paymentMethod *dom
 // GetByID obtiene un método de pago por su ID
 func (r *PaymentMethodRepository) GetByID(ctx context.Context, id string) (*domain.PaymentMethod, error) {
 	query := `
-		SELECT id, name, description, is_active, user_id, created_at, updated_at
+		SELECT id, name, description, is_active, user_id, card_brand, card_last4, card_token, created_at, updated_at
 		FROM payment_methods
 		WHERE id = $1
 	`
@@ -70,6 +73,9 @@ func (r *PaymentMethodRepository) GetByID(ctx context.Context, id string) (*doma
 		&paymentMethod.Description,
 		&paymentMethod.IsActive,
 		&paymentMethod.UserID,
+		&paymentMethod.CardBrand,
+		&paymentMethod.CardLast4,
+		&paymentMethod.CardToken,
 		&paymentMethod.CreatedAt,
 		&paymentMethod.UpdatedAt,
 	)
@@ -87,7 +93,7 @@ func (r *PaymentMethodRepository) GetByID(ctx context.Context, id string) (*doma
 // GetByUserID obtiene todos los métodos de pago de un usuario
 func (r *PaymentMethodRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.PaymentMethod, error) {
 	query := `
-		SELECT id, name, description, is_active, user_id, created_at, updated_at
+		SELECT id, name, description, is_active, user_id, card_brand, card_last4, card_token, created_at, updated_at
 		FROM payment_methods
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -108,6 +114,9 @@ func (r *PaymentMethodRepository) GetByUserID(ctx context.Context, userID string
 			&paymentMethod.Description,
 			&paymentMethod.IsActive,
 			&paymentMethod.UserID,
+			&paymentMethod.CardBrand,
+			&paymentMethod.CardLast4,
+			&paymentMethod.CardToken,
 			&paymentMethod.CreatedAt,
 			&paymentMethod.UpdatedAt,
 		); err != nil {
@@ -129,8 +138,8 @@ func (r *PaymentMethodRepository) Update(ctx context.Context, paymentMethod *dom
 
 	query := `
 		UPDATE payment_methods
-		SET name = $1, description = $2, is_active = $3, updated_at = $4
-		WHERE id = $5
+		SET name = $1, description = $2, is_active = $3, card_brand = $4, card_last4 = $5, card_token = $6, updated_at = $7
+		WHERE id = $8
 	`
 
 	_, err := r.db.ExecContext(
@@ -139,6 +148,9 @@ func (r *PaymentMethodRepository) Update(ctx context.Context, paymentMethod *dom
 		paymentMethod.Name,
 		paymentMethod.Description,
 		paymentMethod.IsActive,
+		paymentMethod.CardBrand,
+		paymentMethod.CardLast4,
+		paymentMethod.CardToken,
 		paymentMethod.UpdatedAt,
 		paymentMethod.ID,
 	)
@@ -12,25 +12,92 @@ import (
 	"MyMoneyBackend/internal/domain"
 )
 
+// planColumns son las columnas que se leen de la tabla plans, en el orden de scanPlan.
+// Los planes anteriores al versionado no tienen familia y son su propia familia.
+const planColumns = `
+	id, COALESCE(family_id, id), version, superseded_at, archived_at, name, description, price, currency_id,
+	interval, interval_count, features, trial_days, entitlements, is_active, is_public, sort_order,
+	created_at, updated_at
+`
+
 // PlanRepository implementa el puerto app.PlanRepository
 type PlanRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPlanRepository crea una nueva instancia de PlanRepository
 func NewPlanRepository(db *sql.DB) *PlanRepository {
 	return &PlanRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
-// Create crea un nuevo plan en la base de datos
+// Create crea un nuevo plan en la base de datos como la primera versión de su familia
 func (r *PlanRepository) Create(ctx context.Context, plan *domain.Plan) error {
 	// Generar un ID único si no se proporciona uno
 	if plan.ID == "" {
 		plan.ID = uuid.New().String()
 	}
+	if plan.FamilyID == "" {
+		plan.FamilyID = plan.ID
+	}
+	if plan.Version == 0 {
+		plan.Version = 1
+	}
+
+	return insertPlan(ctx, r.db, plan)
+}
+
+// CreateVersion guarda next como la nueva versión vigente y marca previous como reemplazada.
+// Falla si previous ya fue reemplazada por otra versión.
+func (r *PlanRepository) CreateVersion(ctx context.Context, previous *domain.Plan, next *domain.Plan) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE plans SET superseded_at = $2, updated_at = $2 WHERE id = $1 AND superseded_at IS NULL`,
+		previous.ID,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("error al reemplazar versión del plan: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("la versión %d del plan %s ya fue reemplazada", previous.Version, previous.FamilyID)
+	}
 
+	if next.ID == "" {
+		next.ID = uuid.New().String()
+	}
+	if err := insertPlan(ctx, tx, next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar nueva versión del plan: %w", err)
+	}
+
+	previous.SupersededAt = &now
+	previous.UpdatedAt = now
+	return nil
+}
+
+// rowQuerier abstrae *sql.DB y *sql.Tx para consultas de una fila
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// insertPlan inserta una versión de plan usando la conexión o una transacción
+func insertPlan(ctx context.Context, db rowQuerier, plan *domain.Plan) error {
 	// Establecer fechas de creación y actualización
 	now := time.Now()
 	plan.CreatedAt = now
@@ -42,27 +109,40 @@ func (r *PlanRepository) Create(ctx context.Context, plan *domain.Plan) error {
 		return fmt.Errorf("error al serializar características: %w", err)
 	}
 
+	// Convertir los derechos de uso a JSON
+	entitlementsJSON, err := json.Marshal(plan.Entitlements)
+	if err != nil {
+		return fmt.Errorf("error al serializar derechos de uso: %w", err)
+	}
+
 	// Consulta SQL para insertar un nuevo plan
 	query := `
 		INSERT INTO plans (
-			id, name, description, price, currency_id, interval, 
-			features, is_active, is_public, sort_order, created_at, updated_at
+			id, family_id, version, superseded_at, name, description, price, currency_id,
+			interval, interval_count, features, trial_days, entitlements, is_active, is_public,
+			sort_order, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 		) RETURNING id
 	`
 
 	// Ejecutar la consulta
-	err = r.db.QueryRowContext(
+	err = db.QueryRowContext(
 		ctx,
 		query,
 		plan.ID,
+		plan.FamilyID,
+		plan.Version,
+		plan.SupersededAt,
 		plan.Name,
 		plan.Description,
 		plan.Price,
 		plan.CurrencyID,
 		plan.Interval,
+		plan.IntervalCount,
 		featuresJSON,
+		plan.TrialDays,
+		entitlementsJSON,
 		plan.IsActive,
 		plan.IsPublic,
 		plan.SortOrder,
@@ -77,92 +157,83 @@ func (r *PlanRepository) Create(ctx context.Context, plan *domain.Plan) error {
 	return nil
 }
 
-// GetByID obtiene un plan por su ID
+// GetByID obtiene una versión de plan por su ID
 func (r *PlanRepository) GetByID(ctx context.Context, id string) (*domain.Plan, error) {
-	query := `
-		SELECT 
-			id, name, description, price, currency_id, interval, 
-			features, is_active, is_public, sort_order, created_at, updated_at
-		FROM plans
-		WHERE id = $1
-	`
-
-	var (
-		plan         domain.Plan
-		featuresJSON []byte
-		intervalStr  string
-	)
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&plan.ID,
-		&plan.Name,
-		&plan.Description,
-		&plan.Price,
-		&plan.CurrencyID,
-		&intervalStr,
-		&featuresJSON,
-		&plan.IsActive,
-		&plan.IsPublic,
-		&plan.SortOrder,
-		&plan.CreatedAt,
-		&plan.UpdatedAt,
-	)
+	query := `SELECT ` + planColumns + ` FROM plans WHERE id = $1`
 
+	plan, err := scanPlan(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("plan no encontrado con id: %s", id)
+			return nil, domain.NewNotFoundError("plan", "plan no encontrado con id: %s", id)
 		}
 		return nil, fmt.Errorf("error al obtener plan por id: %w", err)
 	}
 
-	// Convertir el intervalo
-	plan.Interval = domain.PlanInterval(intervalStr)
+	return plan, nil
+}
 
-	// Decodificar las características
-	if len(featuresJSON) > 0 {
-		if err := json.Unmarshal(featuresJSON, &plan.Features); err != nil {
-			return nil, fmt.Errorf("error al deserializar características: %w", err)
+// GetLatestVersion obtiene la versión vigente de una familia de planes
+func (r *PlanRepository) GetLatestVersion(ctx context.Context, familyID string) (*domain.Plan, error) {
+	query := `
+		SELECT ` + planColumns + `
+		FROM plans
+		WHERE COALESCE(family_id, id) = $1 AND superseded_at IS NULL
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	plan, err := scanPlan(r.db.QueryRowContext(ctx, query, familyID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewNotFoundError("plan", "plan no encontrado con familia: %s", familyID)
 		}
+		return nil, fmt.Errorf("error al obtener versión vigente del plan: %w", err)
 	}
 
-	return &plan, nil
+	return plan, nil
 }
 
-// GetAll obtiene todos los planes
+// GetVersions obtiene todas las versiones de una familia de planes, de la más nueva a la más antigua
+func (r *PlanRepository) GetVersions(ctx context.Context, familyID string) ([]*domain.Plan, error) {
+	query := `
+		SELECT ` + planColumns + `
+		FROM plans
+		WHERE COALESCE(family_id, id) = $1
+		ORDER BY version DESC
+	`
+
+	return r.queryPlans(ctx, query, familyID)
+}
+
+// GetAll obtiene todos los planes, incluidas las versiones reemplazadas
 func (r *PlanRepository) GetAll(ctx context.Context) ([]*domain.Plan, error) {
 	query := `
-		SELECT 
-			id, name, description, price, currency_id, interval, 
-			features, is_active, is_public, sort_order, created_at, updated_at
+		SELECT ` + planColumns + `
 		FROM plans
-		ORDER BY sort_order ASC, name ASC
+		ORDER BY sort_order ASC, name ASC, version DESC
 	`
 
 	return r.queryPlans(ctx, query)
 }
 
-// GetAllPublic obtiene todos los planes públicos
+// GetAllPublic obtiene la versión vigente de todos los planes públicos
 func (r *PlanRepository) GetAllPublic(ctx context.Context) ([]*domain.Plan, error) {
 	query := `
-		SELECT 
-			id, name, description, price, currency_id, interval, 
-			features, is_active, is_public, sort_order, created_at, updated_at
+		SELECT ` + planColumns + `
 		FROM plans
-		WHERE is_public = true
+		WHERE is_public = true AND superseded_at IS NULL
 		ORDER BY sort_order ASC, name ASC
 	`
 
 	return r.queryPlans(ctx, query)
 }
 
-// GetAllActive obtiene todos los planes activos
+// GetAllActive obtiene la versión vigente de todos los planes activos
 func (r *PlanRepository) GetAllActive(ctx context.Context) ([]*domain.Plan, error) {
 	query := `
-		SELECT 
-			id, name, description, price, currency_id, interval, 
-			features, is_active, is_public, sort_order, created_at, updated_at
+		SELECT ` + planColumns + `
 		FROM plans
-		WHERE is_active = true
+		WHERE is_active = true AND superseded_at IS NULL
 		ORDER BY sort_order ASC, name ASC
 	`
 
@@ -179,40 +250,11 @@ func (r *PlanRepository) queryPlans(ctx context.Context, query string, args ...i
 
 	var plans []*domain.Plan
 	for rows.Next() {
-		var (
-			plan         domain.Plan
-			featuresJSON []byte
-			intervalStr  string
-		)
-
-		if err := rows.Scan(
-			&plan.ID,
-			&plan.Name,
-			&plan.Description,
-			&plan.Price,
-			&plan.CurrencyID,
-			&intervalStr,
-			&featuresJSON,
-			&plan.IsActive,
-			&plan.IsPublic,
-			&plan.SortOrder,
-			&plan.CreatedAt,
-			&plan.UpdatedAt,
-		); err != nil {
+		plan, err := scanPlan(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error al escanear plan: %w", err)
 		}
-
-		// Convertir el intervalo
-		plan.Interval = domain.PlanInterval(intervalStr)
-
-		// Decodificar las características
-		if len(featuresJSON) > 0 {
-			if err := json.Unmarshal(featuresJSON, &plan.Features); err != nil {
-				return nil, fmt.Errorf("error al deserializar características: %w", err)
-			}
-		}
-
-		plans = append(plans, &plan)
+		plans = append(plans, plan)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -222,7 +264,70 @@ func (r *PlanRepository) queryPlans(ctx context.Context, query string, args ...i
 	return plans, nil
 }
 
-// Update actualiza un plan existente
+// scanPlan escanea una fila con las columnas de planColumns
+func scanPlan(row rowScanner) (*domain.Plan, error) {
+	var (
+		plan             domain.Plan
+		featuresJSON     []byte
+		entitlementsJSON []byte
+		intervalStr      string
+		supersededAt     sql.NullTime
+		archivedAt       sql.NullTime
+	)
+
+	if err := row.Scan(
+		&plan.ID,
+		&plan.FamilyID,
+		&plan.Version,
+		&supersededAt,
+		&archivedAt,
+		&plan.Name,
+		&plan.Description,
+		&plan.Price,
+		&plan.CurrencyID,
+		&intervalStr,
+		&plan.IntervalCount,
+		&featuresJSON,
+		&plan.TrialDays,
+		&entitlementsJSON,
+		&plan.IsActive,
+		&plan.IsPublic,
+		&plan.SortOrder,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	// Convertir el intervalo
+	plan.Interval = domain.PlanInterval(intervalStr)
+
+	if supersededAt.Valid {
+		plan.SupersededAt = &supersededAt.Time
+	}
+	if archivedAt.Valid {
+		plan.ArchivedAt = &archivedAt.Time
+	}
+
+	// Decodificar las características
+	if len(featuresJSON) > 0 {
+		if err := json.Unmarshal(featuresJSON, &plan.Features); err != nil {
+			return nil, fmt.Errorf("error al deserializar características: %w", err)
+		}
+	}
+
+	// Decodificar los derechos de uso
+	if len(entitlementsJSON) > 0 {
+		if err := json.Unmarshal(entitlementsJSON, &plan.Entitlements); err != nil {
+			return nil, fmt.Errorf("error al deserializar derechos de uso: %w", err)
+		}
+	}
+
+	return &plan, nil
+}
+
+// Update actualiza los datos de una versión de plan en el lugar.
+// Los cambios de oferta deben crear una nueva versión con CreateVersion.
 func (r *PlanRepository) Update(ctx context.Context, plan *domain.Plan) error {
 	// Actualizar fecha de modificación
 	plan.UpdatedAt = time.Now()
@@ -233,19 +338,28 @@ func (r *PlanRepository) Update(ctx context.Context, plan *domain.Plan) error {
 		return fmt.Errorf("error al serializar características: %w", err)
 	}
 
+	// Convertir los derechos de uso a JSON
+	entitlementsJSON, err := json.Marshal(plan.Entitlements)
+	if err != nil {
+		return fmt.Errorf("error al serializar derechos de uso: %w", err)
+	}
+
 	query := `
 		UPDATE plans
-		SET 
+		SET
 			name = $2,
 			description = $3,
 			price = $4,
 			currency_id = $5,
 			interval = $6,
-			features = $7,
-			is_active = $8,
-			is_public = $9,
-			sort_order = $10,
-			updated_at = $11
+			interval_count = $7,
+			features = $8,
+			trial_days = $9,
+			entitlements = $10,
+			is_active = $11,
+			is_public = $12,
+			sort_order = $13,
+			updated_at = $14
 		WHERE id = $1
 	`
 
@@ -258,7 +372,10 @@ func (r *PlanRepository) Update(ctx context.Context, plan *domain.Plan) error {
 		plan.Price,
 		plan.CurrencyID,
 		plan.Interval,
+		plan.IntervalCount,
 		featuresJSON,
+		plan.TrialDays,
+		entitlementsJSON,
 		plan.IsActive,
 		plan.IsPublic,
 		plan.SortOrder,
@@ -275,17 +392,72 @@ func (r *PlanRepository) Update(ctx context.Context, plan *domain.Plan) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("plan no encontrado con id: %s", plan.ID)
+		return domain.NewNotFoundError("plan", "plan no encontrado con id: %s", plan.ID)
+	}
+
+	return nil
+}
+
+// Archive archiva todas las versiones de una familia de planes: dejan de estar activas y públicas
+// pero se conservan para sus suscriptores
+func (r *PlanRepository) Archive(ctx context.Context, familyID string, archivedAt time.Time) error {
+	query := `
+		UPDATE plans
+		SET archived_at = $2, is_active = false, is_public = false, updated_at = $2
+		WHERE COALESCE(family_id, id) = $1 AND archived_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, familyID, archivedAt)
+	if err != nil {
+		return fmt.Errorf("error al archivar plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("plan", "plan no encontrado o ya archivado con familia: %s", familyID)
 	}
 
 	return nil
 }
 
-// Delete elimina un plan por su ID
+// Unarchive quita el archivado de todas las versiones de una familia de planes. Solo la versión
+// vigente vuelve a estar activa; las reemplazadas siguen sin admitir nuevas suscripciones.
+func (r *PlanRepository) Unarchive(ctx context.Context, familyID string, isPublic bool) error {
+	query := `
+		UPDATE plans
+		SET archived_at = NULL,
+			is_active = CASE WHEN superseded_at IS NULL THEN true ELSE is_active END,
+			is_public = CASE WHEN superseded_at IS NULL THEN $2 ELSE is_public END,
+			updated_at = $3
+		WHERE COALESCE(family_id, id) = $1 AND archived_at IS NOT NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, familyID, isPublic, time.Now())
+	if err != nil {
+		return fmt.Errorf("error al desarchivar plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("plan", "plan no encontrado o no archivado con familia: %s", familyID)
+	}
+
+	return nil
+}
+
+// Delete elimina todas las versiones de la familia del plan indicado
 func (r *PlanRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		DELETE FROM plans
-		WHERE id = $1
+		WHERE COALESCE(family_id, id) = (SELECT COALESCE(family_id, id) FROM plans WHERE id = $1)
 	`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -299,7 +471,7 @@ func (r *PlanRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("plan no encontrado con id: %s", id)
+		return domain.NewNotFoundError("plan", "plan no encontrado con id: %s", id)
 	}
 
 	return nil
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"MyMoneyBackend/internal/tracing"
+)
+
+var tracer = otel.Tracer("MyMoneyBackend/repository")
+
+// tracedDB envuelve un DBTX y crea un span de cliente por cada consulta con el SQL sin valores.
+// Exec y QueryRow, sin contexto, no se trazan.
+type tracedDB struct {
+	DBTX
+	system attribute.KeyValue
+}
+
+// traced envuelve db para trazar sus consultas
+func traced(db *sql.DB) DBTX {
+	return tracedDB{DBTX: db, system: dbSystem(db)}
+}
+
+// dbSystem identifica la base de datos de db para los spans
+func dbSystem(db *sql.DB) attribute.KeyValue {
+	if _, ok := db.Driver().(*pq.Driver); ok {
+		return semconv.DBSystemPostgreSQL
+	}
+	return semconv.DBSystemSqlite
+}
+
+func (t tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	result, err := t.DBTX.ExecContext(ctx, query, args...)
+	end(span, err)
+	return result, err
+}
+
+func (t tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.DBTX.QueryContext(ctx, query, args...)
+	end(span, err)
+	return rows, err
+}
+
+func (t tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.DBTX.QueryRowContext(ctx, query, args...)
+	end(span, row.Err())
+	return row
+}
+
+// start abre el span de la consulta como hijo del span del contexto
+func (t tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := tracing.SQLOperation(query)
+	return tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			t.system,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(tracing.SanitizeSQL(query)),
+		),
+	)
+}
+
+// end cierra el span y lo marca con error si la consulta falló. No encontrar filas no es un error.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedTx es una transacción cuyas consultas se trazan
+type tracedTx struct {
+	tracedDB
+	tx txDB
+}
+
+func (t tracedTx) Commit() error   { return t.tx.Commit() }
+func (t tracedTx) Rollback() error { return t.tx.Rollback() }
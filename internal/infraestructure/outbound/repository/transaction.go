@@ -12,13 +12,13 @@ import (
 
 // TransactionRepository implements domain.TransactionRepository for PostgreSQL
 type TransactionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewTransactionRepository creates a new TransactionRepository
 func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
@@ -87,7 +87,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*domain
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("transaction not found: %w", err)
+			return nil, domain.NewNotFoundError("transaction", "transaction not found: %s", id)
 		}
 		return nil, fmt.Errorf("error getting transaction: %w", err)
 	}
@@ -155,6 +155,38 @@ func (r *TransactionRepository) GetByDateRange(ctx context.Context, userID strin
 	return r.scanTransactions(rows)
 }
 
+// CountCreatedBetween counts the transactions of a user recorded in [from, to), whatever their date
+func (r *TransactionRepository) CountCreatedBetween(ctx context.Context, userID string, from, to time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID, from, to).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting transactions: %w", err)
+	}
+
+	return count, nil
+}
+
+// SumExpenses adds up the expenses of a category in a currency dated in [from, to)
+func (r *TransactionRepository) SumExpenses(ctx context.Context, categoryID, currencyID string, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE category_id = $1 AND currency_id = $2 AND type = $3 AND date >= $4 AND date < $5
+	`
+
+	var total float64
+	if err := r.db.QueryRowContext(ctx, query, categoryID, currencyID, domain.TransactionTypeExpense, from, to).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error adding up expenses: %w", err)
+	}
+
+	return total, nil
+}
+
 // Update updates a transaction's information
 func (r *TransactionRepository) Update(ctx context.Context, transaction *domain.Transaction) error {
 	query := `
@@ -190,7 +222,7 @@ func (r *TransactionRepository) Update(ctx context.Context, transaction *domain.
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("transaction not found or does not belong to the user")
+		return domain.NewNotFoundError("transaction", "transaction not found or does not belong to the user")
 	}
 
 	transaction.UpdatedAt = now
@@ -213,7 +245,7 @@ func (r *TransactionRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("transaction not found")
+		return domain.NewNotFoundError("transaction", "transaction not found")
 	}
 
 	return nil
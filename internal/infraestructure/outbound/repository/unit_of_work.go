@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// DBTX abstrae *sql.DB y *sql.Tx para que los repositorios funcionen dentro o fuera de una transacción
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// txDB es una transacción en curso sobre la que un repositorio ejecuta varias consultas
+type txDB interface {
+	DBTX
+	Commit() error
+	Rollback() error
+}
+
+// beginTx inicia una transacción sobre db. Si el repositorio ya pertenece a una unidad de trabajo
+// se reutiliza su transacción y la confirmación o reversión queda a cargo de la unidad de trabajo.
+func beginTx(ctx context.Context, db DBTX) (txDB, error) {
+	switch conn := db.(type) {
+	case tracedDB:
+		tx, err := beginTx(ctx, conn.DBTX)
+		if err != nil {
+			return nil, err
+		}
+		return tracedTx{tracedDB: tracedDB{DBTX: tx, system: conn.system}, tx: tx}, nil
+	case *sql.DB:
+		return conn.BeginTx(ctx, nil)
+	case *sql.Tx:
+		return joinedTx{conn}, nil
+	default:
+		return nil, fmt.Errorf("la conexión %T no admite transacciones", db)
+	}
+}
+
+// joinedTx es una transacción ajena: confirmarla o revertirla no tiene efecto
+type joinedTx struct {
+	*sql.Tx
+}
+
+// Commit no confirma la transacción de la unidad de trabajo
+func (joinedTx) Commit() error { return nil }
+
+// Rollback no revierte la transacción de la unidad de trabajo; lo hace ella al recibir el error
+func (joinedTx) Rollback() error { return nil }
+
+// UnitOfWork implementa el puerto app.UnitOfWork sobre una transacción de database/sql
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork crea una nueva instancia de UnitOfWork
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{
+		db: db,
+	}
+}
+
+// Do ejecuta fn con repositorios ligados a una misma transacción
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos app.TxRepositories) error) (err error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(txRepositories(tracedDB{DBTX: tx, system: dbSystem(u.db)})); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.ErrorContext(ctx, "error al revertir transacción", "error", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+// txRepositories crea los repositorios ligados a la transacción
+func txRepositories(tx DBTX) app.TxRepositories {
+	return app.TxRepositories{
+		Users:          &UserRepository{db: tx},
+		Categories:     &CategoryRepository{db: tx},
+		PaymentMethods: &PaymentMethodRepository{db: tx},
+		Transactions:   &TransactionRepository{db: tx},
+		Currencies:     &CurrencyRepository{db: tx},
+		Plans:          &PlanRepository{db: tx},
+		Subscriptions:  &UserSubscriptionRepository{db: tx},
+		Coupons:        &CouponRepository{db: tx},
+		Invoices:       &InvoiceRepository{db: tx},
+	}
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"MyMoneyBackend/internal/domain"
@@ -12,18 +14,18 @@ import (
 
 // UserRepository implementa la interfaz app.UserRepository
 type UserRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewUserRepository crea un nuevo repositorio de usuarios
 func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
 // Create crea un nuevo usuario en la base de datos
-func (r *UserRepository) Create(user *domain.User) error {
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	if user.ID == "" {
 		user.ID = uuid.New().String()
 	}
@@ -39,7 +41,8 @@ func (r *UserRepository) Create(user *domain.User) error {
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	_, err := r.db.Exec(
+	_, err := r.db.ExecContext(
+		ctx,
 		query,
 		user.ID,
 		user.Email,
@@ -53,7 +56,7 @@ func (r *UserRepository) Create(user *domain.User) error {
 }
 
 // GetByID obtiene un usuario por su ID
-func (r *UserRepository) GetByID(id string) (*domain.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
 		SELECT id, email, name, password, created_at, updated_at
 		FROM users
@@ -61,7 +64,7 @@ func (r *UserRepository) GetByID(id string) (*domain.User, error) {
 	`
 
 	var user domain.User
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Name,
@@ -72,7 +75,7 @@ func (r *UserRepository) GetByID(id string) (*domain.User, error) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, domain.NewNotFoundError("user", "user not found")
 		}
 		return nil, err
 	}
@@ -81,7 +84,7 @@ func (r *UserRepository) GetByID(id string) (*domain.User, error) {
 }
 
 // GetByEmail obtiene un usuario por su email
-func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
 		SELECT id, email, name, password, created_at, updated_at
 		FROM users
@@ -89,7 +92,7 @@ func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 	`
 
 	var user domain.User
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Name,
@@ -100,7 +103,7 @@ func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, domain.NewNotFoundError("user", "user not found")
 		}
 		return nil, err
 	}
@@ -109,7 +112,7 @@ func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 }
 
 // Update actualiza la información de un usuario
-func (r *UserRepository) Update(user *domain.User) error {
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	user.UpdatedAt = time.Now()
 
 	query := `
@@ -118,7 +121,8 @@ func (r *UserRepository) Update(user *domain.User) error {
 		WHERE id = $5
 	`
 
-	result, err := r.db.Exec(
+	result, err := r.db.ExecContext(
+		ctx,
 		query,
 		user.Email,
 		user.Name,
@@ -137,16 +141,29 @@ func (r *UserRepository) Update(user *domain.User) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("user not found")
+		return domain.NewNotFoundError("user", "user not found")
 	}
 
 	return nil
 }
 
+// CountReferences cuenta las facturas del usuario: se conservan como historial de facturación
+// y su clave foránea impide eliminarlo
+func (r *UserRepository) CountReferences(ctx context.Context, id string) (map[string]int, error) {
+	query := `SELECT COUNT(*) FROM invoices WHERE user_id = $1`
+
+	var invoices int
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&invoices); err != nil {
+		return nil, fmt.Errorf("error al contar referencias del usuario: %w", err)
+	}
+
+	return map[string]int{"invoices": invoices}, nil
+}
+
 // Delete elimina un usuario
-func (r *UserRepository) Delete(id string) error {
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM users WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -157,7 +174,7 @@ func (r *UserRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("user not found")
+		return domain.NewNotFoundError("user", "user not found")
 	}
 
 	return nil
@@ -12,15 +12,23 @@ import (
 	"MyMoneyBackend/internal/domain"
 )
 
+// subscriptionColumns son las columnas que se leen de la tabla user_subscriptions, en el orden de scanSubscription
+const subscriptionColumns = `
+	id, user_id, plan_id, status, start_date, end_date,
+	renewal_date, cancellation_date, last_payment_date,
+	next_payment_attempt, trial_end_date, payment_method_id, metadata,
+	created_at, updated_at
+`
+
 // UserSubscriptionRepository implementa el puerto app.UserSubscriptionRepository
 type UserSubscriptionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewUserSubscriptionRepository crea una nueva instancia de UserSubscriptionRepository
 func NewUserSubscriptionRepository(db *sql.DB) *UserSubscriptionRepository {
 	return &UserSubscriptionRepository{
-		db: db,
+		db: traced(db),
 	}
 }
 
@@ -32,7 +40,7 @@ func (r *UserSubscriptionRepository) Create(ctx context.Context, subscription *d
 	}
 
 	// Establecer fechas de creación y actualización
-	now := time.Now()
+	now := time.Now().UTC()
 	subscription.CreatedAt = now
 	subscription.UpdatedAt = now
 
@@ -51,10 +59,10 @@ func (r *UserSubscriptionRepository) Create(ctx context.Context, subscription *d
 		INSERT INTO user_subscriptions (
 			id, user_id, plan_id, status, start_date, end_date, 
 			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
+			next_payment_attempt, trial_end_date, payment_method_id, metadata, 
 			created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		) RETURNING id
 	`
 
@@ -72,6 +80,7 @@ func (r *UserSubscriptionRepository) Create(ctx context.Context, subscription *d
 		subscription.CancellationDate,
 		subscription.LastPaymentDate,
 		subscription.NextPaymentAttempt,
+		subscription.TrialEndDate,
 		subscription.PaymentMethodID,
 		metadataJSON,
 		subscription.CreatedAt,
@@ -88,125 +97,39 @@ func (r *UserSubscriptionRepository) Create(ctx context.Context, subscription *d
 // GetByID obtiene una suscripción por su ID
 func (r *UserSubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE id = $1
 	`
 
-	var (
-		subscription    domain.UserSubscription
-		metadataJSON    []byte
-		renewalDate     sql.NullTime
-		cancelDate      sql.NullTime
-		lastPayDate     sql.NullTime
-		nextPayAttempt  sql.NullTime
-		paymentMethodID sql.NullString
-	)
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&subscription.ID,
-		&subscription.UserID,
-		&subscription.PlanID,
-		&subscription.Status,
-		&subscription.StartDate,
-		&subscription.EndDate,
-		&renewalDate,
-		&cancelDate,
-		&lastPayDate,
-		&nextPayAttempt,
-		&paymentMethodID,
-		&metadataJSON,
-		&subscription.CreatedAt,
-		&subscription.UpdatedAt,
-	)
-
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("suscripción no encontrada con id: %s", id)
+			return nil, domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
 		}
 		return nil, fmt.Errorf("error al obtener suscripción por id: %w", err)
 	}
 
-	// Convertir campos nulos
-	if renewalDate.Valid {
-		subscription.RenewalDate = &renewalDate.Time
-	}
-	if cancelDate.Valid {
-		subscription.CancellationDate = &cancelDate.Time
-	}
-	if lastPayDate.Valid {
-		subscription.LastPaymentDate = &lastPayDate.Time
-	}
-	if nextPayAttempt.Valid {
-		subscription.NextPaymentAttempt = &nextPayAttempt.Time
-	}
-	if paymentMethodID.Valid {
-		subscription.PaymentMethodID = &paymentMethodID.String
-	}
-
-	// Decodificar metadatos
-	if len(metadataJSON) > 0 {
-		var metadata map[string]string
-		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
-			return nil, fmt.Errorf("error al deserializar metadatos: %w", err)
-		}
-		subscription.Metadata = metadata
-	}
-
-	return &subscription, nil
+	return subscription, nil
 }
 
 // GetActiveByUserID obtiene la suscripción activa de un usuario
 func (r *UserSubscriptionRepository) GetActiveByUserID(ctx context.Context, userID string) (*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE user_id = $1 AND status = $2 AND end_date > $3
 		ORDER BY end_date DESC
 		LIMIT 1
 	`
 
-	var (
-		subscription    domain.UserSubscription
-		metadataJSON    []byte
-		renewalDate     sql.NullTime
-		cancelDate      sql.NullTime
-		lastPayDate     sql.NullTime
-		nextPayAttempt  sql.NullTime
-		paymentMethodID sql.NullString
-	)
-
-	err := r.db.QueryRowContext(
+	subscription, err := scanSubscription(r.db.QueryRowContext(
 		ctx,
 		query,
 		userID,
 		domain.SubscriptionStatusActive,
 		time.Now(),
-	).Scan(
-		&subscription.ID,
-		&subscription.UserID,
-		&subscription.PlanID,
-		&subscription.Status,
-		&subscription.StartDate,
-		&subscription.EndDate,
-		&renewalDate,
-		&cancelDate,
-		&lastPayDate,
-		&nextPayAttempt,
-		&paymentMethodID,
-		&metadataJSON,
-		&subscription.CreatedAt,
-		&subscription.UpdatedAt,
-	)
-
+	))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No hay suscripción activa
@@ -214,43 +137,13 @@ func (r *UserSubscriptionRepository) GetActiveByUserID(ctx context.Context, user
 		return nil, fmt.Errorf("error al obtener suscripción activa del usuario: %w", err)
 	}
 
-	// Convertir campos nulos
-	if renewalDate.Valid {
-		subscription.RenewalDate = &renewalDate.Time
-	}
-	if cancelDate.Valid {
-		subscription.CancellationDate = &cancelDate.Time
-	}
-	if lastPayDate.Valid {
-		subscription.LastPaymentDate = &lastPayDate.Time
-	}
-	if nextPayAttempt.Valid {
-		subscription.NextPaymentAttempt = &nextPayAttempt.Time
-	}
-	if paymentMethodID.Valid {
-		subscription.PaymentMethodID = &paymentMethodID.String
-	}
-
-	// Decodificar metadatos
-	if len(metadataJSON) > 0 {
-		var metadata map[string]string
-		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
-			return nil, fmt.Errorf("error al deserializar metadatos: %w", err)
-		}
-		subscription.Metadata = metadata
-	}
-
-	return &subscription, nil
+	return subscription, nil
 }
 
 // GetAllByUserID obtiene todas las suscripciones de un usuario
 func (r *UserSubscriptionRepository) GetAllByUserID(ctx context.Context, userID string) ([]*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -262,11 +155,7 @@ func (r *UserSubscriptionRepository) GetAllByUserID(ctx context.Context, userID
 // GetByStatus obtiene suscripciones por estado
 func (r *UserSubscriptionRepository) GetByStatus(ctx context.Context, status domain.SubscriptionStatus) ([]*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -276,29 +165,21 @@ func (r *UserSubscriptionRepository) GetByStatus(ctx context.Context, status dom
 }
 
 // GetExpiringSubscriptions obtiene suscripciones que expirarán pronto
-func (r *UserSubscriptionRepository) GetExpiringSubscriptions(ctx context.Context, beforeDate time.Time) ([]*domain.UserSubscription, error) {
+func (r *UserSubscriptionRepository) GetExpiringSubscriptions(ctx context.Context, now, beforeDate time.Time) ([]*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE status = $1 AND end_date <= $2 AND end_date > $3
 		ORDER BY end_date ASC
 	`
 
-	return r.querySubscriptions(ctx, query, domain.SubscriptionStatusActive, beforeDate, time.Now())
+	return r.querySubscriptions(ctx, query, domain.SubscriptionStatusActive, beforeDate, now)
 }
 
 // GetPendingRenewals obtiene suscripciones pendientes de renovación
 func (r *UserSubscriptionRepository) GetPendingRenewals(ctx context.Context, beforeDate time.Time) ([]*domain.UserSubscription, error) {
 	query := `
-		SELECT 
-			id, user_id, plan_id, status, start_date, end_date, 
-			renewal_date, cancellation_date, last_payment_date, 
-			next_payment_attempt, payment_method_id, metadata, 
-			created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM user_subscriptions
 		WHERE status = $1 AND renewal_date IS NOT NULL AND renewal_date <= $2
 		ORDER BY renewal_date ASC
@@ -307,6 +188,81 @@ func (r *UserSubscriptionRepository) GetPendingRenewals(ctx context.Context, bef
 	return r.querySubscriptions(ctx, query, domain.SubscriptionStatusActive, beforeDate)
 }
 
+// GetExpired obtiene suscripciones activas cuya fecha de finalización ya pasó
+func (r *UserSubscriptionRepository) GetExpired(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM user_subscriptions
+		WHERE status = $1 AND end_date <= $2
+		ORDER BY end_date ASC
+	`
+
+	return r.querySubscriptions(ctx, query, domain.SubscriptionStatusActive, now)
+}
+
+// GetDueForPaymentRetry obtiene suscripciones con pago fallido cuyo próximo intento ya venció
+func (r *UserSubscriptionRepository) GetDueForPaymentRetry(ctx context.Context, now time.Time) ([]*domain.UserSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM user_subscriptions
+		WHERE status = $1 AND next_payment_attempt IS NOT NULL AND next_payment_attempt <= $2
+		ORDER BY next_payment_attempt ASC
+	`
+
+	return r.querySubscriptions(ctx, query, domain.SubscriptionStatusFailed, now)
+}
+
+// GetBillableByPlanID obtiene las suscripciones activas o en reintento de cobro de una versión de plan
+func (r *UserSubscriptionRepository) GetBillableByPlanID(ctx context.Context, planID string) ([]*domain.UserSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM user_subscriptions
+		WHERE plan_id = $1 AND status IN ($2, $3)
+		ORDER BY end_date ASC
+	`
+
+	return r.querySubscriptions(ctx, query, planID, domain.SubscriptionStatusActive, domain.SubscriptionStatusFailed)
+}
+
+// CountByPlan cuenta las suscripciones de cada versión de plan; solo completa PlanID y los contadores
+func (r *UserSubscriptionRepository) CountByPlan(ctx context.Context) (map[string]*domain.PlanUsage, error) {
+	query := `
+		SELECT
+			plan_id,
+			SUM(CASE WHEN status IN ($1, $2, $3) THEN 1 ELSE 0 END),
+			COUNT(*)
+		FROM user_subscriptions
+		GROUP BY plan_id
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		domain.SubscriptionStatusActive,
+		domain.SubscriptionStatusPending,
+		domain.SubscriptionStatusFailed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al contar suscripciones por plan: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[string]*domain.PlanUsage)
+	for rows.Next() {
+		var planUsage domain.PlanUsage
+		if err := rows.Scan(&planUsage.PlanID, &planUsage.ActiveSubscribers, &planUsage.TotalSubscriptions); err != nil {
+			return nil, fmt.Errorf("error al escanear conteo de suscripciones: %w", err)
+		}
+		usage[planUsage.PlanID] = &planUsage
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sobre conteos de suscripciones: %w", err)
+	}
+
+	return usage, nil
+}
+
 // querySubscriptions ejecuta una consulta y devuelve una lista de suscripciones
 func (r *UserSubscriptionRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*domain.UserSubscription, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -317,62 +273,11 @@ func (r *UserSubscriptionRepository) querySubscriptions(ctx context.Context, que
 
 	var subscriptions []*domain.UserSubscription
 	for rows.Next() {
-		var (
-			subscription    domain.UserSubscription
-			metadataJSON    []byte
-			renewalDate     sql.NullTime
-			cancelDate      sql.NullTime
-			lastPayDate     sql.NullTime
-			nextPayAttempt  sql.NullTime
-			paymentMethodID sql.NullString
-		)
-
-		if err := rows.Scan(
-			&subscription.ID,
-			&subscription.UserID,
-			&subscription.PlanID,
-			&subscription.Status,
-			&subscription.StartDate,
-			&subscription.EndDate,
-			&renewalDate,
-			&cancelDate,
-			&lastPayDate,
-			&nextPayAttempt,
-			&paymentMethodID,
-			&metadataJSON,
-			&subscription.CreatedAt,
-			&subscription.UpdatedAt,
-		); err != nil {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error al escanear suscripción: %w", err)
 		}
-
-		// Convertir campos nulos
-		if renewalDate.Valid {
-			subscription.RenewalDate = &renewalDate.Time
-		}
-		if cancelDate.Valid {
-			subscription.CancellationDate = &cancelDate.Time
-		}
-		if lastPayDate.Valid {
-			subscription.LastPaymentDate = &lastPayDate.Time
-		}
-		if nextPayAttempt.Valid {
-			subscription.NextPaymentAttempt = &nextPayAttempt.Time
-		}
-		if paymentMethodID.Valid {
-			subscription.PaymentMethodID = &paymentMethodID.String
-		}
-
-		// Decodificar metadatos
-		if len(metadataJSON) > 0 {
-			var metadata map[string]string
-			if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
-				return nil, fmt.Errorf("error al deserializar metadatos: %w", err)
-			}
-			subscription.Metadata = metadata
-		}
-
-		subscriptions = append(subscriptions, &subscription)
+		subscriptions = append(subscriptions, subscription)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -382,6 +287,77 @@ func (r *UserSubscriptionRepository) querySubscriptions(ctx context.Context, que
 	return subscriptions, nil
 }
 
+// scanSubscription escanea una fila con las columnas de subscriptionColumns.
+// Las fechas se devuelven en UTC sin importar la zona horaria de la sesión de la base de datos,
+// para que los períodos se calculen igual en cualquier servidor.
+func scanSubscription(row rowScanner) (*domain.UserSubscription, error) {
+	var (
+		subscription    domain.UserSubscription
+		metadataJSON    []byte
+		renewalDate     sql.NullTime
+		cancelDate      sql.NullTime
+		lastPayDate     sql.NullTime
+		nextPayAttempt  sql.NullTime
+		trialEnd        sql.NullTime
+		paymentMethodID sql.NullString
+	)
+
+	if err := row.Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.PlanID,
+		&subscription.Status,
+		&subscription.StartDate,
+		&subscription.EndDate,
+		&renewalDate,
+		&cancelDate,
+		&lastPayDate,
+		&nextPayAttempt,
+		&trialEnd,
+		&paymentMethodID,
+		&metadataJSON,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	subscription.StartDate = subscription.StartDate.UTC()
+	subscription.EndDate = subscription.EndDate.UTC()
+	subscription.CreatedAt = subscription.CreatedAt.UTC()
+	subscription.UpdatedAt = subscription.UpdatedAt.UTC()
+
+	// Convertir campos nulos
+	subscription.RenewalDate = utcTime(renewalDate)
+	subscription.CancellationDate = utcTime(cancelDate)
+	subscription.LastPaymentDate = utcTime(lastPayDate)
+	subscription.NextPaymentAttempt = utcTime(nextPayAttempt)
+	subscription.TrialEndDate = utcTime(trialEnd)
+	if paymentMethodID.Valid {
+		subscription.PaymentMethodID = &paymentMethodID.String
+	}
+
+	// Decodificar metadatos
+	if len(metadataJSON) > 0 {
+		var metadata map[string]string
+		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+			return nil, fmt.Errorf("error al deserializar metadatos: %w", err)
+		}
+		subscription.Metadata = metadata
+	}
+
+	return &subscription, nil
+}
+
+// utcTime convierte una fecha nula en un puntero a la fecha en UTC, o nil si es nula
+func utcTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
+
 // Update actualiza una suscripción existente
 func (r *UserSubscriptionRepository) Update(ctx context.Context, subscription *domain.UserSubscription) error {
 	// Actualizar fecha de modificación
@@ -409,9 +385,10 @@ func (r *UserSubscriptionRepository) Update(ctx context.Context, subscription *d
 			cancellation_date = $8,
 			last_payment_date = $9,
 			next_payment_attempt = $10,
-			payment_method_id = $11,
-			metadata = $12,
-			updated_at = $13
+			trial_end_date = $11,
+			payment_method_id = $12,
+			metadata = $13,
+			updated_at = $14
 		WHERE id = $1
 	`
 
@@ -428,6 +405,7 @@ func (r *UserSubscriptionRepository) Update(ctx context.Context, subscription *d
 		subscription.CancellationDate,
 		subscription.LastPaymentDate,
 		subscription.NextPaymentAttempt,
+		subscription.TrialEndDate,
 		subscription.PaymentMethodID,
 		metadataJSON,
 		subscription.UpdatedAt,
@@ -443,7 +421,7 @@ func (r *UserSubscriptionRepository) Update(ctx context.Context, subscription *d
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("suscripción no encontrada con id: %s", subscription.ID)
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", subscription.ID)
 	}
 
 	return nil
@@ -477,20 +455,31 @@ func (r *UserSubscriptionRepository) UpdateStatus(ctx context.Context, id string
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("suscripción no encontrada con id: %s", id)
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
 	}
 
 	return nil
 }
 
-// CancelSubscription cancela una suscripción
-func (r *UserSubscriptionRepository) CancelSubscription(ctx context.Context, id string, cancellationDate time.Time) error {
+// CancelSubscription cancela una suscripción y guarda sus metadatos
+func (r *UserSubscriptionRepository) CancelSubscription(ctx context.Context, id string, cancellationDate time.Time, metadata map[string]string) error {
+	// Convertir los metadatos a JSON
+	var metadataJSON []byte
+	var err error
+	if metadata != nil {
+		metadataJSON, err = json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("error al serializar metadatos: %w", err)
+		}
+	}
+
 	query := `
 		UPDATE user_subscriptions
 		SET 
 			status = $2,
 			cancellation_date = $3,
-			updated_at = $4
+			metadata = $4,
+			updated_at = $5
 		WHERE id = $1
 	`
 
@@ -500,6 +489,7 @@ func (r *UserSubscriptionRepository) CancelSubscription(ctx context.Context, id
 		id,
 		domain.SubscriptionStatusCancelled,
 		cancellationDate,
+		metadataJSON,
 		time.Now(),
 	)
 
@@ -513,7 +503,7 @@ func (r *UserSubscriptionRepository) CancelSubscription(ctx context.Context, id
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("suscripción no encontrada con id: %s", id)
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
 	}
 
 	return nil
@@ -537,7 +527,7 @@ func (r *UserSubscriptionRepository) Delete(ctx context.Context, id string) erro
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("suscripción no encontrada con id: %s", id)
+		return domain.NewNotFoundError("subscription", "suscripción no encontrada con id: %s", id)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+// Package sqlite abre bases de datos SQLite compatibles con los repositorios de PostgreSQL.
+//
+// Los repositorios usan marcadores $N, que SQLite entiende por posición. Para que las consultas
+// por rango de fechas se comporten igual que en PostgreSQL, las fechas se guardan como texto UTC
+// de ancho fijo, que se ordena cronológicamente al compararlo como cadena. Los documentos JSON
+// (features, metadata, etc.) se guardan como texto.
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// DriverName es el nombre con el que se registra el driver en database/sql
+const DriverName = "mymoney-sqlite"
+
+// TimeFormat es el formato con el que se guardan las fechas. Las columnas de fecha deben
+// declararse como TIMESTAMP para que el driver las lea como time.Time.
+const TimeFormat = "2006-01-02 15:04:05.000000000Z"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Open abre la base de datos del archivo path con claves foráneas activas. Las transacciones
+// toman el bloqueo de escritura al empezar para no fallar a mitad de una lectura-escritura.
+func Open(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open(DriverName, "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir la base de datos SQLite: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error al conectar con la base de datos SQLite: %w", err)
+	}
+
+	return db, nil
+}
+
+// Driver envuelve el driver de modernc.org/sqlite para convertir los parámetros de las consultas
+type Driver struct {
+	sqlite.Driver
+}
+
+// Open abre una conexión con el DSN indicado
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c.(sqliteConn)}, nil
+}
+
+// sqliteConn son las capacidades de la conexión de modernc.org/sqlite que se reexponen
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+// conn es una conexión que convierte las fechas y los []byte antes de enviarlos a SQLite
+type conn struct {
+	sqliteConn
+}
+
+// CheckNamedValue convierte los parámetros: las fechas a TimeFormat en UTC y los []byte a texto.
+// Un []byte nil se guarda como NULL, igual que en PostgreSQL.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		value = v.UTC().Format(TimeFormat)
+	case []byte:
+		if v == nil {
+			value = nil
+		} else {
+			value = string(v)
+		}
+	}
+
+	nv.Value = value
+	return nil
+}
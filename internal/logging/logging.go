@@ -0,0 +1,116 @@
+// Package logging crea el logger estructurado (log/slog) de la aplicación: escribe JSON, añade el
+// ID de la petición y la traza guardados en el contexto y oculta emails, tokens y contraseñas.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redacted reemplaza a los valores sensibles
+const redacted = "****"
+
+// Atributos que se añaden a cada registro a partir del contexto
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+// sensitiveKeys son fragmentos de nombres de atributo cuyo valor nunca se registra
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "api_key", "apikey"}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+type requestIDContextKey struct{}
+
+// WithRequestID devuelve una copia de ctx con el ID de la petición
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestID devuelve el ID de la petición guardado en ctx, o "" si no hay
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// ParseLevel convierte debug, info, warn o error en un nivel de slog
+func ParseLevel(level string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("nivel de log no válido %q: %w", level, err)
+	}
+	return parsed, nil
+}
+
+// New crea un logger JSON que escribe en w a partir de level
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// contextHandler añade a cada registro el ID de la petición y la traza activa del contexto
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String(RequestIDKey, requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String(TraceIDKey, span.TraceID().String()),
+			slog.String(SpanIDKey, span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// redact oculta los atributos sensibles por nombre y los emails y tokens Bearer dentro de los textos,
+// incluido el mensaje y los errores
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, scrub(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, scrub(err.Error()))
+		}
+	}
+	return attr
+}
+
+// scrub oculta la parte local de los emails y los tokens Bearer de text
+func scrub(text string) string {
+	text = emailPattern.ReplaceAllString(text, redacted+"@$1")
+	return bearerPattern.ReplaceAllString(text, "Bearer "+redacted)
+}
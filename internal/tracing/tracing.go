@@ -0,0 +1,114 @@
+// Package tracing configura OpenTelemetry: el proveedor de trazas global, el exportador (OTLP/HTTP
+// o stdout) y la propagación W3C Trace Context entre servicios.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+// Setup instala el proveedor de trazas global según cfg y devuelve la función que envía las trazas
+// pendientes y lo detiene. Con el exportador none las trazas no se registran. stdout es el destino
+// del exportador stdout.
+func Setup(ctx context.Context, cfg domain.TracingConfig, stdout io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "otlp":
+		otlpExporter, err := newOTLPExporter(ctx, cfg.OTLPEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		exporter = otlpExporter
+	case "stdout":
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(stdout))
+		if err != nil {
+			return nil, fmt.Errorf("error al crear el exportador de trazas a stdout: %w", err)
+		}
+		exporter = stdoutExporter
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el recurso de trazas: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Respetar la decisión de muestreo de la traza recibida; muestrear solo las nuevas
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// newOTLPExporter crea el exportador OTLP/HTTP hacia la URL base endpoint (http://collector:4318)
+func newOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el endpoint OTLP %q: %w", endpoint, err)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(parsed.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(parsed.Path, "/") + "/v1/traces"),
+	}
+	if parsed.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el exportador OTLP: %w", err)
+	}
+	return exporter, nil
+}
+
+var (
+	whitespace    = regexp.MustCompile(`\s+`)
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteral = regexp.MustCompile(`([^\w$.])-?\d+(?:\.\d+)?\b`)
+)
+
+// SanitizeSQL prepara una consulta para registrarla en un span: une sus líneas y reemplaza los
+// literales de texto y números por ?. Los parámetros ($1, ?) se conservan; sus valores nunca se
+// registran.
+func SanitizeSQL(query string) string {
+	query = strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+	query = stringLiteral.ReplaceAllString(query, "?")
+	return numberLiteral.ReplaceAllString(query, "${1}?")
+}
+
+// SQLOperation devuelve la primera palabra de la consulta en mayúsculas (SELECT, INSERT, ...)
+func SQLOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
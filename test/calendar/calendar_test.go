@@ -0,0 +1,226 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"MyMoneyBackend/internal/domain"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 10, 30, 0, 0, time.UTC)
+}
+
+func TestAddIntervalClampsToEndOfMonth(t *testing.T) {
+	cases := []struct {
+		name  string
+		from  time.Time
+		unit  domain.PlanInterval
+		count int
+		want  time.Time
+	}{
+		{"un día", date(2024, time.March, 10), domain.PlanIntervalDay, 1, date(2024, time.March, 11)},
+		{"dos semanas", date(2024, time.March, 10), domain.PlanIntervalWeek, 2, date(2024, time.March, 24)},
+		{"mes simple", date(2024, time.March, 10), domain.PlanIntervalMonth, 1, date(2024, time.April, 10)},
+		{"31 de enero a febrero bisiesto", date(2024, time.January, 31), domain.PlanIntervalMonth, 1, date(2024, time.February, 29)},
+		{"31 de enero a febrero", date(2023, time.January, 31), domain.PlanIntervalMonth, 1, date(2023, time.February, 28)},
+		{"trimestre desde 30 de noviembre", date(2023, time.November, 30), domain.PlanIntervalMonth, 3, date(2024, time.February, 29)},
+		{"29 de febrero más un año", date(2024, time.February, 29), domain.PlanIntervalYear, 1, date(2025, time.February, 28)},
+		{"mes hacia atrás", date(2024, time.March, 31), domain.PlanIntervalMonth, -1, date(2024, time.February, 29)},
+		{"de por vida", date(2024, time.March, 10), domain.PlanIntervalLifetime, 1, domain.LifetimeEndDate},
+	}
+
+	for _, tc := range cases {
+		if got := domain.AddInterval(tc.from, tc.unit, tc.count); !got.Equal(tc.want) {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPeriodBoundariesAndRenewal(t *testing.T) {
+	quarterly := &domain.Plan{Interval: domain.PlanIntervalMonth, IntervalCount: 3}
+	start := date(2024, time.January, 15)
+
+	end := domain.PeriodEnd(quarterly, start)
+	if want := date(2024, time.April, 15); !end.Equal(want) {
+		t.Errorf("Expected period end %s, got %s", want, end)
+	}
+	if got := domain.PeriodStart(quarterly, end); !got.Equal(start) {
+		t.Errorf("Expected period start %s, got %s", start, got)
+	}
+	if renewal := domain.RenewalDate(quarterly, end); renewal == nil || !renewal.Equal(end) {
+		t.Errorf("Expected renewal at period end, got %v", renewal)
+	}
+
+	// El inicio del período en curso no puede ser anterior al inicio de la suscripción
+	subscription := &domain.UserSubscription{StartDate: date(2024, time.March, 1), EndDate: end}
+	if got := domain.CurrentPeriodStart(quarterly, subscription); !got.Equal(subscription.StartDate) {
+		t.Errorf("Expected current period to start at subscription start, got %s", got)
+	}
+
+	lifetime := &domain.Plan{Interval: domain.PlanIntervalLifetime, IntervalCount: 1}
+	if got := domain.PeriodEnd(lifetime, start); !got.Equal(domain.LifetimeEndDate) {
+		t.Errorf("Expected lifetime end date, got %s", got)
+	}
+	if renewal := domain.RenewalDate(lifetime, domain.LifetimeEndDate); renewal != nil {
+		t.Errorf("Lifetime plans must not renew, got %s", renewal)
+	}
+}
+
+func TestNextPeriodEndKeepsAnchorDay(t *testing.T) {
+	monthly := &domain.Plan{Interval: domain.PlanIntervalMonth, IntervalCount: 1}
+	yearly := &domain.Plan{Interval: domain.PlanIntervalYear, IntervalCount: 1}
+
+	// Encadenar renovaciones no arrastra el ajuste de febrero a los meses siguientes
+	end := date(2024, time.January, 31)
+	for _, want := range []time.Time{
+		date(2024, time.February, 29),
+		date(2024, time.March, 31),
+		date(2024, time.April, 30),
+		date(2024, time.May, 31),
+	} {
+		end = domain.NextPeriodEnd(monthly, 31, end)
+		if !end.Equal(want) {
+			t.Fatalf("Expected monthly period to end %s, got %s", want, end)
+		}
+	}
+
+	end = date(2024, time.February, 29)
+	for _, want := range []time.Time{date(2025, time.February, 28), date(2026, time.February, 28), date(2027, time.February, 28), date(2028, time.February, 29)} {
+		end = domain.NextPeriodEnd(yearly, 29, end)
+		if !end.Equal(want) {
+			t.Fatalf("Expected yearly period to end %s, got %s", want, end)
+		}
+	}
+
+	cases := []struct {
+		name      string
+		plan      *domain.Plan
+		anchorDay int
+		end       time.Time
+		want      time.Time
+	}{
+		{"sin día de facturación", monthly, 0, date(2024, time.February, 29), date(2024, time.March, 29)},
+		{"fin fuera del día de facturación", monthly, 31, date(2024, time.March, 15), date(2024, time.April, 15)},
+		{"día posterior al de facturación", monthly, 10, date(2024, time.March, 15), date(2024, time.April, 15)},
+		{"semanal", &domain.Plan{Interval: domain.PlanIntervalWeek, IntervalCount: 1}, 31, date(2024, time.February, 29), date(2024, time.March, 7)},
+		{"de por vida", &domain.Plan{Interval: domain.PlanIntervalLifetime, IntervalCount: 1}, 31, date(2024, time.February, 29), domain.LifetimeEndDate},
+	}
+	for _, tc := range cases {
+		if got := domain.NextPeriodEnd(tc.plan, tc.anchorDay, tc.end); !got.Equal(tc.want) {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRenewalIsIndependentOfTimeZone(t *testing.T) {
+	mexico, err := time.LoadLocation("America/Mexico_City")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+
+	monthly := &domain.Plan{Interval: domain.PlanIntervalMonth, IntervalCount: 1}
+	daily := &domain.Plan{Interval: domain.PlanIntervalDay, IntervalCount: 1}
+
+	cases := []struct {
+		name string
+		plan *domain.Plan
+		end  time.Time // Fin del período en UTC, como se lee de la base de datos
+		want time.Time
+	}{
+		// 31 de enero a las 20:00 en Ciudad de México ya es 1 de febrero en UTC
+		{"fin de mes cruzando el día", monthly, time.Date(2024, time.February, 1, 2, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)},
+		// 31 de enero en UTC ya es 1 de febrero en Tokio
+		{"fin de mes ajustado", monthly, time.Date(2024, time.January, 31, 20, 0, 0, 0, time.UTC), time.Date(2024, time.February, 29, 20, 0, 0, 0, time.UTC)},
+		// El cambio de horario en Nueva York no acorta ni alarga el período
+		{"cambio de horario", daily, time.Date(2024, time.March, 10, 5, 0, 0, 0, time.UTC), time.Date(2024, time.March, 11, 5, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		for _, location := range []*time.Location{time.UTC, mexico, tokyo, newYork} {
+			got := domain.PeriodEnd(tc.plan, tc.end.In(location))
+			if !got.Equal(tc.want) {
+				t.Errorf("%s en %s: expected %s, got %s", tc.name, location, tc.want, got)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("%s en %s: expected UTC result, got %s", tc.name, location, got.Location())
+			}
+		}
+	}
+}
+
+func TestUnusedFraction(t *testing.T) {
+	start := date(2024, time.April, 1)
+	end := date(2024, time.May, 1)
+
+	if got := domain.UnusedFraction(start, end, start.Add(end.Sub(start)/2)); got != 0.5 {
+		t.Errorf("Expected half of the period unused, got %f", got)
+	}
+	if got := domain.UnusedFraction(start, end, end); got != 0 {
+		t.Errorf("Expected nothing unused at period end, got %f", got)
+	}
+	if got := domain.UnusedFraction(start, end, start.AddDate(0, 0, -1)); got != 1 {
+		t.Errorf("Expected whole period unused before it starts, got %f", got)
+	}
+	if got := domain.UnusedFraction(end, start, start); got != 0 {
+		t.Errorf("Expected zero for an empty period, got %f", got)
+	}
+}
+
+func TestParsePlanInterval(t *testing.T) {
+	cases := []struct {
+		value     string
+		count     int
+		wantUnit  domain.PlanInterval
+		wantCount int
+	}{
+		{"monthly", 0, domain.PlanIntervalMonth, 1},
+		{"quarterly", 0, domain.PlanIntervalMonth, 3},
+		{"yearly", 0, domain.PlanIntervalYear, 1},
+		{"weekly", 2, domain.PlanIntervalWeek, 2},
+		{"day", 45, domain.PlanIntervalDay, 45},
+		{"lifetime", 5, domain.PlanIntervalLifetime, 1},
+	}
+
+	for _, tc := range cases {
+		unit, count, err := domain.ParsePlanInterval(tc.value, tc.count)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.value, err)
+		}
+		if unit != tc.wantUnit || count != tc.wantCount {
+			t.Errorf("%s x%d: expected %s x%d, got %s x%d", tc.value, tc.count, tc.wantUnit, tc.wantCount, unit, count)
+		}
+	}
+
+	if _, _, err := domain.ParsePlanInterval("fortnightly", 1); err == nil {
+		t.Error("Expected error for unknown interval")
+	}
+	if _, _, err := domain.ParsePlanInterval("month", -1); err == nil {
+		t.Error("Expected error for negative count")
+	}
+}
+
+func TestDescribeInterval(t *testing.T) {
+	cases := map[string]*domain.Plan{
+		"mensual":        {Interval: domain.PlanIntervalMonth, IntervalCount: 1},
+		"trimestral":     {Interval: domain.PlanIntervalMonth, IntervalCount: 3},
+		"cada 2 semanas": {Interval: domain.PlanIntervalWeek, IntervalCount: 2},
+		"anual":          {Interval: domain.PlanIntervalYear, IntervalCount: 1},
+		"pago único":     {Interval: domain.PlanIntervalLifetime, IntervalCount: 1},
+	}
+
+	for want, plan := range cases {
+		if got := plan.DescribeInterval(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
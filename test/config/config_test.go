@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appConfig "MyMoneyBackend/internal/config"
+)
+
+func TestLoadAppliesFileThenEnvThenFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `
+server:
+  port: 7000
+database:
+  driver: sqlite
+  sqlite_path: file.db
+scheduler:
+  interval: 30m
+cors:
+  allowed_origins: ["https://app.example.com"]
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("Unexpected error writing config file: %v", err)
+	}
+	t.Setenv("PORT", "7100")
+	t.Setenv("SQLITE_PATH", "env.db")
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("DB_DRIVER", "")
+	t.Setenv("EXPIRY_NOTICE_WINDOW", "168h")
+
+	cfg, args, err := appConfig.Load([]string{"-config", path, "-port", "7200", "migrate", "status"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Server.Port != 7200 {
+		t.Errorf("Expected flag port 7200, got %d", cfg.Server.Port)
+	}
+	if cfg.Database.Driver != "sqlite" || cfg.Database.SQLitePath != "env.db" {
+		t.Errorf("Expected sqlite driver from file and path from env, got %q and %q", cfg.Database.Driver, cfg.Database.SQLitePath)
+	}
+	if cfg.Scheduler.Interval != 30*time.Minute {
+		t.Errorf("Expected scheduler interval from file, got %s", cfg.Scheduler.Interval)
+	}
+	if len(cfg.Scheduler.DunningSchedule) != 3 {
+		t.Errorf("Expected default dunning schedule to be kept, got %v", cfg.Scheduler.DunningSchedule)
+	}
+	if cfg.Scheduler.ExpiryNoticeWindow != 168*time.Hour {
+		t.Errorf("Expected expiry notice window from env, got %s", cfg.Scheduler.ExpiryNoticeWindow)
+	}
+	if len(args) != 2 || args[0] != "migrate" || args[1] != "status" {
+		t.Errorf("Expected remaining args [migrate status], got %v", args)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid configuration, got %v", err)
+	}
+}
+
+func TestLoadRejectsUnknownFileKeysAndInvalidEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  prot: 8080\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error writing config file: %v", err)
+	}
+	if _, _, err := appConfig.Load([]string{"-config", path}); err == nil {
+		t.Error("Expected error for unknown key in config file")
+	}
+
+	t.Setenv("SUBSCRIPTION_SCHEDULER_INTERVAL", "every hour")
+	_, _, err := appConfig.Load(nil)
+	if err == nil || !strings.Contains(err.Error(), "SUBSCRIPTION_SCHEDULER_INTERVAL") {
+		t.Errorf("Expected error naming SUBSCRIPTION_SCHEDULER_INTERVAL, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := appConfig.Default()
+	cfg.Server.Port = 0
+	cfg.Payment.Provider = "stripe"
+	cfg.RateLimit.Auth.By = "session"
+	cfg.Scheduler.ExpiryNoticeWindow = -time.Hour
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, want := range []string{"server.port", "database.host", "database.password", "auth.jwt_secret", "payment.stripe_secret_key", "rate_limit.auth.by", "scheduler.expiry_notice_window"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateRejectsFakePaymentsInRelease(t *testing.T) {
+	cfg := appConfig.Default()
+	cfg.Auth.JWTSecret = "secret"
+	cfg.Database.Driver = "sqlite"
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+
+	cfg.Server.Mode = "debug"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the fake provider allowed in debug, got %v", err)
+	}
+
+	cfg.Server.Mode = "release"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "payment.provider") {
+		t.Errorf("Expected the fake provider rejected in release, got %v", err)
+	}
+
+	cfg.Payment.Provider = "stripe"
+	cfg.Payment.StripeSecretKey = "sk_live_123"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected stripe allowed in release, got %v", err)
+	}
+}
+
+func TestValidateCORSPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		origins []string
+		creds   bool
+		wantErr bool
+	}{
+		{"wildcard in debug", "debug", []string{"*"}, false, false},
+		{"wildcard in release", "release", []string{"*"}, false, true},
+		{"wildcard with credentials", "debug", []string{"*"}, true, true},
+		{"allowlist in release", "release", []string{"https://app.example.com", "https://*.example.com"}, true, false},
+		{"origin with path", "debug", []string{"https://app.example.com/login"}, false, true},
+		{"origin without scheme", "debug", []string{"app.example.com"}, false, true},
+		{"wildcard port", "debug", []string{"http://localhost:*"}, false, false},
+		{"wildcard host", "debug", []string{"https://*"}, false, true},
+		{"wildcard host with port", "debug", []string{"https://*:8443"}, false, true},
+		{"wildcard top-level domain", "debug", []string{"https://*.com"}, false, true},
+		{"wildcard public suffix", "debug", []string{"https://*.co.uk"}, false, true},
+		{"wildcard second-level public suffix", "debug", []string{"https://*.com.br"}, false, true},
+		{"wildcard registrable domain under a public suffix", "debug", []string{"https://*.example.co.uk"}, false, false},
+		{"wildcard inside a label", "debug", []string{"https://app-*.example.com"}, false, true},
+		{"wildcard after the first label", "debug", []string{"https://app.*.example.com"}, false, true},
+		{"wildcard with empty label", "debug", []string{"https://*..com"}, false, true},
+		{"nested wildcard subdomain", "debug", []string{"https://*.preview.example.com:8443"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cors := appConfig.Default().CORS
+			cors.AllowedOrigins = tt.origins
+			cors.AllowCredentials = tt.creds
+			if err := cors.Validate(tt.mode); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWriteRedactsSecrets(t *testing.T) {
+	cfg := appConfig.Default()
+	cfg.Database.Password = "db-password"
+	cfg.Auth.JWTSecret = "jwt-secret"
+	cfg.Payment.StripeSecretKey = "sk_test_123"
+	cfg.SMTP.Host = "smtp.example.com"
+
+	var out bytes.Buffer
+	if err := appConfig.Write(&out, cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, secret := range []string{"db-password", "jwt-secret", "sk_test_123"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "smtp.example.com") {
+		t.Errorf("Expected non-secret values to be printed, got:\n%s", out.String())
+	}
+	if cfg.Auth.JWTSecret != "jwt-secret" {
+		t.Error("Expected Write not to modify the configuration")
+	}
+}
@@ -0,0 +1,115 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	appConfig "MyMoneyBackend/internal/config"
+	"MyMoneyBackend/internal/container"
+	middlewares "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/routers"
+)
+
+func TestNewFailsWithoutDatabase(t *testing.T) {
+	if _, err := container.New(appConfig.Default(), nil); err == nil {
+		t.Fatal("Expected an error when building the container without a database connection")
+	}
+}
+
+// newTestRouter construye la aplicación sobre una base SQLite temporal ya migrada y la cierra al
+// terminar la prueba
+func newTestRouter(t *testing.T) (*gin.Engine, *container.Container) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := appConfig.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.SQLitePath = filepath.Join(t.TempDir(), "container.db")
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Scheduler.Enabled = false
+
+	conn, err := config.NewConnection(cfg.Database)
+	if err != nil {
+		t.Fatalf("Unexpected error opening SQLite: %v", err)
+	}
+
+	migrator, err := migrate.New(conn.GetDB(), migrate.SQLite, migrations.SQLite)
+	if err != nil {
+		conn.Close()
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		conn.Close()
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+
+	c, err := container.New(cfg, conn)
+	if err != nil {
+		conn.Close()
+		t.Fatalf("Unexpected error building the container: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		if err := c.Close(); err != nil {
+			t.Errorf("Unexpected error closing the container: %v", err)
+		}
+		if err := conn.GetDB().Ping(); err == nil {
+			t.Error("Expected the database connection to be closed")
+		}
+	})
+
+	r := gin.New()
+	r.Use(middlewares.RequestID(), middlewares.Tracing())
+	routers.SetupRouter(r, c.Handlers, c.Middlewares)
+	return r, c
+}
+
+func TestContainerServesRoutesFromASinglePool(t *testing.T) {
+	r, c := newTestRouter(t)
+	if c.Scheduler != nil {
+		t.Error("Expected no scheduler when it is disabled")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from /api/health, got %d", w.Code)
+	}
+	var health struct {
+		Info struct {
+			DatabaseOK bool `json:"databaseOK"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatalf("Unexpected error decoding health response: %v", err)
+	}
+	if !health.Info.DatabaseOK {
+		t.Error("Expected the health check to reach the database")
+	}
+
+	// Las monedas antes usaban una segunda conexión abierta en el router
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/currencies", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 from /api/currencies, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Al apagarse deja de anunciarse como lista antes de esperar a las peticiones en curso
+	c.Handlers.Health.SetReady(false)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/health/check", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 from /api/health/check while shutting down, got %d", w.Code)
+	}
+}
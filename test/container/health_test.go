@@ -0,0 +1,54 @@
+package container
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"MyMoneyBackend/internal/application/health"
+)
+
+func getReport(t *testing.T, r http.Handler, path string, wantCode int) health.Report {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != wantCode {
+		t.Fatalf("Expected status %d from %s, got %d: %s", wantCode, path, w.Code, w.Body.String())
+	}
+	var report health.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Unexpected error decoding %s response: %v", path, err)
+	}
+	return report
+}
+
+func TestProbesReportDependencies(t *testing.T) {
+	r, c := newTestRouter(t)
+
+	if report := getReport(t, r, "/livez", http.StatusOK); report.Status != health.StatusHealthy {
+		t.Errorf("Expected /livez to be healthy, got %q", report.Status)
+	}
+
+	report := getReport(t, r, "/readyz", http.StatusOK)
+	if report.Status != health.StatusHealthy {
+		t.Errorf("Expected /readyz to be healthy, got %+v", report)
+	}
+	names := map[string]bool{}
+	for _, check := range report.Checks {
+		names[check.Name] = true
+		if check.Status != health.StatusHealthy || !check.Critical {
+			t.Errorf("Expected check %q to be critical and healthy, got %+v", check.Name, check)
+		}
+	}
+	if !names["database"] || !names["migrations"] {
+		t.Errorf("Expected database and migrations checks, got %+v", report.Checks)
+	}
+
+	c.Handlers.Health.SetReady(false)
+	report = getReport(t, r, "/readyz", http.StatusServiceUnavailable)
+	if report.Status != health.StatusUnhealthy {
+		t.Errorf("Expected /readyz to be unhealthy while shutting down, got %q", report.Status)
+	}
+	getReport(t, r, "/livez", http.StatusOK)
+}
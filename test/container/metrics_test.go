@@ -0,0 +1,39 @@
+package container
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpointExposesHTTPPoolAndBusinessMetrics(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	body := strings.NewReader(`{"email":"nobody@example.com","password":"wrong-password"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", body)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from /metrics, got %d", w.Code)
+	}
+
+	output := w.Body.String()
+	for _, want := range []string{
+		`mymoney_http_requests_total{method="POST",route="/api/auth/login",status="401"} 1`,
+		`mymoney_http_request_duration_seconds_count{method="POST",route="/api/auth/login"} 1`,
+		`mymoney_logins_total{result="failure"} 1`,
+		`mymoney_logins_total{result="success"} 0`,
+		`mymoney_transactions_created_total 0`,
+		`mymoney_subscription_events_total{event="renewed"} 0`,
+		`mymoney_scheduler_runs_total{outcome="success"} 0`,
+		`go_sql_max_open_connections{db_name="mymoney"}`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected /metrics to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package container
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// doProblem envía la petición y decodifica la respuesta como problema RFC 7807
+func doProblem(t *testing.T, r *gin.Engine, method, path, token, body string, wantCode int) middleware.Problem {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != wantCode {
+		t.Fatalf("Expected status %d from %s %s, got %d: %s", wantCode, method, path, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != middleware.ProblemContentType {
+		t.Errorf("Expected Content-Type %s from %s %s, got %q", middleware.ProblemContentType, method, path, got)
+	}
+	var problem middleware.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Unexpected error decoding problem: %v", err)
+	}
+	if problem.Status != wantCode || problem.Instance != path || problem.RequestID == "" {
+		t.Errorf("Expected status, instance and request ID in the problem, got %+v", problem)
+	}
+	return problem
+}
+
+func TestErrorsAreReturnedAsProblems(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register",
+		strings.NewReader(`{"name":"Ana","email":"ana@example.com","password":"secret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 from register, got %d: %s", w.Code, w.Body.String())
+	}
+	var registered struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &registered); err != nil || registered.AccessToken == "" {
+		t.Fatalf("Expected an access token from register, got %s", w.Body.String())
+	}
+	token := registered.AccessToken
+
+	if problem := doProblem(t, r, http.MethodGet, "/api/transactions/"+uuid.NewString(), token, "", http.StatusNotFound); problem.Code != "transaction_not_found" {
+		t.Errorf("Expected code transaction_not_found, got %q", problem.Code)
+	}
+
+	problem := doProblem(t, r, http.MethodPost, "/api/transactions", token, `{}`, http.StatusBadRequest)
+	if problem.Code != "validation_failed" || len(problem.Errors) == 0 {
+		t.Errorf("Expected validation_failed with the invalid fields, got %+v", problem)
+	}
+
+	if problem := doProblem(t, r, http.MethodPost, "/api/auth/register", "",
+		`{"name":"Ana","email":"ana@example.com","password":"secret123"}`, http.StatusConflict); problem.Code != "email_taken" {
+		t.Errorf("Expected code email_taken, got %q", problem.Code)
+	}
+
+	if problem := doProblem(t, r, http.MethodGet, "/api/transactions", "", "", http.StatusUnauthorized); problem.Code != middleware.CodeUnauthorized {
+		t.Errorf("Expected code %s without a token, got %q", middleware.CodeUnauthorized, problem.Code)
+	}
+}
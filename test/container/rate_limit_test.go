@@ -0,0 +1,94 @@
+package container
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	appConfig "MyMoneyBackend/internal/config"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// login intenta iniciar sesión con una contraseña incorrecta desde ip
+func login(r *gin.Engine, path, email, ip string) *httptest.ResponseRecorder {
+	body := strings.NewReader(fmt.Sprintf(`{"email":%q,"password":"wrong-password"}`, email))
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":4242"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginIsThrottledPerIPAcrossRoutePrefixes(t *testing.T) {
+	r, _ := newTestRouter(t)
+	limit := appConfig.Default().RateLimit.Auth.Requests
+	perEmail := appConfig.Default().RateLimit.Login.Requests
+
+	// /api/auth/login y /auth/login comparten el límite. Cada intento usa otro email para
+	// medir solo el límite por IP; las cabeceras muestran el más cercano de los dos.
+	for i := 0; i < limit; i++ {
+		path := "/api/auth/login"
+		if i%2 == 1 {
+			path = "/auth/login"
+		}
+		w := login(r, path, fmt.Sprintf("nobody%d@example.com", i), "203.0.113.7")
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 for attempt %d, got %d", i+1, w.Code)
+		}
+		remaining := min(limit-i-1, perEmail-1)
+		if got := w.Header().Get(middleware.RateLimitRemainingHeader); got != strconv.Itoa(remaining) {
+			t.Errorf("Expected RateLimit-Remaining %d on attempt %d, got %q", remaining, i+1, got)
+		}
+	}
+
+	w := login(r, "/api/auth/login", "someone@example.com", "203.0.113.7")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 after %d attempts, got %d", limit, w.Code)
+	}
+	if retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retryAfter < 1 {
+		t.Errorf("Expected a positive Retry-After, got %q", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestLoginIsThrottledPerEmailAcrossIPs(t *testing.T) {
+	r, _ := newTestRouter(t)
+	limit := appConfig.Default().RateLimit.Login.Requests
+
+	// Cada intento llega desde otra IP y con otras mayúsculas en el mismo email
+	for i := 0; i < limit; i++ {
+		email := "victim@example.com"
+		if i%2 == 1 {
+			email = "Victim@Example.COM"
+		}
+		path := "/api/auth/login"
+		if i%2 == 1 {
+			path = "/auth/login"
+		}
+		w := login(r, path, email, fmt.Sprintf("198.51.100.%d", i+1))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 for attempt %d, got %d", i+1, w.Code)
+		}
+		if got := w.Header().Get(middleware.RateLimitRemainingHeader); got != strconv.Itoa(limit-i-1) {
+			t.Errorf("Expected RateLimit-Remaining %d on attempt %d, got %q", limit-i-1, i+1, got)
+		}
+	}
+
+	w := login(r, "/api/auth/login", "VICTIM@example.com", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 after %d attempts on the same email, got %d", limit, w.Code)
+	}
+	if retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retryAfter < 1 {
+		t.Errorf("Expected a positive Retry-After, got %q", w.Header().Get("Retry-After"))
+	}
+
+	// Las demás cuentas no se ven afectadas
+	if w := login(r, "/api/auth/login", "other@example.com", "192.0.2.1"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected another email to keep logging in, got %d", w.Code)
+	}
+}
@@ -0,0 +1,97 @@
+package container
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	recorderOnce sync.Once
+	recorder     *tracetest.SpanRecorder
+)
+
+// spanRecorder instala una sola vez por binario un proveedor de trazas que guarda los spans en
+// memoria: los tracers creados antes de instalarlo quedan ligados al primero
+func spanRecorder() *tracetest.SpanRecorder {
+	recorderOnce.Do(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return recorder
+}
+
+func TestTracingContinuesIncomingTraceThroughServicesAndSQL(t *testing.T) {
+	recorder := spanRecorder()
+	r, _ := newTestRouter(t)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	body := strings.NewReader(`{"email":"nobody@example.com","password":"wrong-password"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var server, service sdktrace.ReadOnlySpan
+	var queries []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() != traceID {
+			continue
+		}
+		switch {
+		case span.SpanKind() == trace.SpanKindServer:
+			server = span
+		case span.Name() == "user.AuthenticateUser":
+			service = span
+		case span.SpanKind() == trace.SpanKindClient:
+			queries = append(queries, span)
+		}
+	}
+
+	if server == nil {
+		t.Fatal("Expected a server span continuing the incoming trace")
+	}
+	if server.Name() != "POST /api/auth/login" {
+		t.Errorf("Expected server span named by route, got %q", server.Name())
+	}
+	if server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected server span parent from traceparent, got %s", server.Parent().SpanID())
+	}
+	if service == nil {
+		t.Fatal("Expected a span for the login use case")
+	}
+	if service.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("Expected the use case span to be a child of the server span")
+	}
+	if len(queries) == 0 {
+		t.Fatal("Expected at least one database span")
+	}
+
+	for _, query := range queries {
+		if query.Parent().SpanID() != service.SpanContext().SpanID() {
+			t.Errorf("Expected database span %q to be a child of the use case span", query.Name())
+		}
+		var statement string
+		for _, attr := range query.Attributes() {
+			if attr.Key == semconv.DBStatementKey {
+				statement = attr.Value.AsString()
+			}
+		}
+		if !strings.HasPrefix(statement, "SELECT") {
+			t.Errorf("Expected a SELECT statement on the database span, got %q", statement)
+		}
+		if strings.Contains(statement, "nobody@example.com") {
+			t.Errorf("Expected statement without parameter values, got %q", statement)
+		}
+	}
+}
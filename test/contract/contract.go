@@ -0,0 +1,665 @@
+// Package contract contiene las pruebas de contrato que toda implementación de los puertos de
+// repositorio debe cumplir, sin importar dónde guarde los datos.
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+)
+
+// Repositories agrupa los repositorios de una implementación. Deben compartir el almacenamiento,
+// como los repositorios de una misma base de datos.
+type Repositories struct {
+	Users          app.UserRepository
+	Categories     app.CategoryRepository
+	PaymentMethods app.PaymentMethodRepository
+	Transactions   app.TransactionRepository
+	Currencies     app.CurrencyRepository
+	Plans          app.PlanRepository
+	Subscriptions  app.UserSubscriptionRepository
+}
+
+// Run ejecuta el contrato de todos los repositorios. newRepositories se llama una vez por prueba;
+// las pruebas crean datos con identificadores únicos y los eliminan al terminar, por lo que puede
+// devolver repositorios sobre una base de datos compartida.
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, f *fixture)
+	}{
+		{"Users", testUsers},
+		{"Categories", testCategories},
+		{"PaymentMethods", testPaymentMethods},
+		{"Transactions", testTransactions},
+		{"Currencies", testCurrencies},
+		{"Plans", testPlans},
+		{"Subscriptions", testSubscriptions},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newFixture(t, newRepositories(t)))
+		})
+	}
+}
+
+// fixture crea los datos de los que dependen las pruebas y los elimina al terminar,
+// en el orden que exigen las claves foráneas
+type fixture struct {
+	Repositories
+	ctx           context.Context
+	users         []string
+	subscriptions map[string]bool
+	plans         []string
+	currencies    []string
+}
+
+// newFixture crea un fixture que limpia sus datos al terminar la prueba
+func newFixture(t *testing.T, repos Repositories) *fixture {
+	f := &fixture{Repositories: repos, ctx: context.Background(), subscriptions: make(map[string]bool)}
+	t.Cleanup(func() {
+		// Los usuarios arrastran sus categorías, métodos de pago, transacciones y suscripciones
+		for _, id := range f.users {
+			_ = f.Users.Delete(f.ctx, id)
+		}
+		for _, id := range f.plans {
+			_ = f.Plans.Delete(f.ctx, id)
+		}
+		for _, id := range f.currencies {
+			_ = f.Currencies.Delete(f.ctx, id)
+		}
+	})
+	return f
+}
+
+// now devuelve la hora actual truncada a segundos, la precisión que conservan todos los backends
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// uniqueCode devuelve un código de moneda que no choca con los datos iniciales
+func uniqueCode() string {
+	return "T" + uuid.New().String()[:7]
+}
+
+func (f *fixture) user(t *testing.T) *domain.User {
+	t.Helper()
+	user := &domain.User{
+		Email:    uuid.New().String() + "@contract.test",
+		Name:     "Contract",
+		Password: "hash",
+	}
+	if err := f.Users.Create(f.ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	f.users = append(f.users, user.ID)
+	return user
+}
+
+func (f *fixture) currency(t *testing.T) *domain.Currency {
+	t.Helper()
+	currency := &domain.Currency{Code: uniqueCode(), Name: "Contract", Symbol: "¤", IsActive: true}
+	if err := f.Currencies.Create(f.ctx, currency); err != nil {
+		t.Fatalf("Failed to create currency: %v", err)
+	}
+	f.currencies = append(f.currencies, currency.ID)
+	return currency
+}
+
+func (f *fixture) plan(t *testing.T, currency *domain.Currency, name string, sortOrder int) *domain.Plan {
+	t.Helper()
+	maxCategories := 5
+	plan := &domain.Plan{
+		Name:          name,
+		Description:   "Plan de contrato",
+		Price:         9.99,
+		CurrencyID:    currency.ID,
+		Interval:      domain.PlanIntervalMonth,
+		IntervalCount: 1,
+		Features:      []domain.PlanFeature{{Name: "Reportes", Value: "Ilimitado", Included: true}},
+		Entitlements: domain.Entitlements{
+			MaxCategories: &maxCategories,
+			ExportFormats: []domain.ExportFormat{domain.ExportFormatCSV},
+		},
+		IsActive:  true,
+		IsPublic:  true,
+		SortOrder: sortOrder,
+	}
+	if err := f.Plans.Create(f.ctx, plan); err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	f.plans = append(f.plans, plan.ID)
+	return plan
+}
+
+func (f *fixture) category(t *testing.T, user *domain.User, name string) *domain.Category {
+	t.Helper()
+	category := &domain.Category{Name: name, Description: "Contrato", Color: "#000000", Icon: "tag", UserID: user.ID}
+	if err := f.Categories.Create(f.ctx, category); err != nil {
+		t.Fatalf("Failed to create category: %v", err)
+	}
+	return category
+}
+
+func (f *fixture) paymentMethod(t *testing.T, user *domain.User) *domain.PaymentMethod {
+	t.Helper()
+	paymentMethod := &domain.PaymentMethod{Name: "Tarjeta", IsActive: true, UserID: user.ID}
+	if err := f.PaymentMethods.Create(f.ctx, paymentMethod); err != nil {
+		t.Fatalf("Failed to create payment method: %v", err)
+	}
+	return paymentMethod
+}
+
+func (f *fixture) subscription(t *testing.T, user *domain.User, plan *domain.Plan, status domain.SubscriptionStatus, end time.Time) *domain.UserSubscription {
+	t.Helper()
+	subscription := &domain.UserSubscription{
+		UserID:    user.ID,
+		PlanID:    plan.ID,
+		Status:    status,
+		StartDate: end.AddDate(0, -1, 0),
+		EndDate:   end,
+		Metadata:  map[string]string{"source": "contract"},
+	}
+	if err := f.Subscriptions.Create(f.ctx, subscription); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	f.subscriptions[subscription.ID] = true
+	return subscription
+}
+
+func testUsers(t *testing.T, f *fixture) {
+	user := f.user(t)
+	if user.ID == "" || user.CreatedAt.IsZero() {
+		t.Fatalf("Expected Create to assign ID and timestamps, got %+v", user)
+	}
+
+	got, err := f.Users.GetByEmail(f.ctx, user.Email)
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("Expected user by email, got %v, %v", got, err)
+	}
+
+	duplicate := &domain.User{Email: user.Email, Name: "Otro", Password: "hash"}
+	if err := f.Users.Create(f.ctx, duplicate); err == nil {
+		f.users = append(f.users, duplicate.ID)
+		t.Error("Expected error for duplicate email")
+	}
+
+	user.Name = "Renombrado"
+	if err := f.Users.Update(f.ctx, user); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	if got, _ := f.Users.GetByID(f.ctx, user.ID); got == nil || got.Name != "Renombrado" || got.Password != "hash" {
+		t.Errorf("Expected updated user, got %+v", got)
+	}
+
+	if references, err := f.Users.CountReferences(f.ctx, user.ID); err != nil || references["invoices"] != 0 {
+		t.Errorf("Expected a user without invoices, got %v, %v", references, err)
+	}
+	if err := f.Users.Delete(f.ctx, user.ID); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if _, err := f.Users.GetByID(f.ctx, user.ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error for deleted user, got %v", err)
+	}
+	if err := f.Users.Delete(f.ctx, user.ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error deleting a missing user, got %v", err)
+	}
+}
+
+func testCategories(t *testing.T, f *fixture) {
+	user := f.user(t)
+	groceries := f.category(t, user, "Supermercado")
+	f.category(t, user, "Alquiler")
+
+	categories, err := f.Categories.GetByUserID(f.ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to list categories: %v", err)
+	}
+	if len(categories) != 2 || categories[0].Name != "Alquiler" || categories[1].Name != "Supermercado" {
+		t.Fatalf("Expected categories ordered by name, got %+v", categories)
+	}
+
+	groceries.Color = "#00ff00"
+	if err := f.Categories.Update(f.ctx, groceries); err != nil {
+		t.Fatalf("Failed to update category: %v", err)
+	}
+	got, err := f.Categories.GetByID(f.ctx, groceries.ID)
+	if err != nil || got == nil || got.Color != "#00ff00" || got.UserID != user.ID || got.Budget != nil {
+		t.Errorf("Expected updated category without a budget, got %+v, %v", got, err)
+	}
+
+	currency := f.currency(t)
+	groceries.Budget = &domain.CategoryBudget{Amount: 250.5, CurrencyID: currency.ID}
+	if err := f.Categories.Update(f.ctx, groceries); err != nil {
+		t.Fatalf("Failed to set category budget: %v", err)
+	}
+	got, err = f.Categories.GetByID(f.ctx, groceries.ID)
+	if err != nil || got == nil || got.Budget == nil || *got.Budget != *groceries.Budget {
+		t.Errorf("Expected stored budget %+v, got %+v, %v", groceries.Budget, got, err)
+	}
+
+	groceries.Budget = nil
+	if err := f.Categories.Update(f.ctx, groceries); err != nil {
+		t.Fatalf("Failed to clear category budget: %v", err)
+	}
+	if got, err := f.Categories.GetByID(f.ctx, groceries.ID); err != nil || got == nil || got.Budget != nil {
+		t.Errorf("Expected cleared budget, got %+v, %v", got, err)
+	}
+
+	if err := f.Categories.Delete(f.ctx, groceries.ID); err != nil {
+		t.Fatalf("Failed to delete category: %v", err)
+	}
+	if got, err := f.Categories.GetByID(f.ctx, groceries.ID); err != nil || got != nil {
+		t.Errorf("Expected nil for a missing category, got %+v, %v", got, err)
+	}
+}
+
+func testPaymentMethods(t *testing.T, f *fixture) {
+	user := f.user(t)
+	paymentMethod := f.paymentMethod(t, user)
+
+	paymentMethod.CardBrand = "visa"
+	paymentMethod.CardLast4 = "4242"
+	paymentMethod.CardToken = "tok_contract"
+	if err := f.PaymentMethods.Update(f.ctx, paymentMethod); err != nil {
+		t.Fatalf("Failed to update payment method: %v", err)
+	}
+
+	paymentMethods, err := f.PaymentMethods.GetByUserID(f.ctx, user.ID)
+	if err != nil || len(paymentMethods) != 1 {
+		t.Fatalf("Expected one payment method, got %v, %v", paymentMethods, err)
+	}
+	if got := paymentMethods[0]; got.CardToken != "tok_contract" || !got.HasCard() {
+		t.Errorf("Expected stored card, got %+v", got)
+	}
+
+	if err := f.PaymentMethods.Delete(f.ctx, paymentMethod.ID); err != nil {
+		t.Fatalf("Failed to delete payment method: %v", err)
+	}
+	if got, err := f.PaymentMethods.GetByID(f.ctx, paymentMethod.ID); err != nil || got != nil {
+		t.Errorf("Expected nil for a missing payment method, got %+v, %v", got, err)
+	}
+}
+
+func testTransactions(t *testing.T, f *fixture) {
+	currency := f.currency(t)
+	user := f.user(t)
+	other := f.user(t)
+	category := f.category(t, user, "Comida")
+	paymentMethod := f.paymentMethod(t, user)
+
+	base := now().AddDate(0, 0, -10)
+	var created []*domain.Transaction
+	for day := 0; day < 3; day++ {
+		transaction := &domain.Transaction{
+			ID:              uuid.New().String(),
+			Amount:          float64(10 * (day + 1)),
+			Description:     "Contrato",
+			Date:            base.AddDate(0, 0, day),
+			CategoryID:      category.ID,
+			Type:            domain.TransactionTypeExpense,
+			PaymentMethodID: paymentMethod.ID,
+			UserID:          user.ID,
+			CurrencyID:      currency.ID,
+		}
+		if err := f.Transactions.Create(f.ctx, transaction); err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		created = append(created, transaction)
+	}
+
+	all, err := f.Transactions.GetByUserID(f.ctx, user.ID)
+	if err != nil || len(all) != 3 || all[0].ID != created[2].ID {
+		t.Fatalf("Expected transactions newest first, got %v, %v", all, err)
+	}
+
+	// El rango incluye ambos extremos
+	inRange, err := f.Transactions.GetByDateRange(f.ctx, user.ID, created[0].Date, created[1].Date)
+	if err != nil || len(inRange) != 2 || inRange[0].ID != created[1].ID || inRange[1].ID != created[0].ID {
+		t.Fatalf("Expected the first two transactions in range, got %v, %v", inRange, err)
+	}
+
+	// Se cuentan por fecha de registro, no por la fecha de la transacción
+	if count, err := f.Transactions.CountCreatedBetween(f.ctx, user.ID, now().Add(-time.Hour), now().Add(time.Hour)); err != nil || count != 3 {
+		t.Errorf("Expected three transactions recorded in the last hour, got %d, %v", count, err)
+	}
+	if count, err := f.Transactions.CountCreatedBetween(f.ctx, user.ID, base.Add(-time.Hour), base.Add(time.Hour)); err != nil || count != 0 {
+		t.Errorf("Expected no transactions recorded on their backdated day, got %d, %v", count, err)
+	}
+
+	byCategory, err := f.Transactions.GetByCategoryID(f.ctx, category.ID)
+	if err != nil || len(byCategory) != 3 {
+		t.Errorf("Expected three transactions by category, got %v, %v", byCategory, err)
+	}
+
+	// Los gastos se suman por fecha de la transacción, con el final del rango excluido
+	if spent, err := f.Transactions.SumExpenses(f.ctx, category.ID, currency.ID, created[0].Date, created[2].Date); err != nil || spent != 30 {
+		t.Errorf("Expected 30 spent before the last transaction, got %v, %v", spent, err)
+	}
+	if spent, err := f.Transactions.SumExpenses(f.ctx, category.ID, f.currency(t).ID, base, now()); err != nil || spent != 0 {
+		t.Errorf("Expected nothing spent in another currency, got %v, %v", spent, err)
+	}
+
+	// Solo el dueño puede modificar una transacción
+	stolen := *created[0]
+	stolen.UserID = other.ID
+	if err := f.Transactions.Update(f.ctx, &stolen); err == nil {
+		t.Error("Expected error updating another user's transaction")
+	}
+
+	created[0].Amount = 99.5
+	if err := f.Transactions.Update(f.ctx, created[0]); err != nil {
+		t.Fatalf("Failed to update transaction: %v", err)
+	}
+	if got, err := f.Transactions.GetByID(f.ctx, created[0].ID); err != nil || got.Amount != 99.5 || !got.Date.Equal(created[0].Date) {
+		t.Errorf("Expected updated transaction, got %+v, %v", got, err)
+	}
+
+	// Una categoría con transacciones no puede eliminarse
+	if err := f.Categories.Delete(f.ctx, category.ID); err == nil {
+		t.Error("Expected error deleting a category in use")
+	}
+
+	if references, err := f.Currencies.CountReferences(f.ctx, currency.ID); err != nil || references["transactions"] != 3 {
+		t.Errorf("Expected three transaction references, got %v, %v", references, err)
+	}
+
+	if err := f.Transactions.Delete(f.ctx, created[0].ID); err != nil {
+		t.Fatalf("Failed to delete transaction: %v", err)
+	}
+	if _, err := f.Transactions.GetByID(f.ctx, created[0].ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error for a deleted transaction, got %v", err)
+	}
+	if err := f.Transactions.Delete(f.ctx, created[0].ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error deleting a missing transaction, got %v", err)
+	}
+}
+
+func testCurrencies(t *testing.T, f *fixture) {
+	currency := f.currency(t)
+
+	got, err := f.Currencies.GetByCode(f.ctx, currency.Code)
+	if err != nil || got.ID != currency.ID {
+		t.Fatalf("Expected currency by code, got %v, %v", got, err)
+	}
+
+	if err := f.Currencies.Create(f.ctx, &domain.Currency{Code: currency.Code, Name: "Otra", Symbol: "¤"}); err == nil {
+		t.Error("Expected error for duplicate code")
+	}
+
+	currency.IsActive = false
+	if err := f.Currencies.Update(f.ctx, currency); err != nil {
+		t.Fatalf("Failed to update currency: %v", err)
+	}
+	active, err := f.Currencies.GetAllActive(f.ctx)
+	if err != nil {
+		t.Fatalf("Failed to list active currencies: %v", err)
+	}
+	for _, c := range active {
+		if c.ID == currency.ID {
+			t.Error("Inactive currency must not be listed as active")
+		}
+	}
+
+	all, err := f.Currencies.GetAll(f.ctx)
+	if err != nil {
+		t.Fatalf("Failed to list currencies: %v", err)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Code > all[i].Code {
+			t.Fatalf("Expected currencies ordered by code, got %s before %s", all[i-1].Code, all[i].Code)
+		}
+	}
+
+	plan := f.plan(t, currency, "Referencia", 0)
+	references, err := f.Currencies.CountReferences(f.ctx, currency.ID)
+	if err != nil || references["plans"] != 1 || references["transactions"] != 0 {
+		t.Errorf("Expected one plan reference, got %v, %v", references, err)
+	}
+	if err := f.Currencies.Delete(f.ctx, currency.ID); err == nil {
+		t.Error("Expected error deleting a currency in use")
+	}
+
+	if err := f.Plans.Delete(f.ctx, plan.ID); err != nil {
+		t.Fatalf("Failed to delete plan: %v", err)
+	}
+	if err := f.Currencies.Delete(f.ctx, currency.ID); err != nil {
+		t.Fatalf("Failed to delete currency: %v", err)
+	}
+	if _, err := f.Currencies.GetByID(f.ctx, currency.ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error for a deleted currency, got %v", err)
+	}
+}
+
+func testPlans(t *testing.T, f *fixture) {
+	currency := f.currency(t)
+	plan := f.plan(t, currency, "Contrato Pro", 1)
+	if plan.FamilyID != plan.ID || plan.Version != 1 {
+		t.Fatalf("Expected first version of its own family, got family %s version %d", plan.FamilyID, plan.Version)
+	}
+
+	got, err := f.Plans.GetByID(f.ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("Failed to get plan: %v", err)
+	}
+	if len(got.Features) != 1 || got.Entitlements.MaxCategories == nil || *got.Entitlements.MaxCategories != 5 {
+		t.Errorf("Expected features and entitlements to round-trip, got %+v", got)
+	}
+
+	next := *got
+	next.ID = ""
+	next.Version = 2
+	next.Price = 12.99
+	if err := f.Plans.CreateVersion(f.ctx, got, &next); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+	if got.SupersededAt == nil {
+		t.Error("Expected previous version to be marked as superseded")
+	}
+	stale := next
+	stale.ID = ""
+	stale.Version = 3
+	if err := f.Plans.CreateVersion(f.ctx, plan, &stale); err == nil {
+		t.Error("Expected error creating a version from a superseded plan")
+	}
+
+	latest, err := f.Plans.GetLatestVersion(f.ctx, plan.FamilyID)
+	if err != nil || latest.ID != next.ID || latest.Price != 12.99 {
+		t.Fatalf("Expected version 2 as latest, got %+v, %v", latest, err)
+	}
+	versions, err := f.Plans.GetVersions(f.ctx, plan.FamilyID)
+	if err != nil || len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("Expected two versions newest first, got %v, %v", versions, err)
+	}
+
+	public, err := f.Plans.GetAllPublic(f.ctx)
+	if err != nil {
+		t.Fatalf("Failed to list public plans: %v", err)
+	}
+	for _, p := range public {
+		if p.ID == plan.ID {
+			t.Error("Superseded versions must not be listed as public")
+		}
+	}
+
+	if err := f.Plans.Archive(f.ctx, plan.FamilyID, now()); err != nil {
+		t.Fatalf("Failed to archive plan: %v", err)
+	}
+	archived, err := f.Plans.GetByID(f.ctx, next.ID)
+	if err != nil || !archived.IsArchived() || archived.IsActive || archived.IsPublic {
+		t.Errorf("Expected archived, inactive and private plan, got %+v, %v", archived, err)
+	}
+	if err := f.Plans.Archive(f.ctx, plan.FamilyID, now()); err == nil {
+		t.Error("Expected error archiving an archived family")
+	}
+
+	// Solo la versión vigente vuelve a estar activa
+	if err := f.Plans.Unarchive(f.ctx, plan.FamilyID, true); err != nil {
+		t.Fatalf("Failed to unarchive plan: %v", err)
+	}
+	restored, err := f.Plans.GetByID(f.ctx, next.ID)
+	if err != nil || restored.IsArchived() || !restored.IsActive || !restored.IsPublic {
+		t.Errorf("Expected the current version active and public again, got %+v, %v", restored, err)
+	}
+	if superseded, err := f.Plans.GetByID(f.ctx, plan.ID); err != nil || superseded.IsArchived() || superseded.IsActive {
+		t.Errorf("Expected the superseded version unarchived but inactive, got %+v, %v", superseded, err)
+	}
+	if err := f.Plans.Unarchive(f.ctx, plan.FamilyID, true); err == nil {
+		t.Error("Expected error unarchiving a family that is not archived")
+	}
+
+	if err := f.Plans.Delete(f.ctx, next.ID); err != nil {
+		t.Fatalf("Failed to delete plan family: %v", err)
+	}
+	if _, err := f.Plans.GetByID(f.ctx, plan.ID); err == nil {
+		t.Error("Expected every version of the family to be deleted")
+	}
+}
+
+func testSubscriptions(t *testing.T, f *fixture) {
+	currency := f.currency(t)
+	plan := f.plan(t, currency, "Contrato Suscripción", 0)
+	user := f.user(t)
+	current := now()
+
+	expiring := f.subscription(t, user, plan, domain.SubscriptionStatusActive, current.AddDate(0, 0, 3))
+	expired := f.subscription(t, user, plan, domain.SubscriptionStatusActive, current.AddDate(0, 0, -1))
+	failed := f.subscription(t, user, plan, domain.SubscriptionStatusFailed, current.AddDate(0, 0, -2))
+	cancelled := f.subscription(t, user, plan, domain.SubscriptionStatusCancelled, current.AddDate(0, 0, 10))
+
+	if err := f.Subscriptions.Create(f.ctx, &domain.UserSubscription{UserID: uuid.New().String(), PlanID: plan.ID, Status: domain.SubscriptionStatusActive, StartDate: current, EndDate: current}); err == nil {
+		t.Error("Expected error creating a subscription for a missing user")
+	}
+
+	// Sin metadatos la columna queda NULL
+	bare := &domain.UserSubscription{UserID: f.user(t).ID, PlanID: f.plan(t, currency, "Contrato Sin Metadatos", 0).ID, Status: domain.SubscriptionStatusPending, StartDate: current, EndDate: current}
+	if err := f.Subscriptions.Create(f.ctx, bare); err != nil {
+		t.Fatalf("Expected a subscription without metadata to be created, got %v", err)
+	}
+	if got, err := f.Subscriptions.GetByID(f.ctx, bare.ID); err != nil || len(got.Metadata) != 0 {
+		t.Errorf("Expected no metadata to round-trip, got %+v, %v", got, err)
+	}
+
+	got, err := f.Subscriptions.GetByID(f.ctx, expiring.ID)
+	if err != nil {
+		t.Fatalf("Failed to get subscription: %v", err)
+	}
+	if !got.EndDate.Equal(expiring.EndDate) || got.EndDate.Location() != time.UTC || got.Metadata["source"] != "contract" {
+		t.Errorf("Expected UTC dates and metadata to round-trip, got %+v", got)
+	}
+
+	active, err := f.Subscriptions.GetActiveByUserID(f.ctx, user.ID)
+	if err != nil || active == nil || active.ID != expiring.ID {
+		t.Errorf("Expected the unexpired active subscription, got %+v, %v", active, err)
+	}
+	if active, err := f.Subscriptions.GetActiveByUserID(f.ctx, uuid.New().String()); err != nil || active != nil {
+		t.Errorf("Expected nil for a user without subscription, got %+v, %v", active, err)
+	}
+
+	dueSoon, err := f.Subscriptions.GetExpiringSubscriptions(f.ctx, current, current.AddDate(0, 0, 7))
+	f.assertSubscriptions(t, "expiring", dueSoon, err, expiring.ID)
+	pastDue, err := f.Subscriptions.GetExpired(f.ctx, current)
+	f.assertSubscriptions(t, "expired", pastDue, err, expired.ID)
+
+	retryAt := current.Add(-time.Hour)
+	failed.NextPaymentAttempt = &retryAt
+	failed.Metadata["payment_attempts"] = "1"
+	if err := f.Subscriptions.Update(f.ctx, failed); err != nil {
+		t.Fatalf("Failed to update subscription: %v", err)
+	}
+	retries, err := f.Subscriptions.GetDueForPaymentRetry(f.ctx, current)
+	f.assertSubscriptions(t, "retry", retries, err, failed.ID)
+
+	renewal := current.AddDate(0, 0, 1)
+	expiring.RenewalDate = &renewal
+	if err := f.Subscriptions.Update(f.ctx, expiring); err != nil {
+		t.Fatalf("Failed to update subscription: %v", err)
+	}
+	renewals, err := f.Subscriptions.GetPendingRenewals(f.ctx, current.AddDate(0, 0, 2))
+	f.assertSubscriptions(t, "renewals", renewals, err, expiring.ID)
+
+	billable, err := f.Subscriptions.GetBillableByPlanID(f.ctx, plan.ID)
+	f.assertSubscriptions(t, "billable", billable, err, failed.ID, expired.ID, expiring.ID)
+
+	usage, err := f.Subscriptions.CountByPlan(f.ctx)
+	if err != nil || usage[plan.ID] == nil || usage[plan.ID].ActiveSubscribers != 3 || usage[plan.ID].TotalSubscriptions != 4 {
+		t.Errorf("Expected 3 active of 4 subscriptions, got %+v, %v", usage[plan.ID], err)
+	}
+
+	// Un plan con suscripciones no puede eliminarse
+	if err := f.Plans.Delete(f.ctx, plan.ID); err == nil {
+		t.Error("Expected error deleting a plan in use")
+	}
+
+	if err := f.Subscriptions.CancelSubscription(f.ctx, expired.ID, current, map[string]string{"cancellation_reason": "contrato"}); err != nil {
+		t.Fatalf("Failed to cancel subscription: %v", err)
+	}
+	if err := f.Subscriptions.UpdateStatus(f.ctx, cancelled.ID, domain.SubscriptionStatusExpired); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	cancelledNow, err := f.Subscriptions.GetByStatus(f.ctx, domain.SubscriptionStatusCancelled)
+	if err != nil || !containsID(cancelledNow, expired.ID) || containsID(cancelledNow, cancelled.ID) {
+		t.Errorf("Expected only the newly cancelled subscription, got %v, %v", cancelledNow, err)
+	}
+	if got, err := f.Subscriptions.GetByID(f.ctx, expired.ID); err != nil || got.CancellationDate == nil || !got.CancellationDate.Equal(current) ||
+		got.Metadata["cancellation_reason"] != "contrato" {
+		t.Errorf("Expected cancellation date and reason, got %+v, %v", got, err)
+	}
+
+	all, err := f.Subscriptions.GetAllByUserID(f.ctx, user.ID)
+	if err != nil || len(all) != 4 {
+		t.Errorf("Expected four subscriptions for the user, got %v, %v", all, err)
+	}
+
+	if err := f.Subscriptions.Delete(f.ctx, cancelled.ID); err != nil {
+		t.Fatalf("Failed to delete subscription: %v", err)
+	}
+	if _, err := f.Subscriptions.GetByID(f.ctx, cancelled.ID); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error for a deleted subscription, got %v", err)
+	}
+	if err := f.Subscriptions.UpdateStatus(f.ctx, cancelled.ID, domain.SubscriptionStatusActive); !errors.Is(err, domain.KindNotFound) {
+		t.Errorf("Expected not found error updating a missing subscription, got %v", err)
+	}
+}
+
+// assertSubscriptions comprueba que una consulta devuelva exactamente las suscripciones want y en
+// ese orden, ignorando las de otras pruebas que compartan la base de datos
+func (f *fixture) assertSubscriptions(t *testing.T, name string, got []*domain.UserSubscription, err error, want ...string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	}
+
+	var ids []string
+	for _, subscription := range got {
+		if f.subscriptions[subscription.ID] {
+			ids = append(ids, subscription.ID)
+		}
+	}
+	if len(ids) != len(want) {
+		t.Errorf("%s: expected %v, got %v", name, want, ids)
+		return
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("%s: expected %v, got %v", name, want, ids)
+			return
+		}
+	}
+}
+
+func containsID(subscriptions []*domain.UserSubscription, id string) bool {
+	for _, subscription := range subscriptions {
+		if subscription.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/memory"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	Run(t, func(t *testing.T) Repositories {
+		store := memory.NewStore()
+		return Repositories{
+			Users:          memory.NewUserRepository(store),
+			Categories:     memory.NewCategoryRepository(store),
+			PaymentMethods: memory.NewPaymentMethodRepository(store),
+			Transactions:   memory.NewTransactionRepository(store),
+			Currencies:     memory.NewCurrencyRepository(store),
+			Plans:          memory.NewPlanRepository(store),
+			Subscriptions:  memory.NewUserSubscriptionRepository(store),
+		}
+	})
+}
+
+func TestMemoryRepositoriesConcurrentAccess(t *testing.T) {
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	categories := memory.NewCategoryRepository(store)
+
+	ctx := context.Background()
+	user := &domain.User{Email: "concurrent@contract.test", Name: "Concurrent", Password: "hash"}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			category := &domain.Category{Name: fmt.Sprintf("Categoría %02d", i), UserID: user.ID}
+			if err := categories.Create(ctx, category); err != nil {
+				t.Errorf("Failed to create category: %v", err)
+			}
+			if _, err := categories.GetByUserID(ctx, user.ID); err != nil {
+				t.Errorf("Failed to list categories: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	listed, err := categories.GetByUserID(ctx, user.ID)
+	if err != nil || len(listed) != 20 {
+		t.Fatalf("Expected 20 categories, got %d, %v", len(listed), err)
+	}
+}
@@ -0,0 +1,36 @@
+package contract
+
+import (
+	"testing"
+
+	"MyMoneyBackend/db/config"
+	appConfig "MyMoneyBackend/internal/config"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+)
+
+// TestPostgresRepositories ejecuta el contrato contra la base de datos configurada en el entorno,
+// que debe tener las migraciones aplicadas. Se omite si no hay base de datos disponible.
+func TestPostgresRepositories(t *testing.T) {
+	cfg, _, err := appConfig.Load(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error loading configuration: %v", err)
+	}
+	conn, err := config.NewConnection(cfg.Database)
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db := conn.GetDB()
+	Run(t, func(t *testing.T) Repositories {
+		return Repositories{
+			Users:          repository.NewUserRepository(db),
+			Categories:     repository.NewCategoryRepository(db),
+			PaymentMethods: repository.NewPaymentMethodRepository(db),
+			Transactions:   repository.NewTransactionRepository(db),
+			Currencies:     repository.NewCurrencyRepository(db),
+			Plans:          repository.NewPlanRepository(db),
+			Subscriptions:  repository.NewUserSubscriptionRepository(db),
+		}
+	})
+}
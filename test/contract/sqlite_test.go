@@ -0,0 +1,42 @@
+package contract
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/internal/infraestructure/outbound/sqlite"
+)
+
+// TestSQLiteRepositories ejecuta el contrato con los repositorios SQL sobre un archivo SQLite
+// temporal con las migraciones de SQLite aplicadas
+func TestSQLiteRepositories(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "contract.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error opening SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, migrate.SQLite, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+
+	Run(t, func(t *testing.T) Repositories {
+		return Repositories{
+			Users:          repository.NewUserRepository(db),
+			Categories:     repository.NewCategoryRepository(db),
+			PaymentMethods: repository.NewPaymentMethodRepository(db),
+			Transactions:   repository.NewTransactionRepository(db),
+			Currencies:     repository.NewCurrencyRepository(db),
+			Plans:          repository.NewPlanRepository(db),
+			Subscriptions:  repository.NewUserSubscriptionRepository(db),
+		}
+	})
+}
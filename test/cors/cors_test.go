@@ -0,0 +1,143 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appConfig "MyMoneyBackend/internal/config"
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// newRouter monta el middleware CORS con config sobre una ruta GET /api/plans
+func newRouter(config domain.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.NewCORSMiddleware(config).Handle())
+	r.GET("/api/plans", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func allowlist() domain.CORSConfig {
+	config := appConfig.Default().CORS
+	config.AllowedOrigins = []string{"https://app.example.com", "https://*.preview.example.com", "http://localhost:*"}
+	config.AllowCredentials = true
+	return config
+}
+
+func TestPreflightMatrix(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      domain.CORSConfig
+		origin      string
+		method      string
+		headers     string
+		wantStatus  int
+		wantOrigin  string
+		credentials bool
+	}{
+		{"exact origin", allowlist(), "https://app.example.com", "POST", "Content-Type, Authorization", http.StatusNoContent, "https://app.example.com", true},
+		{"PATCH allowed", allowlist(), "https://app.example.com", "PATCH", "", http.StatusNoContent, "https://app.example.com", true},
+		{"subdomain pattern", allowlist(), "https://pr-42.preview.example.com", "PUT", "X-Request-ID", http.StatusNoContent, "https://pr-42.preview.example.com", true},
+		{"nested subdomain pattern", allowlist(), "https://a.b.preview.example.com", "GET", "", http.StatusNoContent, "https://a.b.preview.example.com", true},
+		{"port pattern", allowlist(), "http://localhost:5173", "DELETE", "", http.StatusNoContent, "http://localhost:5173", true},
+		{"origin case-insensitive", allowlist(), "HTTPS://APP.EXAMPLE.COM", "GET", "", http.StatusNoContent, "HTTPS://APP.EXAMPLE.COM", true},
+		{"pattern needs subdomain", allowlist(), "https://preview.example.com", "GET", "", http.StatusForbidden, "", false},
+		{"pattern suffix attack", allowlist(), "https://evil.preview.example.com.attacker.io", "GET", "", http.StatusForbidden, "", false},
+		{"scheme mismatch", allowlist(), "http://app.example.com", "GET", "", http.StatusForbidden, "", false},
+		{"unknown origin", allowlist(), "https://attacker.io", "POST", "", http.StatusForbidden, "", false},
+		{"method not allowed", allowlist(), "https://app.example.com", "TRACE", "", http.StatusForbidden, "", false},
+		{"header not allowed", allowlist(), "https://app.example.com", "POST", "X-Custom", http.StatusForbidden, "", false},
+		{"wildcard without credentials", appConfig.Default().CORS, "https://anything.io", "POST", "authorization", http.StatusNoContent, "*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/api/plans", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			w := httptest.NewRecorder()
+			newRouter(tt.config).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.credentials {
+				t.Errorf("Expected Access-Control-Allow-Credentials %v, got %v", tt.credentials, got)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+				t.Errorf("Unexpected Access-Control-Allow-Methods %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Expected preflight cached for 600 seconds, got %q", got)
+			}
+			if got := w.Header().Values("Vary"); len(got) != 3 {
+				t.Errorf("Expected Vary on Origin and the requested method and headers, got %v", got)
+			}
+		})
+	}
+}
+
+func TestActualRequestsExposeHeaders(t *testing.T) {
+	r := newRouter(allowlist())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plans", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("Expected the allowed origin echoed on a 200, got %d %v", w.Code, w.Header())
+	}
+	exposed := w.Header().Get("Access-Control-Expose-Headers")
+	for _, want := range []string{"X-Total-Count", "Link", middleware.RateLimitRemainingHeader, "Retry-After", middleware.RequestIDHeader} {
+		if !containsToken(exposed, want) {
+			t.Errorf("Expected %s in Access-Control-Expose-Headers %q", want, exposed)
+		}
+	}
+
+	// Sin CORS el navegador bloquea la lectura; la ruta se sirve igual
+	req = httptest.NewRequest(http.MethodGet, "/api/plans", nil)
+	req.Header.Set("Origin", "https://attacker.io")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected no CORS headers for a foreign origin, got %v", w.Header())
+	}
+}
+
+func TestMaxAgeFollowsConfig(t *testing.T) {
+	config := allowlist()
+	config.MaxAge = 2 * time.Hour
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/plans", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	newRouter(config).ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "7200" {
+		t.Errorf("Expected Access-Control-Max-Age 7200, got %q", got)
+	}
+}
+
+func containsToken(list, token string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == token {
+			return true
+		}
+	}
+	return false
+}
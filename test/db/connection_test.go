@@ -4,11 +4,20 @@ import (
 	"testing"
 
 	"MyMoneyBackend/db/config"
+	appConfig "MyMoneyBackend/internal/config"
 )
 
 func TestDatabaseConnection(t *testing.T) {
 	// Intentar crear una conexión a la base de datos
-	conn, err := config.NewConnection()
+	cfg, _, err := appConfig.Load(nil)
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	// Sin las variables SUPABASE_* no hay base de datos contra la que probar
+	if err := cfg.Database.Validate(); err != nil {
+		t.Skipf("Database not configured: %v", err)
+	}
+	conn, err := config.NewConnection(cfg.Database)
 	if err != nil {
 		t.Fatalf("Error connecting to database: %v", err)
 	}
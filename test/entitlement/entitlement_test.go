@@ -0,0 +1,208 @@
+package entitlement
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	categoryService "MyMoneyBackend/internal/application/category"
+	entitlementService "MyMoneyBackend/internal/application/entitlement"
+	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
+	planService "MyMoneyBackend/internal/application/plan"
+	transactionService "MyMoneyBackend/internal/application/transaction"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/test/testdb"
+)
+
+// Datos iniciales usados en las pruebas: el usuario no tiene categorías ni transacciones
+const (
+	userID = "00000000-0000-0000-0000-000000000002"
+	usdID  = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+)
+
+// harness reúne los servicios que aplican los derechos de uso sobre SQLite
+type harness struct {
+	entitlements   *entitlementService.Service
+	categories     *categoryService.Service
+	transactions   *transactionService.Service
+	plans          *planService.Service
+	paymentMethods *paymentMethodService.Service
+	subRepo        *repository.UserSubscriptionRepository
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := testdb.OpenSeeded(t)
+
+	subRepo := repository.NewUserSubscriptionRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	guard := entitlementService.NewService(subRepo, planRepo)
+
+	return &harness{
+		entitlements: guard,
+		categories:   categoryService.NewService(repository.NewCategoryRepository(db), guard),
+		transactions: transactionService.NewService(
+			repository.NewTransactionRepository(db),
+			repository.NewCategoryRepository(db),
+			repository.NewCurrencyRepository(db),
+			guard, nil, nil,
+		),
+		plans:          planService.NewService(planRepo, repository.NewCurrencyRepository(db), subRepo),
+		paymentMethods: paymentMethodService.NewService(repository.NewPaymentMethodRepository(db), payment.NewFakeGateway()),
+		subRepo:        subRepo,
+	}
+}
+
+// subscribe activa para el usuario un plan con los derechos de uso indicados
+func (h *harness) subscribe(t *testing.T, entitlements domain.Entitlements) *domain.Plan {
+	t.Helper()
+	ctx := context.Background()
+	plan, err := h.plans.CreatePlan(
+		ctx, "Limitado", "Plan de pruebas", 9.99, usdID, domain.PlanIntervalMonth, 1, nil, 0,
+		entitlements, true, true, 10,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the plan: %v", err)
+	}
+
+	now := time.Now()
+	subscription := &domain.UserSubscription{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		PlanID:    plan.ID,
+		Status:    domain.SubscriptionStatusActive,
+		StartDate: now,
+		EndDate:   now.AddDate(0, 1, 0),
+	}
+	if err := h.subRepo.Create(ctx, subscription); err != nil {
+		t.Fatalf("Unexpected error creating the subscription: %v", err)
+	}
+	return plan
+}
+
+func limit(n int) *int {
+	return &n
+}
+
+func TestGetUserEntitlements(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	// Sin suscripción activa se aplica el nivel gratuito
+	resolved, err := h.entitlements.GetUserEntitlements(ctx, userID)
+	if err != nil {
+		t.Fatalf("Unexpected error resolving entitlements: %v", err)
+	}
+	defaults := domain.DefaultEntitlements()
+	if resolved.PlanID != "" || *resolved.Entitlements.MaxCategories != *defaults.MaxCategories {
+		t.Errorf("Expected the default entitlements without a subscription, got %+v", resolved)
+	}
+
+	plan := h.subscribe(t, domain.Entitlements{MaxCategories: limit(50), Attachments: true})
+	resolved, err = h.entitlements.GetUserEntitlements(ctx, userID)
+	if err != nil {
+		t.Fatalf("Unexpected error resolving entitlements: %v", err)
+	}
+	if resolved.PlanID != plan.ID || *resolved.Entitlements.MaxCategories != 50 || resolved.Entitlements.MaxTransactionsPerMonth != nil {
+		t.Errorf("Expected the entitlements of the active plan, got %+v", resolved)
+	}
+}
+
+func TestEntitlementChecks(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	plan := h.subscribe(t, domain.Entitlements{
+		MaxCategories: limit(3),
+		ExportFormats: []domain.ExportFormat{domain.ExportFormatJSON},
+	})
+
+	if err := h.entitlements.CheckLimit(ctx, userID, domain.EntitlementMaxCategories, 2); err != nil {
+		t.Errorf("Expected usage below the limit allowed, got %v", err)
+	}
+	err := h.entitlements.CheckLimit(ctx, userID, domain.EntitlementMaxCategories, 3)
+	var upgrade *domain.UpgradeRequiredError
+	if !errors.As(err, &upgrade) || *upgrade.Limit != 3 || *upgrade.Current != 3 || upgrade.PlanID != plan.ID {
+		t.Errorf("Expected an upgrade required error at the limit, got %v", err)
+	}
+	if err := h.entitlements.CheckLimit(ctx, userID, domain.EntitlementMaxTransactionsPerMonth, 1000); err != nil {
+		t.Errorf("Expected an unlimited entitlement allowed, got %v", err)
+	}
+
+	if err := h.entitlements.CheckFeature(ctx, userID, domain.EntitlementAttachments); !errors.Is(err, domain.ErrUpgradeRequired) {
+		t.Errorf("Expected attachments to require an upgrade, got %v", err)
+	}
+	if err := h.entitlements.CheckExportFormat(ctx, userID, domain.ExportFormatJSON); err != nil {
+		t.Errorf("Expected JSON export allowed, got %v", err)
+	}
+	if err := h.entitlements.CheckExportFormat(ctx, userID, domain.ExportFormatCSV); !errors.Is(err, domain.ErrUpgradeRequired) {
+		t.Errorf("Expected CSV export to require an upgrade, got %v", err)
+	}
+}
+
+func TestCategoryLimit(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	h.subscribe(t, domain.Entitlements{MaxCategories: limit(2)})
+
+	for _, name := range []string{"Comida", "Viajes"} {
+		if _, err := h.categories.CreateCategory(ctx, name, "", "tag", "#FFFFFF", userID); err != nil {
+			t.Fatalf("Unexpected error creating category %s: %v", name, err)
+		}
+	}
+	if _, err := h.categories.CreateCategory(ctx, "Regalos", "", "tag", "#FFFFFF", userID); !errors.Is(err, domain.ErrUpgradeRequired) {
+		t.Errorf("Expected the third category to require an upgrade, got %v", err)
+	}
+}
+
+func TestMonthlyTransactionLimit(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	h.subscribe(t, domain.Entitlements{
+		MaxCategories:           limit(10),
+		MaxTransactionsPerMonth: limit(2),
+		ExportFormats:           []domain.ExportFormat{domain.ExportFormatCSV},
+	})
+
+	category, err := h.categories.CreateCategory(ctx, "Comida", "", "tag", "#FFFFFF", userID)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the category: %v", err)
+	}
+	paymentMethod, err := h.paymentMethods.CreatePaymentMethod(ctx, "Efectivo", "", userID)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the payment method: %v", err)
+	}
+	create := func(date time.Time) error {
+		_, err := h.transactions.CreateTransaction(
+			ctx, 10, "Almuerzo", date, category.ID, paymentMethod.ID, userID, usdID, domain.TransactionTypeExpense,
+		)
+		return err
+	}
+
+	now := time.Now()
+	if err := create(now); err != nil {
+		t.Fatalf("Unexpected error creating the first transaction: %v", err)
+	}
+	// Las transacciones con fecha de otro mes también cuentan en el mes en que se registran
+	if err := create(now.AddDate(0, -2, 0)); err != nil {
+		t.Fatalf("Unexpected error creating a backdated transaction: %v", err)
+	}
+
+	for _, date := range []time.Time{now, now.AddDate(0, -3, 0), now.AddDate(0, 2, 0)} {
+		if err := create(date); !errors.Is(err, domain.ErrUpgradeRequired) {
+			t.Errorf("Expected a transaction dated %s to exceed the monthly limit, got %v", date.Format("2006-01-02"), err)
+		}
+	}
+
+	if _, err := h.transactions.ExportTransactions(ctx, userID, domain.ExportFormatJSON); !errors.Is(err, domain.ErrUpgradeRequired) {
+		t.Errorf("Expected JSON export to require an upgrade, got %v", err)
+	}
+	exported, err := h.transactions.ExportTransactions(ctx, userID, domain.ExportFormatCSV)
+	if err != nil || len(exported) != 2 {
+		t.Errorf("Expected the two transactions exported as CSV, got %d (%v)", len(exported), err)
+	}
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/internal/application/health"
+)
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func newRegistry(t *testing.T, checks ...health.Check) *health.Registry {
+	t.Helper()
+	registry := health.NewRegistry()
+	for _, check := range checks {
+		if err := registry.Register(check); err != nil {
+			t.Fatalf("Unexpected error registering %q: %v", check.Name, err)
+		}
+	}
+	return registry
+}
+
+func TestRunSummarizesChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []health.Check
+		want   health.Status
+	}{
+		{
+			name:   "all passing",
+			checks: []health.Check{{Name: "db", Critical: true, Run: passing}, {Name: "worker", Run: passing}},
+			want:   health.StatusHealthy,
+		},
+		{
+			name:   "non-critical failing",
+			checks: []health.Check{{Name: "db", Critical: true, Run: passing}, {Name: "worker", Run: failing}},
+			want:   health.StatusDegraded,
+		},
+		{
+			name:   "critical failing",
+			checks: []health.Check{{Name: "db", Critical: true, Run: failing}, {Name: "worker", Run: failing}},
+			want:   health.StatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newRegistry(t, tt.checks...).Run(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Expected status %q, got %q", tt.want, report.Status)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("Expected %d check results, got %d", len(tt.checks), len(report.Checks))
+			}
+			for i, result := range report.Checks {
+				if result.Name != tt.checks[i].Name {
+					t.Errorf("Expected results in registration order, got %q at %d", result.Name, i)
+				}
+				if (result.Status == health.StatusHealthy) != (result.Error == "") {
+					t.Errorf("Expected an error only on failing checks, got %+v", result)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTimesOutSlowChecks(t *testing.T) {
+	registry := newRegistry(t, health.Check{
+		Name:     "slow",
+		Critical: true,
+		Timeout:  20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			time.Sleep(time.Second) // no respeta la cancelación
+			return nil
+		},
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the check to be abandoned after its timeout, took %s", elapsed)
+	}
+	if report.Status != health.StatusUnhealthy || report.Checks[0].Error == "" {
+		t.Errorf("Expected a timed out check to be unhealthy with an error, got %+v", report)
+	}
+}
+
+func TestRunCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	registry := newRegistry(t, health.Check{
+		Name:     "db",
+		CacheTTL: time.Hour,
+		Run: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		registry.Run(context.Background())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected the check to run once within its cache TTL, ran %d times", got)
+	}
+}
+
+func TestRegisterRejectsInvalidChecks(t *testing.T) {
+	registry := newRegistry(t, health.Check{Name: "db", Run: passing})
+
+	if err := registry.Register(health.Check{Name: "db", Run: passing}); err == nil {
+		t.Error("Expected an error registering a duplicated name")
+	}
+	if err := registry.Register(health.Check{Name: "nameless"}); err == nil {
+		t.Error("Expected an error registering a check without function")
+	}
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+	"MyMoneyBackend/internal/logging"
+)
+
+// decode devuelve cada línea JSON escrita en buf
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON log line, got %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerRedactsSensitiveData(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	logger.Info("login de ana@example.com",
+		"password", "hunter2",
+		"refresh_token", "abc.def.ghi",
+		"email", "ana@example.com",
+		"header", "Bearer eyJhbGciOiJIUzI1NiJ9.payload.sig",
+		"error", errors.New("usuario ana@example.com no encontrado"),
+	)
+
+	output := buf.String()
+	for _, secret := range []string{"hunter2", "abc.def.ghi", "ana@example.com", "eyJhbGciOiJIUzI1NiJ9"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %q to be redacted, got %s", secret, output)
+		}
+	}
+
+	record := decode(t, &buf)[0]
+	if record["password"] != "****" {
+		t.Errorf("Expected password to be ****, got %v", record["password"])
+	}
+	if record["email"] != "****@example.com" {
+		t.Errorf("Expected email domain to be kept, got %v", record["email"])
+	}
+}
+
+func TestLoggerFiltersByLevel(t *testing.T) {
+	level, err := logging.ParseLevel("warn")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing level: %v", err)
+	}
+	var buf bytes.Buffer
+	logger := logging.New(&buf, level)
+
+	logger.Info("descartado")
+	logger.Warn("registrado")
+
+	records := decode(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "registrado" {
+		t.Errorf("Expected only the warn record, got %v", records)
+	}
+
+	if _, err := logging.ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestRequestIDPropagatesToLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.RequestLogger(logger), middleware.Recovery(logger))
+	r.GET("/items/:id", func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, "user-1")
+		// Un servicio solo recibe el contexto de la petición
+		logger.InfoContext(c.Request.Context(), "consultando item")
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	t.Run("accepts incoming id", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		req.Header.Set(middleware.RequestIDHeader, "abc-123")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get(middleware.RequestIDHeader); got != "abc-123" {
+			t.Errorf("Expected response header abc-123, got %q", got)
+		}
+		records := decode(t, &buf)
+		if len(records) != 2 {
+			t.Fatalf("Expected 2 log records, got %d", len(records))
+		}
+		for _, record := range records {
+			if record[logging.RequestIDKey] != "abc-123" {
+				t.Errorf("Expected request_id abc-123 in %v", record)
+			}
+		}
+		access := records[1]
+		if access["route"] != "/items/:id" || access["status"] != float64(200) || access["user_id"] != "user-1" {
+			t.Errorf("Expected route, status and user_id in the request log, got %v", access)
+		}
+		if _, ok := access["latency_ms"]; !ok {
+			t.Errorf("Expected latency_ms in the request log, got %v", access)
+		}
+	})
+
+	t.Run("generates id when missing or invalid", func(t *testing.T) {
+		for _, incoming := range []string{"", "bad id\nwith newline"} {
+			req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+			if incoming != "" {
+				req.Header.Set(middleware.RequestIDHeader, incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(middleware.RequestIDHeader)
+			if got == "" || got == incoming {
+				t.Errorf("Expected a generated request ID for %q, got %q", incoming, got)
+			}
+		}
+	})
+
+	t.Run("logs panics as errors", func(t *testing.T) {
+		buf.Reset()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		records := decode(t, &buf)
+		if len(records) != 2 || records[0]["level"] != "ERROR" || records[1]["status"] != float64(500) {
+			t.Errorf("Expected a panic record and an error request log, got %v", records)
+		}
+	})
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	if got := logging.RequestID(ctx); got != "req-1" {
+		t.Errorf("Expected req-1, got %q", got)
+	}
+	if got := logging.RequestID(context.Background()); got != "" {
+		t.Errorf("Expected empty request ID, got %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+)
+
+func TestLoadOrdersAndPairsMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t(a);")},
+		"0010_add_index.down.sql":    {Data: []byte("DROP INDEX idx;")},
+		"0002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a INT);")},
+		"0002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                  {Data: []byte("ignorado")},
+	}
+
+	loaded, err := migrate.Load(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(loaded))
+	}
+	if loaded[0].Version != 2 || loaded[1].Version != 10 {
+		t.Errorf("Expected versions 2 and 10 in order, got %d and %d", loaded[0].Version, loaded[1].Version)
+	}
+	if loaded[0].Name != "create_table" || loaded[0].Down != "DROP TABLE t;" {
+		t.Errorf("Unexpected migration: %+v", loaded[0])
+	}
+	if len(loaded[0].Checksum) != 64 || loaded[0].Checksum == loaded[1].Checksum {
+		t.Errorf("Expected distinct SHA-256 checksums, got %q and %q", loaded[0].Checksum, loaded[1].Checksum)
+	}
+}
+
+func TestLoadRejectsIncompleteMigrations(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"sin down": {
+			"0001_users.up.sql": {Data: []byte("CREATE TABLE users (id INT);")},
+		},
+		"sin up": {
+			"0001_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		},
+		"nombres distintos": {
+			"0001_users.up.sql":    {Data: []byte("CREATE TABLE users (id INT);")},
+			"0001_people.down.sql": {Data: []byte("DROP TABLE users;")},
+		},
+	}
+
+	for name, fsys := range cases {
+		if _, err := migrate.Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error loading embedded migrations: %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	for i, migration := range loaded {
+		if migration.Version != int64(i+1) {
+			t.Errorf("Expected consecutive version %d, got %d_%s", i+1, migration.Version, migration.Name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/db/config"
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	appConfig "MyMoneyBackend/internal/config"
+)
+
+// TestPostgresMigrationsUpgradeBaselineSchema aplica las migraciones sobre una base de datos creada
+// con los scripts anteriores al ejecutor (commit a36acfb), en un esquema temporal. Se omite si no
+// hay base de datos PostgreSQL configurada.
+func TestPostgresMigrationsUpgradeBaselineSchema(t *testing.T) {
+	cfg, _, err := appConfig.Load(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error loading configuration: %v", err)
+	}
+	if cfg.Database.Driver != config.DriverPostgres || cfg.Database.Validate() != nil {
+		t.Skip("PostgreSQL not configured")
+	}
+	conn, err := config.NewConnection(cfg.Database)
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	defer conn.Close()
+
+	// Una sola conexión para que search_path apunte siempre al esquema temporal
+	ctx := context.Background()
+	db := conn.GetDB()
+	db.SetMaxOpenConns(1)
+	schema := fmt.Sprintf("migrate_baseline_%d", time.Now().UnixNano())
+	if _, err := db.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Skipf("Cannot create a temporary schema: %v", err)
+	}
+	defer db.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	if _, err := db.ExecContext(ctx, "SET search_path TO "+schema); err != nil {
+		t.Fatalf("Unexpected error selecting the temporary schema: %v", err)
+	}
+
+	baseline, err := os.ReadFile("testdata/baseline_postgres.sql")
+	if err != nil {
+		t.Fatalf("Unexpected error reading the baseline schema: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, string(baseline)); err != nil {
+		t.Fatalf("Unexpected error creating the baseline schema: %v", err)
+	}
+
+	migrator, err := migrate.New(db, migrate.Postgres, migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Expected the migrations to upgrade the baseline schema, got %v", err)
+	}
+	if err := migrator.Verify(ctx); err != nil {
+		t.Errorf("Expected every migration applied, got %v", err)
+	}
+
+	// Los planes existentes pasan a ser la versión 1 de su familia con el intervalo nuevo
+	var familyID, interval string
+	var version, intervalCount int
+	err = db.QueryRowContext(ctx,
+		`SELECT family_id, version, interval, interval_count FROM plans WHERE id = 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13'`,
+	).Scan(&familyID, &version, &interval, &intervalCount)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the upgraded plan: %v", err)
+	}
+	if familyID != "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13" || version != 1 || interval != "year" || intervalCount != 1 {
+		t.Errorf("Expected the baseline plan upgraded in place, got family %s v%d %d %s", familyID, version, intervalCount, interval)
+	}
+
+	var cardColumns int
+	err = db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = $1 AND table_name = 'payment_methods' AND column_name LIKE 'card_%'`,
+		schema,
+	).Scan(&cardColumns)
+	if err != nil || cardColumns != 3 {
+		t.Errorf("Expected the card columns added to payment_methods, got %d (%v)", cardColumns, err)
+	}
+}
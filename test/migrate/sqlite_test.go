@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/db/seeds"
+	"MyMoneyBackend/internal/infraestructure/outbound/sqlite"
+)
+
+func TestSQLiteMigrationsApplySeedAndRollBack(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error opening SQLite: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrate.SQLite, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if err := migrator.Verify(ctx); err == nil {
+		t.Error("Expected Verify to fail before applying migrations")
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("Expected migrations to be applied")
+	}
+	if err := migrator.Verify(ctx); err != nil {
+		t.Errorf("Expected Verify to pass after applying migrations, got %v", err)
+	}
+
+	// Los datos iniciales deben poder ejecutarse más de una vez
+	for i := 0; i < 2; i++ {
+		if _, err := migrate.Seed(ctx, db, seeds.SQLite); err != nil {
+			t.Fatalf("Unexpected error seeding (run %d): %v", i+1, err)
+		}
+	}
+
+	var count int
+	err = db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM transactions WHERE date BETWEEN $1 AND $2`,
+		"2023-05-01 00:00:00.000000000Z",
+		"2023-05-10 23:59:59.999999999Z",
+	).Scan(&count)
+	if err != nil {
+		t.Fatalf("Unexpected error counting seeded transactions: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 seeded transactions between May 1 and May 10, got %d", count)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error reading status: %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt == nil || status.Modified {
+			t.Errorf("Expected %04d_%s applied and unmodified, got %+v", status.Version, status.Name, status)
+		}
+	}
+
+	for range applied {
+		if _, err := migrator.Rollback(ctx); err != nil {
+			t.Fatalf("Unexpected error rolling back: %v", err)
+		}
+	}
+	reverted, err := migrator.Rollback(ctx)
+	if err != nil || reverted != nil {
+		t.Errorf("Expected nothing left to roll back, got %v, %v", reverted, err)
+	}
+}
+
+func TestEmbeddedSQLiteMigrationsMatchPostgres(t *testing.T) {
+	postgres, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error loading Postgres migrations: %v", err)
+	}
+	sqliteMigrations, err := migrate.Load(migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error loading SQLite migrations: %v", err)
+	}
+
+	// SQLite no necesita las migraciones que solo convierten tipos de columnas en PostgreSQL
+	names := make(map[string]bool, len(postgres))
+	for _, migration := range postgres {
+		names[migration.Name] = true
+	}
+	for i, migration := range sqliteMigrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("Expected consecutive version %d, got %d_%s", i+1, migration.Version, migration.Name)
+		}
+		if !names[migration.Name] {
+			t.Errorf("SQLite migration %d_%s has no PostgreSQL counterpart", migration.Version, migration.Name)
+		}
+	}
+}
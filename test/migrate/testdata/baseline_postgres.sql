@@ -0,0 +1,204 @@
+-- Esquema de la base de datos en el commit a36acfb, antes de las migraciones versionadas:
+-- currencies.sql, schema.sql y plans.sql tal como se aplicaban a mano.
+
+-- Tabla para almacenar las monedas
+CREATE TABLE IF NOT EXISTS currencies (
+    id UUID PRIMARY KEY,
+    code VARCHAR(10) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para optimizar búsquedas
+CREATE INDEX IF NOT EXISTS idx_currencies_code ON currencies(code);
+CREATE INDEX IF NOT EXISTS idx_currencies_is_active ON currencies(is_active);
+
+-- Insertar monedas iniciales
+INSERT INTO currencies (id, code, name, symbol, is_active, created_at, updated_at)
+VALUES 
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'USD', 'Dólar estadounidense', '$', TRUE, NOW(), NOW()),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12', 'EUR', 'Euro', '€', TRUE, NOW(), NOW()),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a13', 'GBP', 'Libra esterlina', '£', TRUE, NOW(), NOW()),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a14', 'JPY', 'Yen japonés', '¥', TRUE, NOW(), NOW()),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a15', 'MXN', 'Peso mexicano', '$', TRUE, NOW(), NOW()),
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a16', 'BTC', 'Bitcoin', '₿', TRUE, NOW(), NOW())
+ON CONFLICT (code) DO UPDATE 
+SET name = EXCLUDED.name,
+    symbol = EXCLUDED.symbol,
+    is_active = EXCLUDED.is_active,
+    updated_at = NOW(); 
+
+-- Esquema principal de la base de datos
+
+-- Tabla de usuarios
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para usuarios
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+-- Tabla de categorías
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    color VARCHAR(20),
+    icon VARCHAR(50),
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Índices para categorías
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories(user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_name ON categories(name);
+
+-- Tabla de métodos de pago
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Índices para métodos de pago
+CREATE INDEX IF NOT EXISTS idx_payment_methods_user_id ON payment_methods(user_id);
+CREATE INDEX IF NOT EXISTS idx_payment_methods_name ON payment_methods(name);
+
+-- Tabla de transacciones
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY,
+    amount DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    date TIMESTAMP WITH TIME ZONE NOT NULL,
+    category_id UUID NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('INCOME', 'EXPENSE')),
+    payment_method_id UUID,
+    currency_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT,
+    FOREIGN KEY (payment_method_id) REFERENCES payment_methods(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (currency_id) REFERENCES currencies(id)
+);
+
+-- Índices para transacciones
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions(category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_payment_method_id ON transactions(payment_method_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
+CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
+CREATE INDEX IF NOT EXISTS idx_transactions_currency_id ON transactions(currency_id);
+
+-- Insertar datos de ejemplo para usuario
+INSERT INTO users (id, email, name, password, created_at, updated_at)
+VALUES 
+    ('00000000-0000-0000-0000-000000000001', 'admin@example.com', 'Admin', '$2a$10$1qAz2wSx3eDc4rFv5tGb5edIUVkIuDGJHGNQ4qRntVkH9aHktvQaO', NOW(), NOW()),
+    ('00000000-0000-0000-0000-000000000002', 'user@example.com', 'User', '$2a$10$1qAz2wSx3eDc4rFv5tGb5edIUVkIuDGJHGNQ4qRntVkH9aHktvQaO', NOW(), NOW())
+ON CONFLICT (id) DO NOTHING; 
+
+-- Tabla para almacenar los planes de suscripción
+CREATE TABLE IF NOT EXISTS plans (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency_id UUID NOT NULL REFERENCES currencies(id),
+    interval VARCHAR(50) NOT NULL CHECK (interval IN ('monthly', 'yearly')),
+    features JSONB NOT NULL DEFAULT '[]'::JSONB,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    is_public BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para optimizar búsquedas
+CREATE INDEX IF NOT EXISTS idx_plans_is_active ON plans(is_active);
+CREATE INDEX IF NOT EXISTS idx_plans_is_public ON plans(is_public);
+CREATE INDEX IF NOT EXISTS idx_plans_sort_order ON plans(sort_order);
+
+-- Insertar planes iniciales
+INSERT INTO plans (id, name, description, price, currency_id, interval, features, is_active, is_public, sort_order, created_at, updated_at)
+VALUES 
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b11', 
+        'Gratis', 
+        'Plan básico con funcionalidades limitadas', 
+        0, 
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'monthly',
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "No disponible", "included": false},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "No disponible", "included": false}
+        ]'::JSONB,
+        TRUE,
+        TRUE,
+        1,
+        NOW(),
+        NOW()
+    ),
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12', 
+        'Pro', 
+        'Plan profesional con todas las funcionalidades', 
+        19.99, 
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'monthly',
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "Ilimitado", "included": true}
+        ]'::JSONB,
+        TRUE,
+        TRUE,
+        2,
+        NOW(),
+        NOW()
+    ),
+    (
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13', 
+        'Pro Anual', 
+        'Plan profesional con todas las funcionalidades - Facturación anual', 
+        199.90, 
+        'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', -- USD
+        'yearly',
+        '[
+            {"name": "Procesamiento de texto", "description": "Generación de texto mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de imágenes", "description": "Generación y edición de imágenes mediante IA", "value": "Ilimitado", "included": true},
+            {"name": "Procesamiento de audio", "description": "Transcripción y generación de audio mediante IA", "value": "Ilimitado", "included": true}
+        ]'::JSONB,
+        TRUE,
+        TRUE,
+        3,
+        NOW(),
+        NOW()
+    )
+ON CONFLICT (id) DO UPDATE 
+SET name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    price = EXCLUDED.price,
+    currency_id = EXCLUDED.currency_id,
+    interval = EXCLUDED.interval,
+    features = EXCLUDED.features,
+    is_active = EXCLUDED.is_active,
+    is_public = EXCLUDED.is_public,
+    sort_order = EXCLUDED.sort_order,
+    updated_at = NOW(); 
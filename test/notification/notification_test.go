@@ -0,0 +1,220 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	notificationService "MyMoneyBackend/internal/application/notification"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/notifier"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/test/testdb"
+)
+
+// demoUserID es el usuario de los datos iniciales
+const demoUserID = "d15ab58a-4689-4745-bb27-46ec4757731f"
+
+// recordingNotifier guarda los mensajes que recibe y falla si tiene un error configurado
+type recordingNotifier struct {
+	channel  domain.NotificationChannel
+	err      error
+	messages []*domain.NotificationMessage
+}
+
+func (n *recordingNotifier) Channel() domain.NotificationChannel { return n.channel }
+
+func (n *recordingNotifier) Send(_ context.Context, message *domain.NotificationMessage) error {
+	n.messages = append(n.messages, message)
+	return n.err
+}
+
+// roundTripFunc permite sustituir la red del cliente HTTP en las pruebas
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestPreferenceTargetsAreValidated(t *testing.T) {
+	tests := []struct {
+		channel domain.NotificationChannel
+		target  string
+		valid   bool
+	}{
+		{domain.NotificationChannelWebhook, "https://hooks.example.com/mymoney", true},
+		{domain.NotificationChannelWebhook, "https://93.184.216.34:8443/hook", true},
+		{domain.NotificationChannelWebhook, "http://hooks.example.com/mymoney", false},
+		{domain.NotificationChannelWebhook, "/relative/hook", false},
+		{domain.NotificationChannelWebhook, "https://localhost/hook", false},
+		{domain.NotificationChannelWebhook, "https://api.localhost./hook", false},
+		{domain.NotificationChannelWebhook, "https://127.0.0.1/hook", false},
+		{domain.NotificationChannelWebhook, "https://169.254.169.254/latest/meta-data", false},
+		{domain.NotificationChannelWebhook, "https://10.0.0.5/hook", false},
+		{domain.NotificationChannelWebhook, "https://192.168.1.10/hook", false},
+		{domain.NotificationChannelWebhook, "https://[::1]/hook", false},
+		{domain.NotificationChannelWebhook, "https://[::ffff:127.0.0.1]/hook", false},
+		{domain.NotificationChannelWebhook, "https://100.64.0.1/hook", false},
+		{domain.NotificationChannelEmail, "", true},
+		{domain.NotificationChannelEmail, "alerts@example.com", true},
+		{domain.NotificationChannelEmail, "not-an-email", false},
+		{domain.NotificationChannelEmail, "Ana <ana@example.com>", false},
+	}
+
+	for _, tt := range tests {
+		preference := &domain.NotificationPreference{
+			UserID:  demoUserID,
+			Event:   domain.NotificationEventPaymentFailed,
+			Channel: tt.channel,
+			Enabled: true,
+			Target:  tt.target,
+		}
+		err := preference.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("Expected %s target %q to be valid, got %v", tt.channel, tt.target, err)
+		}
+		if !tt.valid && !errors.Is(err, domain.KindValidation) {
+			t.Errorf("Expected a validation error for %s target %q, got %v", tt.channel, tt.target, err)
+		}
+	}
+}
+
+func TestPublishDeliversThroughEnabledChannels(t *testing.T) {
+	db := testdb.OpenSeeded(t)
+	ctx := context.Background()
+
+	inApp := notifier.NewInAppNotifier(repository.NewNotificationRepository(db))
+	email := &recordingNotifier{channel: domain.NotificationChannelEmail, err: errors.New("smtp caído")}
+	webhook := &recordingNotifier{channel: domain.NotificationChannelWebhook}
+	service := notificationService.NewService(
+		repository.NewNotificationRepository(db),
+		repository.NewNotificationPreferenceRepository(db),
+		repository.NewNotificationTemplateRepository(db),
+		repository.NewUserRepository(db),
+		inApp, email, webhook,
+	)
+
+	if _, err := service.UpdatePreference(ctx, demoUserID, domain.NotificationEventPaymentFailed, domain.NotificationChannelWebhook, true, "https://hooks.example.com/mymoney"); err != nil {
+		t.Fatalf("Unexpected error enabling the webhook: %v", err)
+	}
+	if _, err := service.UpdatePreference(ctx, demoUserID, domain.NotificationEventPaymentFailed, domain.NotificationChannelWebhook, true, "https://169.254.169.254/"); !errors.Is(err, domain.KindValidation) {
+		t.Errorf("Expected an internal webhook URL to be rejected, got %v", err)
+	}
+
+	err := service.Publish(ctx, demoUserID, domain.NotificationEventPaymentFailed, map[string]string{
+		"amount": "9.99", "currency": "USD", "plan_name": "Pro", "reason": "fondos insuficientes", "next_attempt": "2024-05-04",
+	})
+	// Un canal que falla no impide la entrega por los demás, pero se informa
+	if err == nil || !strings.Contains(err.Error(), "smtp caído") {
+		t.Errorf("Expected the email failure to be reported, got %v", err)
+	}
+
+	inbox, err := service.GetInbox(ctx, demoUserID, true)
+	if err != nil || len(inbox) != 1 {
+		t.Fatalf("Expected one unread in-app notification, got %d (%v)", len(inbox), err)
+	}
+	if !strings.Contains(inbox[0].Body, "Demo") || !strings.Contains(inbox[0].Body, "9.99 USD") {
+		t.Errorf("Expected the default template rendered with the event data, got %q", inbox[0].Body)
+	}
+	if len(email.messages) != 1 || email.messages[0].Recipient != "demo@example.com" {
+		t.Errorf("Expected the email sent to the user's address, got %+v", email.messages)
+	}
+	if len(webhook.messages) != 1 || webhook.messages[0].Recipient != "https://hooks.example.com/mymoney" {
+		t.Errorf("Expected the webhook sent to the configured URL, got %+v", webhook.messages)
+	}
+
+	// Los webhooks están desactivados por defecto en el resto de eventos
+	if err := service.Publish(ctx, demoUserID, domain.NotificationEventPasswordChanged, map[string]string{"changed_at": "2024-05-01"}); err == nil {
+		t.Error("Expected the failing email channel to be reported again")
+	}
+	if len(webhook.messages) != 1 {
+		t.Errorf("Expected no webhook for events without a preference, got %d messages", len(webhook.messages))
+	}
+}
+
+func TestTemplatesCoverEveryEventAndCanBeOverridden(t *testing.T) {
+	db := testdb.OpenSeeded(t)
+	ctx := context.Background()
+	inApp := &recordingNotifier{channel: domain.NotificationChannelInApp}
+	service := notificationService.NewService(
+		repository.NewNotificationRepository(db),
+		repository.NewNotificationPreferenceRepository(db),
+		repository.NewNotificationTemplateRepository(db),
+		repository.NewUserRepository(db),
+		inApp,
+	)
+
+	templates, err := service.GetTemplates(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error listing templates: %v", err)
+	}
+	if len(templates) != len(domain.NotificationEvents)*3 {
+		t.Errorf("Expected a template per event and channel, got %d", len(templates))
+	}
+	for _, tmpl := range templates {
+		if tmpl.Subject == "" || tmpl.Body == "" {
+			t.Errorf("Expected a default template for %s/%s", tmpl.Event, tmpl.Channel)
+		}
+	}
+
+	if _, err := service.UpdateTemplate(ctx, domain.NotificationEventTrialEnded, domain.NotificationChannelInApp, "Fin", "{{.plan_name"); err == nil {
+		t.Error("Expected a template that does not parse to be rejected")
+	}
+	if _, err := service.UpdateTemplate(ctx, domain.NotificationEventTrialEnded, domain.NotificationChannelInApp, "Fin de {{.plan_name}}", "Hola {{.user_name}}"); err != nil {
+		t.Fatalf("Unexpected error updating the template: %v", err)
+	}
+	if err := service.Publish(ctx, demoUserID, domain.NotificationEventTrialEnded, map[string]string{"plan_name": "Pro"}); err != nil {
+		t.Fatalf("Unexpected error publishing: %v", err)
+	}
+	if len(inApp.messages) != 1 || inApp.messages[0].Subject != "Fin de Pro" || inApp.messages[0].Body != "Hola Demo" {
+		t.Errorf("Expected the custom template to be used, got %+v", inApp.messages)
+	}
+}
+
+func TestWebhookIsSignedAndBlocksInternalAddresses(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		received = req
+		body, _ = io.ReadAll(req.Body)
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+	})}
+
+	webhook := notifier.NewWebhookNotifier("secreto", client)
+	message := &domain.NotificationMessage{
+		UserID:    demoUserID,
+		Recipient: "https://hooks.example.com/mymoney",
+		Event:     domain.NotificationEventPaymentFailed,
+		Subject:   "No pudimos procesar tu pago",
+	}
+	if err := webhook.Send(context.Background(), message); err != nil {
+		t.Fatalf("Unexpected error sending the webhook: %v", err)
+	}
+
+	timestamp := received.Header.Get(notifier.TimestampHeader)
+	if got := received.Header.Get(notifier.SignatureHeader); got != "sha256="+notifier.Sign("secreto", timestamp, body) {
+		t.Errorf("Expected the body signed with the shared secret, got %q", got)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil || payload["event"] != string(domain.NotificationEventPaymentFailed) {
+		t.Errorf("Expected the event in the payload, got %s", body)
+	}
+
+	// Una URL guardada antes de validar las preferencias se rechaza al enviar
+	message.Recipient = "http://169.254.169.254/latest/meta-data"
+	if err := webhook.Send(context.Background(), message); err == nil {
+		t.Error("Expected an internal webhook URL to be rejected when sending")
+	}
+
+	// El cliente por defecto comprueba la dirección resuelta al conectar
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected the request never to reach a loopback address")
+	}))
+	defer server.Close()
+	if _, err := notifier.NewWebhookClient().Get(server.URL); !errors.Is(err, notifier.ErrBlockedAddress) {
+		t.Errorf("Expected ErrBlockedAddress dialing %s, got %v", server.URL, err)
+	}
+}
@@ -0,0 +1,205 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+func TestFakeGatewayIsDeterministic(t *testing.T) {
+	gateway := payment.NewFakeGateway()
+	ctx := context.Background()
+
+	// Una tarjeta de prueba aprobada se cobra correctamente
+	token, err := gateway.Tokenize(ctx, &domain.CardDetails{Number: payment.TestCardVisa, ExpMonth: 12, ExpYear: 2030, CVC: "123"})
+	if err != nil {
+		t.Fatalf("Error tokenizing card: %v", err)
+	}
+	if token.Token != payment.TokenVisa || token.Last4 != "4242" {
+		t.Errorf("Unexpected token %+v", token)
+	}
+
+	charge, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: token.Token, Amount: 9.99, Currency: "usd", IdempotencyKey: "sub-1"})
+	if err != nil {
+		t.Fatalf("Error charging card: %v", err)
+	}
+	if charge.Status != domain.ChargeStatusSucceeded {
+		t.Errorf("Expected succeeded charge, got %s", charge.Status)
+	}
+
+	// La misma clave de idempotencia devuelve el mismo cobro
+	again, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: token.Token, Amount: 9.99, Currency: "usd", IdempotencyKey: "sub-1"})
+	if err != nil {
+		t.Fatalf("Error repeating charge: %v", err)
+	}
+	if again.ID != charge.ID {
+		t.Errorf("Expected idempotent charge %s, got %s", charge.ID, again.ID)
+	}
+
+	// Una tarjeta de rechazo devuelve un cobro fallido y ErrPaymentFailed
+	declined, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: payment.TokenInsufficientFunds, Amount: 9.99, Currency: "usd"})
+	if !errors.Is(err, domain.ErrPaymentFailed) {
+		t.Fatalf("Expected ErrPaymentFailed, got %v", err)
+	}
+	if declined.Status != domain.ChargeStatusFailed || declined.FailureCode != "insufficient_funds" {
+		t.Errorf("Unexpected declined charge %+v", declined)
+	}
+
+	// El reembolso se hace en la moneda del cobro y no puede superar el monto cobrado
+	if _, err := gateway.Refund(ctx, charge.ID, 5, "EUR"); err == nil {
+		t.Error("Expected refund in another currency to fail")
+	}
+	refund, err := gateway.Refund(ctx, charge.ID, 5, "usd")
+	if err != nil {
+		t.Fatalf("Error refunding charge: %v", err)
+	}
+	if refund.Amount != 5 || refund.Currency != "USD" {
+		t.Errorf("Unexpected refund %+v", refund)
+	}
+	if _, err := gateway.Refund(ctx, charge.ID, 5, "usd"); err == nil {
+		t.Error("Expected refund over the charged amount to fail")
+	}
+}
+
+func TestStripeGatewayAgainstMockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "sk_test_123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"type": "invalid_request_error", "message": "invalid api key"}})
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/payment_methods":
+			if r.Form.Get("type") != "card" {
+				t.Errorf("Expected a card payment method, got %q", r.Form.Get("type"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "pm_123", "card": map[string]string{"brand": "Visa", "last4": r.Form.Get("card[number]")[12:]}})
+		case "/v1/customers":
+			if r.Form.Get("payment_method") != "pm_123" || r.Form.Get("invoice_settings[default_payment_method]") != "pm_123" {
+				t.Errorf("Expected the payment method attached to the customer, got %v", r.Form)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "cus_123"})
+		case "/v1/payment_intents":
+			if r.Form.Get("confirm") != "true" || r.Form.Get("off_session") != "true" {
+				t.Errorf("Expected an off-session confirmed payment intent, got %v", r.Form)
+			}
+			if r.Form.Get("customer") == "cus_3ds" {
+				json.NewEncoder(w).Encode(map[string]any{"id": "pi_3ds", "status": "requires_action", "amount": 999, "currency": "usd", "created": 1700000000})
+				return
+			}
+			if r.Form.Get("customer") == "cus_declined" {
+				w.WriteHeader(http.StatusPaymentRequired)
+				json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{
+					"type": "card_error", "code": "card_declined", "decline_code": "generic_decline",
+					"message": "Your card was declined.", "charge": "ch_declined",
+				}})
+				return
+			}
+			if r.Form.Get("currency") == "jpy" {
+				if r.Form.Get("amount") != "1500" {
+					t.Errorf("Expected JPY charged without decimals, got %s", r.Form.Get("amount"))
+				}
+				json.NewEncoder(w).Encode(map[string]any{"id": "pi_jpy", "latest_charge": "ch_jpy", "status": "succeeded", "amount": 1500, "currency": "jpy", "created": 1700000000})
+				return
+			}
+			if r.Header.Get("Idempotency-Key") != "sub-1" {
+				t.Errorf("Expected Idempotency-Key header, got %q", r.Header.Get("Idempotency-Key"))
+			}
+			if r.Form.Get("amount") != "999" || r.Form.Get("currency") != "usd" {
+				t.Errorf("Unexpected charge amount %s %s", r.Form.Get("amount"), r.Form.Get("currency"))
+			}
+			if r.Form.Get("customer") != "cus_123" || r.Form.Get("payment_method") != "pm_123" {
+				t.Errorf("Expected the stored customer and payment method charged, got %v", r.Form)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "pi_123", "latest_charge": "ch_123", "status": "succeeded", "amount": 999, "currency": "usd", "created": 1700000000})
+		case "/v1/refunds":
+			if r.Form.Get("charge") == "ch_jpy" {
+				if r.Form.Get("amount") != "500" {
+					t.Errorf("Expected JPY refunded without decimals, got %s", r.Form.Get("amount"))
+				}
+				json.NewEncoder(w).Encode(map[string]any{"id": "re_jpy", "charge": "ch_jpy", "amount": 500, "currency": "jpy", "status": "succeeded", "created": 1700000000})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "re_123", "charge": r.Form.Get("charge"), "amount": 999, "currency": "usd", "status": "succeeded", "created": 1700000000})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gateway := payment.NewStripeGateway(server.URL, "sk_test_123", server.Client())
+	ctx := context.Background()
+
+	token, err := gateway.Tokenize(ctx, &domain.CardDetails{Number: payment.TestCardVisa, ExpMonth: 12, ExpYear: 2030, CVC: "123"})
+	if err != nil {
+		t.Fatalf("Error tokenizing card: %v", err)
+	}
+	if token.Token != "cus_123:pm_123" || token.Brand != "visa" || token.Last4 != "4242" {
+		t.Errorf("Unexpected token %+v", token)
+	}
+
+	charge, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: token.Token, Amount: 9.99, Currency: "USD", IdempotencyKey: "sub-1"})
+	if err != nil {
+		t.Fatalf("Error charging card: %v", err)
+	}
+	if charge.ID != "ch_123" || charge.Amount != 9.99 || charge.Currency != "USD" {
+		t.Errorf("Unexpected charge %+v", charge)
+	}
+
+	// Los tokens de un solo uso no se pueden volver a cobrar
+	if _, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: "tok_123", Amount: 9.99, Currency: "USD"}); !errors.Is(err, domain.ErrPaymentFailed) {
+		t.Errorf("Expected ErrPaymentFailed for a single-use token, got %v", err)
+	}
+
+	declined, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: "cus_declined:pm_declined", Amount: 9.99, Currency: "USD"})
+	if !errors.Is(err, domain.ErrPaymentFailed) {
+		t.Fatalf("Expected ErrPaymentFailed, got %v", err)
+	}
+	if declined.Status != domain.ChargeStatusFailed || declined.FailureCode != "generic_decline" {
+		t.Errorf("Unexpected declined charge %+v", declined)
+	}
+
+	// Un cobro que requiere autenticar al usuario no se puede completar en una renovación
+	pending, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: "cus_3ds:pm_3ds", Amount: 9.99, Currency: "USD"})
+	if !errors.Is(err, domain.ErrPaymentFailed) {
+		t.Fatalf("Expected ErrPaymentFailed, got %v", err)
+	}
+	if pending.ID != "pi_3ds" || pending.Status != domain.ChargeStatusFailed || pending.FailureCode != "requires_action" {
+		t.Errorf("Unexpected pending charge %+v", pending)
+	}
+
+	refund, err := gateway.Refund(ctx, charge.ID, 0, charge.Currency)
+	if err != nil {
+		t.Fatalf("Error refunding charge: %v", err)
+	}
+	if refund.ChargeID != "ch_123" || refund.Amount != 9.99 || refund.Currency != "USD" {
+		t.Errorf("Unexpected refund %+v", refund)
+	}
+
+	// Las monedas sin decimales se envían y se leen en unidades enteras
+	yen, err := gateway.Charge(ctx, &domain.ChargeRequest{Token: token.Token, Amount: 1500, Currency: "JPY", IdempotencyKey: "sub-2"})
+	if err != nil {
+		t.Fatalf("Error charging card in JPY: %v", err)
+	}
+	if yen.Amount != 1500 || yen.Currency != "JPY" {
+		t.Errorf("Unexpected JPY charge %+v", yen)
+	}
+	refund, err = gateway.Refund(ctx, yen.ID, 500, yen.Currency)
+	if err != nil {
+		t.Fatalf("Error refunding JPY charge: %v", err)
+	}
+	if refund.Amount != 500 || refund.Currency != "JPY" {
+		t.Errorf("Unexpected JPY refund %+v", refund)
+	}
+}
@@ -0,0 +1,224 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	currencyService "MyMoneyBackend/internal/application/currency"
+	planService "MyMoneyBackend/internal/application/plan"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/test/testdb"
+)
+
+// Datos iniciales usados en las pruebas
+const (
+	userID    = "00000000-0000-0000-0000-000000000002"
+	usdID     = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+	proPlanID = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12"
+)
+
+// harness reúne los servicios de planes y monedas sobre SQLite
+type harness struct {
+	plans      *planService.Service
+	currencies *currencyService.Service
+	subRepo    *repository.UserSubscriptionRepository
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := testdb.OpenSeeded(t)
+
+	subRepo := repository.NewUserSubscriptionRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db)
+	return &harness{
+		plans:      planService.NewService(repository.NewPlanRepository(db), currencyRepo, subRepo),
+		currencies: currencyService.NewService(currencyRepo),
+		subRepo:    subRepo,
+	}
+}
+
+// newPlan crea un plan público de pago mensual en la moneda indicada
+func (h *harness) newPlan(t *testing.T, name, currencyID string) *domain.Plan {
+	t.Helper()
+	plan, err := h.plans.CreatePlan(
+		context.Background(), name, "Plan de pruebas", 4.99, currencyID, domain.PlanIntervalMonth, 1, nil, 0,
+		domain.DefaultEntitlements(), true, true, 50,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the plan: %v", err)
+	}
+	return plan
+}
+
+// subscribe guarda una suscripción al plan con el estado indicado
+func (h *harness) subscribe(t *testing.T, planID string, status domain.SubscriptionStatus) {
+	t.Helper()
+	now := time.Now()
+	subscription := &domain.UserSubscription{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		PlanID:    planID,
+		Status:    status,
+		StartDate: now,
+		EndDate:   now.AddDate(0, 1, 0),
+	}
+	if err := h.subRepo.Create(context.Background(), subscription); err != nil {
+		t.Fatalf("Unexpected error creating the subscription: %v", err)
+	}
+}
+
+func containsPlan(plans []*domain.Plan, id string) bool {
+	for _, plan := range plans {
+		if plan.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeletingPlansInUseIsRejected(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	plan := h.newPlan(t, "Usado", usdID)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusActive)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusCancelled)
+
+	// Las suscripciones canceladas también cuentan: el plan debe archivarse
+	err := h.plans.DeletePlan(ctx, plan.ID)
+	var inUse *domain.ResourceInUseError
+	if !errors.As(err, &inUse) || !errors.Is(err, domain.ErrResourceInUse) {
+		t.Fatalf("Expected a resource in use error, got %v", err)
+	}
+	if inUse.Resource != "plan" || inUse.References["user_subscriptions"] != 2 || !inUse.Archivable {
+		t.Errorf("Expected two subscriptions referencing an archivable plan, got %+v", inUse)
+	}
+	if _, err := h.plans.GetPlanByID(ctx, plan.ID); err != nil {
+		t.Errorf("Expected the plan kept, got %v", err)
+	}
+
+	// Un plan sin suscripciones se elimina con todas sus versiones
+	unused := h.newPlan(t, "Sin usar", usdID)
+	if err := h.plans.DeletePlan(ctx, unused.ID); err != nil {
+		t.Fatalf("Unexpected error deleting an unused plan: %v", err)
+	}
+	if _, err := h.plans.GetPlanByID(ctx, unused.ID); err == nil {
+		t.Error("Expected the unused plan to be deleted")
+	}
+}
+
+func TestDeletingCurrenciesInUseIsRejected(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	err := h.currencies.DeleteCurrency(ctx, usdID)
+	var inUse *domain.ResourceInUseError
+	if !errors.As(err, &inUse) || !errors.Is(err, domain.ErrResourceInUse) {
+		t.Fatalf("Expected a resource in use error, got %v", err)
+	}
+	if inUse.Resource != "currency" || inUse.References["plans"] == 0 || !inUse.Archivable {
+		t.Errorf("Expected plans referencing an archivable currency, got %+v", inUse)
+	}
+
+	unused, err := h.currencies.CreateCurrency(ctx, "CHF", "Franco suizo", "Fr", true)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the currency: %v", err)
+	}
+	if err := h.currencies.DeleteCurrency(ctx, unused.ID); err != nil {
+		t.Fatalf("Unexpected error deleting an unused currency: %v", err)
+	}
+	if _, err := h.currencies.GetCurrencyByID(ctx, unused.ID); err == nil {
+		t.Error("Expected the unused currency to be deleted")
+	}
+}
+
+func TestArchivedPlansAreHiddenUntilUnarchived(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	plan := h.newPlan(t, "Temporada", usdID)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusActive)
+
+	if _, err := h.plans.UnarchivePlan(ctx, plan.ID, true); !errors.Is(err, domain.KindConflict) {
+		t.Errorf("Expected a conflict unarchiving a plan that is not archived, got %v", err)
+	}
+
+	archived, err := h.plans.ArchivePlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error archiving the plan: %v", err)
+	}
+	if !archived.IsArchived() || archived.IsActive || archived.IsPublic {
+		t.Errorf("Expected an archived, inactive and private plan, got %+v", archived)
+	}
+	public, err := h.plans.GetPublicPlans(ctx)
+	if err != nil || containsPlan(public, plan.ID) {
+		t.Errorf("Expected the archived plan hidden from public plans, got %v (%v)", public, err)
+	}
+	active, err := h.plans.GetActivePlans(ctx)
+	if err != nil || containsPlan(active, plan.ID) {
+		t.Errorf("Expected the archived plan hidden from active plans, got %v (%v)", active, err)
+	}
+	if _, err := h.plans.ArchivePlan(ctx, plan.ID); !errors.Is(err, domain.ErrPlanArchived) {
+		t.Errorf("Expected an error archiving the plan twice, got %v", err)
+	}
+
+	restored, err := h.plans.UnarchivePlan(ctx, plan.ID, true)
+	if err != nil {
+		t.Fatalf("Unexpected error unarchiving the plan: %v", err)
+	}
+	if restored.IsArchived() || !restored.IsActive || !restored.IsPublic {
+		t.Errorf("Expected the plan offered again, got %+v", restored)
+	}
+	public, err = h.plans.GetPublicPlans(ctx)
+	if err != nil || !containsPlan(public, plan.ID) {
+		t.Errorf("Expected the unarchived plan public again, got %v (%v)", public, err)
+	}
+
+	// No se vuelve a ofrecer un plan cuya moneda se archivó
+	currency, err := h.currencies.CreateCurrency(ctx, "CHF", "Franco suizo", "Fr", true)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the currency: %v", err)
+	}
+	swiss := h.newPlan(t, "Suizo", currency.ID)
+	if _, err := h.plans.ArchivePlan(ctx, swiss.ID); err != nil {
+		t.Fatalf("Unexpected error archiving the plan: %v", err)
+	}
+	if _, err := h.currencies.ArchiveCurrency(ctx, currency.ID); err != nil {
+		t.Fatalf("Unexpected error archiving the currency: %v", err)
+	}
+	if _, err := h.plans.UnarchivePlan(ctx, swiss.ID, false); !errors.Is(err, domain.KindConflict) {
+		t.Errorf("Expected a conflict unarchiving a plan in an archived currency, got %v", err)
+	}
+}
+
+func TestPlanUsageCountsSubscribers(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	plan := h.newPlan(t, "Contado", usdID)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusActive)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusActive)
+	h.subscribe(t, plan.ID, domain.SubscriptionStatusExpired)
+	unused := h.newPlan(t, "Vacío", usdID)
+
+	usage, err := h.plans.GetPlanUsage(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error getting plan usage: %v", err)
+	}
+	byPlan := make(map[string]*domain.PlanUsage, len(usage))
+	for _, planUsage := range usage {
+		byPlan[planUsage.PlanID] = planUsage
+	}
+
+	if got := byPlan[plan.ID]; got == nil || got.ActiveSubscribers != 2 || got.TotalSubscriptions != 3 || got.Name != "Contado" {
+		t.Errorf("Expected 2 active of 3 subscriptions, got %+v", got)
+	}
+	if got := byPlan[unused.ID]; got == nil || got.ActiveSubscribers != 0 || got.TotalSubscriptions != 0 {
+		t.Errorf("Expected an unused plan listed without subscribers, got %+v", got)
+	}
+	if _, ok := byPlan[proPlanID]; !ok {
+		t.Errorf("Expected every plan listed, got %d plans", len(usage))
+	}
+}
@@ -0,0 +1,168 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/internal/domain"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+)
+
+// serve monta ErrorHandler sobre una ruta GET /resource que registra err con c.Error
+func serve(t *testing.T, err error) (*httptest.ResponseRecorder, middleware.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.ErrorHandler())
+	r.GET("/resource", func(c *gin.Context) { c.Error(err) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/resource", nil))
+
+	if got := w.Header().Get("Content-Type"); got != middleware.ProblemContentType {
+		t.Errorf("Expected Content-Type %s, got %q", middleware.ProblemContentType, got)
+	}
+	var problem middleware.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Expected a JSON problem, got %q: %v", w.Body.String(), err)
+	}
+	return w, problem
+}
+
+func TestDomainErrorsMapToStatusAndCode(t *testing.T) {
+	limit := 10
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"not found", domain.NewNotFoundError("transaction", "transaction not found: %s", "tx-1"), http.StatusNotFound, "transaction_not_found"},
+		{"wrapped not found", fmt.Errorf("error al obtener plan de origen: %w", domain.NewNotFoundError("plan", "plan no encontrado")), http.StatusNotFound, "plan_not_found"},
+		{"conflict", domain.ErrPlanArchived, http.StatusConflict, "plan_archived"},
+		{"validation", domain.ErrInvalidAmount, http.StatusBadRequest, "validation_failed"},
+		{"unauthorized", domain.ErrUnauthenticated, http.StatusUnauthorized, "unauthorized"},
+		{"forbidden", domain.NewForbiddenError("access_denied", "access denied"), http.StatusForbidden, "access_denied"},
+		{"plan limit", &domain.UpgradeRequiredError{Entitlement: domain.EntitlementMaxCategories, Limit: &limit}, http.StatusForbidden, "upgrade_required"},
+		{"payment failed", fmt.Errorf("%w: tarjeta rechazada", domain.ErrPaymentFailed), http.StatusPaymentRequired, "payment_failed"},
+		{"resource in use", &domain.ResourceInUseError{Resource: "plan", ID: "p1", References: map[string]int{"user_subscriptions": 2}}, http.StatusConflict, "resource_in_use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := serve(t, tt.err)
+			if w.Code != tt.wantStatus || problem.Status != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d (body %d)", tt.wantStatus, w.Code, problem.Status)
+			}
+			if problem.Code != tt.wantCode {
+				t.Errorf("Expected code %q, got %q", tt.wantCode, problem.Code)
+			}
+			if problem.Type != middleware.ProblemTypePrefix+tt.wantCode {
+				t.Errorf("Expected type derived from the code, got %q", problem.Type)
+			}
+			if problem.Title != http.StatusText(tt.wantStatus) || problem.Detail != tt.err.Error() {
+				t.Errorf("Expected title and detail from status and error, got %q / %q", problem.Title, problem.Detail)
+			}
+			if problem.Instance != "/resource" || problem.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+				t.Errorf("Expected instance and request ID, got %q / %q", problem.Instance, problem.RequestID)
+			}
+		})
+	}
+}
+
+func TestKindsMatchWithErrorsIs(t *testing.T) {
+	err := fmt.Errorf("error al obtener suscripción: %w", domain.NewNotFoundError("subscription", "no encontrada"))
+	if !errors.Is(err, domain.KindNotFound) || errors.Is(err, domain.KindConflict) {
+		t.Error("Expected a wrapped not found error to match only KindNotFound")
+	}
+	if !errors.Is(&domain.UpgradeRequiredError{}, domain.KindPlanLimit) {
+		t.Error("Expected UpgradeRequiredError to match KindPlanLimit")
+	}
+	if !errors.Is(fmt.Errorf("%w: expirado", domain.ErrCouponNotRedeemable), domain.ErrCouponNotRedeemable) {
+		t.Error("Expected sentinels to keep matching by identity")
+	}
+}
+
+func TestPlanLimitAndResourceInUseCarryDetails(t *testing.T) {
+	limit, current := 10, 10
+	_, problem := serve(t, &domain.UpgradeRequiredError{Entitlement: domain.EntitlementMaxCategories, Limit: &limit, Current: &current, PlanID: "free"})
+
+	details, ok := problem.Details.(map[string]any)
+	if !ok || details["entitlement"] != string(domain.EntitlementMaxCategories) || details["limit"] != float64(10) || details["plan_id"] != "free" {
+		t.Errorf("Expected the plan limit in details, got %#v", problem.Details)
+	}
+}
+
+func TestValidationErrorsListFields(t *testing.T) {
+	_, problem := serve(t, domain.NewValidationErrors(
+		domain.FieldError{Field: "amount", Message: "el monto debe ser mayor que cero"},
+		domain.FieldError{Field: "date", Message: "la fecha no puede estar vacía"},
+	))
+
+	if len(problem.Errors) != 2 || problem.Errors[0].Field != "amount" || problem.Errors[1].Field != "date" {
+		t.Errorf("Expected both invalid fields, got %+v", problem.Errors)
+	}
+}
+
+func TestUntypedErrorsAreInternalWithoutDetail(t *testing.T) {
+	w, problem := serve(t, errors.New("pq: connection refused to 10.0.0.3"))
+
+	if w.Code != http.StatusInternalServerError || problem.Code != middleware.CodeInternal {
+		t.Errorf("Expected 500 %s, got %d %s", middleware.CodeInternal, w.Code, problem.Code)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.3") {
+		t.Errorf("Expected internal errors not to leak, got %s", w.Body.String())
+	}
+}
+
+func TestBindingErrorUsesJSONFieldNames(t *testing.T) {
+	type request struct {
+		CurrencyID string  `json:"currency_id" binding:"required"`
+		Amount     float64 `json:"amount" binding:"required,gt=0"`
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/resource", func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(middleware.BindingError(err))
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	for body, wantFields := range map[string][]string{
+		`{"amount": -1}`: {"currency_id", "amount"},
+		`{"amount": "x"`: nil,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/resource", strings.NewReader(body)))
+
+		var problem middleware.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected a 400 problem for %s, got %d %s", body, w.Code, w.Body.String())
+		}
+		if wantFields == nil {
+			if problem.Code != middleware.CodeInvalidRequest {
+				t.Errorf("Expected %s for malformed JSON, got %q", middleware.CodeInvalidRequest, problem.Code)
+			}
+			continue
+		}
+		var fields []string
+		for _, field := range problem.Errors {
+			fields = append(fields, field.Field)
+		}
+		if strings.Join(fields, ",") != strings.Join(wantFields, ",") {
+			t.Errorf("Expected fields %v, got %v", wantFields, fields)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+	middleware "MyMoneyBackend/internal/infraestructure/inbound/httprest/middlewares"
+	"MyMoneyBackend/internal/infraestructure/outbound/ratelimit"
+	"MyMoneyBackend/internal/infraestructure/outbound/sqlite"
+)
+
+// take consume n peticiones de key y devuelve el último resultado
+func take(t *testing.T, store app.RateLimitStore, key string, rule domain.RateLimitRule, n int) app.RateLimitResult {
+	t.Helper()
+	var result app.RateLimitResult
+	for i := 0; i < n; i++ {
+		var err error
+		if result, err = store.Take(context.Background(), key, rule); err != nil {
+			t.Fatalf("Unexpected error taking from %q: %v", key, err)
+		}
+	}
+	return result
+}
+
+// assertLimits comprueba que store permita rule.Requests peticiones por clave y rechace la siguiente
+func assertLimits(t *testing.T, store app.RateLimitStore) {
+	t.Helper()
+	rule := domain.RateLimitRule{Requests: 3, Period: time.Hour}
+
+	if result := take(t, store, "auth:ip:10.0.0.1", rule, 3); !result.Allowed || result.Remaining != 0 || result.Limit != 3 {
+		t.Errorf("Expected the third request allowed with none remaining, got %+v", result)
+	}
+	result := take(t, store, "auth:ip:10.0.0.1", rule, 1)
+	if result.Allowed || result.RetryAfter <= 0 || result.Reset <= 0 {
+		t.Errorf("Expected the fourth request rejected with a retry delay, got %+v", result)
+	}
+	if result := take(t, store, "auth:ip:10.0.0.2", rule, 1); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("Expected another key to have its own limit, got %+v", result)
+	}
+}
+
+func TestMemoryStoreLimitsAndRefills(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	assertLimits(t, store)
+
+	rule := domain.RateLimitRule{Requests: 2, Period: 100 * time.Millisecond}
+	if result := take(t, store, "refill", rule, 3); result.Allowed {
+		t.Fatalf("Expected the third request rejected, got %+v", result)
+	}
+	time.Sleep(60 * time.Millisecond) // recupera un token cada 50ms
+	if result := take(t, store, "refill", rule, 1); !result.Allowed {
+		t.Errorf("Expected a token to be refilled, got %+v", result)
+	}
+}
+
+func TestDatabaseStoreSharesLimitsBetweenInstances(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "ratelimit.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error opening SQLite: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrate.SQLite, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+
+	assertLimits(t, ratelimit.NewDatabaseStore(db))
+
+	// Dos instancias sobre la misma base cuentan juntas
+	rule := domain.RateLimitRule{Requests: 2, Period: time.Hour}
+	take(t, ratelimit.NewDatabaseStore(db), "write:user:1", rule, 2)
+	if result := take(t, ratelimit.NewDatabaseStore(db), "write:user:1", rule, 1); result.Allowed {
+		t.Errorf("Expected the limit to be shared between stores, got %+v", result)
+	}
+}
+
+func TestWritesLimitsPerUserAndSkipsReads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := middleware.NewRateLimitMiddleware(ratelimit.NewMemoryStore(), domain.RateLimitConfig{
+		Write: domain.RateLimitRule{Requests: 1, Period: time.Minute, By: "user"},
+	})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, c.GetHeader("X-User"))
+	}, limiter.Writes())
+	r.Any("/transactions", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/transactions", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "alice"); w.Code != http.StatusOK || w.Header().Get(middleware.RateLimitRemainingHeader) != "0" {
+		t.Fatalf("Expected the first write allowed with RateLimit headers, got %d %v", w.Code, w.Header())
+	}
+	w := do(http.MethodPost, "alice")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 on the second write, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get(middleware.RateLimitLimitHeader) != "1" {
+		t.Errorf("Expected Retry-After and RateLimit-Limit headers, got %v", w.Header())
+	}
+	if w := do(http.MethodPost, "bob"); w.Code != http.StatusOK {
+		t.Errorf("Expected another user to have its own limit, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "alice"); w.Code != http.StatusOK || w.Header().Get(middleware.RateLimitLimitHeader) != "" {
+		t.Errorf("Expected reads not to be limited, got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/internal/infraestructure/inbound/httprest/server"
+)
+
+func TestServeDrainsInFlightRequestsOnShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening: %v", err)
+	}
+	srv := &http.Server{Handler: handler}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var notReady atomic.Bool
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(ctx, srv, listener, 5*time.Second, func() { notReady.Store(true) })
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	// La bandera de preparación cambia antes de que termine la petición en curso
+	deadline := time.Now().Add(2 * time.Second)
+	for !notReady.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected beforeShutdown to be called while requests are in flight")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-serveErr:
+		t.Fatalf("Expected Serve to wait for the in-flight request, returned %v", err)
+	default:
+	}
+
+	close(release)
+	got := <-response
+	if got.err != nil || got.body != "done" {
+		t.Errorf("Expected the in-flight request to complete with %q, got %q (%v)", "done", got.body, got.err)
+	}
+	if err := <-serveErr; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+
+	if _, err := http.Get("http://" + listener.Addr().String()); err == nil {
+		t.Error("Expected new connections to be refused after shutdown")
+	}
+}
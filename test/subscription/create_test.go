@@ -0,0 +1,121 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+func TestCreateSubscriptionKeepsPreviousWhenActivationFails(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	limit := 1
+	coupon, err := h.coupons.CreateCoupon(ctx, &domain.CouponRequest{
+		Code: "UNICO", DiscountType: domain.CouponDiscountPercent, PercentOff: 20,
+		Duration: domain.CouponDurationOnce, MaxRedemptions: &limit, IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the coupon: %v", err)
+	}
+	previous, err := h.subscriptions.CreateSubscription(ctx, demoUserID, freePlanID, time.Now(), time.Time{}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing to the free plan: %v", err)
+	}
+
+	// La transacción que cancela la anterior y activa la nueva se revierte
+	h.uow.err = errors.New("conexión perdida")
+	_, err = h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, time.Now(), time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), coupon.Code, nil)
+	if err == nil || !errors.Is(err, h.uow.err) {
+		t.Fatalf("Expected the activation error, got %v", err)
+	}
+
+	active, err := h.subscriptions.GetActiveSubscription(ctx, demoUserID)
+	if err != nil || active == nil || active.ID != previous.ID {
+		t.Fatalf("Expected the previous subscription to stay active, got %+v (%v)", active, err)
+	}
+
+	subscriptions, err := h.subscriptions.GetUserSubscriptions(ctx, demoUserID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing subscriptions: %v", err)
+	}
+	var failed *domain.UserSubscription
+	for _, subscription := range subscriptions {
+		if subscription.ID != previous.ID {
+			failed = subscription
+		}
+	}
+	if failed == nil || failed.Status != domain.SubscriptionStatusFailed || failed.Metadata["last_payment_error"] == "" {
+		t.Fatalf("Expected the new subscription marked failed with the cause, got %+v", failed)
+	}
+	if _, ok := failed.Metadata["coupon_id"]; ok {
+		t.Errorf("Expected the coupon removed from the failed subscription, got %v", failed.Metadata)
+	}
+
+	// El cobro se devolvió con su nota de crédito
+	charge := lastInvoice(t, h, failed.ID, domain.InvoiceTypeCharge)
+	refund := lastInvoice(t, h, failed.ID, domain.InvoiceTypeRefund)
+	if charge == nil || refund == nil || refund.Total != -charge.Total || refund.ChargeID != charge.ChargeID {
+		t.Errorf("Expected the charge refunded, got charge %+v refund %+v", charge, refund)
+	}
+
+	// El canje se liberó y el cupón de un solo uso sigue disponible
+	released, err := h.coupons.GetCouponByID(ctx, coupon.ID)
+	if err != nil || released.TimesRedeemed != 0 {
+		t.Errorf("Expected the redemption released, got %+v (%v)", released, err)
+	}
+
+	h.uow.err = nil
+	created, err := h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, time.Now(), time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), coupon.Code, nil)
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed with the same coupon, got %v", err)
+	}
+	cancelled, err := h.subscriptions.GetSubscriptionByID(ctx, previous.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the previous subscription: %v", err)
+	}
+	if cancelled.Status != domain.SubscriptionStatusCancelled || created.Status != domain.SubscriptionStatusActive {
+		t.Errorf("Expected the previous subscription cancelled and the new one active, got %s / %s", cancelled.Status, created.Status)
+	}
+}
+
+func TestCancellationsRecordReasonAndMetric(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	previous, err := h.subscriptions.CreateSubscription(ctx, demoUserID, freePlanID, time.Now(), time.Time{}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing to the free plan: %v", err)
+	}
+
+	// Suscribirse a otro plan cancela la anterior con el mismo motivo y métrica que una cancelación
+	created := h.subscribe(t, demoUserID, proPlanID, time.Now())
+	replaced, err := h.subscriptions.GetSubscriptionByID(ctx, previous.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the previous subscription: %v", err)
+	}
+	if replaced.Status != domain.SubscriptionStatusCancelled || replaced.CancellationDate == nil ||
+		replaced.Metadata["cancellation_reason"] != "replaced" || replaced.Metadata["replaced_by"] != created.ID {
+		t.Errorf("Expected the previous subscription cancelled as replaced, got %s %v", replaced.Status, replaced.Metadata)
+	}
+	if h.metrics.created != 2 || h.metrics.cancelled != 1 {
+		t.Errorf("Expected two subscriptions created and one cancelled, got %+v", h.metrics)
+	}
+
+	if err := h.subscriptions.CancelSubscription(ctx, created.ID, "demasiado caro"); err != nil {
+		t.Fatalf("Unexpected error cancelling the subscription: %v", err)
+	}
+	cancelled, err := h.subscriptions.GetSubscriptionByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if cancelled.Status != domain.SubscriptionStatusCancelled || cancelled.Metadata["cancellation_reason"] != "demasiado caro" ||
+		cancelled.Metadata["billing_anchor_day"] == "" {
+		t.Errorf("Expected the reason stored with the existing metadata, got %s %v", cancelled.Status, cancelled.Metadata)
+	}
+	if h.metrics.cancelled != 2 {
+		t.Errorf("Expected two cancellations counted, got %+v", h.metrics)
+	}
+}
@@ -0,0 +1,139 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	userService "MyMoneyBackend/internal/application/user"
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+)
+
+func TestInvoicesAreNumberedPerUser(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Now())
+	if _, err := h.subscriptions.RenewSubscription(ctx, subscription.ID, subscription.EndDate.AddDate(0, 1, 0)); err != nil {
+		t.Fatalf("Unexpected error renewing: %v", err)
+	}
+	other := h.subscribe(t, otherUserID, proPlanID, time.Now())
+
+	invoices, err := h.invoices.GetSubscriptionInvoices(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing invoices: %v", err)
+	}
+	if len(invoices) != 2 {
+		t.Fatalf("Expected 2 invoices, got %d", len(invoices))
+	}
+	// Las facturas se listan de la más reciente a la más antigua
+	for i, want := range []int64{2, 1} {
+		if invoices[i].Sequence != want || invoices[i].Number != domain.FormatInvoiceNumber(want) {
+			t.Errorf("Expected invoice %d to be %s, got %s (sequence %d)", i, domain.FormatInvoiceNumber(want), invoices[i].Number, invoices[i].Sequence)
+		}
+	}
+
+	// Cada usuario tiene su propia numeración
+	if invoice := lastInvoice(t, h, other.ID, domain.InvoiceTypeCharge); invoice == nil || invoice.Sequence != 1 {
+		t.Errorf("Expected the other user's first invoice numbered 1, got %+v", invoice)
+	}
+}
+
+func TestInvoicesAreImmutable(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Now())
+	invoice := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge)
+	if invoice == nil {
+		t.Fatal("Expected the first charge invoiced")
+	}
+
+	if _, err := h.db.ExecContext(ctx, `UPDATE invoices SET total = 0 WHERE id = $1`, invoice.ID); err == nil {
+		t.Error("Expected updating an invoice to fail")
+	}
+	if _, err := h.db.ExecContext(ctx, `DELETE FROM invoices WHERE id = $1`, invoice.ID); err == nil {
+		t.Error("Expected deleting an invoice to fail")
+	}
+	// El historial de facturación impide borrar al usuario y, en cascada, sus suscripciones,
+	// también saltándose el servicio
+	if err := repository.NewUserRepository(h.db).Delete(ctx, demoUserID); err == nil {
+		t.Error("Expected deleting a user with invoices to fail")
+	}
+
+	stored, err := h.invoices.GetInvoiceByID(ctx, invoice.ID)
+	if err != nil || stored == nil || stored.Total != invoice.Total {
+		t.Errorf("Expected the invoice unchanged, got %+v (%v)", stored, err)
+	}
+}
+
+func TestDeletingAUserWithInvoicesIsRejected(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	users := userService.NewUserService(repository.NewUserRepository(h.db), nil, nil)
+	h.subscribe(t, demoUserID, proPlanID, time.Now())
+
+	err := users.DeleteUser(ctx, demoUserID)
+	var inUse *domain.ResourceInUseError
+	if !errors.As(err, &inUse) || inUse.References["invoices"] != 1 || inUse.Archivable {
+		t.Fatalf("Expected a resource in use error with one invoice, got %v", err)
+	}
+	if _, err := users.GetUserByID(ctx, demoUserID); err != nil {
+		t.Errorf("Expected the user kept, got %v", err)
+	}
+
+	// Un usuario sin facturas se puede eliminar
+	if err := users.DeleteUser(ctx, otherUserID); err != nil {
+		t.Errorf("Expected a user without invoices deleted, got %v", err)
+	}
+}
+
+func TestLifecycleIssuesInvoicesThatFailedToSave(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	h.invoiceRepo.err = errors.New("base de datos no disponible")
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Now())
+	chargedAt := time.Now()
+	if invoice := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge); invoice != nil {
+		t.Fatalf("Expected no invoice saved while the repository fails, got %+v", invoice)
+	}
+
+	// Mientras la base de datos siga fallando la factura sigue pendiente
+	result, err := h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, time.Now())
+	if err == nil || result.Invoiced != 0 {
+		t.Errorf("Expected the pending invoice to fail again, got %+v (%v)", result, err)
+	}
+	pending, err := h.invoiceRepo.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error listing pending invoices: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("Expected one pending invoice with the failed attempt recorded, got %+v", pending)
+	}
+
+	h.invoiceRepo.err = nil
+	result, err = h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, time.Now())
+	if err != nil || result.Invoiced != 1 {
+		t.Fatalf("Expected the pending invoice issued, got %+v (%v)", result, err)
+	}
+
+	invoice := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge)
+	if invoice == nil || invoice.ID != pending[0].Invoice.ID || invoice.Number != domain.FormatInvoiceNumber(1) || !near(invoice.Total, 19.99) {
+		t.Fatalf("Expected the charge invoiced as INV-000001 for 19.99, got %+v", invoice)
+	}
+	if invoice.IssuedAt.After(chargedAt) {
+		t.Errorf("Expected the invoice dated at the charge (before %s), got %s", chargedAt, invoice.IssuedAt)
+	}
+
+	// Una vez emitida no se vuelve a emitir
+	result, err = h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, time.Now())
+	if err != nil || result.Invoiced != 0 {
+		t.Errorf("Expected nothing left to invoice, got %+v (%v)", result, err)
+	}
+	if invoices, err := h.invoices.GetSubscriptionInvoices(ctx, subscription.ID); err != nil || len(invoices) != 1 {
+		t.Errorf("Expected a single invoice, got %d (%v)", len(invoices), err)
+	}
+}
@@ -0,0 +1,277 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+// stubLock concede o niega el liderazgo y cancela el planificador al empezar la segunda pasada
+type stubLock struct {
+	leader   bool
+	cancel   context.CancelFunc
+	acquired int
+	released bool
+}
+
+func (l *stubLock) TryAcquire(context.Context) (bool, error) {
+	l.acquired++
+	if l.acquired > 1 {
+		l.cancel()
+		return false, nil
+	}
+	return l.leader, nil
+}
+
+func (l *stubLock) Release(context.Context) error {
+	l.released = true
+	return nil
+}
+
+func TestLifecycleRenewsDueSubscriptions(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+	now := subscription.EndDate.Add(time.Hour)
+
+	result, err := h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, now)
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.Renewed != 1 || result.Failed != 0 || result.Expired != 0 {
+		t.Errorf("Expected the subscription renewed before expiring, got %+v", result)
+	}
+	renewed, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if want := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC); renewed.Status != domain.SubscriptionStatusActive || !renewed.EndDate.Equal(want) {
+		t.Errorf("Expected an active subscription until %s, got %s until %s", want, renewed.Status, renewed.EndDate)
+	}
+	if renewed.LastPaymentDate == nil {
+		t.Error("Expected the renewal payment date recorded")
+	}
+}
+
+// renewalDue suscribe al usuario al plan Pro desde una fecha fija y cambia su tarjeta por una
+// sin fondos; devuelve la suscripción y el instante en que vence su renovación
+func renewalDue(t *testing.T, h *harness) (*domain.UserSubscription, time.Time) {
+	t.Helper()
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+	if _, err := h.subscriptions.UpdatePaymentMethod(context.Background(), subscription.ID, *h.card(t, demoUserID, payment.TestCardInsufficientFunds)); err != nil {
+		t.Fatalf("Unexpected error changing the card: %v", err)
+	}
+	return subscription, subscription.EndDate.Add(time.Hour)
+}
+
+func TestLifecycleRetriesFailedRenewalUntilRecovered(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	schedule := userSubscriptionService.DunningSchedule{24 * time.Hour, 72 * time.Hour}
+	subscription, now := renewalDue(t, h)
+
+	result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now)
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.Renewed != 0 || result.Failed != 1 || result.Expired != 0 {
+		t.Errorf("Expected the renewal to fail without expiring, got %+v", result)
+	}
+	failed, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if failed.Status != domain.SubscriptionStatusFailed || failed.Metadata["payment_attempts"] != "1" || failed.Metadata["last_payment_error"] == "" {
+		t.Errorf("Expected a failed subscription with one attempt recorded, got %s %v", failed.Status, failed.Metadata)
+	}
+	if failed.NextPaymentAttempt == nil || !failed.NextPaymentAttempt.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("Expected the next attempt after the first delay, got %v", failed.NextPaymentAttempt)
+	}
+	if invoice := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge); invoice == nil || invoice.Status != domain.InvoiceStatusFailed {
+		t.Errorf("Expected the declined charge invoiced as failed, got %+v", invoice)
+	}
+	if len(h.published.events) != 1 || h.published.events[0] != domain.NotificationEventPaymentFailed {
+		t.Errorf("Expected the user notified of the failed payment, got %v", h.published.events)
+	}
+
+	// Antes del siguiente intento no se vuelve a cobrar
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now.Add(time.Hour)); err != nil || result != (userSubscriptionService.LifecycleResult{}) {
+		t.Errorf("Expected nothing to do before the next attempt, got %+v (%v)", result, err)
+	}
+
+	// El usuario cambia la tarjeta y el reintento recupera la suscripción
+	failed.PaymentMethodID = h.card(t, demoUserID, payment.TestCardVisa)
+	if err := h.subRepo.Update(ctx, failed); err != nil {
+		t.Fatalf("Unexpected error changing the card: %v", err)
+	}
+	result, err = h.subscriptions.RunLifecycle(ctx, schedule, 0, now.Add(25*time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.Recovered != 1 || result.Failed != 0 {
+		t.Errorf("Expected the retry to recover the subscription, got %+v", result)
+	}
+	recovered, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if recovered.Status != domain.SubscriptionStatusActive || recovered.NextPaymentAttempt != nil {
+		t.Errorf("Expected an active subscription without pending attempts, got %s %v", recovered.Status, recovered.NextPaymentAttempt)
+	}
+	if _, ok := recovered.Metadata["payment_attempts"]; ok {
+		t.Errorf("Expected the attempts cleared, got %v", recovered.Metadata)
+	}
+	if want := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC); !recovered.EndDate.Equal(want) {
+		t.Errorf("Expected the period extended to %s, got %s", want, recovered.EndDate)
+	}
+}
+
+func TestLifecycleExpiresSubscriptionAfterLastRetry(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	schedule := userSubscriptionService.DunningSchedule{24 * time.Hour}
+	subscription, now := renewalDue(t, h)
+
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now); err != nil || result.Failed != 1 {
+		t.Fatalf("Expected the renewal to fail, got %+v (%v)", result, err)
+	}
+
+	result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now.Add(25*time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.Failed != 1 || result.Exhausted != 1 || result.Recovered != 0 {
+		t.Errorf("Expected the last retry to exhaust the schedule, got %+v", result)
+	}
+	expired, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if expired.Status != domain.SubscriptionStatusExpired || expired.NextPaymentAttempt != nil || expired.Metadata["payment_attempts"] != "2" {
+		t.Errorf("Expected an expired subscription after two attempts, got %s %v %v", expired.Status, expired.NextPaymentAttempt, expired.Metadata)
+	}
+	// Solo se avisa del fallo con reintento pendiente, no al expirar
+	if len(h.published.events) != 1 {
+		t.Errorf("Expected a single payment failed notification, got %v", h.published.events)
+	}
+
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now.Add(30*24*time.Hour)); err != nil || result != (userSubscriptionService.LifecycleResult{}) {
+		t.Errorf("Expected an expired subscription to be left alone, got %+v (%v)", result, err)
+	}
+}
+
+func TestLifecycleKeepsTheBillingDayAcrossShortMonths(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC))
+
+	for _, want := range []time.Time{
+		time.Date(2024, time.March, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, time.May, 31, 10, 0, 0, 0, time.UTC),
+	} {
+		result, err := h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, subscription.EndDate.Add(time.Hour))
+		if err != nil || result.Renewed != 1 {
+			t.Fatalf("Expected the subscription renewed, got %+v (%v)", result, err)
+		}
+		renewed, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error getting the subscription: %v", err)
+		}
+		if !renewed.EndDate.Equal(want) {
+			t.Fatalf("Expected the period after %s to end %s, got %s", subscription.EndDate, want, renewed.EndDate)
+		}
+		subscription = renewed
+	}
+}
+
+func TestLifecycleExpiresSubscriptionsPastTheirEndDate(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+
+	// Sin fecha de renovación la suscripción no se renueva y expira al terminar el período
+	subscription.RenewalDate = nil
+	if err := h.subRepo.Update(ctx, subscription); err != nil {
+		t.Fatalf("Unexpected error updating the subscription: %v", err)
+	}
+
+	result, err := h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, subscription.EndDate.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.Expired != 1 || result.Renewed != 0 {
+		t.Errorf("Expected the subscription expired, got %+v", result)
+	}
+	expired, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil || expired.Status != domain.SubscriptionStatusExpired {
+		t.Errorf("Expected an expired subscription, got %+v (%v)", expired, err)
+	}
+}
+
+func TestSchedulerRunsTheLifecycleOnlyAsLeader(t *testing.T) {
+	for _, leader := range []bool{false, true} {
+		h := newHarness(t)
+		subscription := h.subscribe(t, demoUserID, proPlanID, time.Now().AddDate(0, -1, -1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		lock := &stubLock{leader: leader, cancel: cancel}
+		config := userSubscriptionService.SchedulerConfig{Interval: time.Millisecond}
+		userSubscriptionService.NewScheduler(h.subscriptions, lock, config).Run(ctx)
+
+		if lock.acquired != 2 || !lock.released {
+			t.Errorf("Expected the lock tried on every pass and released on shutdown, got %+v", lock)
+		}
+		current, err := h.subscriptions.GetSubscriptionByID(context.Background(), subscription.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error getting the subscription: %v", err)
+		}
+		if renewed := current.EndDate.After(subscription.EndDate); renewed != leader {
+			t.Errorf("Expected renewed=%t for leader=%t, got end date %s", leader, leader, current.EndDate)
+		}
+	}
+}
+
+func TestLifecycleNotifiesExpiringSubscriptionsOncePerPeriod(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	schedule := userSubscriptionService.DefaultDunningSchedule
+	window := 72 * time.Hour
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC))
+	now := subscription.EndDate.Add(-48 * time.Hour)
+
+	// Sin antelación configurada no se avisa
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, 0, now); err != nil || result.ExpiryNotices != 0 {
+		t.Errorf("Expected no expiry notice without a window, got %+v (%v)", result, err)
+	}
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, window, now.Add(-48*time.Hour)); err != nil || result.ExpiryNotices != 0 {
+		t.Errorf("Expected no expiry notice before the window, got %+v (%v)", result, err)
+	}
+
+	result, err := h.subscriptions.RunLifecycle(ctx, schedule, window, now)
+	if err != nil || result.ExpiryNotices != 1 {
+		t.Fatalf("Expected one expiry notice within the window, got %+v (%v)", result, err)
+	}
+	if len(h.published.events) != 1 || h.published.events[0] != domain.NotificationEventSubscriptionExpiring {
+		t.Errorf("Expected the user notified of the expiring subscription, got %v", h.published.events)
+	}
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, window, now.Add(time.Hour)); err != nil || result.ExpiryNotices != 0 {
+		t.Errorf("Expected the notice not repeated for the same end date, got %+v (%v)", result, err)
+	}
+
+	// Tras renovarse se avisa de nuevo antes del fin del nuevo período
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, window, subscription.EndDate.Add(time.Hour)); err != nil || result.Renewed != 1 {
+		t.Fatalf("Expected the subscription renewed, got %+v (%v)", result, err)
+	}
+	renewed, err := h.subscriptions.GetSubscriptionByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if result, err := h.subscriptions.RunLifecycle(ctx, schedule, window, renewed.EndDate.Add(-48*time.Hour)); err != nil || result.ExpiryNotices != 1 {
+		t.Errorf("Expected a new notice for the renewed period, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,133 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+func TestCouponRestrictedToPlanAppliesToNewVersions(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	coupon, err := h.coupons.CreateCoupon(ctx, &domain.CouponRequest{
+		Code: "pro20", DiscountType: domain.CouponDiscountPercent, PercentOff: 20,
+		Duration: domain.CouponDurationOnce, PlanIDs: []string{proPlanID}, IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the coupon: %v", err)
+	}
+
+	v2 := h.reprice(t, proPlanID, 24.99)
+	if _, err := h.coupons.ValidateCoupon(ctx, coupon.Code, v2.ID); err != nil {
+		t.Errorf("Expected the coupon to apply to the new version of the plan, got %v", err)
+	}
+	if _, err := h.coupons.ValidateCoupon(ctx, coupon.Code, yearlyPlanID); !errors.Is(err, domain.ErrCouponNotRedeemable) {
+		t.Errorf("Expected the coupon not to apply to other plans, got %v", err)
+	}
+
+	// Un cupón creado sobre una versión se guarda con la familia del plan
+	later, err := h.coupons.CreateCoupon(ctx, &domain.CouponRequest{
+		Code: "pro10", DiscountType: domain.CouponDiscountPercent, PercentOff: 10,
+		Duration: domain.CouponDurationOnce, PlanIDs: []string{v2.ID}, IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the coupon: %v", err)
+	}
+	if len(later.PlanIDs) != 1 || later.PlanIDs[0] != proPlanID {
+		t.Errorf("Expected the coupon stored with the plan family, got %v", later.PlanIDs)
+	}
+	v3 := h.reprice(t, v2.ID, 29.99)
+	if _, err := h.coupons.ValidateCoupon(ctx, later.Code, v3.ID); err != nil {
+		t.Errorf("Expected the coupon to apply after another edit, got %v", err)
+	}
+}
+
+func TestCreateSubscriptionRejectsSupersededAndArchivedPlans(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	v2 := h.reprice(t, proPlanID, 24.99)
+	_, err := h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, now, time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "", nil)
+	if !hasCode(err, "plan_superseded") {
+		t.Errorf("Expected plan_superseded subscribing to an old version, got %v", err)
+	}
+
+	if _, err := h.plans.ArchivePlan(ctx, v2.ID); err != nil {
+		t.Fatalf("Unexpected error archiving the plan: %v", err)
+	}
+	if _, err := h.subscriptions.CreateSubscription(ctx, demoUserID, v2.ID, now, time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "", nil); !errors.Is(err, domain.ErrPlanArchived) {
+		t.Errorf("Expected ErrPlanArchived subscribing to an archived plan, got %v", err)
+	}
+}
+
+func TestMigratePlanCohortSchedulesSubscribersOfOldVersion(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	migrated := h.subscribe(t, demoUserID, proPlanID, now)
+	downgrading := h.subscribe(t, otherUserID, proPlanID, now)
+	if _, err := h.subscriptions.ChangeSubscriptionPlan(ctx, downgrading.ID, freePlanID, "", ""); err != nil {
+		t.Fatalf("Unexpected error scheduling the downgrade: %v", err)
+	}
+
+	// Sin versión nueva el plan de origen ya es el vigente
+	if _, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, "", 30, now); !hasCode(err, "plan_already_current") {
+		t.Errorf("Expected plan_already_current before a new version exists, got %v", err)
+	}
+
+	v2 := h.reprice(t, proPlanID, 24.99)
+
+	if _, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, "", -1, now); !errors.Is(err, domain.KindValidation) {
+		t.Errorf("Expected a validation error for negative notice days, got %v", err)
+	}
+	if _, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, yearlyPlanID, 30, now); !errors.Is(err, domain.KindValidation) {
+		t.Errorf("Expected a validation error migrating to another plan family, got %v", err)
+	}
+	if _, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, proPlanID, 30, now); !hasCode(err, "plan_superseded") {
+		t.Errorf("Expected plan_superseded migrating to an old version, got %v", err)
+	}
+
+	result, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, "", 30, now)
+	if err != nil {
+		t.Fatalf("Unexpected error migrating the cohort: %v", err)
+	}
+	if result.ToPlanID != v2.ID || result.Scheduled != 1 || result.Skipped != 1 {
+		t.Errorf("Expected one subscription scheduled to %s and the pending downgrade skipped, got %+v", v2.ID, result)
+	}
+
+	subscription, err := h.subscriptions.GetSubscriptionByID(ctx, migrated.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription: %v", err)
+	}
+	if subscription.PlanID != proPlanID || subscription.Metadata["scheduled_plan_id"] != v2.ID {
+		t.Errorf("Expected the old price kept with the new version scheduled, got plan %s metadata %v", subscription.PlanID, subscription.Metadata)
+	}
+	if subscription.Metadata["scheduled_plan_effective_at"] != now.AddDate(0, 0, 30).Format(time.RFC3339) {
+		t.Errorf("Expected the migration effective after the notice, got %q", subscription.Metadata["scheduled_plan_effective_at"])
+	}
+
+	downgraded, err := h.subscriptions.GetSubscriptionByID(ctx, downgrading.ID)
+	if err != nil || downgraded.Metadata["scheduled_plan_id"] != freePlanID {
+		t.Errorf("Expected the pending downgrade kept, got %v (%v)", downgraded.Metadata, err)
+	}
+
+	if _, err := h.plans.ArchivePlan(ctx, v2.ID); err != nil {
+		t.Fatalf("Unexpected error archiving the plan: %v", err)
+	}
+	if _, err := h.subscriptions.MigratePlanCohort(ctx, proPlanID, "", 30, now); !errors.Is(err, domain.ErrPlanArchived) {
+		t.Errorf("Expected ErrPlanArchived migrating to an archived plan, got %v", err)
+	}
+}
+
+// hasCode indica si err es un error de dominio con el código indicado
+func hasCode(err error, code string) bool {
+	var domainErr *domain.Error
+	return errors.As(err, &domainErr) && domainErr.Code == code
+}
@@ -0,0 +1,168 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+func TestRepeatingCouponDiscountsItsPeriods(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	if _, err := h.coupons.CreateCoupon(ctx, &domain.CouponRequest{
+		Code: "MITAD", DiscountType: domain.CouponDiscountPercent, PercentOff: 50,
+		Duration: domain.CouponDurationRepeating, DurationPeriods: 2, IsActive: true,
+	}); err != nil {
+		t.Fatalf("Unexpected error creating the coupon: %v", err)
+	}
+
+	start := time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC)
+	subscription, err := h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, start, time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "mitad", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing with the coupon: %v", err)
+	}
+	if subscription.Metadata["coupon_periods_remaining"] != "1" {
+		t.Errorf("Expected one discounted period left after the first charge, got %v", subscription.Metadata)
+	}
+
+	// Cada cobro consume un período; al agotarse el cupón se retira
+	wantTotals := []float64{10, 10, 19.99}
+	for i, want := range wantTotals {
+		if i > 0 {
+			renewed, err := h.subscriptions.RenewSubscription(ctx, subscription.ID, domain.PeriodEnd(&domain.Plan{Interval: domain.PlanIntervalMonth, IntervalCount: 1}, subscription.EndDate))
+			if err != nil {
+				t.Fatalf("Unexpected error renewing: %v", err)
+			}
+			subscription = renewed
+		}
+		if charge := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge); charge == nil || !near(charge.Total, want) {
+			t.Errorf("Expected charge %d to total %.2f, got %+v", i+1, want, charge)
+		}
+	}
+	if _, ok := subscription.Metadata["coupon_id"]; ok {
+		t.Errorf("Expected the coupon removed once its periods are used, got %v", subscription.Metadata)
+	}
+}
+
+func TestCouponRedemptionRules(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	for _, request := range []*domain.CouponRequest{
+		{Code: "ANUAL", DiscountType: domain.CouponDiscountPercent, PercentOff: 10, Duration: domain.CouponDurationOnce, PlanIDs: []string{yearlyPlanID}, IsActive: true},
+		{Code: "BIENVENIDA", DiscountType: domain.CouponDiscountPercent, PercentOff: 10, Duration: domain.CouponDurationOnce, IsActive: true},
+	} {
+		if _, err := h.coupons.CreateCoupon(ctx, request); err != nil {
+			t.Fatalf("Unexpected error creating coupon %s: %v", request.Code, err)
+		}
+	}
+
+	// Un cupón de otro plan no se canjea y no deja una suscripción a medias
+	_, err := h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, time.Now(), time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "ANUAL", nil)
+	if !errors.Is(err, domain.ErrCouponNotRedeemable) {
+		t.Errorf("Expected ErrCouponNotRedeemable for a coupon of another plan, got %v", err)
+	}
+	if subscriptions, err := h.subscriptions.GetUserSubscriptions(ctx, demoUserID); err != nil || len(subscriptions) != 0 {
+		t.Errorf("Expected no subscription left after a rejected coupon, got %d (%v)", len(subscriptions), err)
+	}
+
+	first, err := h.subscriptions.CreateSubscription(ctx, demoUserID, proPlanID, time.Now(), time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "BIENVENIDA", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error redeeming the coupon: %v", err)
+	}
+
+	// Cada usuario canjea un cupón una sola vez
+	_, err = h.subscriptions.CreateSubscription(ctx, demoUserID, yearlyPlanID, time.Now(), time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "BIENVENIDA", nil)
+	if !errors.Is(err, domain.ErrCouponNotRedeemable) {
+		t.Errorf("Expected ErrCouponNotRedeemable redeeming the coupon twice, got %v", err)
+	}
+	if active, err := h.subscriptions.GetActiveSubscription(ctx, demoUserID); err != nil || active == nil || active.ID != first.ID {
+		t.Errorf("Expected the first subscription to stay active, got %+v (%v)", active, err)
+	}
+
+	// Otro usuario sí puede canjearlo
+	if _, err := h.subscriptions.CreateSubscription(ctx, otherUserID, proPlanID, time.Now(), time.Time{}, h.card(t, otherUserID, payment.TestCardVisa), "BIENVENIDA", nil); err != nil {
+		t.Errorf("Expected another user to redeem the coupon, got %v", err)
+	}
+}
+
+func TestTrialIsGrantedOncePerUser(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	trialPlan := h.newPlan(t, "Prueba", 19.99, domain.PlanIntervalMonth, 14)
+	otherTrialPlan := h.newPlan(t, "Prueba Plus", 29.99, domain.PlanIntervalMonth, 7)
+	start := time.Now()
+
+	trial, err := h.subscriptions.CreateSubscription(ctx, demoUserID, trialPlan.ID, start, time.Time{}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error starting the trial: %v", err)
+	}
+	if trial.TrialEndDate == nil || !trial.EndDate.Equal(start.AddDate(0, 0, 14)) || trial.Status != domain.SubscriptionStatusActive {
+		t.Errorf("Expected an active 14 day trial, got %s until %s (trial end %v)", trial.Status, trial.EndDate, trial.TrialEndDate)
+	}
+	if invoice := lastInvoice(t, h, trial.ID, domain.InvoiceTypeCharge); invoice != nil {
+		t.Errorf("Expected nothing charged during the trial, got %+v", invoice)
+	}
+	if err := h.subscriptions.CancelSubscription(ctx, trial.ID, "probando"); err != nil {
+		t.Fatalf("Unexpected error cancelling the trial: %v", err)
+	}
+
+	// La segunda prueba, aunque sea de otro plan, se cobra desde el inicio
+	if _, err := h.subscriptions.CreateSubscription(ctx, demoUserID, otherTrialPlan.ID, start, time.Time{}, nil, "", nil); !errors.Is(err, domain.KindValidation) {
+		t.Errorf("Expected a payment method required without a second trial, got %v", err)
+	}
+	paid, err := h.subscriptions.CreateSubscription(ctx, demoUserID, otherTrialPlan.ID, start, time.Time{}, h.card(t, demoUserID, payment.TestCardVisa), "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing: %v", err)
+	}
+	if paid.TrialEndDate != nil {
+		t.Errorf("Expected no second trial, got trial until %s", paid.TrialEndDate)
+	}
+	if invoice := lastInvoice(t, h, paid.ID, domain.InvoiceTypeCharge); invoice == nil || !near(invoice.Total, 29.99) {
+		t.Errorf("Expected the first period charged, got %+v", invoice)
+	}
+
+	// Los planes sin prueba no la ofrecen a usuarios nuevos
+	noTrial := h.subscribe(t, otherUserID, proPlanID, start)
+	if noTrial.TrialEndDate != nil {
+		t.Errorf("Expected no trial on a plan without trial days, got %s", noTrial.TrialEndDate)
+	}
+}
+
+func TestLifecycleEndsTrials(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	trialPlan := h.newPlan(t, "Prueba", 19.99, domain.PlanIntervalMonth, 14)
+	start := time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC)
+
+	unpaid, err := h.subscriptions.CreateSubscription(ctx, demoUserID, trialPlan.ID, start, time.Time{}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error starting the trial: %v", err)
+	}
+	paid, err := h.subscriptions.CreateSubscription(ctx, otherUserID, trialPlan.ID, start, time.Time{}, h.card(t, otherUserID, payment.TestCardVisa), "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error starting the trial: %v", err)
+	}
+
+	// Al terminar la prueba solo continúa, y se cobra, la que tiene método de pago
+	result, err := h.subscriptions.RunLifecycle(ctx, userSubscriptionService.DefaultDunningSchedule, 0, unpaid.EndDate.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error running the lifecycle: %v", err)
+	}
+	if result.TrialsExpired != 1 || result.Renewed != 1 {
+		t.Errorf("Expected one trial expired and one converted, got %+v", result)
+	}
+	if expired, err := h.subscriptions.GetSubscriptionByID(ctx, unpaid.ID); err != nil || expired.Status != domain.SubscriptionStatusExpired {
+		t.Errorf("Expected the trial without payment method expired, got %+v (%v)", expired, err)
+	}
+	if len(h.published.events) == 0 || h.published.events[0] != domain.NotificationEventTrialEnded {
+		t.Errorf("Expected the user notified of the trial end, got %v", h.published.events)
+	}
+	if charge := lastInvoice(t, h, paid.ID, domain.InvoiceTypeCharge); charge == nil || !near(charge.Total, 19.99) {
+		t.Errorf("Expected the converted trial charged, got %+v", charge)
+	}
+}
@@ -0,0 +1,228 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+)
+
+// near compara importes calculados con instantes ligeramente distintos
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 0.02
+}
+
+func TestPreviewPlanChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       func(h *harness) string
+		to         func(h *harness) string
+		percentOff float64
+		wantType   domain.PlanChangeType
+		wantCredit func(fraction float64) float64
+		wantCost   func(fraction float64) float64
+		wantNow    bool
+	}{
+		{
+			name:       "upgrade keeps the period",
+			from:       func(*harness) string { return proPlanID },
+			to:         func(h *harness) string { return h.newPlan(t, "Premium", 39.99, domain.PlanIntervalMonth, 0).ID },
+			wantType:   domain.PlanChangeUpgrade,
+			wantCredit: func(f float64) float64 { return 19.99 * f },
+			wantCost:   func(f float64) float64 { return 39.99 * f },
+			wantNow:    true,
+		},
+		{
+			name:       "another interval starts a new period",
+			from:       func(*harness) string { return proPlanID },
+			to:         func(*harness) string { return yearlyPlanID },
+			wantType:   domain.PlanChangeUpgrade,
+			wantCredit: func(f float64) float64 { return 19.99 * f },
+			wantCost:   func(float64) float64 { return 199.90 },
+			wantNow:    true,
+		},
+		{
+			name:       "downgrade waits for the period end",
+			from:       func(*harness) string { return proPlanID },
+			to:         func(*harness) string { return freePlanID },
+			wantType:   domain.PlanChangeDowngrade,
+			wantCredit: func(f float64) float64 { return 19.99 * f },
+			wantCost:   func(float64) float64 { return 0 },
+		},
+		{
+			name:       "credit uses the discounted price paid",
+			from:       func(*harness) string { return proPlanID },
+			to:         func(h *harness) string { return h.newPlan(t, "Premium", 39.99, domain.PlanIntervalMonth, 0).ID },
+			percentOff: 50,
+			wantType:   domain.PlanChangeUpgrade,
+			wantCredit: func(f float64) float64 { return 19.99 / 2 * f },
+			wantCost:   func(f float64) float64 { return 39.99 * f },
+			wantNow:    true,
+		},
+		{
+			name:       "trial has nothing to credit",
+			from:       func(h *harness) string { return h.newPlan(t, "Prueba", 19.99, domain.PlanIntervalMonth, 14).ID },
+			to:         func(h *harness) string { return h.newPlan(t, "Premium", 39.99, domain.PlanIntervalMonth, 0).ID },
+			wantType:   domain.PlanChangeUpgrade,
+			wantCredit: func(float64) float64 { return 0 },
+			wantCost:   func(float64) float64 { return 0 },
+			wantNow:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			ctx := context.Background()
+
+			code := ""
+			if tt.percentOff > 0 {
+				code = "DESCUENTO"
+				_, err := h.coupons.CreateCoupon(ctx, &domain.CouponRequest{
+					Code: code, DiscountType: domain.CouponDiscountPercent, PercentOff: tt.percentOff,
+					Duration: domain.CouponDurationForever, IsActive: true,
+				})
+				if err != nil {
+					t.Fatalf("Unexpected error creating the coupon: %v", err)
+				}
+			}
+			subscription, err := h.subscriptions.CreateSubscription(
+				ctx, demoUserID, tt.from(h), time.Now().AddDate(0, 0, -10), time.Time{},
+				h.card(t, demoUserID, payment.TestCardVisa), code, nil,
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error subscribing: %v", err)
+			}
+			toPlanID := tt.to(h)
+
+			preview, err := h.subscriptions.PreviewPlanChange(ctx, subscription.ID, toPlanID, "")
+			if err != nil {
+				t.Fatalf("Unexpected error previewing the change: %v", err)
+			}
+			now := time.Now()
+			fraction := domain.UnusedFraction(subscription.StartDate, subscription.EndDate, now)
+
+			if preview.ChangeType != tt.wantType {
+				t.Errorf("Expected a %s, got %s", tt.wantType, preview.ChangeType)
+			}
+			if !near(preview.Credit, tt.wantCredit(fraction)) {
+				t.Errorf("Expected credit %.2f, got %.2f", tt.wantCredit(fraction), preview.Credit)
+			}
+			if !near(preview.NewPlanCost, tt.wantCost(fraction)) {
+				t.Errorf("Expected new plan cost %.2f, got %.2f", tt.wantCost(fraction), preview.NewPlanCost)
+			}
+			if tt.wantType == domain.PlanChangeUpgrade && !near(preview.AmountDue, math.Max(preview.NewPlanCost-preview.Credit, 0)) {
+				t.Errorf("Expected the cost minus the credit due, got %+v", preview)
+			}
+			if tt.wantNow && now.Sub(preview.EffectiveAt) > time.Minute {
+				t.Errorf("Expected the change effective now, got %s", preview.EffectiveAt)
+			}
+			if !tt.wantNow && !preview.EffectiveAt.Equal(subscription.EndDate) {
+				t.Errorf("Expected the change effective at the period end %s, got %s", subscription.EndDate, preview.EffectiveAt)
+			}
+		})
+	}
+}
+
+func TestChangeSubscriptionPlanChargesUpgradesAndSchedulesDowngrades(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	premium := h.newPlan(t, "Premium", 39.99, domain.PlanIntervalMonth, 0)
+	business := h.newPlan(t, "Business", 59.99, domain.PlanIntervalMonth, 0)
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Now().AddDate(0, 0, -10))
+
+	preview, err := h.subscriptions.PreviewPlanChange(ctx, subscription.ID, premium.ID, "")
+	if err != nil {
+		t.Fatalf("Unexpected error previewing the upgrade: %v", err)
+	}
+	upgraded, err := h.subscriptions.ChangeSubscriptionPlan(ctx, subscription.ID, premium.ID, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error upgrading: %v", err)
+	}
+	if upgraded.PlanID != premium.ID || !upgraded.EndDate.Equal(subscription.EndDate) {
+		t.Errorf("Expected the upgrade applied keeping the period, got plan %s until %s", upgraded.PlanID, upgraded.EndDate)
+	}
+	proration := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeProration)
+	if proration == nil || proration.Status != domain.InvoiceStatusPaid || !near(proration.Total, preview.AmountDue) {
+		t.Errorf("Expected a paid proration invoice for %.2f, got %+v", preview.AmountDue, proration)
+	}
+
+	// El siguiente cambio acredita lo pagado por el prorrateo del plan actual
+	next, err := h.subscriptions.PreviewPlanChange(ctx, subscription.ID, business.ID, "")
+	if err != nil {
+		t.Fatalf("Unexpected error previewing the second upgrade: %v", err)
+	}
+	fraction := domain.UnusedFraction(subscription.StartDate, subscription.EndDate, time.Now())
+	if !near(next.Credit, 39.99*fraction) {
+		t.Errorf("Expected the prorated Premium line credited (%.2f), got %.2f", 39.99*fraction, next.Credit)
+	}
+
+	downgraded, err := h.subscriptions.ChangeSubscriptionPlan(ctx, subscription.ID, freePlanID, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error downgrading: %v", err)
+	}
+	if downgraded.PlanID != premium.ID || downgraded.Metadata["scheduled_plan_id"] != freePlanID {
+		t.Errorf("Expected the downgrade scheduled for the period end, got plan %s metadata %v", downgraded.PlanID, downgraded.Metadata)
+	}
+}
+
+func TestNextRenewalProrationIsChargedOnRenewal(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	premium := h.newPlan(t, "Premium", 39.99, domain.PlanIntervalMonth, 0)
+	subscription := h.subscribe(t, demoUserID, proPlanID, time.Now().AddDate(0, 0, -10))
+
+	preview, err := h.subscriptions.PreviewPlanChange(ctx, subscription.ID, premium.ID, domain.ProrationBillingNextRenewal)
+	if err != nil {
+		t.Fatalf("Unexpected error previewing the upgrade: %v", err)
+	}
+	changed, err := h.subscriptions.ChangeSubscriptionPlan(ctx, subscription.ID, premium.ID, domain.ProrationBillingNextRenewal, "")
+	if err != nil {
+		t.Fatalf("Unexpected error upgrading: %v", err)
+	}
+	if changed.PlanID != premium.ID || !near(parseAmount(changed.Metadata["pending_proration_amount"]), preview.AmountDue) {
+		t.Errorf("Expected the upgrade applied with %.2f pending, got plan %s metadata %v", preview.AmountDue, changed.PlanID, changed.Metadata)
+	}
+	if invoice := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeProration); invoice != nil {
+		t.Errorf("Expected nothing charged until the renewal, got %+v", invoice)
+	}
+
+	renewed, err := h.subscriptions.RenewSubscription(ctx, subscription.ID, domain.PeriodEnd(premium, changed.EndDate))
+	if err != nil {
+		t.Fatalf("Unexpected error renewing: %v", err)
+	}
+	if _, ok := renewed.Metadata["pending_proration_amount"]; ok {
+		t.Errorf("Expected the pending proration cleared, got %v", renewed.Metadata)
+	}
+	charge := lastInvoice(t, h, subscription.ID, domain.InvoiceTypeCharge)
+	if charge == nil || !near(charge.Total, 39.99+parseAmount(changed.Metadata["pending_proration_amount"])) {
+		t.Errorf("Expected the renewal to charge the plan plus the pending proration, got %+v", charge)
+	}
+}
+
+// lastInvoice devuelve la última factura del tipo indicado de la suscripción, o nil si no hay
+func lastInvoice(t *testing.T, h *harness, subscriptionID string, invoiceType domain.InvoiceType) *domain.Invoice {
+	t.Helper()
+	invoices, err := h.invoices.GetSubscriptionInvoices(context.Background(), subscriptionID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing invoices: %v", err)
+	}
+	var last *domain.Invoice
+	for _, invoice := range invoices {
+		if invoice.Type == invoiceType && (last == nil || invoice.Sequence > last.Sequence) {
+			last = invoice
+		}
+	}
+	return last
+}
+
+// parseAmount convierte un importe guardado en los metadatos
+func parseAmount(value string) float64 {
+	var amount float64
+	fmt.Sscanf(value, "%f", &amount)
+	return amount
+}
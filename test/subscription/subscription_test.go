@@ -0,0 +1,208 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	couponService "MyMoneyBackend/internal/application/coupon"
+	invoiceService "MyMoneyBackend/internal/application/invoice"
+	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
+	planService "MyMoneyBackend/internal/application/plan"
+	userSubscriptionService "MyMoneyBackend/internal/application/user_subscription"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/domain/ports/app"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/test/testdb"
+)
+
+// Datos iniciales usados en las pruebas
+const (
+	demoUserID   = "d15ab58a-4689-4745-bb27-46ec4757731f"
+	otherUserID  = "00000000-0000-0000-0000-000000000002"
+	freePlanID   = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b11"
+	proPlanID    = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b12"
+	yearlyPlanID = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380b13"
+	usdID        = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+)
+
+// recordingPublisher guarda los eventos publicados
+type recordingPublisher struct {
+	events []domain.NotificationEvent
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _ string, event domain.NotificationEvent, _ map[string]string) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+// countingMetrics cuenta los cambios de suscripción registrados
+type countingMetrics struct {
+	created, cancelled, renewed int
+}
+
+func (m *countingMetrics) TransactionCreated()    {}
+func (m *countingMetrics) LoginSucceeded()        {}
+func (m *countingMetrics) LoginFailed()           {}
+func (m *countingMetrics) SubscriptionCreated()   { m.created++ }
+func (m *countingMetrics) SubscriptionCancelled() { m.cancelled++ }
+func (m *countingMetrics) SubscriptionRenewed()   { m.renewed++ }
+func (m *countingMetrics) SchedulerRun(string)    {}
+
+// faultyUnitOfWork ejecuta las operaciones en la transacción real y, si tiene un error
+// configurado, la revierte devolviéndolo al terminar
+type faultyUnitOfWork struct {
+	app.UnitOfWork
+	err error
+}
+
+func (u *faultyUnitOfWork) Do(ctx context.Context, fn func(repos app.TxRepositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos app.TxRepositories) error {
+		if err := fn(repos); err != nil {
+			return err
+		}
+		return u.err
+	})
+}
+
+// faultyInvoiceRepository no puede guardar facturas mientras tenga un error configurado
+type faultyInvoiceRepository struct {
+	*repository.InvoiceRepository
+	err error
+}
+
+func (r *faultyInvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.InvoiceRepository.Create(ctx, invoice)
+}
+
+func (r *faultyInvoiceRepository) IssuePending(ctx context.Context, pending *domain.PendingInvoice) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.InvoiceRepository.IssuePending(ctx, pending)
+}
+
+// harness reúne el servicio de suscripciones sobre SQLite con la pasarela de pruebas
+type harness struct {
+	subscriptions  *userSubscriptionService.Service
+	plans          *planService.Service
+	coupons        *couponService.Service
+	paymentMethods *paymentMethodService.Service
+	invoices       *invoiceService.Service
+	invoiceRepo    *faultyInvoiceRepository
+	subRepo        *repository.UserSubscriptionRepository
+	planRepo       *repository.PlanRepository
+	published      *recordingPublisher
+	metrics        *countingMetrics
+	uow            *faultyUnitOfWork
+	db             *sql.DB
+}
+
+// newHarness construye los servicios como el contenedor, contando las métricas y guardando las notificaciones
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := testdb.OpenSeeded(t)
+
+	subRepo := repository.NewUserSubscriptionRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db)
+	couponRepo := repository.NewCouponRepository(db)
+	gateway := payment.NewFakeGateway()
+	invoiceRepo := &faultyInvoiceRepository{InvoiceRepository: repository.NewInvoiceRepository(db)}
+	invoices := invoiceService.NewService(invoiceRepo, 0.21)
+	published := &recordingPublisher{}
+	metrics := &countingMetrics{}
+	uow := &faultyUnitOfWork{UnitOfWork: repository.NewUnitOfWork(db)}
+
+	return &harness{
+		subscriptions: userSubscriptionService.NewService(
+			subRepo,
+			planRepo,
+			repository.NewUserRepository(db),
+			paymentMethodRepo,
+			currencyRepo,
+			couponRepo,
+			gateway,
+			invoices,
+			published,
+			uow,
+			metrics,
+		),
+		plans:          planService.NewService(planRepo, currencyRepo, subRepo),
+		coupons:        couponService.NewService(couponRepo, planRepo),
+		paymentMethods: paymentMethodService.NewService(paymentMethodRepo, gateway),
+		invoices:       invoices,
+		invoiceRepo:    invoiceRepo,
+		subRepo:        subRepo,
+		planRepo:       planRepo,
+		published:      published,
+		metrics:        metrics,
+		uow:            uow,
+		db:             db,
+	}
+}
+
+// card crea un método de pago con la tarjeta de prueba indicada y devuelve su ID
+func (h *harness) card(t *testing.T, userID, number string) *string {
+	t.Helper()
+	ctx := context.Background()
+	paymentMethod, err := h.paymentMethods.CreatePaymentMethod(ctx, "Tarjeta", "Tarjeta de pruebas", userID)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the payment method: %v", err)
+	}
+	card := &domain.CardDetails{Number: number, ExpMonth: 12, ExpYear: time.Now().Year() + 2, CVC: "123"}
+	if _, err := h.paymentMethods.AttachCard(ctx, paymentMethod.ID, card); err != nil {
+		t.Fatalf("Unexpected error attaching the card: %v", err)
+	}
+	return &paymentMethod.ID
+}
+
+// subscribe suscribe al usuario al plan con una tarjeta visa a partir de start
+func (h *harness) subscribe(t *testing.T, userID, planID string, start time.Time) *domain.UserSubscription {
+	t.Helper()
+	subscription, err := h.subscriptions.CreateSubscription(
+		context.Background(), userID, planID, start, time.Time{}, h.card(t, userID, payment.TestCardVisa), "", nil,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing to %s: %v", planID, err)
+	}
+	return subscription
+}
+
+// reprice crea una nueva versión del plan con otro precio y el resto de datos sin cambios
+func (h *harness) reprice(t *testing.T, planID string, price float64) *domain.Plan {
+	t.Helper()
+	ctx := context.Background()
+	current, err := h.plans.GetPlanByID(ctx, planID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting plan %s: %v", planID, err)
+	}
+	plan, err := h.plans.UpdatePlan(
+		ctx, current.ID, current.Name, current.Description, price, current.CurrencyID,
+		current.Interval, current.IntervalCount, current.Features, current.TrialDays, current.Entitlements,
+		current.IsActive, current.IsPublic, current.SortOrder,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error creating a new version of %s: %v", planID, err)
+	}
+	return plan
+}
+
+// newPlan crea un plan en dólares con el precio, el intervalo y los días de prueba indicados
+func (h *harness) newPlan(t *testing.T, name string, price float64, interval domain.PlanInterval, trialDays int) *domain.Plan {
+	t.Helper()
+	plan, err := h.plans.CreatePlan(
+		context.Background(), name, "Plan de pruebas", price, usdID, interval, 1, nil, trialDays,
+		domain.Entitlements{}, true, true, 10,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error creating plan %s: %v", name, err)
+	}
+	return plan
+}
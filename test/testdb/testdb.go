@@ -0,0 +1,44 @@
+// Package testdb abre bases de datos SQLite temporales con las migraciones aplicadas, para probar
+// los servicios sobre los repositorios SQL reales.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"MyMoneyBackend/db/migrate"
+	"MyMoneyBackend/db/migrations"
+	"MyMoneyBackend/db/seeds"
+	"MyMoneyBackend/internal/infraestructure/outbound/sqlite"
+)
+
+// Open crea una base de datos SQLite migrada en un directorio temporal; se cierra al terminar la prueba
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error opening SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, migrate.SQLite, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Unexpected error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+	return db
+}
+
+// OpenSeeded crea la base de datos como Open y carga los datos iniciales (monedas, planes y usuarios)
+func OpenSeeded(t *testing.T) *sql.DB {
+	t.Helper()
+	db := Open(t)
+	if _, err := migrate.Seed(context.Background(), db, seeds.SQLite); err != nil {
+		t.Fatalf("Unexpected error seeding: %v", err)
+	}
+	return db
+}
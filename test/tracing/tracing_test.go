@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/tracing"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT id, email\n\t FROM users\n\tWHERE email = $1",
+			want:  "SELECT id, email FROM users WHERE email = $1",
+		},
+		{
+			query: "UPDATE users SET name = 'O''Brien' WHERE id = ?",
+			want:  "UPDATE users SET name = ? WHERE id = ?",
+		},
+		{
+			query: "SELECT * FROM plans WHERE price > 9.99 AND trial_days = 14 LIMIT 10",
+			want:  "SELECT * FROM plans WHERE price > ? AND trial_days = ? LIMIT ?",
+		},
+		{
+			query: "SELECT col1, t2.x FROM t2 WHERE amount = -5",
+			want:  "SELECT col1, t2.x FROM t2 WHERE amount = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tracing.SanitizeSQL(tt.query); got != tt.want {
+			t.Errorf("SanitizeSQL(%q): expected %q, got %q", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestSetupExportsToOTLPCollector(t *testing.T) {
+	var received atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			received.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	shutdown, err := tracing.Setup(context.Background(), domain.TracingConfig{
+		Exporter:     "otlp",
+		OTLPEndpoint: collector.URL,
+		ServiceName:  "mymoney-test",
+		SampleRatio:  1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error setting up tracing: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error flushing spans: %v", err)
+	}
+	if received.Load() == 0 {
+		t.Error("Expected the collector to receive spans on /v1/traces")
+	}
+}
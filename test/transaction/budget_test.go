@@ -0,0 +1,115 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	categoryService "MyMoneyBackend/internal/application/category"
+	paymentMethodService "MyMoneyBackend/internal/application/paymentmethod"
+	transactionService "MyMoneyBackend/internal/application/transaction"
+	"MyMoneyBackend/internal/domain"
+	"MyMoneyBackend/internal/infraestructure/outbound/payment"
+	"MyMoneyBackend/internal/infraestructure/outbound/repository"
+	"MyMoneyBackend/test/testdb"
+)
+
+// Datos iniciales usados en las pruebas
+const (
+	userID = "00000000-0000-0000-0000-000000000002"
+	usdID  = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+	eurID  = "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12"
+)
+
+// publication es un evento publicado con sus datos
+type publication struct {
+	userID string
+	event  domain.NotificationEvent
+	data   map[string]string
+}
+
+// recordingPublisher guarda los eventos publicados
+type recordingPublisher struct {
+	published []publication
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, userID string, event domain.NotificationEvent, data map[string]string) error {
+	p.published = append(p.published, publication{userID: userID, event: event, data: data})
+	return nil
+}
+
+func TestBudgetExceededIsNotifiedOnceWhenCrossingTheLimit(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.OpenSeeded(t)
+	published := &recordingPublisher{}
+
+	categories := categoryService.NewService(repository.NewCategoryRepository(db), nil)
+	paymentMethods := paymentMethodService.NewService(repository.NewPaymentMethodRepository(db), payment.NewFakeGateway())
+	transactions := transactionService.NewService(
+		repository.NewTransactionRepository(db),
+		repository.NewCategoryRepository(db),
+		repository.NewCurrencyRepository(db),
+		nil, published, nil,
+	)
+
+	category, err := categories.CreateCategory(ctx, "Ocio", "", "tag", "#FFFFFF", userID)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the category: %v", err)
+	}
+	if _, err := categories.SetCategoryBudget(ctx, category.ID, &domain.CategoryBudget{Amount: 0, CurrencyID: usdID}); err == nil {
+		t.Error("Expected error setting a budget of zero")
+	}
+	if _, err := categories.SetCategoryBudget(ctx, category.ID, &domain.CategoryBudget{Amount: 100, CurrencyID: usdID}); err != nil {
+		t.Fatalf("Unexpected error setting the budget: %v", err)
+	}
+	paymentMethod, err := paymentMethods.CreatePaymentMethod(ctx, "Efectivo", "", userID)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the payment method: %v", err)
+	}
+
+	month := time.Date(2024, time.May, 10, 12, 0, 0, 0, time.UTC)
+	create := func(amount float64, date time.Time, currencyID string, transactionType domain.TransactionType) {
+		t.Helper()
+		if _, err := transactions.CreateTransaction(
+			ctx, amount, "Cine", date, category.ID, paymentMethod.ID, userID, currencyID, transactionType,
+		); err != nil {
+			t.Fatalf("Unexpected error creating the transaction: %v", err)
+		}
+	}
+
+	create(60, month, usdID, domain.TransactionTypeExpense)
+	create(40, month.AddDate(0, 0, 1), usdID, domain.TransactionTypeExpense) // Justo en el límite
+	create(500, month, eurID, domain.TransactionTypeExpense)
+	create(500, month, usdID, domain.TransactionTypeIncome)
+	create(90, month.AddDate(0, -1, 0), usdID, domain.TransactionTypeExpense)
+	if len(published.published) != 0 {
+		t.Fatalf("Expected no notification within the budget, got %+v", published.published)
+	}
+
+	create(0.5, month.AddDate(0, 0, 2), usdID, domain.TransactionTypeExpense)
+	create(20, month.AddDate(0, 0, 3), usdID, domain.TransactionTypeExpense)
+	if len(published.published) != 1 {
+		t.Fatalf("Expected one notification when crossing the budget, got %+v", published.published)
+	}
+	got := published.published[0]
+	if got.userID != userID || got.event != domain.NotificationEventBudgetExceeded ||
+		got.data["budget_name"] != "Ocio" || got.data["spent"] != "100.50" || got.data["limit"] != "100.00" ||
+		got.data["currency"] != "USD" || got.data["month"] != "2024-05" {
+		t.Errorf("Expected budget_exceeded for May with the spent amount, got %+v", got)
+	}
+
+	// El presupuesto se reinicia cada mes
+	create(120, month.AddDate(0, 1, 0), usdID, domain.TransactionTypeExpense)
+	if len(published.published) != 2 || published.published[1].data["month"] != "2024-06" {
+		t.Errorf("Expected a new notification in June, got %+v", published.published)
+	}
+
+	// Sin presupuesto no se notifica
+	if _, err := categories.SetCategoryBudget(ctx, category.ID, nil); err != nil {
+		t.Fatalf("Unexpected error clearing the budget: %v", err)
+	}
+	create(1000, month.AddDate(0, 2, 0), usdID, domain.TransactionTypeExpense)
+	if len(published.published) != 2 {
+		t.Errorf("Expected no notification without a budget, got %+v", published.published)
+	}
+}